@@ -0,0 +1,380 @@
+// Package ber encodes and decodes SNMPv1 (RFC 1157) and SNMPv2c (RFC 1901,
+// RFC 3416) messages with the Basic Encoding Rules. Varbinds are the
+// library's VarBind and Value, so messages can be rendered, validated and
+// decoded with a Registry as they come off the wire.
+package ber
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Message versions.
+const (
+	Version1  = 0
+	Version2c = 1
+)
+
+// PDUType is the tag of a PDU.
+type PDUType byte
+
+const (
+	GetRequest     PDUType = 0xa0
+	GetNextRequest PDUType = 0xa1
+	Response       PDUType = 0xa2
+	SetRequest     PDUType = 0xa3
+	TrapV1         PDUType = 0xa4
+	GetBulkRequest PDUType = 0xa5
+	InformRequest  PDUType = 0xa6
+	TrapV2         PDUType = 0xa7
+	Report         PDUType = 0xa8
+)
+
+var pduTypeNames = map[PDUType]string{
+	GetRequest:     "GetRequest",
+	GetNextRequest: "GetNextRequest",
+	Response:       "Response",
+	SetRequest:     "SetRequest",
+	TrapV1:         "Trap",
+	GetBulkRequest: "GetBulkRequest",
+	InformRequest:  "InformRequest",
+	TrapV2:         "SNMPv2-Trap",
+	Report:         "Report",
+}
+
+func (t PDUType) String() string {
+	if name, ok := pduTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("PDUType(0x%02x)", byte(t))
+}
+
+// ErrorStatus is the error-status of a Response.
+type ErrorStatus int
+
+const (
+	NoError ErrorStatus = iota
+	TooBig
+	NoSuchName
+	BadValue
+	ReadOnly
+	GenErr
+	NoAccess
+	WrongType
+	WrongLength
+	WrongEncoding
+	WrongValue
+	NoCreation
+	InconsistentValue
+	ResourceUnavailable
+	CommitFailed
+	UndoFailed
+	AuthorizationError
+	NotWritable
+	InconsistentName
+)
+
+var errorStatusNames = [...]string{
+	NoError:             "noError",
+	TooBig:              "tooBig",
+	NoSuchName:          "noSuchName",
+	BadValue:            "badValue",
+	ReadOnly:            "readOnly",
+	GenErr:              "genErr",
+	NoAccess:            "noAccess",
+	WrongType:           "wrongType",
+	WrongLength:         "wrongLength",
+	WrongEncoding:       "wrongEncoding",
+	WrongValue:          "wrongValue",
+	NoCreation:          "noCreation",
+	InconsistentValue:   "inconsistentValue",
+	ResourceUnavailable: "resourceUnavailable",
+	CommitFailed:        "commitFailed",
+	UndoFailed:          "undoFailed",
+	AuthorizationError:  "authorizationError",
+	NotWritable:         "notWritable",
+	InconsistentName:    "inconsistentName",
+}
+
+func (s ErrorStatus) String() string {
+	if s >= 0 && int(s) < len(errorStatusNames) {
+		return errorStatusNames[s]
+	}
+	return "ErrorStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParseErrorStatus parses the RFC 3416 name of an error-status, e.g. the
+// Status of a *mib_parser.SetError.
+func ParseErrorStatus(s string) (ErrorStatus, error) {
+	for status, name := range errorStatusNames {
+		if name == s {
+			return ErrorStatus(status), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown error-status %q", s)
+}
+
+// Message is an SNMPv1 or SNMPv2c message.
+type Message struct {
+	// Version is Version1 or Version2c.
+	Version   int
+	Community string
+	PDU       PDU
+}
+
+// PDU is the protocol data unit of a message.
+type PDU struct {
+	Type      PDUType
+	RequestID int32
+	// ErrorStatus and ErrorIndex are set in responses. ErrorIndex counts
+	// varbinds from 1.
+	ErrorStatus ErrorStatus
+	ErrorIndex  int
+	// NonRepeaters and MaxRepetitions are the parameters of a
+	// GetBulkRequest, encoded in place of the error fields.
+	NonRepeaters   int
+	MaxRepetitions int
+	VarBinds       []mib_parser.VarBind
+	// Trap holds the content of an SNMPv1 Trap-PDU, including its
+	// varbinds; the other fields are unused then. Unmarshal sets its
+	// Community from the message.
+	Trap *mib_parser.TrapV1
+}
+
+// Options configures Marshal.
+type Options struct {
+	// Registry, when set, is used to check the values of SetRequest,
+	// Response, InformRequest, trap and Report PDUs against the SYNTAX of
+	// their objects. Marshal refuses values the object cannot hold, such
+	// as an INTEGER outside its enumeration or a string longer than its
+	// SIZE. Instances of unknown objects are not checked.
+	Registry *mib_parser.Registry
+}
+
+// Marshal encodes msg.
+func Marshal(msg *Message, opts Options) ([]byte, error) {
+	if err := checkVersion(msg.Version, msg.PDU.Type); err != nil {
+		return nil, err
+	}
+	vbs := msg.PDU.VarBinds
+	if msg.PDU.Type == TrapV1 {
+		if msg.PDU.Trap == nil {
+			return nil, fmt.Errorf("ber: Trap PDU without Trap")
+		}
+		vbs = msg.PDU.Trap.VarBinds
+	}
+	if opts.Registry != nil && carriesValues(msg.PDU.Type) {
+		if err := checkSyntax(opts.Registry, vbs); err != nil {
+			return nil, err
+		}
+	}
+	var list []byte
+	for _, vb := range vbs {
+		enc, err := MarshalVarBind(vb)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, enc...)
+	}
+	var pdu []byte
+	switch p := msg.PDU; p.Type {
+	case TrapV1:
+		enterprise, err := marshalOID(p.Trap.Enterprise)
+		if err != nil {
+			return nil, err
+		}
+		addr := p.Trap.AgentAddress.To4()
+		if addr == nil {
+			addr = net.IPv4zero.To4()
+		}
+		pdu = appendTLV(pdu, 0x06, enterprise)
+		pdu = appendTLV(pdu, 0x40, addr)
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.Trap.GenericTrap)))
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.Trap.SpecificTrap)))
+		pdu = appendTLV(pdu, 0x43, encodeUint(uint64(p.Trap.Timestamp)))
+	case GetBulkRequest:
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.RequestID)))
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.NonRepeaters)))
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.MaxRepetitions)))
+	default:
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.RequestID)))
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.ErrorStatus)))
+		pdu = appendTLV(pdu, 0x02, encodeInt(int64(p.ErrorIndex)))
+	}
+	pdu = appendTLV(pdu, 0x30, list)
+	var seq []byte
+	seq = appendTLV(seq, 0x02, encodeInt(int64(msg.Version)))
+	seq = appendTLV(seq, 0x04, []byte(msg.Community))
+	seq = appendTLV(seq, byte(msg.PDU.Type), pdu)
+	return appendTLV(nil, 0x30, seq), nil
+}
+
+// Unmarshal decodes a message. Values are decoded as by UnmarshalValue.
+func Unmarshal(b []byte) (*Message, error) {
+	seq, _, err := readExpected(b, 0x30, "message")
+	if err != nil {
+		return nil, err
+	}
+	var msg Message
+	version, seq, err := readInt(seq, "version")
+	if err != nil {
+		return nil, err
+	}
+	msg.Version = int(version)
+	community, seq, err := readExpected(seq, 0x04, "community")
+	if err != nil {
+		return nil, err
+	}
+	msg.Community = string(community)
+	tag, pdu, _, err := readTLV(seq)
+	if err != nil {
+		return nil, err
+	}
+	msg.PDU.Type = PDUType(tag)
+	if _, ok := pduTypeNames[msg.PDU.Type]; !ok {
+		return nil, fmt.Errorf("ber: unknown PDU tag 0x%02x", tag)
+	}
+	if err := checkVersion(msg.Version, msg.PDU.Type); err != nil {
+		return nil, err
+	}
+	if msg.PDU.Type == TrapV1 {
+		if pdu, err = unmarshalTrap(pdu, &msg); err != nil {
+			return nil, err
+		}
+	} else {
+		var fields [3]int64
+		for i, name := range []string{"request-id", "error-status", "error-index"} {
+			if fields[i], pdu, err = readInt(pdu, name); err != nil {
+				return nil, err
+			}
+		}
+		if fields[0] < math.MinInt32 || fields[0] > math.MaxInt32 {
+			return nil, fmt.Errorf("ber: request-id %d out of range", fields[0])
+		}
+		msg.PDU.RequestID = int32(fields[0])
+		if msg.PDU.Type == GetBulkRequest {
+			msg.PDU.NonRepeaters, msg.PDU.MaxRepetitions = int(fields[1]), int(fields[2])
+		} else {
+			msg.PDU.ErrorStatus, msg.PDU.ErrorIndex = ErrorStatus(fields[1]), int(fields[2])
+		}
+	}
+	vbs, err := unmarshalVarBinds(pdu)
+	if err != nil {
+		return nil, err
+	}
+	if msg.PDU.Type == TrapV1 {
+		msg.PDU.Trap.VarBinds = vbs
+	} else {
+		msg.PDU.VarBinds = vbs
+	}
+	return &msg, nil
+}
+
+func unmarshalTrap(pdu []byte, msg *Message) ([]byte, error) {
+	trap := &mib_parser.TrapV1{Community: msg.Community}
+	enterprise, pdu, err := readExpected(pdu, 0x06, "enterprise")
+	if err != nil {
+		return nil, err
+	}
+	if trap.Enterprise, err = unmarshalOID(enterprise); err != nil {
+		return nil, err
+	}
+	addr, pdu, err := readExpected(pdu, 0x40, "agent-addr")
+	if err != nil {
+		return nil, err
+	}
+	if len(addr) != 4 {
+		return nil, fmt.Errorf("ber: agent-addr of %d octets", len(addr))
+	}
+	trap.AgentAddress = net.IP(append([]byte(nil), addr...))
+	generic, pdu, err := readInt(pdu, "generic-trap")
+	if err != nil {
+		return nil, err
+	}
+	specific, pdu, err := readInt(pdu, "specific-trap")
+	if err != nil {
+		return nil, err
+	}
+	trap.GenericTrap, trap.SpecificTrap = int(generic), int(specific)
+	timestamp, pdu, err := readExpected(pdu, 0x43, "time-stamp")
+	if err != nil {
+		return nil, err
+	}
+	ticks, err := decodeUint(timestamp)
+	if err != nil || ticks > math.MaxUint32 {
+		return nil, fmt.Errorf("ber: invalid time-stamp % x", timestamp)
+	}
+	trap.Timestamp = uint32(ticks)
+	msg.PDU.Trap = trap
+	return pdu, nil
+}
+
+func unmarshalVarBinds(pdu []byte) ([]mib_parser.VarBind, error) {
+	list, _, err := readExpected(pdu, 0x30, "variable-bindings")
+	if err != nil {
+		return nil, err
+	}
+	var vbs []mib_parser.VarBind
+	for len(list) > 0 {
+		var vb, name []byte
+		if vb, list, err = readExpected(list, 0x30, "variable binding"); err != nil {
+			return nil, err
+		}
+		if name, vb, err = readExpected(vb, 0x06, "variable name"); err != nil {
+			return nil, err
+		}
+		oid, err := unmarshalOID(name)
+		if err != nil {
+			return nil, err
+		}
+		v, _, err := UnmarshalValue(vb)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s", err, oid)
+		}
+		vbs = append(vbs, mib_parser.VarBind{OID: oid, Value: v})
+	}
+	return vbs, nil
+}
+
+// checkVersion rejects PDUs the message version does not have.
+func checkVersion(version int, t PDUType) error {
+	switch {
+	case version != Version1 && version != Version2c:
+		return fmt.Errorf("ber: unsupported version %d", version)
+	case version == Version1 && (t == GetBulkRequest || t == InformRequest || t == TrapV2 || t == Report),
+		version == Version2c && t == TrapV1:
+		return fmt.Errorf("ber: %s is not part of SNMP%s", t, map[int]string{Version1: "v1", Version2c: "v2c"}[version])
+	}
+	return nil
+}
+
+func carriesValues(t PDUType) bool {
+	switch t {
+	case GetRequest, GetNextRequest, GetBulkRequest:
+		return false
+	}
+	return true
+}
+
+// checkSyntax validates every value against the object it is an instance
+// of. Exceptions and NULL are not values of an object and pass.
+func checkSyntax(reg *mib_parser.Registry, vbs []mib_parser.VarBind) error {
+	for i, vb := range vbs {
+		switch vb.Value.Type() {
+		case mib_parser.TypeNull, mib_parser.TypeNoSuchObject, mib_parser.TypeNoSuchInstance, mib_parser.TypeEndOfMibView:
+			continue
+		}
+		in, ok := reg.Instance(vb.OID)
+		if !ok || in.Object == nil {
+			continue
+		}
+		if err := reg.ValidateValue(in.Module, in.Object, vb.Value); err != nil {
+			return fmt.Errorf("ber: varbind %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package ber
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+var valueTags = map[mib_parser.ValueType]byte{
+	mib_parser.TypeInteger:          0x02,
+	mib_parser.TypeOctetString:      0x04,
+	mib_parser.TypeNull:             0x05,
+	mib_parser.TypeObjectIdentifier: 0x06,
+	mib_parser.TypeIpAddress:        0x40,
+	mib_parser.TypeCounter32:        0x41,
+	mib_parser.TypeGauge32:          0x42,
+	mib_parser.TypeTimeTicks:        0x43,
+	mib_parser.TypeOpaque:           0x44,
+	mib_parser.TypeCounter64:        0x46,
+	mib_parser.TypeNoSuchObject:     0x80,
+	mib_parser.TypeNoSuchInstance:   0x81,
+	mib_parser.TypeEndOfMibView:     0x82,
+}
+
+var tagTypes = map[byte]mib_parser.ValueType{}
+
+func init() {
+	for typ, tag := range valueTags {
+		tagTypes[tag] = typ
+	}
+}
+
+var errTruncated = errors.New("ber: truncated encoding")
+
+// Tag returns the BER tag values of type t are encoded with, e.g. 0x41
+// for the Counter32 application type.
+func Tag(t mib_parser.ValueType) (byte, bool) {
+	tag, ok := valueTags[t]
+	return tag, ok
+}
+
+// TypeOf returns the value type encoded with tag.
+func TypeOf(tag byte) (mib_parser.ValueType, bool) {
+	t, ok := tagTypes[tag]
+	return t, ok
+}
+
+// MarshalVarBind encodes a VarBind SEQUENCE.
+func MarshalVarBind(vb mib_parser.VarBind) ([]byte, error) {
+	name, err := marshalOID(vb.OID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := MarshalValue(vb.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s", err, vb.OID)
+	}
+	return appendTLV(nil, 0x30, append(appendTLV(nil, 0x06, name), value...)), nil
+}
+
+// MarshalValue encodes v with the tag of its type. Integer types must
+// fit their type's range: 32 bits, signed for INTEGER and unsigned for
+// Counter32, Gauge32 and TimeTicks, and 64 unsigned bits for Counter64.
+func MarshalValue(v mib_parser.Value) ([]byte, error) {
+	tag, ok := valueTags[v.Type()]
+	if !ok {
+		return nil, fmt.Errorf("ber: unsupported value type %s", v.Type())
+	}
+	var content []byte
+	switch v.Type() {
+	case mib_parser.TypeInteger:
+		n, ok := toInt64(v.Data())
+		if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, invalidValue(v)
+		}
+		content = encodeInt(n)
+	case mib_parser.TypeCounter32, mib_parser.TypeGauge32, mib_parser.TypeTimeTicks:
+		n, ok := toUint64(v.Data())
+		if !ok || n > math.MaxUint32 {
+			return nil, invalidValue(v)
+		}
+		content = encodeUint(n)
+	case mib_parser.TypeCounter64:
+		n, ok := toUint64(v.Data())
+		if !ok {
+			return nil, invalidValue(v)
+		}
+		content = encodeUint(n)
+	case mib_parser.TypeOctetString, mib_parser.TypeOpaque:
+		if content, ok = toBytes(v.Data()); !ok {
+			return nil, invalidValue(v)
+		}
+	case mib_parser.TypeIpAddress:
+		if content, ok = toBytes(v.Data()); !ok || len(content) != 4 {
+			return nil, invalidValue(v)
+		}
+	case mib_parser.TypeObjectIdentifier:
+		oid, ok := toOID(v.Data())
+		if !ok {
+			return nil, invalidValue(v)
+		}
+		var err error
+		if content, err = marshalOID(oid); err != nil {
+			return nil, err
+		}
+	}
+	return appendTLV(nil, tag, content), nil
+}
+
+func invalidValue(v mib_parser.Value) error {
+	return fmt.Errorf("ber: invalid %s value %v", v.Type(), v.Data())
+}
+
+// UnmarshalValue decodes the value at the start of b and returns the
+// bytes that follow it. INTEGER values are decoded as int64, Counter32,
+// Gauge32 and TimeTicks as uint32, Counter64 as uint64, OCTET STRING and
+// Opaque as []byte, IpAddress as net.IP and OBJECT IDENTIFIER as mib_parser.OID.
+func UnmarshalValue(b []byte) (mib_parser.Value, []byte, error) {
+	tag, content, rest, err := readTLV(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	typ, ok := tagTypes[tag]
+	if !ok {
+		return nil, nil, fmt.Errorf("ber: unsupported value tag 0x%02x", tag)
+	}
+	var data any
+	switch typ {
+	case mib_parser.TypeInteger:
+		n, err := decodeInt(content)
+		if err != nil || n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, nil, fmt.Errorf("ber: invalid INTEGER % x", content)
+		}
+		data = n
+	case mib_parser.TypeCounter32, mib_parser.TypeGauge32, mib_parser.TypeTimeTicks:
+		n, err := decodeUint(content)
+		if err != nil || n > math.MaxUint32 {
+			return nil, nil, fmt.Errorf("ber: invalid %s % x", typ, content)
+		}
+		data = uint32(n)
+	case mib_parser.TypeCounter64:
+		n, err := decodeUint(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ber: invalid Counter64 % x", content)
+		}
+		data = n
+	case mib_parser.TypeOctetString, mib_parser.TypeOpaque:
+		data = append([]byte(nil), content...)
+	case mib_parser.TypeIpAddress:
+		if len(content) != 4 {
+			return nil, nil, fmt.Errorf("ber: IpAddress of %d octets", len(content))
+		}
+		data = net.IP(append([]byte(nil), content...))
+	case mib_parser.TypeObjectIdentifier:
+		if data, err = unmarshalOID(content); err != nil {
+			return nil, nil, err
+		}
+	default:
+		if len(content) != 0 {
+			return nil, nil, fmt.Errorf("ber: %s with content", typ)
+		}
+	}
+	return mib_parser.NewValue(typ, data), rest, nil
+}
+
+// readTLV splits the first tag-length-value off b.
+func readTLV(b []byte) (tag byte, content, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errTruncated
+	}
+	tag, n, b := b[0], int(b[1]), b[2:]
+	if tag&0x1f == 0x1f {
+		return 0, nil, nil, fmt.Errorf("ber: unsupported multi-octet tag")
+	}
+	if n&0x80 != 0 {
+		k := n & 0x7f
+		if k == 0 || k > 3 || len(b) < k {
+			return 0, nil, nil, fmt.Errorf("ber: unsupported length encoding")
+		}
+		n = 0
+		for _, c := range b[:k] {
+			n = n<<8 | int(c)
+		}
+		b = b[k:]
+	}
+	if n > len(b) {
+		return 0, nil, nil, errTruncated
+	}
+	return tag, b[:n], b[n:], nil
+}
+
+// readExpected is readTLV for a field that must have the given tag.
+func readExpected(b []byte, tag byte, field string) (content, rest []byte, err error) {
+	got, content, rest, err := readTLV(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if got != tag {
+		return nil, nil, fmt.Errorf("ber: %s has tag 0x%02x, want 0x%02x", field, got, tag)
+	}
+	return content, rest, nil
+}
+
+func readInt(b []byte, field string) (int64, []byte, error) {
+	content, rest, err := readExpected(b, 0x02, field)
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := decodeInt(content)
+	if err != nil {
+		return 0, nil, fmt.Errorf("ber: %s: %w", field, err)
+	}
+	return n, rest, nil
+}
+
+func appendTLV(dst []byte, tag byte, content []byte) []byte {
+	dst = append(dst, tag)
+	switch n := len(content); {
+	case n < 0x80:
+		dst = append(dst, byte(n))
+	case n < 0x100:
+		dst = append(dst, 0x81, byte(n))
+	case n < 0x10000:
+		dst = append(dst, 0x82, byte(n>>8), byte(n))
+	default:
+		dst = append(dst, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(dst, content...)
+}
+
+// encodeInt encodes n in the fewest two's complement octets.
+func encodeInt(n int64) []byte {
+	b := []byte{byte(n)}
+	for (n > 0x7f || n < -0x80) && len(b) < 8 {
+		n >>= 8
+		b = append([]byte{byte(n)}, b...)
+	}
+	return b
+}
+
+// encodeUint encodes n as a non-negative INTEGER, with a leading zero
+// octet when its top bit is set.
+func encodeUint(n uint64) []byte {
+	b := []byte{byte(n)}
+	for n > 0xff {
+		n >>= 8
+		b = append([]byte{byte(n)}, b...)
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return b
+}
+
+func decodeInt(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("INTEGER of %d octets", len(b))
+	}
+	n := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		n = n<<8 | int64(c)
+	}
+	return n, nil
+}
+
+// decodeUint decodes an unsigned value. A top bit set without the leading
+// zero octet is read as part of the value, as some agents encode
+// Counter32 values that way.
+func decodeUint(b []byte) (uint64, error) {
+	if len(b) == 9 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("unsigned INTEGER of %d octets", len(b))
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// marshalOID encodes the content octets of an OBJECT IDENTIFIER.
+func marshalOID(oid mib_parser.OID) ([]byte, error) {
+	b, err := oid.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("ber: %w", err)
+	}
+	return b, nil
+}
+
+func unmarshalOID(b []byte) (mib_parser.OID, error) {
+	var oid mib_parser.OID
+	if err := oid.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("ber: %w", err)
+	}
+	return oid, nil
+}
+
+func toInt64(data any) (int64, bool) {
+	switch n := data.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	if u, ok := toUint64(data); ok && u <= math.MaxInt64 {
+		return int64(u), true
+	}
+	return 0, false
+}
+
+func toUint64(data any) (uint64, bool) {
+	switch n := data.(type) {
+	case uint:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case int, int8, int16, int32, int64:
+		if i, _ := toInt64(n); i >= 0 {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+func toBytes(data any) ([]byte, bool) {
+	switch b := data.(type) {
+	case []byte:
+		return b, true
+	case net.IP:
+		if ip4 := b.To4(); ip4 != nil {
+			return ip4, true
+		}
+		return b, true
+	case string:
+		return []byte(b), true
+	}
+	return nil, false
+}
+
+func toOID(data any) (mib_parser.OID, bool) {
+	switch oid := data.(type) {
+	case mib_parser.OID:
+		return oid, true
+	case []int:
+		return oid, true
+	case string:
+		arcs, err := mib_parser.ParseOID(oid)
+		return arcs, err == nil
+	}
+	return nil, false
+}
@@ -0,0 +1,190 @@
+"use strict";
+
+const view = document.getElementById("view");
+const treeRoot = document.getElementById("tree");
+
+async function api(path) {
+  const resp = await fetch("api/" + path);
+  const body = await resp.json();
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k === "onclick") e.onclick = v; else e.setAttribute(k, v);
+  }
+  for (const c of children.flat()) {
+    if (c !== null && c !== undefined) e.append(c);
+  }
+  return e;
+}
+
+function link(ref) {
+  if (!ref.module && !ref.oid) return el("span", { class: "missing", title: "not loaded" }, ref.name);
+  if (!ref.kind) return el("span", {}, ref.name);
+  return el("a", { href: `#/def/${ref.module}/${ref.name}` }, ref.name);
+}
+
+function list(refs) {
+  return refs.flatMap((r, i) => (i ? [", ", link(r)] : [link(r)]));
+}
+
+function fields(pairs) {
+  const dl = el("dl");
+  for (const [k, v] of pairs) {
+    if (v === undefined || v === null || v === "" || (Array.isArray(v) && !v.length)) continue;
+    dl.append(el("dt", {}, k), el("dd", {}, v));
+  }
+  return dl;
+}
+
+function show(...nodes) {
+  view.replaceChildren(...nodes);
+  view.scrollTop = 0;
+}
+
+function fail(err) {
+  show(el("p", { class: "error" }, err.message));
+}
+
+async function showModules() {
+  const mods = await api("modules");
+  const rows = mods.map(m => el("tr", {},
+    el("td", {}, el("a", { href: `#/module/${m.name}` }, m.name)),
+    el("td", {}, m.file),
+    el("td", {}, String(m.definitions))));
+  show(el("h1", {}, "Modules"),
+    el("table", {}, el("tr", {}, el("th", {}, "Module"), el("th", {}, "File"), el("th", {}, "Definitions")), rows));
+}
+
+async function showModule(name) {
+  const m = await api(`modules/${encodeURIComponent(name)}`);
+  const imports = m.imports.map(i => el("li", {},
+    i.loaded ? el("a", { href: `#/module/${i.module}` }, i.module) : el("span", { class: "missing" }, i.module),
+    ": ", i.symbols.join(", ")));
+  const defs = m.definitions.map(d => el("tr", {},
+    el("td", {}, link(d)), el("td", { class: "kind" }, d.kind), el("td", { class: "oid" }, d.oid || "")));
+  const id = m.identity || {};
+  show(el("h1", {}, m.name), el("div", { class: "kind" }, m.file),
+    fields([["Last updated", id.lastUpdated], ["Organization", id.organization], ["Description", id.description],
+      ["Imported by", m.importedBy.flatMap((n, i) => [i ? ", " : "", el("a", { href: `#/module/${n}` }, n)])]]),
+    el("h2", {}, "Imports"), el("ul", {}, imports),
+    el("h2", {}, "Definitions"), el("table", {}, defs));
+}
+
+async function showDefinition(module, name) {
+  const d = await api(`definitions/${encodeURIComponent(module)}/${encodeURIComponent(name)}`);
+  const syn = d.syntax;
+  let syntax = null;
+  if (syn) {
+    const parts = [syn.base];
+    if (syn.conventions) parts.push(" via ", ...list(syn.conventions));
+    syntax = el("div", {}, el("code", {}, syn.text), el("div", { class: "kind" }, ...parts));
+    if (syn.displayHint) syntax.append(el("div", { class: "kind" }, `DISPLAY-HINT "${syn.displayHint}"`));
+    if (syn.enumeration) {
+      syntax.append(el("table", {}, syn.enumeration.map(n => el("tr", {}, el("td", {}, String(n.Value)), el("td", {}, n.Name)))));
+    }
+    const ranges = (rs, pre) => rs ? `${pre}(${rs.map(r => (r.Min === r.Max ? r.Min : `${r.Min}..${r.Max}`)).join(" | ")})` : "";
+    const c = ranges(syn.ranges, "") + ranges(syn.sizes, "SIZE ");
+    if (c) syntax.append(el("div", { class: "kind" }, c));
+  }
+  show(
+    el("h1", {}, d.name, " ", el("span", { class: "kind" }, d.kind)),
+    el("div", {}, el("a", { href: `#/module/${d.module}` }, d.module), d.oid ? el("span", { class: "oid" }, "  " + d.oid) : null),
+    el("div", { class: "kind" }, list(d.path || []).flatMap((p, i) => (i ? [" . ", p] : [p]))),
+    fields([["Syntax", syntax], ["Access", d.access], ["Status", d.status], ["Units", d.units],
+      ["Index", d.index ? list(d.index) : null], ["Augments", d.augments ? link(d.augments) : null],
+      ["Default", d.defval], ["Members", d.members ? list(d.members) : null],
+      ["Description", d.description], ["Reference", d.reference]]),
+    d.source ? el("div", {}, el("h2", {}, `${d.source.file}:${d.source.line}`), el("pre", {}, d.source.text)) : null);
+  if (d.oid) revealTree(d.oid);
+}
+
+async function showSearch(q) {
+  document.getElementById("query").value = q;
+  if (/^\.?\d+(\.\d+)*$/.test(q) || q.includes("::")) {
+    try {
+      const r = await api(`lookup?q=${encodeURIComponent(q)}`);
+      location.hash = `#/def/${r.module}/${r.name}`;
+      return;
+    } catch (e) { /* fall back to a text search */ }
+  }
+  const res = await api(`search?limit=50&q=${encodeURIComponent(q)}`);
+  const facets = Object.entries(res.facets).filter(([, v]) => Object.keys(v).length).map(([f, v]) =>
+    el("div", { class: "kind" }, f + ": ", Object.entries(v).sort((a, b) => b[1] - a[1]).flatMap(([val, n], i) => [
+      i ? ", " : "", el("a", { href: `#/search/${encodeURIComponent(`${q} ${f}:${val.replace(/ /g, "-")}`)}` }, val), ` (${n})`])));
+  const hits = res.hits.map(h => el("div", { class: "hit" },
+    link(h), " ", el("span", { class: "kind" }, `${h.module} ${h.kind} ${h.syntax || ""}`),
+    el("p", {}, (h.description || "").replace(/\s+/g, " ").slice(0, 200))));
+  show(el("h1", {}, `${res.total} matches`), ...facets, el("hr"), ...hits);
+}
+
+// The OID tree is loaded one level at a time.
+async function expand(li, oid) {
+  const node = await api(`tree?oid=${oid}`);
+  const ul = el("ul");
+  for (const c of node.children) ul.append(treeItem(c));
+  li.querySelector("ul")?.remove();
+  li.append(ul);
+  li.dataset.open = "1";
+  li.querySelector(".toggle").textContent = "▾";
+}
+
+function treeItem(c) {
+  const label = el("span", {}, el("span", { class: "toggle" }, c.leaf ? " " : "▸"), `${c.name || ""}(${c.arc})`);
+  const li = el("li", { "data-oid": c.oid }, label);
+  label.onclick = () => {
+    if (c.kind) location.hash = `#/def/${c.module}/${c.name}`;
+    if (c.leaf) return;
+    if (li.dataset.open) {
+      li.querySelector("ul").remove();
+      delete li.dataset.open;
+      li.querySelector(".toggle").textContent = "▸";
+    } else {
+      expand(li, c.oid).catch(fail);
+    }
+  };
+  return li;
+}
+
+async function revealTree(oid) {
+  const arcs = oid.split(".");
+  let parent = treeRoot;
+  for (let n = 1; n <= arcs.length; n++) {
+    const prefix = arcs.slice(0, n).join(".");
+    const li = parent.querySelector(`:scope > ul > li[data-oid="${prefix}"]`);
+    if (!li) return;
+    if (n < arcs.length && !li.dataset.open) await expand(li, prefix);
+    parent = li;
+  }
+  treeRoot.querySelectorAll(".selected").forEach(e => e.classList.remove("selected"));
+  parent.firstChild.classList.add("selected");
+  parent.scrollIntoView({ block: "nearest" });
+}
+
+async function initTree() {
+  const root = await api("tree");
+  treeRoot.replaceChildren(el("ul", {}, root.children.map(treeItem)));
+}
+
+function route() {
+  const parts = location.hash.replace(/^#\/?/, "").split("/").map(decodeURIComponent);
+  let done;
+  switch (parts[0]) {
+    case "def": done = showDefinition(parts[1], parts[2]); break;
+    case "module": done = showModule(parts[1]); break;
+    case "search": done = showSearch(parts.slice(1).join("/")); break;
+    default: done = showModules();
+  }
+  done.catch(fail);
+}
+
+document.getElementById("search").onsubmit = e => {
+  e.preventDefault();
+  const q = document.getElementById("query").value.trim();
+  if (q) location.hash = `#/search/${encodeURIComponent(q)}`;
+};
+window.onhashchange = route;
+initTree().then(route).catch(fail);
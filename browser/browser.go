@@ -0,0 +1,513 @@
+// Package browser is a read-only web UI and JSON API over a set of MIB
+// files: the OID tree, definitions with their resolved syntax and source
+// text, the IMPORTS between modules and a search over descriptors and
+// descriptions. The UI's assets are embedded, so a Server needs nothing but
+// the MIB files.
+package browser
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/search"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// Server serves the browser. Its API is:
+//
+//	GET /api/modules                      every module with its IMPORTS
+//	GET /api/modules/{module}             one module and its definitions
+//	GET /api/definitions/{module}/{name}  one definition
+//	GET /api/tree?oid=1.3.6.1             a node of the OID tree and its children
+//	GET /api/lookup?q=IF-MIB::ifInOctets.3  the definition at a name or OID
+//	GET /api/search?q=...                 search, see search.Index.ServeHTTP
+//
+// Everything else serves the embedded UI.
+type Server struct {
+	reg     *mib_parser.Registry
+	index   *search.Index
+	sources map[string]*source
+	mux     *http.ServeMux
+}
+
+// source is the file a module was parsed from.
+type source struct {
+	path  string
+	lines []string
+}
+
+// Load parses the MIB files and returns a server over them.
+func Load(paths ...string) (*Server, error) {
+	s := &Server{reg: mib_parser.NewRegistry(), sources: map[string]*source{}}
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		m, err := mib_parser.ParseMIB(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := s.reg.Add(m); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		text := strings.ReplaceAll(string(src), "\r\n", "\n")
+		s.sources[m.Name] = &source{path: path, lines: strings.Split(text, "\n")}
+	}
+	s.reg.Resolve()
+	s.index = search.New(s.reg)
+
+	static, err := fs.Sub(assets, "assets")
+	if err != nil {
+		return nil, err
+	}
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /api/modules", s.modules)
+	s.mux.HandleFunc("GET /api/modules/{module}", s.module)
+	s.mux.HandleFunc("GET /api/definitions/{module}/{name}", s.definition)
+	s.mux.HandleFunc("GET /api/tree", s.tree)
+	s.mux.HandleFunc("GET /api/lookup", s.lookup)
+	s.mux.Handle("GET /api/search", s.index)
+	s.mux.HandleFunc("GET /api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such API endpoint")
+	})
+	s.mux.Handle("GET /", http.FileServerFS(static))
+	return s, nil
+}
+
+// Registry returns the modules the server browses.
+func (s *Server) Registry() *mib_parser.Registry {
+	return s.reg
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Ref names a definition, linking to /api/definitions/{Module}/{Name}. An
+// empty Kind means there is nothing to link to: a well-known node such as
+// iso, or, when Module is empty too, a name no loaded module defines.
+type Ref struct {
+	Module string `json:"module,omitempty"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind,omitempty"`
+	OID    string `json:"oid,omitempty"`
+}
+
+// ModuleSummary is one entry of /api/modules.
+type ModuleSummary struct {
+	Name        string   `json:"name"`
+	File        string   `json:"file"`
+	Definitions int      `json:"definitions"`
+	Imports     []Import `json:"imports"`
+}
+
+// Import is one "FROM module" group of IMPORTS.
+type Import struct {
+	Module string `json:"module"`
+	// Loaded reports whether the imported module is being browsed.
+	Loaded  bool     `json:"loaded"`
+	Symbols []string `json:"symbols"`
+}
+
+// Module is the response of /api/modules/{module}.
+type Module struct {
+	ModuleSummary
+	Identity *Identity `json:"identity,omitempty"`
+	// ImportedBy lists the modules that import from this one.
+	ImportedBy  []string `json:"importedBy"`
+	Definitions []Ref    `json:"definitions"`
+}
+
+// Identity is the MODULE-IDENTITY of a module.
+type Identity struct {
+	Name         string                `json:"name"`
+	LastUpdated  string                `json:"lastUpdated,omitempty"`
+	Organization string                `json:"organization,omitempty"`
+	ContactInfo  string                `json:"contactInfo,omitempty"`
+	Description  string                `json:"description,omitempty"`
+	Revisions    []mib_parser.Revision `json:"revisions,omitempty"`
+}
+
+// Definition is the response of /api/definitions/{module}/{name}.
+type Definition struct {
+	Ref
+	Status      string  `json:"status,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Reference   string  `json:"reference,omitempty"`
+	Syntax      *Syntax `json:"syntax,omitempty"`
+	Access      string  `json:"access,omitempty"`
+	Units       string  `json:"units,omitempty"`
+	DefVal      string  `json:"defval,omitempty"`
+	Index       []Ref   `json:"index,omitempty"`
+	Augments    *Ref    `json:"augments,omitempty"`
+	// Members are the OBJECTS or NOTIFICATIONS of notifications and
+	// groups.
+	Members []Ref `json:"members,omitempty"`
+	// Path lists the named ancestors from the root of the OID tree.
+	Path   []Ref    `json:"path,omitempty"`
+	Source *Snippet `json:"source,omitempty"`
+}
+
+// Syntax is a SYNTAX clause resolved through its textual conventions.
+type Syntax struct {
+	Text        string                   `json:"text"`
+	Base        string                   `json:"base"`
+	Conventions []Ref                    `json:"conventions,omitempty"`
+	DisplayHint string                   `json:"displayHint,omitempty"`
+	Enumeration []mib_parser.NamedNumber `json:"enumeration,omitempty"`
+	Ranges      []mib_parser.Range       `json:"ranges,omitempty"`
+	Sizes       []mib_parser.Range       `json:"sizes,omitempty"`
+}
+
+// Snippet is the source text of a definition.
+type Snippet struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// TreeNode is the response of /api/tree.
+type TreeNode struct {
+	Ref
+	Children []TreeChild `json:"children"`
+}
+
+// TreeChild is a child of a TreeNode. Unnamed nodes that only lead to
+// named ones have an empty Name.
+type TreeChild struct {
+	Ref
+	Arc  int  `json:"arc"`
+	Leaf bool `json:"leaf"`
+}
+
+// Lookup is the response of /api/lookup: the deepest definition at or
+// above the requested OID and the sub-identifiers below it.
+type Lookup struct {
+	Ref
+	Suffix string `json:"suffix,omitempty"`
+}
+
+func (s *Server) modules(w http.ResponseWriter, r *http.Request) {
+	out := []ModuleSummary{}
+	for _, m := range s.reg.Modules() {
+		out = append(out, s.summary(m))
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) summary(m *mib_parser.Module) ModuleSummary {
+	sum := ModuleSummary{
+		Name:        m.Name,
+		Definitions: len(m.Objects()) + len(m.TextualConventions),
+		Imports:     []Import{},
+	}
+	if src := s.sources[m.Name]; src != nil {
+		sum.File = filepath.Base(src.path)
+	}
+	for _, imp := range m.Imports {
+		_, loaded := s.reg.Module(imp.Module)
+		sum.Imports = append(sum.Imports, Import{Module: imp.Module, Loaded: loaded, Symbols: imp.Symbols})
+	}
+	return sum
+}
+
+func (s *Server) module(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.reg.Module(r.PathValue("module"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no module "+r.PathValue("module"))
+		return
+	}
+	out := Module{ModuleSummary: s.summary(m), ImportedBy: []string{}, Definitions: []Ref{}}
+	if mi := m.ModuleIdentity; mi != nil {
+		out.Identity = &Identity{
+			Name: mi.Name, LastUpdated: mi.LastUpdated, Organization: mi.Organization,
+			ContactInfo: mi.ContactInfo, Description: mi.Description, Revisions: mi.Revisions,
+		}
+	}
+	for _, other := range s.reg.Modules() {
+		for _, imp := range other.Imports {
+			if imp.Module == m.Name {
+				out.ImportedBy = append(out.ImportedBy, other.Name)
+				break
+			}
+		}
+	}
+	for _, obj := range m.Objects() {
+		out.Definitions = append(out.Definitions, objectRef(obj))
+	}
+	for _, name := range sortedNames(m.TextualConventions) {
+		out.Definitions = append(out.Definitions, Ref{Module: m.Name, Name: name, Kind: string(mib_parser.KindTextualConvention)})
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) definition(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.reg.Module(r.PathValue("module"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no module "+r.PathValue("module"))
+		return
+	}
+	name := r.PathValue("name")
+	var def *Definition
+	var line int
+	if obj, ok := m.Object(name); ok {
+		def, line = s.object(m, obj), obj.ObjectPos().Line
+	} else if tc, ok := m.TextualConventions[name]; ok {
+		def = &Definition{
+			Ref:    Ref{Module: m.Name, Name: tc.Name, Kind: string(mib_parser.KindTextualConvention)},
+			Status: tc.Status, Description: tc.Description, Reference: tc.Reference,
+			Syntax: s.syntax(m, tc.Syntax),
+		}
+		if def.Syntax != nil && tc.DisplayHint != "" {
+			def.Syntax.DisplayHint = tc.DisplayHint
+		}
+		line = tc.Pos.Line
+	} else {
+		writeError(w, http.StatusNotFound, "no definition "+m.Name+"::"+name)
+		return
+	}
+	def.Source = s.snippet(m, line, def.Kind == string(mib_parser.KindTextualConvention))
+	writeJSON(w, def)
+}
+
+func (s *Server) object(m *mib_parser.Module, obj mib_parser.Object) *Definition {
+	def := &Definition{
+		Ref:         objectRef(obj),
+		Status:      obj.ObjectStatus().String(),
+		Description: obj.ObjectDescription(),
+	}
+	for n := len(obj.OIDSlice()) - 1; n > 0; n-- {
+		if sym, suffix, ok := s.reg.SymbolAt(obj.OIDSlice()[:n]); ok && len(suffix) == 0 {
+			def.Path = append([]Ref{s.symbolRef(sym)}, def.Path...)
+		}
+	}
+	switch o := obj.(type) {
+	case *mib_parser.ObjectType:
+		def.Reference, def.Access, def.Units, def.DefVal = o.Reference, o.Access, o.Units, o.DefVal
+		def.Syntax = s.syntax(m, o.Syntax)
+		for _, idx := range o.Index {
+			def.Index = append(def.Index, s.ref(m, idx))
+		}
+		if o.Augments != "" {
+			ref := s.ref(m, o.Augments)
+			def.Augments = &ref
+		}
+	case *mib_parser.ObjectIdentity:
+		def.Reference = o.Reference
+	case *mib_parser.NotificationType:
+		def.Reference = o.Reference
+		def.Members = s.refs(m, o.Objects)
+	case *mib_parser.ObjectGroup:
+		def.Reference = o.Reference
+		def.Members = s.refs(m, o.Objects)
+	case *mib_parser.NotificationGroup:
+		def.Reference = o.Reference
+		def.Members = s.refs(m, o.Notifications)
+	case *mib_parser.ModuleCompliance:
+		def.Reference = o.Reference
+	case *mib_parser.AgentCapabilities:
+		def.Reference = o.Reference
+	}
+	return def
+}
+
+// syntax resolves a SYNTAX clause of m, linking each textual convention
+// to the module defining it.
+func (s *Server) syntax(m *mib_parser.Module, text string) *Syntax {
+	if text == "" {
+		return nil
+	}
+	res := s.reg.ResolveSyntax(m, text)
+	out := &Syntax{
+		Text: text, Base: res.Base, DisplayHint: res.DisplayHint(),
+		Enumeration: res.NamedNumbers, Ranges: res.Ranges, Sizes: res.Sizes,
+	}
+	owner, base := m, mib_parser.ParseSyntax(text).Base
+	for range res.Conventions {
+		tc, next, ok := s.reg.TextualConvention(owner, base)
+		if !ok {
+			break
+		}
+		out.Conventions = append(out.Conventions, Ref{Module: next.Name, Name: tc.Name, Kind: string(mib_parser.KindTextualConvention)})
+		owner, base = next, mib_parser.ParseSyntax(tc.Syntax).Base
+	}
+	return out
+}
+
+func (s *Server) tree(w http.ResponseWriter, r *http.Request) {
+	var oid mib_parser.OID
+	if q := r.URL.Query().Get("oid"); q != "" {
+		var err error
+		if oid, err = mib_parser.ParseOID(q); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	node := TreeNode{Ref: Ref{OID: oid.String()}, Children: []TreeChild{}}
+	if sym, suffix, ok := s.reg.SymbolAt(oid); ok && len(suffix) == 0 {
+		node.Ref = s.symbolRef(sym)
+	}
+	symbols := s.reg.Symbols()
+	i := sort.Search(len(symbols), func(i int) bool { return symbols[i].OID.Compare(oid) > 0 })
+	for ; i < len(symbols) && symbols[i].OID.HasPrefix(oid); i++ {
+		sym := symbols[i]
+		child := oid.Append(sym.OID[len(oid)])
+		if n := len(node.Children); n > 0 && node.Children[n-1].Arc == child[len(child)-1] {
+			if len(sym.OID) > len(child) {
+				node.Children[n-1].Leaf = false
+			}
+			continue
+		}
+		c := TreeChild{Ref: Ref{OID: child.String()}, Arc: child[len(child)-1], Leaf: len(sym.OID) == len(child)}
+		if sym.OID.Equal(child) {
+			c.Ref = s.symbolRef(sym)
+		}
+		node.Children = append(node.Children, c)
+	}
+	writeJSON(w, node)
+}
+
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	oid, err := mib_parser.ParseOID(q)
+	if err != nil {
+		if oid, err = s.reg.ResolveName(q); err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+	sym, suffix, ok := s.reg.SymbolAt(oid)
+	if !ok {
+		writeError(w, http.StatusNotFound, "nothing is defined at "+oid.String())
+		return
+	}
+	writeJSON(w, Lookup{Ref: s.symbolRef(sym), Suffix: suffix.String()})
+}
+
+// symbolRef returns the Ref of a registry symbol; well-known SNMPv2-SMI
+// nodes that no loaded module defines have no Kind.
+func (s *Server) symbolRef(sym mib_parser.Symbol) Ref {
+	if m, ok := s.reg.Module(sym.Module); ok {
+		if obj, ok := m.Object(sym.Name); ok {
+			return objectRef(obj)
+		}
+	}
+	return Ref{Module: sym.Module, Name: sym.Name, OID: sym.OID.String()}
+}
+
+// ref finds the definition name as seen from module m: defined there,
+// imported from a loaded module, or defined anywhere in the registry.
+func (s *Server) ref(m *mib_parser.Module, name string) Ref {
+	if obj, ok := m.Object(name); ok {
+		return objectRef(obj)
+	}
+	for _, imp := range m.Imports {
+		for _, sym := range imp.Symbols {
+			if sym != name {
+				continue
+			}
+			if owner, ok := s.reg.Module(imp.Module); ok {
+				if obj, ok := owner.Object(name); ok {
+					return objectRef(obj)
+				}
+			}
+		}
+	}
+	if sym, ok := s.reg.LookupSymbol("", name); ok {
+		return s.symbolRef(sym)
+	}
+	return Ref{Name: name}
+}
+
+func (s *Server) refs(m *mib_parser.Module, names []string) []Ref {
+	var out []Ref
+	for _, name := range names {
+		out = append(out, s.ref(m, name))
+	}
+	return out
+}
+
+func objectRef(obj mib_parser.Object) Ref {
+	return Ref{Module: obj.ObjectModule(), Name: obj.ObjectName(), Kind: string(obj.ObjectKind()), OID: obj.OIDString()}
+}
+
+// snippet returns the source text of the definition starting at line of
+// m. It runs until the end of the definition's "::=" value or, for textual
+// conventions whose value is the rest of the definition, until the next
+// definition.
+func (s *Server) snippet(m *mib_parser.Module, line int, tc bool) *Snippet {
+	src := s.sources[m.Name]
+	if src == nil || line < 1 || line > len(src.lines) {
+		return nil
+	}
+	end := len(src.lines)
+	for _, obj := range m.Objects() {
+		if l := obj.ObjectPos().Line; l > line && l <= end {
+			end = l - 1
+		}
+	}
+	for _, tc := range m.TextualConventions {
+		if l := tc.Pos.Line; l > line && l <= end {
+			end = l - 1
+		}
+	}
+	if !tc {
+		depth, assigned := 0, false
+		for i := line - 1; i < end; i++ {
+			text := src.lines[i]
+			if !assigned {
+				j := strings.Index(text, "::=")
+				if j < 0 {
+					continue
+				}
+				assigned, text = true, text[j:]
+			}
+			depth += strings.Count(text, "{") - strings.Count(text, "}")
+			if depth <= 0 {
+				end = i + 1
+				break
+			}
+		}
+	}
+	for end > line {
+		text := strings.TrimSpace(src.lines[end-1])
+		if text != "" && text != "END" && !strings.HasPrefix(text, "--") {
+			break
+		}
+		end--
+	}
+	return &Snippet{File: filepath.Base(src.path), Line: line, Text: strings.Join(src.lines[line-1:end], "\n")}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for name := range m {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,710 @@
+package mib_parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ModuleBuilder assembles a Module in Go code, e.g. for authoring private
+// enterprise MIBs. Every Set/Add method validates its definition against the
+// SMIv2 rules and against what has been added so far, and returns an error
+// without changing the module when the definition is rejected. Parents must
+// therefore be added before their children.
+//
+// Build runs the checks that need the complete module (INDEX, OBJECTS and
+// group members must exist) and fills in IMPORTS for the well-known SMIv2
+// symbols the module uses; MIB serialises the result to SMIv2 text.
+type ModuleBuilder struct {
+	mod      *Module
+	imports  []Import
+	imported map[string]string
+	defined  map[string]string
+	types    map[string]bool
+	nodes    map[string]OID
+	children map[string]string
+}
+
+var moduleNameRe = regexp.MustCompile(`^[A-Z][A-Za-z0-9-]*$`)
+
+var validAccess = map[string]bool{
+	"not-accessible":        true,
+	"accessible-for-notify": true,
+	"read-only":             true,
+	"read-write":            true,
+	"read-create":           true,
+}
+
+var validStatus = map[string]bool{
+	"current":    true,
+	"deprecated": true,
+	"obsolete":   true,
+}
+
+// NewModuleBuilder starts a module with the given module name.
+func NewModuleBuilder(name string) (*ModuleBuilder, error) {
+	if !moduleNameRe.MatchString(name) || strings.HasSuffix(name, "-") || strings.Contains(name, "--") {
+		return nil, fmt.Errorf("builder: invalid module name %q", name)
+	}
+	return &ModuleBuilder{
+		mod: &Module{
+			Name:               name,
+			ObjectIdentifiers:  map[string]*ObjectIdentifier{},
+			ObjectsByName:      map[string]*ObjectType{},
+			ObjectIdentities:   map[string]*ObjectIdentity{},
+			TextualConventions: map[string]*TextualConvention{},
+			NotificationTypes:  map[string]*NotificationType{},
+			ObjectGroups:       map[string]*ObjectGroup{},
+			NotificationGroups: map[string]*NotificationGroup{},
+			ModuleCompliances:  map[string]*ModuleCompliance{},
+			AgentCapabilities:  map[string]*AgentCapabilities{},
+		},
+		imported: map[string]string{},
+		defined:  map[string]string{},
+		types:    map[string]bool{},
+		nodes:    map[string]OID{},
+		children: map[string]string{},
+	}, nil
+}
+
+// Import adds "<symbols> FROM <module>" to the IMPORTS clause. Well-known
+// SMIv2 symbols (macros, base types, SNMPv2-TC conventions and SNMPv2-SMI
+// nodes) need not be imported explicitly; Build adds them as needed.
+func (b *ModuleBuilder) Import(module string, symbols ...string) error {
+	if !moduleNameRe.MatchString(module) {
+		return fmt.Errorf("builder: invalid module name %q", module)
+	}
+	if module == b.mod.Name {
+		return fmt.Errorf("builder: module %s cannot import from itself", module)
+	}
+	for _, s := range symbols {
+		if s == "" {
+			return fmt.Errorf("builder: empty symbol imported from %s", module)
+		}
+		if kind, ok := b.defined[s]; ok {
+			return fmt.Errorf("builder: %s is already defined as %s", s, kind)
+		}
+		if from, ok := b.imported[s]; ok && from != module {
+			return fmt.Errorf("builder: %s is already imported from %s", s, from)
+		}
+	}
+	for _, s := range symbols {
+		if _, ok := b.imported[s]; ok {
+			continue
+		}
+		b.imported[s] = module
+		if oid, ok := wellKnownNodes[s]; ok && wellKnownSymbols[s] == module {
+			b.nodes[s] = oid
+		}
+		b.addImport(module, s)
+	}
+	return nil
+}
+
+func (b *ModuleBuilder) addImport(module, symbol string) {
+	for i := range b.imports {
+		if b.imports[i].Module == module {
+			b.imports[i].Symbols = append(b.imports[i].Symbols, symbol)
+			return
+		}
+	}
+	b.imports = append(b.imports, Import{Module: module, Symbols: []string{symbol}})
+}
+
+// SetModuleIdentity sets the MODULE-IDENTITY. LAST-UPDATED and REVISION dates
+// must use the "YYYYMMDDHHMMZ" (or legacy "YYMMDDHHMMZ") format.
+func (b *ModuleBuilder) SetModuleIdentity(mi ModuleIdentity) error {
+	if b.mod.ModuleIdentity != nil {
+		return fmt.Errorf("builder: MODULE-IDENTITY already set to %s", b.mod.ModuleIdentity.Name)
+	}
+	if err := b.checkNew(mi.Name, false); err != nil {
+		return err
+	}
+	if !ValidDate(mi.LastUpdated) {
+		return fmt.Errorf("builder: %s: invalid LAST-UPDATED %q", mi.Name, mi.LastUpdated)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"ORGANIZATION", mi.Organization},
+		{"CONTACT-INFO", mi.ContactInfo},
+		{"DESCRIPTION", mi.Description},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("builder: %s: %s is required", mi.Name, field.name)
+		}
+	}
+	for _, rev := range mi.Revisions {
+		if !ValidDate(rev.Date) {
+			return fmt.Errorf("builder: %s: invalid REVISION date %q", mi.Name, rev.Date)
+		}
+		if strings.TrimSpace(rev.Description) == "" {
+			return fmt.Errorf("builder: %s: REVISION %s needs a DESCRIPTION", mi.Name, rev.Date)
+		}
+	}
+	oid, err := b.place(mi.Name, mi.Assignment)
+	if err != nil {
+		return err
+	}
+	c := mi
+	c.OID = oid
+	c.Revisions = append([]Revision(nil), mi.Revisions...)
+	b.mod.ModuleIdentity = &c
+	b.define(c.Name, "MODULE-IDENTITY", c.Assignment, oid)
+	return nil
+}
+
+// AddNode adds a plain "<name> OBJECT IDENTIFIER ::= { parent subID }" node.
+func (b *ModuleBuilder) AddNode(name, parent string, subID int) error {
+	if err := b.checkNew(name, false); err != nil {
+		return err
+	}
+	a := OIDAssignment{Parent: parent, SubIDs: []int{subID}}
+	oid, err := b.place(name, a)
+	if err != nil {
+		return err
+	}
+	b.mod.ObjectIdentifiers[name] = &ObjectIdentifier{Name: name, OID: oid, Assignment: a}
+	b.define(name, "OBJECT IDENTIFIER", a, oid)
+	return nil
+}
+
+// AddObjectIdentity adds an OBJECT-IDENTITY definition.
+func (b *ModuleBuilder) AddObjectIdentity(o ObjectIdentity) error {
+	if err := b.checkNew(o.Name, false); err != nil {
+		return err
+	}
+	if err := checkStatusDescription(o.Name, o.Status, o.Description); err != nil {
+		return err
+	}
+	oid, err := b.place(o.Name, o.Assignment)
+	if err != nil {
+		return err
+	}
+	c := o
+	c.OID = oid
+	b.mod.ObjectIdentities[c.Name] = &c
+	b.define(c.Name, "OBJECT-IDENTITY", c.Assignment, oid)
+	return nil
+}
+
+// AddTextualConvention adds a TEXTUAL-CONVENTION. Its SYNTAX must be a base
+// type; RFC 2579 does not allow a convention to refine another convention.
+func (b *ModuleBuilder) AddTextualConvention(tc TextualConvention) error {
+	if err := b.checkNew(tc.Name, true); err != nil {
+		return err
+	}
+	if err := checkStatusDescription(tc.Name, tc.Status, tc.Description); err != nil {
+		return err
+	}
+	base, err := b.checkSyntax(tc.Name, tc.Syntax)
+	if err != nil {
+		return err
+	}
+	if _, ok := b.mod.TextualConventions[base]; ok {
+		return fmt.Errorf("builder: %s: SYNTAX must not refer to textual convention %s", tc.Name, base)
+	}
+	c := tc
+	b.mod.TextualConventions[c.Name] = &c
+	b.defined[c.Name] = "TEXTUAL-CONVENTION"
+	b.types[c.Name] = true
+	return nil
+}
+
+// AddObjectType adds an OBJECT-TYPE definition. Index objects and AUGMENTS
+// targets may be added later; Build checks that they exist.
+func (b *ModuleBuilder) AddObjectType(o ObjectType) error {
+	if err := b.checkNew(o.Name, false); err != nil {
+		return err
+	}
+	if _, err := b.checkSyntax(o.Name, o.Syntax); err != nil {
+		return err
+	}
+	if !validAccess[o.Access] {
+		return fmt.Errorf("builder: %s: invalid MAX-ACCESS %q", o.Name, o.Access)
+	}
+	if err := checkStatusDescription(o.Name, o.Status, o.Description); err != nil {
+		return err
+	}
+	if len(o.Index) > 0 && o.Augments != "" {
+		return fmt.Errorf("builder: %s: INDEX and AUGMENTS are mutually exclusive", o.Name)
+	}
+	if o.Implied && len(o.Index) == 0 {
+		return fmt.Errorf("builder: %s: IMPLIED requires an INDEX", o.Name)
+	}
+	oid, err := b.place(o.Name, o.Assignment)
+	if err != nil {
+		return err
+	}
+	c := o
+	c.OID = oid
+	c.Index = append([]string(nil), o.Index...)
+	b.mod.ObjectsByName[c.Name] = &c
+	b.define(c.Name, "OBJECT-TYPE", c.Assignment, oid)
+	return nil
+}
+
+// AddTable adds a conceptual table, its row and the row's columns in one
+// step. Missing pieces are filled in following the usual conventions: the
+// row type is the row name with an upper-case first letter, the table's
+// SYNTAX is "SEQUENCE OF <RowType>", table and row are not-accessible, the
+// row is { table 1 } and the columns are numbered from 1 under the row.
+// Either every definition is added or none is.
+func (b *ModuleBuilder) AddTable(table, row ObjectType, columns ...ObjectType) error {
+	rowType := row.Syntax
+	if rowType == "" {
+		rowType = upperFirst(row.Name)
+	}
+	if table.Syntax == "" {
+		table.Syntax = "SEQUENCE OF " + rowType
+	}
+	if table.Syntax != "SEQUENCE OF "+rowType {
+		return fmt.Errorf("builder: %s: SYNTAX must be SEQUENCE OF %s", table.Name, rowType)
+	}
+	row.Syntax = rowType
+	for _, o := range []*ObjectType{&table, &row} {
+		if o.Access == "" {
+			o.Access = "not-accessible"
+		}
+		if o.Access != "not-accessible" {
+			return fmt.Errorf("builder: %s: tables and rows must be not-accessible", o.Name)
+		}
+	}
+	if len(row.Index) == 0 && row.Augments == "" {
+		return fmt.Errorf("builder: %s: a row needs INDEX or AUGMENTS", row.Name)
+	}
+	if row.Assignment.Parent == "" && len(row.Assignment.SubIDs) == 0 {
+		row.Assignment = OIDAssignment{Parent: table.Name, SubIDs: []int{1}}
+	}
+	if row.Assignment.Parent != table.Name {
+		return fmt.Errorf("builder: %s: a row must be registered under its table %s", row.Name, table.Name)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("builder: %s: a row needs at least one column", row.Name)
+	}
+	if err := b.checkNew(rowType, true); err != nil {
+		return err
+	}
+
+	var added []string
+	rollback := func(err error) error {
+		for _, name := range added {
+			b.remove(name)
+		}
+		return err
+	}
+	b.types[rowType] = true
+	added = append(added, rowType)
+	for _, o := range []ObjectType{table, row} {
+		if err := b.AddObjectType(o); err != nil {
+			return rollback(err)
+		}
+		added = append(added, o.Name)
+	}
+	for i, col := range columns {
+		if col.Assignment.Parent == "" && len(col.Assignment.SubIDs) == 0 {
+			col.Assignment = OIDAssignment{Parent: row.Name, SubIDs: []int{i + 1}}
+		}
+		if col.Assignment.Parent != row.Name {
+			return rollback(fmt.Errorf("builder: %s: a column must be registered under its row %s", col.Name, row.Name))
+		}
+		if col.Access == "not-accessible" && !contains(row.Index, col.Name) {
+			return rollback(fmt.Errorf("builder: %s: only index columns may be not-accessible", col.Name))
+		}
+		if err := b.AddObjectType(col); err != nil {
+			return rollback(err)
+		}
+		added = append(added, col.Name)
+	}
+	return nil
+}
+
+// AddNotificationType adds a NOTIFICATION-TYPE definition.
+func (b *ModuleBuilder) AddNotificationType(n NotificationType) error {
+	if err := b.checkNew(n.Name, false); err != nil {
+		return err
+	}
+	if err := checkStatusDescription(n.Name, n.Status, n.Description); err != nil {
+		return err
+	}
+	oid, err := b.place(n.Name, n.Assignment)
+	if err != nil {
+		return err
+	}
+	c := n
+	c.OID = oid
+	c.Objects = append([]string(nil), n.Objects...)
+	b.mod.NotificationTypes[c.Name] = &c
+	b.define(c.Name, "NOTIFICATION-TYPE", c.Assignment, oid)
+	return nil
+}
+
+// AddObjectGroup adds an OBJECT-GROUP definition.
+func (b *ModuleBuilder) AddObjectGroup(g ObjectGroup) error {
+	if err := b.checkNew(g.Name, false); err != nil {
+		return err
+	}
+	if len(g.Objects) == 0 {
+		return fmt.Errorf("builder: %s: OBJECTS must not be empty", g.Name)
+	}
+	if err := checkStatusDescription(g.Name, g.Status, g.Description); err != nil {
+		return err
+	}
+	oid, err := b.place(g.Name, g.Assignment)
+	if err != nil {
+		return err
+	}
+	c := g
+	c.OID = oid
+	c.Objects = append([]string(nil), g.Objects...)
+	b.mod.ObjectGroups[c.Name] = &c
+	b.define(c.Name, "OBJECT-GROUP", c.Assignment, oid)
+	return nil
+}
+
+// AddNotificationGroup adds a NOTIFICATION-GROUP definition.
+func (b *ModuleBuilder) AddNotificationGroup(g NotificationGroup) error {
+	if err := b.checkNew(g.Name, false); err != nil {
+		return err
+	}
+	if len(g.Notifications) == 0 {
+		return fmt.Errorf("builder: %s: NOTIFICATIONS must not be empty", g.Name)
+	}
+	if err := checkStatusDescription(g.Name, g.Status, g.Description); err != nil {
+		return err
+	}
+	oid, err := b.place(g.Name, g.Assignment)
+	if err != nil {
+		return err
+	}
+	c := g
+	c.OID = oid
+	c.Notifications = append([]string(nil), g.Notifications...)
+	b.mod.NotificationGroups[c.Name] = &c
+	b.define(c.Name, "NOTIFICATION-GROUP", c.Assignment, oid)
+	return nil
+}
+
+// AddModuleCompliance adds a MODULE-COMPLIANCE definition.
+func (b *ModuleBuilder) AddModuleCompliance(mc ModuleCompliance) error {
+	if err := b.checkNew(mc.Name, false); err != nil {
+		return err
+	}
+	if err := checkStatusDescription(mc.Name, mc.Status, mc.Description); err != nil {
+		return err
+	}
+	if len(mc.Modules) == 0 {
+		return fmt.Errorf("builder: %s: at least one MODULE clause is required", mc.Name)
+	}
+	for _, cm := range mc.Modules {
+		for _, o := range cm.Objects {
+			if o.MinAccess != "" && !validAccess[o.MinAccess] {
+				return fmt.Errorf("builder: %s: invalid MIN-ACCESS %q for %s", mc.Name, o.MinAccess, o.Name)
+			}
+		}
+	}
+	oid, err := b.place(mc.Name, mc.Assignment)
+	if err != nil {
+		return err
+	}
+	c := mc
+	c.OID = oid
+	c.Modules = append([]ComplianceModule(nil), mc.Modules...)
+	b.mod.ModuleCompliances[c.Name] = &c
+	b.define(c.Name, "MODULE-COMPLIANCE", c.Assignment, oid)
+	return nil
+}
+
+// Build checks cross references and returns the module with its IMPORTS
+// clause completed. All problems found are reported together.
+func (b *ModuleBuilder) Build() (*Module, error) {
+	var errs []error
+	m := b.mod
+	if m.ModuleIdentity == nil {
+		errs = append(errs, fmt.Errorf("builder: %s has no MODULE-IDENTITY", m.Name))
+	}
+	used := map[string]bool{}
+	use := func(symbol string) {
+		if symbol != "" && !builtinTypes[symbol] {
+			used[symbol] = true
+		}
+	}
+	mustObject := func(owner, name string) {
+		use(name)
+		if _, ok := m.ObjectsByName[name]; !ok && b.imported[name] == "" {
+			errs = append(errs, fmt.Errorf("builder: %s refers to unknown object %s", owner, name))
+		}
+	}
+
+	macros := map[string]bool{}
+	if m.ModuleIdentity != nil {
+		macros["MODULE-IDENTITY"] = true
+		use(m.ModuleIdentity.Assignment.Parent)
+	}
+	for _, n := range m.ObjectIdentifiers {
+		use(n.Assignment.Parent)
+	}
+	for _, o := range m.ObjectIdentities {
+		macros["OBJECT-IDENTITY"] = true
+		use(o.Assignment.Parent)
+	}
+	for _, tc := range m.TextualConventions {
+		macros["TEXTUAL-CONVENTION"] = true
+		use(syntaxBase(tc.Syntax))
+	}
+	for _, o := range m.ObjectsByName {
+		macros["OBJECT-TYPE"] = true
+		use(o.Assignment.Parent)
+		use(syntaxBase(o.Syntax))
+		for _, idx := range o.Index {
+			mustObject(o.Name, idx)
+		}
+		if o.Augments != "" {
+			mustObject(o.Name, o.Augments)
+		}
+	}
+	for _, n := range m.NotificationTypes {
+		macros["NOTIFICATION-TYPE"] = true
+		use(n.Assignment.Parent)
+		for _, obj := range n.Objects {
+			mustObject(n.Name, obj)
+		}
+	}
+	for _, g := range m.ObjectGroups {
+		macros["OBJECT-GROUP"] = true
+		use(g.Assignment.Parent)
+		for _, obj := range g.Objects {
+			mustObject(g.Name, obj)
+		}
+	}
+	for _, g := range m.NotificationGroups {
+		macros["NOTIFICATION-GROUP"] = true
+		use(g.Assignment.Parent)
+		for _, n := range g.Notifications {
+			if _, ok := m.NotificationTypes[n]; !ok && b.imported[n] == "" {
+				errs = append(errs, fmt.Errorf("builder: %s refers to unknown notification %s", g.Name, n))
+			}
+		}
+	}
+	for _, mc := range m.ModuleCompliances {
+		macros["MODULE-COMPLIANCE"] = true
+		use(mc.Assignment.Parent)
+		for _, cm := range mc.Modules {
+			if cm.Module != "" {
+				continue
+			}
+			groups := append([]string(nil), cm.MandatoryGroups...)
+			for _, g := range cm.Groups {
+				groups = append(groups, g.Name)
+			}
+			for _, g := range groups {
+				_, og := m.ObjectGroups[g]
+				_, ng := m.NotificationGroups[g]
+				if !og && !ng {
+					errs = append(errs, fmt.Errorf("builder: %s refers to unknown group %s", mc.Name, g))
+				}
+			}
+			for _, o := range cm.Objects {
+				mustObject(mc.Name, o.Name)
+			}
+		}
+	}
+	for macro := range macros {
+		use(macro)
+	}
+
+	imports := make([]Import, 0, len(b.imports))
+	for _, imp := range b.imports {
+		imports = append(imports, Import{Module: imp.Module, Symbols: append([]string(nil), imp.Symbols...)})
+	}
+	symbols := make([]string, 0, len(used))
+	for s := range used {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	for _, s := range symbols {
+		if _, ok := b.defined[s]; ok || b.types[s] || b.imported[s] != "" {
+			continue
+		}
+		from, ok := wellKnownSymbols[s]
+		if !ok {
+			errs = append(errs, fmt.Errorf("builder: %s is neither defined nor imported", s))
+			continue
+		}
+		found := false
+		for i := range imports {
+			if imports[i].Module == from {
+				imports[i].Symbols = append(imports[i].Symbols, s)
+				found = true
+			}
+		}
+		if !found {
+			imports = append(imports, Import{Module: from, Symbols: []string{s}})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	m.Imports = imports
+	m.Types = nil
+	for _, name := range sortedKeys(m.ObjectsByName) {
+		if rowType, ok := strings.CutPrefix(m.ObjectsByName[name].Syntax, "SEQUENCE OF "); ok && b.types[rowType] {
+			m.Types = append(m.Types, rowType)
+		}
+	}
+	m.bind()
+	return m, nil
+}
+
+// MIB builds the module and serialises it to SMIv2 text with FormatMIB.
+func (b *ModuleBuilder) MIB() ([]byte, error) {
+	m, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return FormatMIB(m)
+}
+
+// checkNew validates a new descriptor (or type name when upper is set) and
+// makes sure it is not yet defined or imported.
+func (b *ModuleBuilder) checkNew(name string, upper bool) error {
+	if err := checkDescriptor(name, upper); err != nil {
+		return err
+	}
+	if kind, ok := b.defined[name]; ok {
+		return fmt.Errorf("builder: %s is already defined as %s", name, kind)
+	}
+	if b.types[name] {
+		return fmt.Errorf("builder: %s is already defined as a type", name)
+	}
+	if from, ok := b.imported[name]; ok {
+		return fmt.Errorf("builder: %s is already imported from %s", name, from)
+	}
+	return nil
+}
+
+// checkDescriptor applies the RFC 2578 section 3.1 rules for descriptors:
+// a letter first (lower-case for values, upper-case for types), then letters,
+// digits and non-consecutive hyphens, at most 64 characters. Hyphens are not
+// allowed in new SMIv2 value descriptors.
+func checkDescriptor(name string, upper bool) error {
+	if name == "" {
+		return fmt.Errorf("builder: empty descriptor")
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("builder: descriptor %s is longer than 64 characters", name)
+	}
+	first := rune(name[0])
+	if upper && !unicode.IsUpper(first) {
+		return fmt.Errorf("builder: type name %s must start with an upper-case letter", name)
+	}
+	if !upper && !unicode.IsLower(first) {
+		return fmt.Errorf("builder: descriptor %s must start with a lower-case letter", name)
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-') {
+			return fmt.Errorf("builder: descriptor %s contains invalid character %q", name, r)
+		}
+	}
+	if !upper && strings.Contains(name, "-") {
+		return fmt.Errorf("builder: descriptor %s must not contain hyphens", name)
+	}
+	if strings.Contains(name, "--") || strings.HasSuffix(name, "-") {
+		return fmt.Errorf("builder: %s has a misplaced hyphen", name)
+	}
+	return nil
+}
+
+func checkStatusDescription(name, status, description string) error {
+	if !validStatus[status] {
+		return fmt.Errorf("builder: %s: invalid STATUS %q", name, status)
+	}
+	if strings.TrimSpace(description) == "" {
+		return fmt.Errorf("builder: %s: DESCRIPTION is required", name)
+	}
+	return nil
+}
+
+// checkSyntax makes sure the base type of a SYNTAX is builtin, well-known,
+// defined in this module or imported, and returns it.
+func (b *ModuleBuilder) checkSyntax(owner, syntax string) (string, error) {
+	base := syntaxBase(syntax)
+	if base == "" {
+		return "", fmt.Errorf("builder: %s: SYNTAX is required", owner)
+	}
+	if builtinTypes[base] || b.types[base] || b.imported[base] != "" {
+		return base, nil
+	}
+	if _, ok := wellKnownSymbols[base]; ok && unicode.IsUpper(rune(base[0])) {
+		return base, nil
+	}
+	return "", fmt.Errorf("builder: %s: unknown SYNTAX type %s", owner, base)
+}
+
+// place validates an OID assignment and returns the resolved OID, or nil when
+// the parent is imported from a module the builder knows nothing about.
+func (b *ModuleBuilder) place(name string, a OIDAssignment) (OID, error) {
+	if a.Parent == "" {
+		return nil, fmt.Errorf("builder: %s: OID value needs a parent", name)
+	}
+	if len(a.SubIDs) != 1 || a.SubIDs[0] < 0 {
+		return nil, fmt.Errorf("builder: %s: OID value must be { parent n } with n >= 0", name)
+	}
+	key := fmt.Sprintf("%s.%d", a.Parent, a.SubIDs[0])
+	if other, ok := b.children[key]; ok {
+		return nil, fmt.Errorf("builder: %s: OID { %s %d } is already assigned to %s", name, a.Parent, a.SubIDs[0], other)
+	}
+	base, ok := b.nodes[a.Parent]
+	if !ok {
+		if base, ok = wellKnownNodes[a.Parent]; !ok {
+			if b.imported[a.Parent] == "" {
+				return nil, fmt.Errorf("builder: %s: unknown parent %s", name, a.Parent)
+			}
+			return nil, nil
+		}
+	}
+	if len(base) == 0 {
+		return nil, nil
+	}
+	return base.Append(a.SubIDs[0]), nil
+}
+
+func (b *ModuleBuilder) define(name, kind string, a OIDAssignment, oid OID) {
+	b.defined[name] = kind
+	b.nodes[name] = oid
+	b.children[fmt.Sprintf("%s.%d", a.Parent, a.SubIDs[0])] = name
+}
+
+// remove undoes an addition made by AddTable.
+func (b *ModuleBuilder) remove(name string) {
+	if o, ok := b.mod.ObjectsByName[name]; ok {
+		delete(b.children, fmt.Sprintf("%s.%d", o.Assignment.Parent, o.Assignment.SubIDs[0]))
+		delete(b.mod.ObjectsByName, name)
+	}
+	delete(b.defined, name)
+	delete(b.nodes, name)
+	delete(b.types, name)
+}
+
+// syntaxBase returns the type name a SYNTAX is built on, e.g. "Integer32"
+// for "Integer32 (1..10)" or "IfEntry" for "SEQUENCE OF IfEntry".
+func syntaxBase(syntax string) string {
+	return strings.TrimPrefix(baseTypeName(syntax), "SEQUENCE OF ")
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package mib_parser
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// cacheMagic starts every cache file; the trailing byte is the format
+// version and is bumped whenever Module changes shape.
+var cacheMagic = []byte("GMPCACHE\x04")
+
+// cacheFile is the gob-encoded body of a cache file.
+type cacheFile struct {
+	Entries []cacheEntry
+}
+
+// cacheEntry is one source file with the module parsed from it. Modules are
+// stored as ParseMIB returned them; cross-module resolution is cheap and is
+// redone on load so that a changed dependency never leaves stale OIDs.
+type cacheEntry struct {
+	Path   string
+	Hash   [sha256.Size]byte
+	Module *Module
+}
+
+// CacheStats reports how LoadCached obtained its modules.
+type CacheStats struct {
+	// Hits counts modules taken from the cache.
+	Hits int
+	// Misses counts modules that were parsed because they were missing
+	// from the cache or their source changed.
+	Misses int
+	// Written reports whether the cache file was (re)written.
+	Written bool
+}
+
+// LoadCached loads the given MIB files into a resolved registry, using the
+// precompiled cache at cachePath for every file whose content hash still
+// matches. Changed or new files are parsed and the cache is rewritten. A
+// missing, unreadable or outdated cache file is not an error; it is simply
+// rebuilt.
+func LoadCached(cachePath string, paths ...string) (*Registry, CacheStats, error) {
+	var stats CacheStats
+	cached := map[string]cacheEntry{}
+	if old, err := readCache(cachePath); err == nil {
+		for _, e := range old.Entries {
+			cached[e.Path] = e
+		}
+	}
+	reg := NewRegistry()
+	var fresh cacheFile
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, stats, err
+		}
+		hash := sha256.Sum256(src)
+		e, ok := cached[path]
+		if ok && e.Hash == hash && e.Module != nil {
+			stats.Hits++
+		} else {
+			stats.Misses++
+			m, err := ParseMIB(src)
+			if err != nil {
+				return nil, stats, fmt.Errorf("%s: %w", path, err)
+			}
+			e = cacheEntry{Path: path, Hash: hash, Module: m}
+		}
+		fresh.Entries = append(fresh.Entries, e)
+	}
+	if stats.Misses > 0 || len(cached) != len(fresh.Entries) {
+		if err := writeCache(cachePath, &fresh); err != nil {
+			return nil, stats, err
+		}
+		stats.Written = true
+	}
+	// The cache is written before resolving, so it keeps the per-file state.
+	for _, e := range fresh.Entries {
+		if err := reg.Add(e.Module); err != nil {
+			return nil, stats, fmt.Errorf("%s: %w", e.Path, err)
+		}
+	}
+	reg.Resolve()
+	return reg, stats, nil
+}
+
+// LoadCachedDir is LoadCached for every regular file in dir.
+func LoadCachedDir(cachePath, dir string) (*Registry, CacheStats, error) {
+	paths, err := mibFiles(dir)
+	if err != nil {
+		return nil, CacheStats{}, err
+	}
+	return LoadCached(cachePath, paths...)
+}
+
+func readCache(path string) (*cacheFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	magic := make([]byte, len(cacheMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, cacheMagic) {
+		return nil, errors.New("cache: unknown format")
+	}
+	var c cacheFile
+	if err := gob.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	for _, e := range c.Entries {
+		if e.Module != nil {
+			e.Module.initMaps()
+		}
+	}
+	return &c, nil
+}
+
+// writeCache replaces the cache file atomically so concurrent readers never
+// see a partial file.
+func writeCache(path string, c *cacheFile) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	if _, err := w.Write(cacheMagic); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gob.NewEncoder(w).Encode(c); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// initMaps replaces nil definition maps with empty ones; gob does not
+// transmit empty maps.
+func (m *Module) initMaps() {
+	if m.ObjectIdentifiers == nil {
+		m.ObjectIdentifiers = map[string]*ObjectIdentifier{}
+	}
+	if m.ObjectsByName == nil {
+		m.ObjectsByName = map[string]*ObjectType{}
+	}
+	if m.ObjectIdentities == nil {
+		m.ObjectIdentities = map[string]*ObjectIdentity{}
+	}
+	if m.TextualConventions == nil {
+		m.TextualConventions = map[string]*TextualConvention{}
+	}
+	if m.NotificationTypes == nil {
+		m.NotificationTypes = map[string]*NotificationType{}
+	}
+	if m.ObjectGroups == nil {
+		m.ObjectGroups = map[string]*ObjectGroup{}
+	}
+	if m.NotificationGroups == nil {
+		m.NotificationGroups = map[string]*NotificationGroup{}
+	}
+	if m.ModuleCompliances == nil {
+		m.ModuleCompliances = map[string]*ModuleCompliance{}
+	}
+	if m.AgentCapabilities == nil {
+		m.AgentCapabilities = map[string]*AgentCapabilities{}
+	}
+}
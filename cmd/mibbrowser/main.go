@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Olian04/go-mib-parser/browser"
+)
+
+func main() {
+	dirs := flag.String("M", os.Getenv("MIBDIRS"), "colon-separated MIB directories (default: $MIBDIRS)")
+	files := flag.String("m", "", "comma-separated MIB files to load in addition to -M")
+	addr := flag.String("http", "localhost:8080", "address to serve the browser on")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibbrowser [-M dirs] [-m files] [-http addr]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	paths, err := load(*dirs, *files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	srv, err := browser.Load(paths...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "mibbrowser: %d modules, serving http://%s/\n", len(srv.Registry().Modules()), *addr)
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// load lists every file of the -M directories and the -m files.
+func load(dirs, files string) ([]string, error) {
+	var paths []string
+	for _, dir := range filepath.SplitList(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, f := range strings.Split(files, ",") {
+		if f != "" {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("mibbrowser: no MIBs loaded; use -M, -m or $MIBDIRS")
+	}
+	return paths, nil
+}
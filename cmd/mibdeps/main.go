@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func main() {
+	dirs := flag.String("M", os.Getenv("MIBDIRS"), "colon-separated MIB directories (default: $MIBDIRS)")
+	files := flag.String("m", "", "comma-separated MIB files to load in addition to -M")
+	format := flag.String("format", "text", "output format: text, dot or json")
+	module := flag.String("module", "", "only report on this module and the modules it requires")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibdeps [-M dirs] [-m files] [-format text|dot|json] [-module name]")
+		fmt.Fprintln(os.Stderr, "The text report exits with status 1 when it finds cycles, missing modules, unused imports")
+		fmt.Fprintln(os.Stderr, "or references that IMPORTS do not account for.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reg, err := load(*dirs, *files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	g := reg.DependencyGraph()
+	imports := reg.CheckImports()
+	if *module != "" {
+		if _, ok := reg.Module(*module); !ok {
+			fmt.Fprintf(os.Stderr, "mibdeps: module %s is not loaded\n", *module)
+			os.Exit(1)
+		}
+		g = subgraph(g, append(g.Requires(*module), *module))
+		var kept []mib_parser.ImportProblem
+		for _, p := range imports {
+			if slices.Contains(g.Modules, p.Module) {
+				kept = append(kept, p)
+			}
+		}
+		imports = kept
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	problems := false
+	switch *format {
+	case "text":
+		problems = report(w, g, imports)
+	case "dot":
+		err = g.WriteDOT(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(g)
+	default:
+		fmt.Fprintf(os.Stderr, "mibdeps: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if problems {
+		os.Exit(1)
+	}
+}
+
+// report prints the load order, the problems of g and the import problems,
+// and reports whether there were any.
+func report(w *bufio.Writer, g *mib_parser.DependencyGraph, imports []mib_parser.ImportProblem) bool {
+	fmt.Fprintln(w, "load order:")
+	for _, m := range g.LoadOrder {
+		fmt.Fprintf(w, "  %s\n", m)
+	}
+	for _, c := range g.Cycles {
+		fmt.Fprintf(w, "cycle: %s\n", strings.Join(c, ", "))
+	}
+	for _, m := range g.Missing {
+		fmt.Fprintf(w, "missing: %s (imported by %s)\n", m.Module, strings.Join(m.ImportedBy, ", "))
+	}
+	unused := g.UnusedImports()
+	for _, u := range unused {
+		fmt.Fprintln(w, u)
+	}
+	for _, p := range imports {
+		fmt.Fprintln(w, p)
+	}
+	return len(g.Cycles) > 0 || len(g.Missing) > 0 || len(unused) > 0 || len(imports) > 0
+}
+
+// subgraph returns g restricted to the edges between the given modules.
+func subgraph(g *mib_parser.DependencyGraph, modules []string) *mib_parser.DependencyGraph {
+	keep := map[string]bool{}
+	for _, m := range modules {
+		keep[m] = true
+	}
+	filter := func(list []string) []string {
+		out := []string{}
+		for _, m := range list {
+			if keep[m] {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+	sub := &mib_parser.DependencyGraph{
+		Modules:   filter(g.Modules),
+		Edges:     []mib_parser.Dependency{},
+		LoadOrder: filter(g.LoadOrder),
+	}
+	for _, e := range g.Edges {
+		if keep[e.From] {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	for _, m := range g.Missing {
+		if keep[m.Module] {
+			m.ImportedBy = filter(m.ImportedBy)
+			sub.Missing = append(sub.Missing, m)
+		}
+	}
+	for _, c := range g.Cycles {
+		if keep[c[0]] {
+			sub.Cycles = append(sub.Cycles, c)
+		}
+	}
+	return sub
+}
+
+// load reads every file of the -M directories and the -m files.
+func load(dirs, files string) (*mib_parser.Registry, error) {
+	var paths []string
+	for _, dir := range filepath.SplitList(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, f := range strings.Split(files, ",") {
+		if f != "" {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("mibdeps: no MIBs loaded; use -M, -m or $MIBDIRS")
+	}
+	reg := mib_parser.NewRegistry()
+	return reg, reg.LoadFiles(paths...)
+}
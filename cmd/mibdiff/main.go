@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func main() {
+	breakingOnly := flag.Bool("breaking", false, "only report breaking changes")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibdiff [-breaking] <old-mib> <new-mib>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	oldMod := mustParse(flag.Arg(0))
+	newMod := mustParse(flag.Arg(1))
+	d := mib_parser.DiffModules(oldMod, newMod)
+	for _, c := range d.Changes {
+		if *breakingOnly && !c.Breaking {
+			continue
+		}
+		fmt.Println(c)
+	}
+	if d.Breaking() {
+		os.Exit(1)
+	}
+}
+
+func mustParse(path string) *mib_parser.Module {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	mod, err := mib_parser.ParseMIB(b)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		os.Exit(2)
+	}
+	return mod
+}
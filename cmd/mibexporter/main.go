@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/snmpexporter"
+)
+
+// lookupFlags collects repeated -lookup flags.
+type lookupFlags []snmpexporter.Lookup
+
+func (l *lookupFlags) String() string { return "" }
+
+func (l *lookupFlags) Set(s string) error {
+	indexes, lookup, ok := strings.Cut(s, "=")
+	if !ok || indexes == "" || lookup == "" {
+		return fmt.Errorf("lookup %q is not index[,index]=object", s)
+	}
+	*l = append(*l, snmpexporter.Lookup{SourceIndexes: strings.Split(indexes, ","), Lookup: lookup})
+	return nil
+}
+
+func main() {
+	name := flag.String("name", "", "exporter module name (default: derived from the first walk root)")
+	walk := flag.String("walk", "", "comma-separated walk roots: descriptors or dotted OIDs")
+	drop := flag.Bool("drop-source-indexes", false, "drop the index labels replaced by lookups")
+	var lookups lookupFlags
+	flag.Var(&lookups, "lookup", "add a label from another object, as index[,index]=object (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibexporter -walk roots [flags] <path-to-mib>...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *walk == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var paths []string
+	seen := map[string]bool{}
+	for _, path := range flag.Args() {
+		if abs, err := filepath.Abs(path); err == nil && !seen[abs] {
+			seen[abs] = true
+			paths = append(paths, path)
+		}
+	}
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadFiles(paths...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for i := range lookups {
+		lookups[i].DropSourceIndexes = *drop
+	}
+	roots := strings.Split(*walk, ",")
+	mod, err := snmpexporter.Generate(reg, snmpexporter.Options{Walk: roots, Lookups: lookups})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	moduleName := *name
+	if moduleName == "" {
+		moduleName = defaultName(reg, roots[0])
+	}
+	if err := snmpexporter.WriteYAML(os.Stdout, map[string]*snmpexporter.Module{moduleName: mod}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// defaultName names the exporter module after the MIB defining root, the
+// way snmp_exporter names its modules (e.g., "if_mib").
+func defaultName(reg *mib_parser.Registry, root string) string {
+	for _, m := range reg.Modules() {
+		if _, ok := m.ObjectsByName[root]; ok {
+			return strings.ToLower(strings.ReplaceAll(m.Name, "-", "_"))
+		}
+	}
+	return "snmp"
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/codegen"
+)
+
+func main() {
+	module := flag.String("module", "", "module to generate (default: the module of the first file)")
+	pkg := flag.String("pkg", "", "Go package name (default: derived from the module name)")
+	out := flag.String("o", "", "output file (default: stdout)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibgen [flags] <path-to-mib> [dependency-mib]...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	name := *module
+	if name == "" {
+		src, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		first, err := mib_parser.ParseMIB(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+			os.Exit(1)
+		}
+		name = first.Name
+	}
+	// The module's own file may also match a dependency glob.
+	var paths []string
+	seen := map[string]bool{}
+	for _, path := range flag.Args() {
+		if abs, err := filepath.Abs(path); err == nil && !seen[abs] {
+			seen[abs] = true
+			paths = append(paths, path)
+		}
+	}
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadFiles(paths...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	mod, ok := reg.Module(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "mibgen: module %s not found\n", name)
+		os.Exit(1)
+	}
+	src, err := codegen.Generate(mod, codegen.Options{Package: *pkg, Registry: reg})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/lint"
+)
+
+func main() {
+	severity := flag.String("severity", "info", "minimum severity to report (info, warning, error)")
+	disable := flag.String("disable", "", "comma-separated rule IDs to disable")
+	suppress := flag.String("suppress", "", "comma-separated rule:descriptor pairs to suppress")
+	listRules := flag.Bool("rules", false, "list the available rules and exit")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: miblint [flags] <path-to-mib>...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *listRules {
+		for _, r := range lint.Rules() {
+			fmt.Printf("%-36s %-8s %s\n", r.ID, r.Severity, r.Description)
+		}
+		return
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	minSeverity, err := lint.ParseSeverity(*severity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var base []lint.Suppression
+	for _, id := range splitList(*disable) {
+		base = append(base, lint.Suppression{Rule: id})
+	}
+	for _, pair := range splitList(*suppress) {
+		rule, name, ok := strings.Cut(pair, ":")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "miblint: -suppress entry %q is not rule:descriptor\n", pair)
+			os.Exit(2)
+		}
+		base = append(base, lint.Suppression{Rule: rule, Name: name})
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		mod, err := mib_parser.ParseMIB(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		opts := lint.Options{
+			MinSeverity: minSeverity,
+			Suppress:    append(append([]lint.Suppression(nil), base...), lint.InlineSuppressions(src)...),
+		}
+		for _, f := range lint.Lint(mod, opts) {
+			fmt.Printf("%s: %s\n", path, f)
+			if f.Severity == lint.Error {
+				failed = true
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
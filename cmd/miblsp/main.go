@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Olian04/go-mib-parser/lsp"
+)
+
+func main() {
+	dirs := flag.String("M", os.Getenv("MIBDIRS"), "colon-separated MIB directories to resolve IMPORTS against (default: $MIBDIRS)")
+	files := flag.String("m", "", "comma-separated MIB files to load in addition to -M")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: miblsp [-M dirs] [-m files]")
+		fmt.Fprintln(os.Stderr, "Speaks the Language Server Protocol on stdin and stdout.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	paths, err := load(*dirs, *files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	srv, err := lsp.New(paths...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := srv.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// load lists every file of the -M directories and the -m files. Unlike the
+// other commands, miblsp runs without any: the workspace may hold every
+// MIB it needs.
+func load(dirs, files string) ([]string, error) {
+	var paths []string
+	for _, dir := range filepath.SplitList(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, f := range strings.Split(files, ",") {
+		if f != "" {
+			paths = append(paths, f)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/search"
+)
+
+func main() {
+	dirs := flag.String("M", os.Getenv("MIBDIRS"), "colon-separated MIB directories (default: $MIBDIRS)")
+	files := flag.String("m", "", "comma-separated MIB files to load in addition to -M")
+	kind := flag.String("kind", "", "comma-separated kinds to keep, e.g. OBJECT-TYPE")
+	module := flag.String("module", "", "comma-separated modules to keep")
+	syntax := flag.String("syntax", "", "comma-separated base syntaxes or textual conventions to keep")
+	access := flag.String("access", "", "comma-separated MAX-ACCESS values to keep")
+	status := flag.String("status", "", "comma-separated statuses to keep")
+	limit := flag.Int("n", 10, "number of hits to print; 0 prints all")
+	asJSON := flag.Bool("json", false, "print the results, including facet counts, as JSON")
+	addr := flag.String("http", "", "serve the search API on this address instead, e.g. :8080")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibsearch [-M dirs] [-m files] [filters] [-n hits] [-json] words...")
+		fmt.Fprintln(os.Stderr, "       mibsearch [-M dirs] [-m files] -http addr")
+		fmt.Fprintln(os.Stderr, "Words may include facet:value filters, e.g. kind:object-type.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *addr == "" && flag.NArg() == 0 && *kind == "" && *module == "" && *syntax == "" && *access == "" && *status == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reg, err := load(*dirs, *files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ix := search.New(reg)
+	if *addr != "" {
+		http.Handle("/search", ix)
+		fmt.Fprintf(os.Stderr, "mibsearch: serving http://%s/search?q=...\n", *addr)
+		if err := http.ListenAndServe(*addr, nil); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	q := search.ParseQuery(strings.Join(flag.Args(), " "))
+	q.Kinds = append(q.Kinds, list(*kind)...)
+	q.Modules = append(q.Modules, list(*module)...)
+	q.Syntaxes = append(q.Syntaxes, list(*syntax)...)
+	q.Access = append(q.Access, list(*access)...)
+	q.Statuses = append(q.Statuses, list(*status)...)
+	q.Limit = *limit
+	res := ix.Search(q)
+
+	w := bufio.NewWriter(os.Stdout)
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(res)
+	} else {
+		for _, h := range res.Hits {
+			fmt.Fprintf(w, "%s::%s\t%s", h.Module, h.Name, h.Kind)
+			if h.Syntax != "" {
+				fmt.Fprintf(w, " %s", h.Syntax)
+			}
+			if h.OID != "" {
+				fmt.Fprintf(w, "\t%s", h.OID)
+			}
+			fmt.Fprintln(w)
+			if d := summary(h.Description); d != "" {
+				fmt.Fprintf(w, "    %s\n", d)
+			}
+		}
+		fmt.Fprintf(w, "%d of %d matches\n", len(res.Hits), res.Total)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// summary returns the first sentence of a description on one line.
+func summary(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i+1]
+	}
+	if len(s) > 100 {
+		s = s[:97] + "..."
+	}
+	return s
+}
+
+func list(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// load reads every file of the -M directories and the -m files.
+func load(dirs, files string) (*mib_parser.Registry, error) {
+	var paths []string
+	for _, dir := range filepath.SplitList(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, f := range strings.Split(files, ",") {
+		if f != "" {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("mibsearch: no MIBs loaded; use -M, -m or $MIBDIRS")
+	}
+	reg := mib_parser.NewRegistry()
+	return reg, reg.LoadFiles(paths...)
+}
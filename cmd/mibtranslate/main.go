@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func main() {
+	dirs := flag.String("M", os.Getenv("MIBDIRS"), "colon-separated MIB directories (default: $MIBDIRS)")
+	files := flag.String("m", "", "comma-separated MIB files to load in addition to -M")
+	numeric := flag.Bool("On", false, "print OIDs numerically")
+	full := flag.Bool("Of", false, "print the full symbolic path")
+	short := flag.Bool("Os", false, "print the descriptor without the module name")
+	describe := flag.Bool("Td", false, "print the definition of each OID")
+	tree := flag.Bool("Tp", false, "print the subtree below each OID (or the whole tree)")
+	search := flag.Bool("TB", false, "treat arguments as regular expressions and list the matching descriptors")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibtranslate [-M dirs] [-m files] [flags] <oid>...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	reg, err := load(*dirs, *files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if *tree && flag.NArg() == 0 {
+		printTree(w, reg, nil)
+		return
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	failed := false
+	for _, arg := range flag.Args() {
+		if *search {
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+				continue
+			}
+			for _, s := range reg.SearchSymbols(re) {
+				fmt.Fprintln(w, format(reg, s.OID, *numeric, *full, *short))
+			}
+			continue
+		}
+		oid, err := parseOID(reg, arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", arg, err)
+			failed = true
+			continue
+		}
+		switch {
+		case *tree:
+			printTree(w, reg, oid)
+		case *describe:
+			printDescription(w, reg, oid)
+		default:
+			fmt.Fprintln(w, format(reg, oid, *numeric, *full, *short))
+		}
+	}
+	if failed {
+		w.Flush()
+		os.Exit(1)
+	}
+}
+
+// load reads every file of the -M directories and the -m files.
+func load(dirs, files string) (*mib_parser.Registry, error) {
+	var paths []string
+	for _, dir := range filepath.SplitList(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, f := range strings.Split(files, ",") {
+		if f != "" {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("mibtranslate: no MIBs loaded; use -M, -m or $MIBDIRS")
+	}
+	reg := mib_parser.NewRegistry()
+	return reg, reg.LoadFiles(paths...)
+}
+
+// parseOID accepts qualified names such as `IF-MIB::ifName."eth0"`, numeric
+// OIDs and full symbolic paths (".iso.org.dod.internet").
+func parseOID(reg *mib_parser.Registry, s string) (mib_parser.OID, error) {
+	oid, err := reg.ResolveName(s)
+	if err == nil || !strings.HasPrefix(s, ".") {
+		return oid, err
+	}
+	oid = nil
+	for i, label := range strings.Split(s[1:], ".") {
+		if n, err := strconv.Atoi(label); err == nil && n >= 0 {
+			oid = append(oid, n)
+			continue
+		}
+		var sym mib_parser.Symbol
+		var ok bool
+		if i == 0 {
+			sym, ok = reg.LookupSymbol("", label)
+		} else {
+			sym, ok = child(reg, oid, label)
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown object identifier %q", label)
+		}
+		oid = sym.OID.Append()
+	}
+	return oid, nil
+}
+
+// child finds the node named label directly below parent.
+func child(reg *mib_parser.Registry, parent mib_parser.OID, label string) (mib_parser.Symbol, bool) {
+	for _, s := range reg.Symbols() {
+		if s.Name == label && len(s.OID) == len(parent)+1 && s.OID.HasPrefix(parent) {
+			return s, true
+		}
+	}
+	return mib_parser.Symbol{}, false
+}
+
+func format(reg *mib_parser.Registry, oid mib_parser.OID, numeric, full, short bool) string {
+	switch {
+	case numeric:
+		return "." + oid.String()
+	case full:
+		var labels []string
+		for n := 1; n <= len(oid); n++ {
+			if s, rest, ok := reg.SymbolAt(oid[:n]); ok && len(rest) == 0 {
+				labels = append(labels, s.Name)
+			} else {
+				labels = append(labels, strconv.Itoa(oid[n-1]))
+			}
+		}
+		return "." + strings.Join(labels, ".")
+	}
+	q := reg.QualifiedNameOf(oid)
+	if s, _, ok := reg.SymbolAt(oid); ok && !short {
+		q.Module = s.Module
+	} else {
+		q.Module = ""
+	}
+	return q.String()
+}
+
+func printDescription(w *bufio.Writer, reg *mib_parser.Registry, oid mib_parser.OID) {
+	s, rest, ok := reg.SymbolAt(oid)
+	if !ok || len(rest) > 0 {
+		fmt.Fprintln(w, format(reg, oid, false, false, false))
+		return
+	}
+	fmt.Fprintln(w, s)
+	m, _ := reg.Module(s.Module)
+	clause := func(keyword, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "  %s\t%s\n", keyword, value)
+		}
+	}
+	text := func(keyword, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "  %s\t\"%s\"\n", keyword, value)
+		}
+	}
+	list := func(keyword string, names []string) {
+		if len(names) > 0 {
+			fmt.Fprintf(w, "  %s\t{ %s }\n", keyword, strings.Join(names, ", "))
+		}
+	}
+	var macro string
+	switch {
+	case m == nil:
+		macro = "OBJECT IDENTIFIER"
+	case m.ObjectsByName[s.Name] != nil:
+		o := m.ObjectsByName[s.Name]
+		fmt.Fprintf(w, "%s OBJECT-TYPE\n  -- FROM\t%s\n", s.Name, s.Module)
+		syn := mib_parser.ParseSyntax(o.Syntax)
+		if tc, _, ok := reg.TextualConvention(m, syn.Base); ok {
+			fmt.Fprintf(w, "  -- TEXTUAL CONVENTION %s\n", tc.Name)
+			text("DISPLAY-HINT", tc.DisplayHint)
+		}
+		clause("SYNTAX", syn.String())
+		text("UNITS", o.Units)
+		clause("MAX-ACCESS", o.Access)
+		clause("STATUS", o.Status)
+		text("DESCRIPTION", o.Description)
+		text("REFERENCE", o.Reference)
+		list("INDEX", o.Index)
+		if o.Augments != "" {
+			list("AUGMENTS", []string{o.Augments})
+		}
+		if o.DefVal != "" {
+			fmt.Fprintf(w, "  DEFVAL\t{ %s }\n", o.DefVal)
+		}
+	case m.NotificationTypes[s.Name] != nil:
+		n := m.NotificationTypes[s.Name]
+		fmt.Fprintf(w, "%s %s\n  -- FROM\t%s\n", s.Name, n.ObjectKind(), s.Module)
+		list("OBJECTS", n.Objects)
+		clause("STATUS", n.Status)
+		text("DESCRIPTION", n.Description)
+		text("REFERENCE", n.Reference)
+	case m.ObjectIdentities[s.Name] != nil:
+		o := m.ObjectIdentities[s.Name]
+		fmt.Fprintf(w, "%s OBJECT-IDENTITY\n  -- FROM\t%s\n", s.Name, s.Module)
+		clause("STATUS", o.Status)
+		text("DESCRIPTION", o.Description)
+		text("REFERENCE", o.Reference)
+	case m.ModuleIdentity != nil && m.ModuleIdentity.Name == s.Name:
+		mi := m.ModuleIdentity
+		fmt.Fprintf(w, "%s MODULE-IDENTITY\n  -- FROM\t%s\n", s.Name, s.Module)
+		text("LAST-UPDATED", mi.LastUpdated)
+		text("ORGANIZATION", mi.Organization)
+		text("CONTACT-INFO", mi.ContactInfo)
+		text("DESCRIPTION", mi.Description)
+	case m.ObjectGroups[s.Name] != nil:
+		g := m.ObjectGroups[s.Name]
+		fmt.Fprintf(w, "%s OBJECT-GROUP\n  -- FROM\t%s\n", s.Name, s.Module)
+		list("OBJECTS", g.Objects)
+		clause("STATUS", g.Status)
+		text("DESCRIPTION", g.Description)
+	case m.NotificationGroups[s.Name] != nil:
+		g := m.NotificationGroups[s.Name]
+		fmt.Fprintf(w, "%s NOTIFICATION-GROUP\n  -- FROM\t%s\n", s.Name, s.Module)
+		list("NOTIFICATIONS", g.Notifications)
+		clause("STATUS", g.Status)
+		text("DESCRIPTION", g.Description)
+	case m.ModuleCompliances[s.Name] != nil:
+		c := m.ModuleCompliances[s.Name]
+		fmt.Fprintf(w, "%s MODULE-COMPLIANCE\n  -- FROM\t%s\n", s.Name, s.Module)
+		clause("STATUS", c.Status)
+		text("DESCRIPTION", c.Description)
+	case m.AgentCapabilities[s.Name] != nil:
+		c := m.AgentCapabilities[s.Name]
+		fmt.Fprintf(w, "%s AGENT-CAPABILITIES\n  -- FROM\t%s\n", s.Name, s.Module)
+		text("PRODUCT-RELEASE", c.ProductRelease)
+		clause("STATUS", c.Status)
+		text("DESCRIPTION", c.Description)
+	default:
+		macro = "OBJECT IDENTIFIER"
+	}
+	if macro != "" {
+		fmt.Fprintf(w, "%s %s\n  -- FROM\t%s\n", s.Name, macro, s.Module)
+	}
+	var path []string
+	for n := 1; n < len(oid); n++ {
+		if p, rest, ok := reg.SymbolAt(oid[:n]); ok && len(rest) == 0 {
+			path = append(path, fmt.Sprintf("%s(%d)", p.Name, oid[n-1]))
+		} else {
+			path = append(path, strconv.Itoa(oid[n-1]))
+		}
+	}
+	path = append(path, strconv.Itoa(oid[len(oid)-1]))
+	fmt.Fprintf(w, "::= { %s }\n", strings.Join(path, " "))
+}
+
+// printTree prints the named nodes below root in the layout of
+// snmptranslate -Tp. A nil root prints the whole tree.
+func printTree(w *bufio.Writer, reg *mib_parser.Registry, root mib_parser.OID) {
+	children := map[string][]mib_parser.Symbol{}
+	seen := map[string]bool{}
+	for _, s := range reg.Symbols() {
+		key := s.OID.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		parent := s.OID.Parent().String()
+		children[parent] = append(children[parent], s)
+	}
+	if root == nil {
+		roots := children[""]
+		for i, s := range roots {
+			printNode(w, reg, children, s, "", i == len(roots)-1)
+		}
+		return
+	}
+	s, rest, ok := reg.SymbolAt(root)
+	if !ok || len(rest) > 0 {
+		return
+	}
+	printNode(w, reg, children, s, "", true)
+}
+
+// printNode prints s and its descendants. prefix continues the vertical
+// lines of s's ancestors; last reports whether s is its parent's last child.
+func printNode(w *bufio.Writer, reg *mib_parser.Registry, children map[string][]mib_parser.Symbol, s mib_parser.Symbol, prefix string, last bool) {
+	arc := s.OID[len(s.OID)-1]
+	kids := children[s.OID.String()]
+	inner := prefix + "|  "
+	if last {
+		inner = prefix + "   "
+	}
+	detail := inner + "   "
+	if len(kids) > 0 {
+		detail = inner + "|  "
+	}
+	var o *mib_parser.ObjectType
+	if m, ok := reg.Module(s.Module); ok {
+		o = m.ObjectsByName[s.Name]
+	}
+	switch {
+	case o != nil && len(kids) == 0 && len(o.Index) == 0 && o.Augments == "" && !strings.HasPrefix(o.Syntax, "SEQUENCE"):
+		syn := mib_parser.ParseSyntax(o.Syntax)
+		fmt.Fprintf(w, "%s+-- %s %-9s %s(%d)\n", prefix, accessCode(o.Access), syn.Base, s.Name, arc)
+		if len(syn.NamedNumbers) > 0 {
+			var values []string
+			for _, n := range syn.NamedNumbers {
+				values = append(values, fmt.Sprintf("%s(%d)", n.Name, n.Value))
+			}
+			fmt.Fprintf(w, "%s      Values: %s\n", detail, strings.Join(values, ", "))
+		}
+	default:
+		fmt.Fprintf(w, "%s+--%s(%d)\n", prefix, s.Name, arc)
+		if o != nil && len(o.Index) > 0 {
+			fmt.Fprintf(w, "%sIndex: %s\n", detail, strings.Join(o.Index, ", "))
+		}
+		if o != nil && o.Augments != "" {
+			fmt.Fprintf(w, "%sAugments: %s\n", detail, o.Augments)
+		}
+	}
+	for i, c := range kids {
+		fmt.Fprintf(w, "%s|\n", inner)
+		printNode(w, reg, children, c, inner, i == len(kids)-1)
+	}
+}
+
+// accessCode abbreviates MAX-ACCESS the way snmptranslate -Tp does.
+func accessCode(access string) string {
+	switch access {
+	case "read-only":
+		return "-R--"
+	case "read-write":
+		return "-RW-"
+	case "read-create":
+		return "CR--"
+	case "write-only":
+		return "--W-"
+	case "accessible-for-notify":
+		return "---N"
+	}
+	return "----"
+}
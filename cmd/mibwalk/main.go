@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/walk"
+)
+
+func main() {
+	dirs := flag.String("M", os.Getenv("MIBDIRS"), "colon-separated MIB directories (default: $MIBDIRS)")
+	files := flag.String("m", "", "comma-separated MIB files to load in addition to -M")
+	format := flag.String("format", "json", "output format: json or csv")
+	table := flag.String("table", "", "with -format csv, print this table with one line per row")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: mibwalk [-M dirs] [-m files] [-format json|csv] [-table name] [dump]")
+		fmt.Fprintln(os.Stderr, "Reads `snmpwalk -On` output or an snmprec file (default: stdin).")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 || *format != "json" && *format != "csv" || *table != "" && *format != "csv" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reg, err := load(*dirs, *files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var in io.Reader = os.Stdin
+	if flag.NArg() == 1 && flag.Arg(0) != "-" {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	vbs, err := walk.Parse(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	rep := walk.Interpret(reg, vbs)
+
+	w := bufio.NewWriter(os.Stdout)
+	switch {
+	case *table != "":
+		t, ok := rep.Table(*table)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "mibwalk: the dump has no rows of table %s\n", *table)
+			os.Exit(1)
+		}
+		err = t.WriteCSV(w)
+	case *format == "csv":
+		err = rep.WriteCSV(w)
+	default:
+		err = rep.WriteJSON(w)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// load reads every file of the -M directories and the -m files.
+func load(dirs, files string) (*mib_parser.Registry, error) {
+	var paths []string
+	for _, dir := range filepath.SplitList(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	for _, f := range strings.Split(files, ",") {
+		if f != "" {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("mibwalk: no MIBs loaded; use -M, -m or $MIBDIRS")
+	}
+	reg := mib_parser.NewRegistry()
+	return reg, reg.LoadFiles(paths...)
+}
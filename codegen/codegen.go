@@ -0,0 +1,675 @@
+// Package codegen turns a parsed MIB module into Go source: typed OID
+// constants for every node, enum types for INTEGER enumerations, flag types
+// for BITS, and a row struct with a varbind decoder for every table.
+//
+// The generated file only depends on the standard library.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Options configures Generate.
+type Options struct {
+	// Package is the Go package name of the generated file. It defaults to
+	// PackageName of the module name.
+	Package string
+	// Registry, when set, resolves textual conventions imported from other
+	// modules, so that e.g. a DisplayString column becomes a Go string.
+	// Without it, columns of imported types are decoded as any.
+	Registry *mib_parser.Registry
+}
+
+// PackageName derives a Go package name from a module name, e.g. "IF-MIB"
+// becomes "ifmib".
+func PackageName(module string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(module) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "mib" + name
+	}
+	return name
+}
+
+// Generate returns the gofmt'ed Go source for module m.
+func Generate(m *mib_parser.Module, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = PackageName(m.Name)
+	}
+	g := &generator{
+		mod:     m,
+		reg:     opts.Registry,
+		enums:   map[string]*enumType{},
+		helpers: map[string]bool{},
+		imports: map[string]bool{},
+	}
+	g.collectTables()
+	for _, name := range sortedKeys(m.TextualConventions) {
+		g.resolve(m, name, 0)
+	}
+	for _, name := range sortedKeys(m.ObjectsByName) {
+		g.objectType(m.ObjectsByName[name])
+	}
+
+	var body bytes.Buffer
+	g.writeOIDs(&body)
+	g.writeEnums(&body)
+	g.writeTables(&body)
+	g.writeHelpers(&body)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by mibgen from %s. DO NOT EDIT.\n\n", m.Name)
+	fmt.Fprintf(&out, "// Package %s holds the OIDs, enumerations and tables of %s.\n", opts.Package, m.Name)
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+	if len(g.imports) > 0 {
+		out.WriteString("import (\n")
+		for _, imp := range sortedKeys(g.imports) {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: %s: %w", m.Name, err)
+	}
+	return src, nil
+}
+
+// kind is the Go representation chosen for a SYNTAX.
+type kind int
+
+const (
+	kindAny kind = iota
+	kindInt32
+	kindUint32
+	kindUint64
+	kindBytes
+	kindString
+	kindOID
+	kindIP
+	kindEnum
+	kindBits
+)
+
+// goType is a resolved SYNTAX.
+type goType struct {
+	kind kind
+	// name is the Go type for enum and bits kinds.
+	name string
+	// size is the fixed length of an OCTET STRING, or -1.
+	size int
+}
+
+// expr returns the Go type expression of t.
+func (t goType) expr() string {
+	switch t.kind {
+	case kindInt32:
+		return "int32"
+	case kindUint32:
+		return "uint32"
+	case kindUint64:
+		return "uint64"
+	case kindBytes:
+		return "[]byte"
+	case kindString:
+		return "string"
+	case kindOID:
+		return "OID"
+	case kindIP:
+		return "net.IP"
+	case kindEnum, kindBits:
+		return t.name
+	}
+	return "any"
+}
+
+// enumType is a generated enumeration or BITS type.
+type enumType struct {
+	name   string
+	source string
+	bits   bool
+	values []mib_parser.NamedNumber
+}
+
+// table is a conceptual table with its row and columns.
+type table struct {
+	table   *mib_parser.ObjectType
+	row     *mib_parser.ObjectType
+	columns []*mib_parser.ObjectType
+	// index lists the INDEX objects, following AUGMENTS.
+	index []*mib_parser.ObjectType
+	// implied reports whether the last index is IMPLIED.
+	implied bool
+}
+
+type generator struct {
+	mod     *mib_parser.Module
+	reg     *mib_parser.Registry
+	tables  []*table
+	enums   map[string]*enumType
+	helpers map[string]bool
+	imports map[string]bool
+}
+
+// builtinKinds maps the SMI base types to their Go representation.
+var builtinKinds = map[string]kind{
+	"INTEGER":           kindInt32,
+	"Integer32":         kindInt32,
+	"Unsigned32":        kindUint32,
+	"Gauge32":           kindUint32,
+	"Counter32":         kindUint32,
+	"TimeTicks":         kindUint32,
+	"Gauge":             kindUint32,
+	"Counter":           kindUint32,
+	"Counter64":         kindUint64,
+	"OCTET STRING":      kindBytes,
+	"Opaque":            kindBytes,
+	"OBJECT IDENTIFIER": kindOID,
+	"IpAddress":         kindIP,
+	"NetworkAddress":    kindIP,
+}
+
+// objectType resolves the syntax of an OBJECT-TYPE. Inline enumerations and
+// BITS get a type named after the object.
+func (g *generator) objectType(o *mib_parser.ObjectType) goType {
+	syn := mib_parser.ParseSyntax(o.Syntax)
+	if len(syn.NamedNumbers) > 0 {
+		return g.enumFor(goName(o.Name), o.Name, syn)
+	}
+	t := g.resolve(g.mod, syn.Base, 0)
+	if t.kind == kindBytes || t.kind == kindString {
+		if n, ok := fixedSize(syn); ok {
+			t.size = n
+		}
+	}
+	return t
+}
+
+// resolve maps a type name visible in module m to a Go type, following
+// textual conventions defined in m or imported from a registry module.
+func (g *generator) resolve(m *mib_parser.Module, name string, depth int) goType {
+	if k, ok := builtinKinds[name]; ok {
+		return goType{kind: k, size: -1}
+	}
+	tc, owner, ok := g.reg.TextualConvention(m, name)
+	if !ok || depth > 8 {
+		return goType{kind: kindAny, size: -1}
+	}
+	syn := mib_parser.ParseSyntax(tc.Syntax)
+	if len(syn.NamedNumbers) > 0 {
+		return g.enumFor(goName(tc.Name), tc.Name, syn)
+	}
+	t := g.resolve(owner, syn.Base, depth+1)
+	if t.kind == kindBytes && textHint(tc.DisplayHint) {
+		t.kind = kindString
+	}
+	if t.kind == kindBytes || t.kind == kindString {
+		if n, ok := fixedSize(syn); ok {
+			t.size = n
+		}
+	}
+	return t
+}
+
+// enumFor registers the enumeration or BITS type name.
+func (g *generator) enumFor(name, source string, syn mib_parser.Syntax) goType {
+	bits := syn.Base == "BITS"
+	if bits {
+		for _, n := range syn.NamedNumbers {
+			if n.Value < 0 || n.Value > 63 {
+				return goType{kind: kindBytes, size: -1}
+			}
+		}
+	}
+	if _, ok := g.enums[name]; !ok {
+		g.enums[name] = &enumType{name: name, source: source, bits: bits, values: syn.NamedNumbers}
+	}
+	if bits {
+		return goType{kind: kindBits, name: name, size: -1}
+	}
+	return goType{kind: kindEnum, name: name, size: -1}
+}
+
+// textHint reports whether a DISPLAY-HINT renders the octets as text, as
+// DisplayString's "255a" and SnmpAdminString's "255t" do.
+func textHint(hint string) bool {
+	digits := strings.TrimLeft(hint, "0123456789")
+	return len(digits) < len(hint) && (digits == "a" || digits == "t")
+}
+
+// fixedSize returns the length of a SIZE constraint with a single value.
+func fixedSize(syn mib_parser.Syntax) (int, bool) {
+	if len(syn.Sizes) == 1 && syn.Sizes[0].Min == syn.Sizes[0].Max {
+		return int(syn.Sizes[0].Min), true
+	}
+	return 0, false
+}
+
+func (g *generator) collectTables() {
+	m := g.mod
+	for _, name := range sortedKeys(m.ObjectsByName) {
+		tbl := m.ObjectsByName[name]
+		if !strings.HasPrefix(mib_parser.ParseSyntax(tbl.Syntax).Base, "SEQUENCE OF ") {
+			continue
+		}
+		var row *mib_parser.ObjectType
+		for _, o := range m.ObjectsByName {
+			if o.Assignment.Parent == tbl.Name && (len(o.Index) > 0 || o.Augments != "") {
+				row = o
+			}
+		}
+		if row == nil || len(row.OID) == 0 {
+			continue
+		}
+		t := &table{table: tbl, row: row}
+		for _, o := range m.ObjectsByName {
+			if o.Assignment.Parent == row.Name && len(o.OID) == len(row.OID)+1 {
+				t.columns = append(t.columns, o)
+			}
+		}
+		sort.Slice(t.columns, func(i, j int) bool {
+			return t.columns[i].OID[len(row.OID)] < t.columns[j].OID[len(row.OID)]
+		})
+		indexRow := row
+		if row.Augments != "" {
+			indexRow = g.object(row.Augments)
+		}
+		if indexRow != nil {
+			t.implied = indexRow.Implied
+			for _, name := range indexRow.Index {
+				t.index = append(t.index, g.object(name))
+			}
+		}
+		g.tables = append(g.tables, t)
+	}
+}
+
+// object finds an OBJECT-TYPE in the module or, through the registry, in
+// any loaded module. It returns nil when the object is unknown.
+func (g *generator) object(name string) *mib_parser.ObjectType {
+	if o, ok := g.mod.ObjectsByName[name]; ok {
+		return o
+	}
+	if g.reg != nil {
+		for _, m := range g.reg.Modules() {
+			if o, ok := m.ObjectsByName[name]; ok {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// oidDef is one node for which an OID constant is generated.
+type oidDef struct {
+	name string
+	oid  mib_parser.OID
+}
+
+func (g *generator) writeOIDs(w *bytes.Buffer) {
+	m := g.mod
+	var defs []oidDef
+	add := func(name string, oid mib_parser.OID) {
+		if len(oid) > 0 {
+			defs = append(defs, oidDef{name, oid})
+		}
+	}
+	if m.ModuleIdentity != nil {
+		add(m.ModuleIdentity.Name, m.ModuleIdentity.OID)
+	}
+	for name, n := range m.ObjectIdentifiers {
+		add(name, n.OID)
+	}
+	for name, o := range m.ObjectIdentities {
+		add(name, o.OID)
+	}
+	for name, o := range m.ObjectsByName {
+		add(name, o.OID)
+	}
+	for name, n := range m.NotificationTypes {
+		add(name, n.OID)
+	}
+	for name, grp := range m.ObjectGroups {
+		add(name, grp.OID)
+	}
+	for name, grp := range m.NotificationGroups {
+		add(name, grp.OID)
+	}
+	for name, mc := range m.ModuleCompliances {
+		add(name, mc.OID)
+	}
+	for name, ac := range m.AgentCapabilities {
+		add(name, ac.OID)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if c := defs[i].oid.Compare(defs[j].oid); c != 0 {
+			return c < 0
+		}
+		return defs[i].name < defs[j].name
+	})
+
+	w.WriteString("// OID is a dotted numeric object identifier without a leading dot.\n")
+	w.WriteString("type OID string\n\n")
+	w.WriteString("func (o OID) String() string { return string(o) }\n\n")
+	if len(defs) == 0 {
+		return
+	}
+	w.WriteString("// Object identifiers of the module's definitions.\nconst (\n")
+	for _, d := range defs {
+		fmt.Fprintf(w, "\t%sOID OID = %q // %s\n", goName(d.name), d.oid.String(), d.name)
+	}
+	w.WriteString(")\n\n")
+}
+
+func (g *generator) writeEnums(w *bytes.Buffer) {
+	for _, name := range sortedKeys(g.enums) {
+		e := g.enums[name]
+		if e.bits {
+			g.writeBits(w, e)
+		} else {
+			g.writeEnum(w, e)
+		}
+	}
+}
+
+func (g *generator) writeEnum(w *bytes.Buffer, e *enumType) {
+	g.imports["fmt"] = true
+	fmt.Fprintf(w, "// %s is the enumeration of %s.\n", e.name, e.source)
+	fmt.Fprintf(w, "type %s int32\n\nconst (\n", e.name)
+	for _, v := range e.values {
+		fmt.Fprintf(w, "\t%s%s %s = %d\n", e.name, goName(v.Name), e.name, v.Value)
+	}
+	w.WriteString(")\n\n")
+	fmt.Fprintf(w, "func (v %s) String() string {\n\tswitch v {\n", e.name)
+	for _, v := range e.values {
+		fmt.Fprintf(w, "\tcase %s%s:\n\t\treturn %q\n", e.name, goName(v.Name), v.Name)
+	}
+	fmt.Fprintf(w, "\t}\n\treturn fmt.Sprintf(\"%s(%%d)\", int32(v))\n}\n\n", e.name)
+}
+
+func (g *generator) writeBits(w *bytes.Buffer, e *enumType) {
+	g.imports["fmt"] = true
+	g.imports["strings"] = true
+	g.helpers["bitsFromBytes"] = true
+	fmt.Fprintf(w, "// %s holds the BITS of %s. Bit n of the SNMP octet string is 1 << n.\n", e.name, e.source)
+	fmt.Fprintf(w, "type %s uint64\n\nconst (\n", e.name)
+	for _, v := range e.values {
+		fmt.Fprintf(w, "\t%s%s %s = 1 << %d\n", e.name, goName(v.Name), e.name, v.Value)
+	}
+	w.WriteString(")\n\n")
+	fmt.Fprintf(w, "// %sFromBytes decodes the SNMP octet string encoding of %s.\n", e.name, e.name)
+	fmt.Fprintf(w, "func %sFromBytes(b []byte) %s { return %s(bitsFromBytes(b)) }\n\n", e.name, e.name, e.name)
+	fmt.Fprintf(w, "// Has reports whether all bits of f are set.\n")
+	fmt.Fprintf(w, "func (v %s) Has(f %s) bool { return v&f == f }\n\n", e.name, e.name)
+	fmt.Fprintf(w, "func (v %s) String() string {\n\tvar names []string\n\tfor n := 0; n < 64; n++ {\n", e.name)
+	fmt.Fprintf(w, "\t\tif v&(1<<n) == 0 {\n\t\t\tcontinue\n\t\t}\n\t\tswitch %s(1 << n) {\n", e.name)
+	for _, v := range e.values {
+		fmt.Fprintf(w, "\t\tcase %s%s:\n\t\t\tnames = append(names, %q)\n", e.name, goName(v.Name), v.Name)
+	}
+	w.WriteString("\t\tdefault:\n\t\t\tnames = append(names, fmt.Sprintf(\"bit%d\", n))\n\t\t}\n\t}\n")
+	w.WriteString("\treturn strings.Join(names, \"|\")\n}\n\n")
+}
+
+func (g *generator) writeTables(w *bytes.Buffer) {
+	if len(g.tables) == 0 {
+		return
+	}
+	w.WriteString("// VarBind is one variable binding of an SNMP response: a full instance\n")
+	w.WriteString("// OID, with or without a leading dot, and its value. Values may be any Go\n")
+	w.WriteString("// integer type, []byte, string, OID or net.IP.\n")
+	w.WriteString("type VarBind struct {\n\tOID   string\n\tValue any\n}\n\n")
+	for _, t := range g.tables {
+		g.writeTable(w, t)
+	}
+}
+
+// field is one member of a generated row struct.
+type field struct {
+	name   string
+	object *mib_parser.ObjectType
+	typ    goType
+}
+
+func (g *generator) writeTable(w *bytes.Buffer, t *table) {
+	rowType := goName(t.row.Name)
+	var fields []field
+	seen := map[string]bool{}
+	for _, o := range t.index {
+		if o != nil && o.Assignment.Parent != t.row.Name && !seen[o.Name] {
+			seen[o.Name] = true
+			fields = append(fields, field{goName(o.Name), o, g.objectType(o)})
+		}
+	}
+	for _, o := range t.columns {
+		seen[o.Name] = true
+		fields = append(fields, field{goName(o.Name), o, g.objectType(o)})
+	}
+	byName := map[string]field{}
+	for _, f := range fields {
+		byName[f.object.Name] = f
+	}
+	for _, f := range fields {
+		g.useType(f.typ)
+	}
+
+	fmt.Fprintf(w, "// %s is a row of %s.\n", rowType, t.table.Name)
+	fmt.Fprintf(w, "type %s struct {\n", rowType)
+	w.WriteString("\t// Instance is the index part of the row's OIDs, e.g. \"3\".\n\tInstance string\n")
+	for _, f := range fields {
+		fmt.Fprintf(w, "\t%s %s\n", f.name, f.typ.expr())
+	}
+	w.WriteString("}\n\n")
+
+	decodeIndex := g.writeDecodeIndex(w, t, rowType, byName)
+
+	g.imports["fmt"] = true
+	g.helpers["cutInstance"] = true
+	tableFunc := "Decode" + goName(t.table.Name)
+	fmt.Fprintf(w, "// %s collects the rows of %s from the varbinds of a walk.\n", tableFunc, t.table.Name)
+	w.WriteString("// Varbinds outside the table are ignored; rows keep the order in which\n// they first appear.\n")
+	fmt.Fprintf(w, "func %s(vbs []VarBind) ([]*%s, error) {\n", tableFunc, rowType)
+	fmt.Fprintf(w, "\trows := map[string]*%s{}\n\tvar order []string\n", rowType)
+	w.WriteString("\tfor _, vb := range vbs {\n")
+	fmt.Fprintf(w, "\t\tcolumn, instance, ok := cutInstance(vb.OID, %sOID)\n", rowType)
+	w.WriteString("\t\tif !ok {\n\t\t\tcontinue\n\t\t}\n\t\trow := rows[instance]\n\t\tif row == nil {\n")
+	fmt.Fprintf(w, "\t\t\trow = &%s{Instance: instance}\n", rowType)
+	if decodeIndex {
+		w.WriteString("\t\t\tif err := row.decodeIndex(instance); err != nil {\n")
+		w.WriteString("\t\t\t\treturn nil, fmt.Errorf(\"%s: %w\", vb.OID, err)\n\t\t\t}\n")
+	}
+	w.WriteString("\t\t\trows[instance] = row\n\t\t\torder = append(order, instance)\n\t\t}\n")
+	w.WriteString("\t\tvar err error\n\t\tswitch column {\n")
+	for _, o := range t.columns {
+		f := byName[o.Name]
+		fmt.Fprintf(w, "\t\tcase %d:\n", o.OID[len(t.row.OID)])
+		w.WriteString(g.decodeValue("row."+f.name, f.typ))
+	}
+	w.WriteString("\t\t}\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"%s: %w\", vb.OID, err)\n\t\t}\n\t}\n")
+	fmt.Fprintf(w, "\tout := make([]*%s, 0, len(order))\n", rowType)
+	w.WriteString("\tfor _, instance := range order {\n\t\tout = append(out, rows[instance])\n\t}\n\treturn out, nil\n}\n\n")
+}
+
+// writeDecodeIndex writes the row's decodeIndex method. It reports false,
+// writing nothing, when an index object's type cannot be decoded.
+func (g *generator) writeDecodeIndex(w *bytes.Buffer, t *table, rowType string, byName map[string]field) bool {
+	if len(t.index) == 0 {
+		return false
+	}
+	for _, o := range t.index {
+		if o == nil {
+			return false
+		}
+		switch byName[o.Name].typ.kind {
+		case kindAny, kindUint64, kindBits:
+			return false
+		}
+	}
+	g.helpers["parseArcs"] = true
+	g.imports["errors"] = true
+	fmt.Fprintf(w, "func (r *%s) decodeIndex(instance string) error {\n", rowType)
+	w.WriteString("\tarcs, err := parseArcs(instance)\n\tif err != nil {\n\t\treturn err\n\t}\n")
+	for i, o := range t.index {
+		f := byName[o.Name]
+		implied := t.implied && i == len(t.index)-1
+		target := "r." + f.name
+		var call string
+		switch f.typ.kind {
+		case kindInt32, kindEnum:
+			g.helpers["indexInt32"] = true
+			call = "indexInt32(arcs)"
+		case kindUint32:
+			g.helpers["indexUint32"] = true
+			call = "indexUint32(arcs)"
+		case kindBytes:
+			g.helpers["indexBytes"] = true
+			call = fmt.Sprintf("indexBytes(arcs, %d, %t)", f.typ.size, implied)
+		case kindString:
+			g.helpers["indexBytes"] = true
+			call = fmt.Sprintf("indexBytes(arcs, %d, %t)", f.typ.size, implied)
+		case kindOID:
+			g.helpers["indexOID"] = true
+			call = fmt.Sprintf("indexOID(arcs, %t)", implied)
+		case kindIP:
+			g.helpers["indexIP"] = true
+			call = "indexIP(arcs)"
+		}
+		switch f.typ.kind {
+		case kindEnum:
+			fmt.Fprintf(w, "\tvar %s int32\n", lowerFirst(f.name))
+			fmt.Fprintf(w, "\tif %s, arcs, err = %s; err != nil {\n\t\treturn err\n\t}\n", lowerFirst(f.name), call)
+			fmt.Fprintf(w, "\t%s = %s(%s)\n", target, f.typ.name, lowerFirst(f.name))
+		case kindString:
+			fmt.Fprintf(w, "\tvar %s []byte\n", lowerFirst(f.name))
+			fmt.Fprintf(w, "\tif %s, arcs, err = %s; err != nil {\n\t\treturn err\n\t}\n", lowerFirst(f.name), call)
+			fmt.Fprintf(w, "\t%s = string(%s)\n", target, lowerFirst(f.name))
+		default:
+			fmt.Fprintf(w, "\tif %s, arcs, err = %s; err != nil {\n\t\treturn err\n\t}\n", target, call)
+		}
+	}
+	w.WriteString("\tif len(arcs) != 0 {\n\t\treturn errors.New(\"trailing sub-identifiers in index\")\n\t}\n\treturn nil\n}\n\n")
+	return true
+}
+
+// decodeValue returns the statements that decode vb.Value into target.
+func (g *generator) decodeValue(target string, t goType) string {
+	switch t.kind {
+	case kindInt32:
+		g.helpers["decodeInt32"] = true
+		return fmt.Sprintf("\t\t\t%s, err = decodeInt32(vb.Value)\n", target)
+	case kindUint32:
+		g.helpers["decodeUint32"] = true
+		return fmt.Sprintf("\t\t\t%s, err = decodeUint32(vb.Value)\n", target)
+	case kindUint64:
+		g.helpers["decodeUint64"] = true
+		return fmt.Sprintf("\t\t\t%s, err = decodeUint64(vb.Value)\n", target)
+	case kindBytes:
+		g.helpers["decodeBytes"] = true
+		return fmt.Sprintf("\t\t\t%s, err = decodeBytes(vb.Value)\n", target)
+	case kindString:
+		g.helpers["decodeBytes"] = true
+		return fmt.Sprintf("\t\t\tvar v []byte\n\t\t\tv, err = decodeBytes(vb.Value)\n\t\t\t%s = string(v)\n", target)
+	case kindOID:
+		g.helpers["decodeOID"] = true
+		return fmt.Sprintf("\t\t\t%s, err = decodeOID(vb.Value)\n", target)
+	case kindIP:
+		g.helpers["decodeIP"] = true
+		return fmt.Sprintf("\t\t\t%s, err = decodeIP(vb.Value)\n", target)
+	case kindEnum:
+		g.helpers["decodeInt32"] = true
+		return fmt.Sprintf("\t\t\tvar v int32\n\t\t\tv, err = decodeInt32(vb.Value)\n\t\t\t%s = %s(v)\n", target, t.name)
+	case kindBits:
+		g.helpers["decodeBytes"] = true
+		return fmt.Sprintf("\t\t\tvar v []byte\n\t\t\tv, err = decodeBytes(vb.Value)\n\t\t\t%s = %sFromBytes(v)\n", target, t.name)
+	}
+	return fmt.Sprintf("\t\t\t%s = vb.Value\n", target)
+}
+
+// useType records the imports a field type needs.
+func (g *generator) useType(t goType) {
+	if t.kind == kindIP {
+		g.imports["net"] = true
+	}
+}
+
+// helper is a function copied into the generated file when used.
+type helper struct {
+	imports []string
+	deps    []string
+	src     string
+}
+
+// helperOrder fixes the order in which helpers are written.
+var helperOrder = []string{
+	"cutInstance", "parseArcs",
+	"indexInt32", "indexUint32", "indexBytes", "indexOID", "indexIP",
+	"decodeInteger", "decodeInt32", "decodeUint32", "decodeUint64",
+	"decodeBytes", "decodeOID", "decodeIP", "bitsFromBytes",
+}
+
+func (g *generator) writeHelpers(w *bytes.Buffer) {
+	for changed := true; changed; {
+		changed = false
+		for name := range g.helpers {
+			for _, dep := range helpers[name].deps {
+				if !g.helpers[dep] {
+					g.helpers[dep] = true
+					changed = true
+				}
+			}
+		}
+	}
+	for _, name := range helperOrder {
+		if !g.helpers[name] {
+			continue
+		}
+		h := helpers[name]
+		for _, imp := range h.imports {
+			g.imports[imp] = true
+		}
+		w.WriteString(h.src)
+		w.WriteString("\n")
+	}
+}
+
+// goName turns a descriptor into an exported Go identifier: "ifOperStatus"
+// becomes "IfOperStatus" and "mib-2" becomes "Mib2".
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "-") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,280 @@
+package codegen
+
+// helpers are the support functions of generated decoders. Generate copies
+// the ones a module needs, so generated packages have no dependencies.
+var helpers = map[string]helper{
+	"cutInstance": {
+		imports: []string{"strconv", "strings"},
+		src: `// cutInstance splits oid into the column below row and the instance suffix.
+func cutInstance(oid string, row OID) (uint32, string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), string(row)+".")
+	if !ok {
+		return 0, "", false
+	}
+	column, instance, ok := strings.Cut(rest, ".")
+	if !ok {
+		return 0, "", false
+	}
+	n, err := strconv.ParseUint(column, 10, 32)
+	if err != nil {
+		return 0, "", false
+	}
+	return uint32(n), instance, true
+}
+`,
+	},
+	"parseArcs": {
+		imports: []string{"strconv", "strings"},
+		src: `func parseArcs(s string) ([]uint32, error) {
+	parts := strings.Split(s, ".")
+	arcs := make([]uint32, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		arcs[i] = uint32(n)
+	}
+	return arcs, nil
+}
+`,
+	},
+	"indexInt32": {
+		imports: []string{"errors", "math"},
+		src: `func indexInt32(arcs []uint32) (int32, []uint32, error) {
+	if len(arcs) == 0 {
+		return 0, nil, errors.New("index too short")
+	}
+	if arcs[0] > math.MaxInt32 {
+		return 0, nil, errors.New("index out of range")
+	}
+	return int32(arcs[0]), arcs[1:], nil
+}
+`,
+	},
+	"indexUint32": {
+		imports: []string{"errors"},
+		src: `func indexUint32(arcs []uint32) (uint32, []uint32, error) {
+	if len(arcs) == 0 {
+		return 0, nil, errors.New("index too short")
+	}
+	return arcs[0], arcs[1:], nil
+}
+`,
+	},
+	"indexBytes": {
+		imports: []string{"errors"},
+		src: `// indexBytes decodes an OCTET STRING index of the given fixed size, or a
+// length-prefixed one when size is negative and the index is not IMPLIED.
+func indexBytes(arcs []uint32, size int, implied bool) ([]byte, []uint32, error) {
+	n := size
+	switch {
+	case size >= 0:
+	case implied:
+		n = len(arcs)
+	default:
+		if len(arcs) == 0 {
+			return nil, nil, errors.New("index too short")
+		}
+		n, arcs = int(arcs[0]), arcs[1:]
+	}
+	if n > len(arcs) {
+		return nil, nil, errors.New("index too short")
+	}
+	b := make([]byte, n)
+	for i, arc := range arcs[:n] {
+		if arc > 255 {
+			return nil, nil, errors.New("index octet out of range")
+		}
+		b[i] = byte(arc)
+	}
+	return b, arcs[n:], nil
+}
+`,
+	},
+	"indexOID": {
+		imports: []string{"errors", "strconv", "strings"},
+		src: `func indexOID(arcs []uint32, implied bool) (OID, []uint32, error) {
+	n := len(arcs)
+	if !implied {
+		if len(arcs) == 0 {
+			return "", nil, errors.New("index too short")
+		}
+		n, arcs = int(arcs[0]), arcs[1:]
+		if n > len(arcs) {
+			return "", nil, errors.New("index too short")
+		}
+	}
+	parts := make([]string, n)
+	for i, arc := range arcs[:n] {
+		parts[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+	return OID(strings.Join(parts, ".")), arcs[n:], nil
+}
+`,
+	},
+	"indexIP": {
+		imports: []string{"errors", "net"},
+		src: `func indexIP(arcs []uint32) (net.IP, []uint32, error) {
+	if len(arcs) < 4 {
+		return nil, nil, errors.New("index too short")
+	}
+	ip := make(net.IP, 4)
+	for i, arc := range arcs[:4] {
+		if arc > 255 {
+			return nil, nil, errors.New("index octet out of range")
+		}
+		ip[i] = byte(arc)
+	}
+	return ip, arcs[4:], nil
+}
+`,
+	},
+	"decodeInteger": {
+		imports: []string{"fmt", "math"},
+		src: `func decodeInteger(v any) (int64, error) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return int64(v), nil
+		}
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), nil
+		}
+	default:
+		return 0, fmt.Errorf("unexpected %T value", v)
+	}
+	return 0, fmt.Errorf("value %v out of range", v)
+}
+`,
+	},
+	"decodeInt32": {
+		imports: []string{"fmt", "math"},
+		deps:    []string{"decodeInteger"},
+		src: `func decodeInt32(v any) (int32, error) {
+	n, err := decodeInteger(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, fmt.Errorf("value %d out of range", n)
+	}
+	return int32(n), nil
+}
+`,
+	},
+	"decodeUint32": {
+		imports: []string{"fmt", "math"},
+		deps:    []string{"decodeInteger"},
+		src: `func decodeUint32(v any) (uint32, error) {
+	n, err := decodeInteger(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > math.MaxUint32 {
+		return 0, fmt.Errorf("value %d out of range", n)
+	}
+	return uint32(n), nil
+}
+`,
+	},
+	"decodeUint64": {
+		imports: []string{"fmt"},
+		deps:    []string{"decodeInteger"},
+		src: `func decodeUint64(v any) (uint64, error) {
+	switch v := v.(type) {
+	case uint64:
+		return v, nil
+	case uint:
+		return uint64(v), nil
+	}
+	n, err := decodeInteger(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("value %d out of range", n)
+	}
+	return uint64(n), nil
+}
+`,
+	},
+	"decodeBytes": {
+		imports: []string{"fmt"},
+		src: `func decodeBytes(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("unexpected %T value", v)
+}
+`,
+	},
+	"decodeOID": {
+		imports: []string{"fmt", "strings"},
+		src: `func decodeOID(v any) (OID, error) {
+	switch v := v.(type) {
+	case OID:
+		return v, nil
+	case string:
+		return OID(strings.TrimPrefix(v, ".")), nil
+	}
+	return "", fmt.Errorf("unexpected %T value", v)
+}
+`,
+	},
+	"decodeIP": {
+		imports: []string{"fmt", "net"},
+		src: `func decodeIP(v any) (net.IP, error) {
+	switch v := v.(type) {
+	case net.IP:
+		return v, nil
+	case []byte:
+		if len(v) == 4 {
+			return net.IP(v), nil
+		}
+	case string:
+		if ip := net.ParseIP(v); ip != nil {
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected IpAddress value %v", v)
+}
+`,
+	},
+	"bitsFromBytes": {
+		src: `// bitsFromBytes maps BITS octets to flags: bit n is the (n % 8)th most
+// significant bit of octet n / 8.
+func bitsFromBytes(b []byte) uint64 {
+	var v uint64
+	for i, octet := range b {
+		for j := 0; j < 8 && i*8+j < 64; j++ {
+			if octet&(0x80>>j) != 0 {
+				v |= 1 << (i*8 + j)
+			}
+		}
+	}
+	return v
+}
+`,
+	},
+}
@@ -0,0 +1,441 @@
+package mib_parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DependencyGraph is the graph of IMPORTS between the modules of a registry.
+// It is a snapshot: modules added to the registry later are not reflected.
+type DependencyGraph struct {
+	// Modules lists the loaded modules sorted by name.
+	Modules []string `json:"modules"`
+	// Edges lists one edge per importing module and imported module, sorted
+	// by From and then To. Edges to modules that are not loaded are included.
+	Edges []Dependency `json:"edges"`
+	// Missing lists the imported modules that are not loaded, sorted by name.
+	Missing []MissingModule `json:"missing,omitempty"`
+	// Cycles lists the groups of modules that import each other directly or
+	// indirectly. Each group is sorted by name, and the groups by their
+	// first module.
+	Cycles [][]string `json:"cycles,omitempty"`
+	// LoadOrder lists the loaded modules so that every module comes after
+	// the modules it imports. Modules of a cycle are listed together in name
+	// order; otherwise ties are broken by name.
+	LoadOrder []string `json:"loadOrder"`
+}
+
+// Dependency is an edge of a DependencyGraph: From imports symbols from To.
+type Dependency struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Symbols lists the imported symbols in IMPORTS order; several
+	// "FROM To" clauses are merged.
+	Symbols []string `json:"symbols"`
+	// Unused lists the imported symbols that From never refers to.
+	Unused []string `json:"unused,omitempty"`
+}
+
+// MissingModule is an imported module that is not loaded.
+type MissingModule struct {
+	Module string `json:"module"`
+	// ImportedBy lists the loaded modules that import it, sorted by name.
+	ImportedBy []string `json:"importedBy"`
+}
+
+// UnusedImport is a symbol a module imports but never refers to.
+type UnusedImport struct {
+	Module string
+	From   string
+	Symbol string
+}
+
+func (u UnusedImport) String() string {
+	return fmt.Sprintf("%s: %s imported from %s is not used", u.Module, u.Symbol, u.From)
+}
+
+// DependencyGraph builds the graph of IMPORTS between the loaded modules.
+func (r *Registry) DependencyGraph() *DependencyGraph {
+	g := &DependencyGraph{Modules: sortedKeys(r.modules), Edges: []Dependency{}}
+	missing := map[string][]string{}
+	for _, name := range g.Modules {
+		m := r.modules[name]
+		refs := map[string]bool{}
+		for _, ref := range m.references() {
+			refs[ref.name] = true
+		}
+		var edges []Dependency
+		index := map[string]int{}
+		for _, imp := range m.Imports {
+			i, ok := index[imp.Module]
+			if !ok {
+				i = len(edges)
+				index[imp.Module] = i
+				edges = append(edges, Dependency{From: name, To: imp.Module})
+				if _, loaded := r.modules[imp.Module]; !loaded {
+					missing[imp.Module] = append(missing[imp.Module], name)
+				}
+			}
+			for _, s := range imp.Symbols {
+				if contains(edges[i].Symbols, s) {
+					continue
+				}
+				edges[i].Symbols = append(edges[i].Symbols, s)
+				if !refs[s] {
+					edges[i].Unused = append(edges[i].Unused, s)
+				}
+			}
+		}
+		sort.Slice(edges, func(i, j int) bool { return edges[i].To < edges[j].To })
+		g.Edges = append(g.Edges, edges...)
+	}
+	for _, name := range sortedKeys(missing) {
+		g.Missing = append(g.Missing, MissingModule{Module: name, ImportedBy: missing[name]})
+	}
+	g.order()
+	return g
+}
+
+// order computes Cycles and LoadOrder from the strongly connected
+// components of the loaded modules (Tarjan's algorithm).
+func (g *DependencyGraph) order() {
+	imports := g.imports()
+	index, low := map[string]int{}, map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var components [][]string
+	var visit func(string)
+	visit = func(v string) {
+		index[v], low[v] = len(index), len(index)
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range imports[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var c []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			c = append(c, w)
+			if w == v {
+				break
+			}
+		}
+		sort.Strings(c)
+		components = append(components, c)
+	}
+	for _, v := range g.Modules {
+		if _, seen := index[v]; !seen {
+			visit(v)
+		}
+	}
+
+	component := map[string]int{}
+	for i, c := range components {
+		for _, v := range c {
+			component[v] = i
+		}
+		if len(c) > 1 || contains(imports[c[0]], c[0]) {
+			g.Cycles = append(g.Cycles, c)
+		}
+	}
+	sort.Slice(g.Cycles, func(i, j int) bool { return g.Cycles[i][0] < g.Cycles[j][0] })
+
+	// Kahn's algorithm over the components, taking the ready component
+	// with the smallest first module each time.
+	pending := make([]int, len(components))
+	dependents := make([][]int, len(components))
+	for v, ws := range imports {
+		for _, w := range ws {
+			if cv, cw := component[v], component[w]; cv != cw {
+				pending[cv]++
+				dependents[cw] = append(dependents[cw], cv)
+			}
+		}
+	}
+	var ready []int
+	for i := range components {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	g.LoadOrder = make([]string, 0, len(g.Modules))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return components[ready[i]][0] < components[ready[j]][0] })
+		c := ready[0]
+		ready = ready[1:]
+		g.LoadOrder = append(g.LoadOrder, components[c]...)
+		for _, d := range dependents[c] {
+			if pending[d]--; pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+}
+
+// imports maps each loaded module to the loaded modules it imports.
+func (g *DependencyGraph) imports() map[string][]string {
+	loaded := map[string]bool{}
+	for _, m := range g.Modules {
+		loaded[m] = true
+	}
+	out := map[string][]string{}
+	for _, e := range g.Edges {
+		if loaded[e.To] {
+			out[e.From] = append(out[e.From], e.To)
+		}
+	}
+	return out
+}
+
+// Requires returns the modules module imports directly or indirectly,
+// including ones that are not loaded, sorted by name.
+func (g *DependencyGraph) Requires(module string) []string {
+	next := map[string][]string{}
+	for _, e := range g.Edges {
+		next[e.From] = append(next[e.From], e.To)
+	}
+	return reachable(next, module)
+}
+
+// RequiredBy returns the loaded modules that import module directly or
+// indirectly, sorted by name.
+func (g *DependencyGraph) RequiredBy(module string) []string {
+	next := map[string][]string{}
+	for _, e := range g.Edges {
+		next[e.To] = append(next[e.To], e.From)
+	}
+	return reachable(next, module)
+}
+
+// reachable returns the nodes reachable from start, excluding start unless
+// it is on a cycle, sorted by name.
+func reachable(next map[string][]string, start string) []string {
+	seen := map[string]bool{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range next[v] {
+			if !seen[w] {
+				seen[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// UnusedImports lists the symbols that are imported but never referred to,
+// sorted by module, imported module and symbol.
+func (g *DependencyGraph) UnusedImports() []UnusedImport {
+	var out []UnusedImport
+	for _, e := range g.Edges {
+		unused := append([]string(nil), e.Unused...)
+		sort.Strings(unused)
+		for _, s := range unused {
+			out = append(out, UnusedImport{Module: e.From, From: e.To, Symbol: s})
+		}
+	}
+	return out
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. Edges point from
+// the importing module to the imported one and are labelled with the number
+// of imported symbols. Modules that are not loaded are drawn dashed, edges
+// within a cycle red and edges whose symbols are all unused dotted.
+func (g *DependencyGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	cycle := map[string]int{}
+	for i, c := range g.Cycles {
+		for _, m := range c {
+			cycle[m] = i + 1
+		}
+	}
+	fmt.Fprintln(bw, "digraph imports {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	for _, m := range g.Modules {
+		fmt.Fprintf(bw, "\t%s;\n", dotID(m))
+	}
+	for _, m := range g.Missing {
+		fmt.Fprintf(bw, "\t%s [style=dashed];\n", dotID(m.Module))
+	}
+	for _, e := range g.Edges {
+		attrs := []string{fmt.Sprintf("label=%d", len(e.Symbols))}
+		if c := cycle[e.From]; c != 0 && c == cycle[e.To] {
+			attrs = append(attrs, "color=red")
+		}
+		if len(e.Symbols) > 0 && len(e.Unused) == len(e.Symbols) {
+			attrs = append(attrs, "style=dotted")
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [%s];\n", dotID(e.From), dotID(e.To), strings.Join(attrs, ", "))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotID quotes a module name for DOT; module names contain hyphens.
+func dotID(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// reference is a use of a descriptor, type or macro name within a module.
+type reference struct {
+	name string
+	// from is the definition that refers to name; empty inside a MACRO.
+	from string
+	// defVal marks a DEFVAL identifier, which may be an enumeration label
+	// rather than a descriptor.
+	defVal bool
+	// macro marks an identifier used inside a MACRO body. Most of those
+	// are the macro's own notation, not symbols to import.
+	macro bool
+}
+
+// references lists the names module m refers to: OID parents, SYNTAX type
+// names, INDEX and AUGMENTS objects, group, notification and compliance
+// members, DEFVAL descriptors, the macros its definitions are written with
+// and the identifiers inside its own MACRO bodies. Names defined in other
+// modules need to be imported.
+func (m *Module) references() []reference {
+	var out []reference
+	add := func(from string, names ...string) {
+		for _, name := range names {
+			if name != "" {
+				out = append(out, reference{name: name, from: from})
+			}
+		}
+	}
+	defVal := func(from, v string) {
+		if name := defValName(v); name != "" {
+			out = append(out, reference{name: name, from: from, defVal: true})
+		}
+	}
+	syntax := func(from, s string) {
+		if base := syntaxBase(s); base != "" && !builtinTypes[base] {
+			add(from, base)
+		}
+	}
+	// Each macro is attributed to the first definition written with it.
+	type use struct {
+		from string
+		pos  Position
+	}
+	macros := map[string]use{}
+	macro := func(name, from string, pos Position) {
+		if u, ok := macros[name]; !ok || pos.Line < u.pos.Line || pos.Line == u.pos.Line && pos.Column < u.pos.Column {
+			macros[name] = use{from, pos}
+		}
+	}
+	if mi := m.ModuleIdentity; mi != nil {
+		macro("MODULE-IDENTITY", mi.Name, mi.Pos)
+		add(mi.Name, mi.Assignment.Parent)
+	}
+	for _, n := range m.ObjectIdentifiers {
+		add(n.Name, n.Assignment.Parent)
+	}
+	for _, o := range m.ObjectIdentities {
+		macro("OBJECT-IDENTITY", o.Name, o.Pos)
+		add(o.Name, o.Assignment.Parent)
+	}
+	for _, tc := range m.TextualConventions {
+		macro("TEXTUAL-CONVENTION", tc.Name, tc.Pos)
+		syntax(tc.Name, tc.Syntax)
+	}
+	for _, o := range m.ObjectsByName {
+		macro("OBJECT-TYPE", o.Name, o.Pos)
+		add(o.Name, o.Assignment.Parent)
+		syntax(o.Name, o.Syntax)
+		add(o.Name, o.Index...)
+		add(o.Name, o.Augments)
+		defVal(o.Name, o.DefVal)
+	}
+	for _, n := range m.NotificationTypes {
+		if n.TrapType {
+			macro("TRAP-TYPE", n.Name, n.Pos)
+		} else {
+			macro("NOTIFICATION-TYPE", n.Name, n.Pos)
+		}
+		add(n.Name, n.Assignment.Parent)
+		add(n.Name, n.Objects...)
+	}
+	for _, g := range m.ObjectGroups {
+		macro("OBJECT-GROUP", g.Name, g.Pos)
+		add(g.Name, g.Assignment.Parent)
+		add(g.Name, g.Objects...)
+	}
+	for _, g := range m.NotificationGroups {
+		macro("NOTIFICATION-GROUP", g.Name, g.Pos)
+		add(g.Name, g.Assignment.Parent)
+		add(g.Name, g.Notifications...)
+	}
+	for _, mc := range m.ModuleCompliances {
+		macro("MODULE-COMPLIANCE", mc.Name, mc.Pos)
+		add(mc.Name, mc.Assignment.Parent)
+		for _, cm := range mc.Modules {
+			for _, o := range cm.Objects {
+				syntax(mc.Name, o.Syntax)
+				syntax(mc.Name, o.WriteSyntax)
+			}
+			// Members of another module's MODULE clause are named
+			// without being imported.
+			if cm.Module != "" && cm.Module != m.Name {
+				continue
+			}
+			add(mc.Name, cm.MandatoryGroups...)
+			for _, g := range cm.Groups {
+				add(mc.Name, g.Name)
+			}
+			for _, o := range cm.Objects {
+				add(mc.Name, o.Name)
+			}
+		}
+	}
+	for _, ac := range m.AgentCapabilities {
+		macro("AGENT-CAPABILITIES", ac.Name, ac.Pos)
+		add(ac.Name, ac.Assignment.Parent)
+		for _, s := range ac.Supports {
+			for _, v := range s.Variations {
+				syntax(ac.Name, v.Syntax)
+				syntax(ac.Name, v.WriteSyntax)
+				defVal(ac.Name, v.DefVal)
+			}
+		}
+	}
+	for _, name := range sortedKeys(macros) {
+		add(macros[name].from, name)
+	}
+	for _, name := range m.MacroReferences {
+		out = append(out, reference{name: name, macro: true})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].from < out[j].from })
+	return out
+}
+
+// defValName returns the identifier of a DEFVAL written as a single
+// descriptor: an OBJECT IDENTIFIER value such as "zeroDotZero" or an
+// enumeration label. Numbers, strings and BITS values yield "".
+func defValName(defVal string) string {
+	v := strings.TrimSpace(defVal)
+	if v == "" || !isLower(v[0]) || strings.ContainsAny(v, " ,{}'\"") {
+		return ""
+	}
+	return v
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,403 @@
+package mib_parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies a difference between two versions of a module.
+type ChangeKind string
+
+const (
+	ChangeAdded        ChangeKind = "added"
+	ChangeRemoved      ChangeKind = "removed"
+	ChangeRenamed      ChangeKind = "renamed"
+	ChangeOID          ChangeKind = "oid-changed"
+	ChangeKindChanged  ChangeKind = "kind-changed"
+	ChangeSyntax       ChangeKind = "syntax-changed"
+	ChangeNarrowed     ChangeKind = "syntax-narrowed"
+	ChangeWidened      ChangeKind = "syntax-widened"
+	ChangeEnum         ChangeKind = "enum-changed"
+	ChangeAccess       ChangeKind = "access-changed"
+	ChangeStatus       ChangeKind = "status-changed"
+	ChangeIndex        ChangeKind = "index-changed"
+	ChangeObjects      ChangeKind = "objects-changed"
+	ChangeUnits        ChangeKind = "units-changed"
+	ChangeDefVal       ChangeKind = "defval-changed"
+	ChangeDisplayHint  ChangeKind = "display-hint-changed"
+	ChangeDescription  ChangeKind = "description-changed"
+	ChangeReference    ChangeKind = "reference-changed"
+	ChangeLastUpdated  ChangeKind = "last-updated-changed"
+	ChangeModuleRename ChangeKind = "module-renamed"
+)
+
+// Change is a single difference between two versions of a module.
+type Change struct {
+	// Kind classifies the change.
+	Kind ChangeKind
+	// Name is the definition's descriptor in the new module (the old
+	// descriptor for removals).
+	Name string
+	// OldName is the previous descriptor of a renamed definition.
+	OldName string
+	// Definition is the macro of the definition (e.g., OBJECT-TYPE).
+	Definition string
+	// Old and New are the changed values, rendered as text.
+	Old, New string
+	// Breaking reports whether RFC 2578 section 10 forbids the change
+	// without assigning a new OID.
+	Breaking bool
+	// Reason explains the classification.
+	Reason string
+}
+
+func (c Change) String() string {
+	verdict := "allowed"
+	if c.Breaking {
+		verdict = "BREAKING"
+	}
+	s := fmt.Sprintf("%-8s %-20s %s", verdict, c.Kind, c.Name)
+	if c.Definition != "" {
+		s += " (" + c.Definition + ")"
+	}
+	if c.Old != "" || c.New != "" {
+		s += fmt.Sprintf(": %q -> %q", c.Old, c.New)
+	}
+	if c.Reason != "" {
+		s += "; " + c.Reason
+	}
+	return s
+}
+
+// ModuleDiff is the result of DiffModules.
+type ModuleDiff struct {
+	Old, New string
+	Changes  []Change
+}
+
+// Breaking reports whether any change is breaking.
+func (d *ModuleDiff) Breaking() bool {
+	for _, c := range d.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// diffDef is the comparable part of any OID-bearing definition or TC.
+type diffDef struct {
+	name, kind  string
+	oid         string
+	syntax      string
+	access      string
+	status      string
+	description string
+	reference   string
+	units       string
+	defval      string
+	hint        string
+	index       string
+	objects     string
+}
+
+// DiffModules compares two versions of a module and classifies every
+// difference according to the compatibility rules of RFC 2578 section 10
+// (and RFC 2579/2580 for textual conventions and conformance statements).
+// Renames are detected by definitions that keep their OID under a new
+// descriptor.
+func DiffModules(oldMod, newMod *Module) *ModuleDiff {
+	d := &ModuleDiff{Old: oldMod.Name, New: newMod.Name}
+	if oldMod.Name != newMod.Name {
+		d.add(Change{Kind: ChangeModuleRename, Old: oldMod.Name, New: newMod.Name, Breaking: true,
+			Reason: "importing modules refer to the module name"})
+	}
+	if oldMod.ModuleIdentity != nil && newMod.ModuleIdentity != nil && oldMod.ModuleIdentity.LastUpdated != newMod.ModuleIdentity.LastUpdated {
+		d.add(Change{Kind: ChangeLastUpdated, Name: newMod.ModuleIdentity.Name, Definition: "MODULE-IDENTITY",
+			Old: oldMod.ModuleIdentity.LastUpdated, New: newMod.ModuleIdentity.LastUpdated})
+	}
+
+	olds, news := diffDefs(oldMod), diffDefs(newMod)
+	byOID := map[string]string{}
+	for name, def := range news {
+		if _, ok := olds[name]; !ok && def.oid != "" {
+			byOID[def.oid] = name
+		}
+	}
+	renamed := map[string]bool{}
+	for _, name := range sortedKeys(olds) {
+		o := olds[name]
+		n, ok := news[name]
+		if !ok {
+			if newName, ok := byOID[o.oid]; ok && o.oid != "" && news[newName].kind == o.kind {
+				renamed[newName] = true
+				d.add(Change{Kind: ChangeRenamed, Name: newName, OldName: name, Definition: o.kind, Old: name, New: newName,
+					Breaking: true, Reason: "descriptors must not change; other modules import them by name"})
+				d.compare(newMod, o, news[newName])
+				continue
+			}
+			d.add(Change{Kind: ChangeRemoved, Name: name, Definition: o.kind, Breaking: true,
+				Reason: "definitions must not be removed; set STATUS obsolete instead"})
+			continue
+		}
+		d.compare(newMod, o, n)
+	}
+	for _, name := range sortedKeys(news) {
+		if _, ok := olds[name]; ok || renamed[name] {
+			continue
+		}
+		d.add(Change{Kind: ChangeAdded, Name: name, Definition: news[name].kind})
+	}
+	return d
+}
+
+func (d *ModuleDiff) add(c Change) {
+	d.Changes = append(d.Changes, c)
+}
+
+func (d *ModuleDiff) compare(newMod *Module, o, n diffDef) {
+	change := func(kind ChangeKind, from, to string, breaking bool, reason string) {
+		d.add(Change{Kind: kind, Name: n.name, Definition: n.kind, Old: from, New: to, Breaking: breaking, Reason: reason})
+	}
+	if o.kind != n.kind {
+		change(ChangeKindChanged, o.kind, n.kind, true, "the definition's macro changed")
+		return
+	}
+	if o.oid != n.oid {
+		change(ChangeOID, o.oid, n.oid, true, "the OID of an existing definition must not change")
+	}
+	if o.syntax != n.syntax {
+		d.compareSyntax(newMod, o, n)
+	}
+	if o.access != n.access {
+		change(ChangeAccess, o.access, n.access, true, "MAX-ACCESS may not change (RFC 2578 10.2)")
+	}
+	if o.status != n.status {
+		if statusRank(n.status) >= statusRank(o.status) && statusRank(o.status) > 0 {
+			change(ChangeStatus, o.status, n.status, false, "current may become deprecated or obsolete (RFC 2578 10.2)")
+		} else {
+			change(ChangeStatus, o.status, n.status, true, "STATUS may only move from current towards obsolete")
+		}
+	}
+	if o.index != n.index {
+		if strings.HasPrefix(n.index, "AUGMENTS") && !strings.HasPrefix(o.index, "AUGMENTS") {
+			change(ChangeIndex, o.index, n.index, false, "an INDEX may be replaced by an equivalent AUGMENTS (RFC 2578 10.2)")
+		} else {
+			change(ChangeIndex, o.index, n.index, true, "the indexing of a row must not change")
+		}
+	}
+	if o.objects != n.objects {
+		change(ChangeObjects, o.objects, n.objects, true, "the OBJECTS/NOTIFICATIONS list must not change")
+	}
+	if o.units != n.units {
+		change(ChangeUnits, o.units, n.units, o.units != "", "a UNITS clause may be added but not changed (RFC 2578 10.2)")
+	}
+	if o.defval != n.defval {
+		change(ChangeDefVal, o.defval, n.defval, false, "a DEFVAL may be added or updated (RFC 2578 10.2)")
+	}
+	if o.hint != n.hint {
+		change(ChangeDisplayHint, o.hint, n.hint, o.hint != "", "a DISPLAY-HINT may be added but not changed (RFC 2579 5)")
+	}
+	if collapseSpace(o.description) != collapseSpace(n.description) {
+		change(ChangeDescription, "", "", false, "DESCRIPTION clarifications are editorial (RFC 2578 10.2)")
+	}
+	if collapseSpace(o.reference) != collapseSpace(n.reference) {
+		change(ChangeReference, "", "", false, "a REFERENCE may be added or updated (RFC 2578 10.2)")
+	}
+}
+
+func (d *ModuleDiff) compareSyntax(newMod *Module, o, n diffDef) {
+	reported := false
+	change := func(kind ChangeKind, breaking bool, reason string) {
+		reported = true
+		d.add(Change{Kind: kind, Name: n.name, Definition: n.kind, Old: o.syntax, New: n.syntax, Breaking: breaking, Reason: reason})
+	}
+	from, to := ParseSyntax(o.syntax), ParseSyntax(n.syntax)
+	if from.Base != to.Base {
+		// Replacing a base type with a textual convention that refines it
+		// is allowed, provided the constraints stay the same.
+		if tc, ok := newMod.TextualConventions[to.Base]; ok {
+			ts := ParseSyntax(tc.Syntax)
+			if ts.Base == from.Base && sameConstraints(ts, from) {
+				change(ChangeSyntax, false, "a base type may be replaced by an equivalent textual convention (RFC 2578 10.2)")
+				return
+			}
+		}
+		change(ChangeSyntax, true, "the base type must not change")
+		return
+	}
+	if !sameNamedNumbers(from.NamedNumbers, to.NamedNumbers) {
+		removed := false
+		for _, nn := range from.NamedNumbers {
+			if _, ok := to.Enum(nn.Value); !ok {
+				removed = true
+			}
+		}
+		if removed {
+			change(ChangeEnum, true, "enumerations and named bits must not be removed")
+		} else {
+			change(ChangeEnum, false, "enumerations and named bits may be added or relabelled (RFC 2578 10.2)")
+		}
+	}
+	for _, c := range []struct {
+		from, to []Range
+		what     string
+	}{{from.Ranges, to.Ranges, "range"}, {from.Sizes, to.Sizes, "SIZE"}} {
+		if sameRanges(c.from, c.to) {
+			continue
+		}
+		switch {
+		case rangesCover(c.from, c.to):
+			change(ChangeNarrowed, true, "the "+c.what+" was narrowed; RFC 2578 10.2 does not allow constraint changes")
+		case rangesCover(c.to, c.from):
+			change(ChangeWidened, true, "the "+c.what+" was widened; existing managers may not accept the new values")
+		default:
+			change(ChangeSyntax, true, "the "+c.what+" changed")
+		}
+	}
+	if !reported && strings.Join(lexSyntax(o.syntax), " ") != strings.Join(lexSyntax(n.syntax), " ") {
+		change(ChangeSyntax, true, "the SYNTAX changed")
+	}
+}
+
+func diffDefs(m *Module) map[string]diffDef {
+	out := map[string]diffDef{}
+	oid := func(o OID, a OIDAssignment) string {
+		if len(o) > 0 {
+			return o.String()
+		}
+		if a.Parent == "" && len(a.SubIDs) == 0 {
+			return ""
+		}
+		parts := []string{a.Parent}
+		for _, s := range a.SubIDs {
+			parts = append(parts, fmt.Sprint(s))
+		}
+		return strings.Join(parts, ".")
+	}
+	if mi := m.ModuleIdentity; mi != nil {
+		out[mi.Name] = diffDef{name: mi.Name, kind: "MODULE-IDENTITY", oid: oid(mi.OID, mi.Assignment), description: mi.Description}
+	}
+	for _, n := range m.ObjectIdentifiers {
+		out[n.Name] = diffDef{name: n.Name, kind: "OBJECT IDENTIFIER", oid: oid(n.OID, n.Assignment)}
+	}
+	for _, o := range m.ObjectIdentities {
+		out[o.Name] = diffDef{name: o.Name, kind: "OBJECT-IDENTITY", oid: oid(o.OID, o.Assignment), status: o.Status,
+			description: o.Description, reference: o.Reference}
+	}
+	for _, tc := range m.TextualConventions {
+		out[tc.Name] = diffDef{name: tc.Name, kind: "TEXTUAL-CONVENTION", syntax: tc.Syntax, status: tc.Status,
+			description: tc.Description, reference: tc.Reference, hint: tc.DisplayHint}
+	}
+	for _, o := range m.ObjectsByName {
+		index := strings.Join(o.Index, ", ")
+		if o.Implied {
+			index = "IMPLIED " + index
+		}
+		if o.Augments != "" {
+			index = "AUGMENTS " + o.Augments
+		}
+		out[o.Name] = diffDef{name: o.Name, kind: "OBJECT-TYPE", oid: oid(o.OID, o.Assignment), syntax: o.Syntax,
+			access: o.Access, status: o.Status, description: o.Description, reference: o.Reference,
+			units: o.Units, defval: o.DefVal, index: index}
+	}
+	for _, n := range m.NotificationTypes {
+		out[n.Name] = diffDef{name: n.Name, kind: "NOTIFICATION-TYPE", oid: oid(n.OID, n.Assignment), status: n.Status,
+			description: n.Description, reference: n.Reference, objects: strings.Join(n.Objects, ", ")}
+	}
+	for _, g := range m.ObjectGroups {
+		out[g.Name] = diffDef{name: g.Name, kind: "OBJECT-GROUP", oid: oid(g.OID, g.Assignment), status: g.Status,
+			description: g.Description, reference: g.Reference, objects: strings.Join(g.Objects, ", ")}
+	}
+	for _, g := range m.NotificationGroups {
+		out[g.Name] = diffDef{name: g.Name, kind: "NOTIFICATION-GROUP", oid: oid(g.OID, g.Assignment), status: g.Status,
+			description: g.Description, reference: g.Reference, objects: strings.Join(g.Notifications, ", ")}
+	}
+	for _, mc := range m.ModuleCompliances {
+		out[mc.Name] = diffDef{name: mc.Name, kind: "MODULE-COMPLIANCE", oid: oid(mc.OID, mc.Assignment), status: mc.Status,
+			description: mc.Description, reference: mc.Reference}
+	}
+	for _, ac := range m.AgentCapabilities {
+		out[ac.Name] = diffDef{name: ac.Name, kind: "AGENT-CAPABILITIES", oid: oid(ac.OID, ac.Assignment), status: ac.Status,
+			description: ac.Description, reference: ac.Reference}
+	}
+	return out
+}
+
+func statusRank(s string) int {
+	switch s {
+	case "current", "mandatory":
+		return 1
+	case "deprecated":
+		return 2
+	case "obsolete":
+		return 3
+	}
+	return 0
+}
+
+func sameRanges(a, b []Range) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameConstraints(a, b Syntax) bool {
+	return sameRanges(a.Ranges, b.Ranges) && sameRanges(a.Sizes, b.Sizes) && sameNamedNumbers(a.NamedNumbers, b.NamedNumbers)
+}
+
+func sameNamedNumbers(a, b []NamedNumber) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rangesCover reports whether every value allowed by inner is allowed by
+// outer. An empty constraint allows everything.
+func rangesCover(outer, inner []Range) bool {
+	if len(outer) == 0 {
+		return true
+	}
+	if len(inner) == 0 {
+		return false
+	}
+	for _, in := range inner {
+		covered := false
+		for _, out := range outer {
+			if in.Min >= out.Min && in.Max <= out.Max {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+// Package displayhint formats values according to the DISPLAY-HINT clause
+// of a textual convention (RFC 2579 section 3.1).
+package displayhint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// octetSpec is one octet-format specification, e.g. "*1x:" or "2d-".
+type octetSpec struct {
+	repeat     bool
+	length     int
+	format     byte
+	separator  string
+	terminator string
+}
+
+// FormatOctets renders b according to an octet-string hint such as "1x:",
+// "255a" or DateAndTime's "2d-1d-1d,1d:1d:1d.1d,1a1d:1d". The last
+// specification is reused until b is exhausted, and a separator is left out
+// after the final octet.
+func FormatOctets(hint string, b []byte) (string, error) {
+	specs, err := parseOctetHint(hint)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for i := 0; len(b) > 0; i++ {
+		spec := specs[min(i, len(specs)-1)]
+		count := 1
+		if spec.repeat {
+			count, b = int(b[0]), b[1:]
+		}
+		for n := 0; n < count && len(b) > 0; n++ {
+			size := min(spec.length, len(b))
+			if err := formatOctets(&out, spec.format, b[:size]); err != nil {
+				return "", err
+			}
+			b = b[size:]
+			if len(b) > 0 && (n < count-1 || spec.terminator == "") {
+				out.WriteString(spec.separator)
+			}
+		}
+		if len(b) > 0 {
+			out.WriteString(spec.terminator)
+		}
+	}
+	return out.String(), nil
+}
+
+func parseOctetHint(hint string) ([]octetSpec, error) {
+	var specs []octetSpec
+	for i := 0; i < len(hint); {
+		var spec octetSpec
+		if hint[i] == '*' {
+			spec.repeat = true
+			i++
+		}
+		start := i
+		for i < len(hint) && hint[i] >= '0' && hint[i] <= '9' {
+			i++
+		}
+		length, err := strconv.Atoi(hint[start:i])
+		if err != nil || length == 0 {
+			return nil, fmt.Errorf("display hint %q: expected an octet length at offset %d", hint, start)
+		}
+		spec.length = length
+		if i == len(hint) || !strings.ContainsRune("xdoat", rune(hint[i])) {
+			return nil, fmt.Errorf("display hint %q: expected one of x, d, o, a or t at offset %d", hint, i)
+		}
+		spec.format = hint[i]
+		i++
+		if i < len(hint) && isSeparator(hint[i]) {
+			spec.separator = hint[i : i+1]
+			i++
+		}
+		if spec.repeat && i < len(hint) && isSeparator(hint[i]) {
+			spec.terminator = hint[i : i+1]
+			i++
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("display hint is empty")
+	}
+	return specs, nil
+}
+
+func isSeparator(c byte) bool {
+	return c != '*' && (c < '0' || c > '9')
+}
+
+func formatOctets(out *strings.Builder, format byte, b []byte) error {
+	switch format {
+	case 'a':
+		for _, c := range b {
+			if c > 0x7f {
+				return fmt.Errorf("display hint: non-ASCII octet %#x", c)
+			}
+		}
+		out.Write(b)
+		return nil
+	case 't':
+		if !utf8.Valid(b) {
+			return fmt.Errorf("display hint: invalid UTF-8 %q", b)
+		}
+		out.Write(b)
+		return nil
+	}
+	if len(b) > 8 {
+		return fmt.Errorf("display hint: %d octets do not fit an integer", len(b))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	switch format {
+	case 'x':
+		fmt.Fprintf(out, "%0*x", 2*len(b), v)
+	case 'd':
+		out.WriteString(strconv.FormatUint(v, 10))
+	case 'o':
+		out.WriteString(strconv.FormatUint(v, 8))
+	}
+	return nil
+}
+
+// FormatInteger renders v according to an integer hint: "d", "d-N" (N
+// implied decimal places, e.g. 1234 with "d-2" is "12.34"), "x", "o" or
+// "b".
+func FormatInteger(hint string, v int64) (string, error) {
+	switch {
+	case hint == "d":
+		return strconv.FormatInt(v, 10), nil
+	case hint == "x":
+		return strconv.FormatInt(v, 16), nil
+	case hint == "o":
+		return strconv.FormatInt(v, 8), nil
+	case hint == "b":
+		return strconv.FormatInt(v, 2), nil
+	case strings.HasPrefix(hint, "d-"):
+		places, err := strconv.Atoi(hint[2:])
+		if err != nil || places < 0 {
+			return "", fmt.Errorf("display hint %q: invalid decimal places", hint)
+		}
+		sign, digits := "", strconv.FormatInt(v, 10)
+		if v < 0 {
+			sign, digits = "-", digits[1:]
+		}
+		if places == 0 {
+			return sign + digits, nil
+		}
+		if len(digits) <= places {
+			digits = strings.Repeat("0", places-len(digits)+1) + digits
+		}
+		return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:], nil
+	}
+	return "", fmt.Errorf("display hint %q: not an integer hint", hint)
+}
@@ -0,0 +1,780 @@
+package mib_parser
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// formatWidth is the column at which DESCRIPTION-like texts, IMPORTS lists
+// and long enumerations are wrapped.
+const formatWidth = 72
+
+// FormatMIB renders a module as SMIv2 source text in a canonical layout:
+// IMPORTS grouped by module and sorted, the MODULE-IDENTITY first, textual
+// conventions next, then MACROs and other type assignments as they were
+// written, and every other definition in OID order. DESCRIPTION and
+// REFERENCE texts are re-wrapped, SEQUENCE types for conceptual rows are
+// emitted with aligned members, and long enumerations are split one label
+// per line. Notifications parsed from an SMIv1 TRAP-TYPE are written as
+// TRAP-TYPE again. The output parses back with ParseMIB into an equivalent
+// module.
+func FormatMIB(m *Module) ([]byte, error) {
+	if m == nil || m.Name == "" {
+		return nil, fmt.Errorf("format: module has no name")
+	}
+	f := &formatter{mod: m, assign: map[string]OIDAssignment{}, oids: map[string]OID{}}
+	f.collect()
+	defs, err := f.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &mibWriter{indent: "    "}
+	w.printf("%s DEFINITIONS ::= BEGIN\n", m.Name)
+	f.writeImports(w)
+	for _, d := range defs {
+		w.printf("\n")
+		d.write(w)
+	}
+	w.printf("\nEND\n")
+	return []byte(w.b.String()), nil
+}
+
+type formatter struct {
+	mod    *Module
+	assign map[string]OIDAssignment
+	oids   map[string]OID
+}
+
+// formatDef is a single top-level definition queued for output.
+type formatDef struct {
+	name  string
+	root  string
+	arcs  OID
+	write func(w *mibWriter)
+}
+
+// collect indexes the OID assignments of every OID-bearing definition so
+// definitions can be ordered even when their OIDs are not resolved.
+func (f *formatter) collect() {
+	add := func(name string, oid OID, a OIDAssignment) {
+		f.assign[name] = a
+		f.oids[name] = oid
+	}
+	m := f.mod
+	if m.ModuleIdentity != nil {
+		add(m.ModuleIdentity.Name, m.ModuleIdentity.OID, m.ModuleIdentity.Assignment)
+	}
+	for name, n := range m.ObjectIdentifiers {
+		add(name, n.OID, n.Assignment)
+	}
+	for name, o := range m.ObjectIdentities {
+		add(name, o.OID, o.Assignment)
+	}
+	for name, o := range m.ObjectsByName {
+		add(name, o.OID, o.Assignment)
+	}
+	for name, n := range m.NotificationTypes {
+		add(name, n.OID, n.Assignment)
+	}
+	for name, g := range m.ObjectGroups {
+		add(name, g.OID, g.Assignment)
+	}
+	for name, g := range m.NotificationGroups {
+		add(name, g.OID, g.Assignment)
+	}
+	for name, c := range m.ModuleCompliances {
+		add(name, c.OID, c.Assignment)
+	}
+	for name, c := range m.AgentCapabilities {
+		add(name, c.OID, c.Assignment)
+	}
+}
+
+// sortKey returns the position of a definition in the OID tree. Resolved
+// definitions sort by numeric OID (empty root); unresolved ones sort by the
+// first ancestor that is not defined in this module followed by the arcs
+// below it.
+func (f *formatter) sortKey(name string, depth int) (string, OID) {
+	if oid := f.oids[name]; len(oid) > 0 {
+		return "", oid
+	}
+	a, ok := f.assign[name]
+	if !ok || depth > 128 {
+		return name, nil
+	}
+	if a.Parent == "" {
+		return "", a.SubIDs
+	}
+	if _, local := f.assign[a.Parent]; !local {
+		return a.Parent, a.SubIDs
+	}
+	root, arcs := f.sortKey(a.Parent, depth+1)
+	return root, arcs.Append(a.SubIDs...)
+}
+
+func (f *formatter) definitions() ([]formatDef, error) {
+	m := f.mod
+	var head, tcs, types, defs []formatDef
+	if mi := m.ModuleIdentity; mi != nil {
+		value, err := oidValue(mi.Name, mi.Assignment, mi.OID)
+		if err != nil {
+			return nil, err
+		}
+		head = append(head, formatDef{name: mi.Name, write: func(w *mibWriter) { writeModuleIdentity(w, mi, value) }})
+	}
+	for _, tc := range m.TextualConventions {
+		tcs = append(tcs, formatDef{name: tc.Name, write: func(w *mibWriter) { writeTextualConvention(w, tc) }})
+	}
+	sort.Slice(tcs, func(i, j int) bool { return tcs[i].name < tcs[j].name })
+
+	// SEQUENCE types of conceptual rows are generated next to their rows;
+	// other type assignments and MACROs are copied from the source.
+	rowTypes := map[string]bool{}
+	for _, o := range m.ObjectsByName {
+		if f.rowSequence(o) != "" {
+			rowTypes[o.Syntax] = true
+		}
+	}
+	for _, name := range append(append([]string(nil), m.Macros...), m.Types...) {
+		if rowTypes[name] {
+			continue
+		}
+		text, ok := m.DefinitionText[name]
+		if !ok {
+			return nil, fmt.Errorf("format: no source text for %s", name)
+		}
+		types = append(types, formatDef{name: name, write: func(w *mibWriter) { w.printf("%s\n", text) }})
+	}
+
+	queue := func(name string, a OIDAssignment, oid OID, write func(w *mibWriter, value string)) error {
+		value, err := oidValue(name, a, oid)
+		if err != nil {
+			return err
+		}
+		root, arcs := f.sortKey(name, 0)
+		defs = append(defs, formatDef{name: name, root: root, arcs: arcs, write: func(w *mibWriter) { write(w, value) }})
+		return nil
+	}
+	for _, n := range m.ObjectIdentifiers {
+		if err := queue(n.Name, n.Assignment, n.OID, func(w *mibWriter, value string) {
+			w.printf("%s OBJECT IDENTIFIER ::= %s\n", n.Name, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range m.ObjectIdentities {
+		if err := queue(o.Name, o.Assignment, o.OID, func(w *mibWriter, value string) {
+			writeObjectIdentity(w, o, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range m.ObjectsByName {
+		if err := queue(o.Name, o.Assignment, o.OID, func(w *mibWriter, value string) {
+			writeObjectType(w, o, value)
+			if seq := f.rowSequence(o); seq != "" {
+				w.printf("\n%s", seq)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range m.NotificationTypes {
+		if err := queue(n.Name, n.Assignment, n.OID, func(w *mibWriter, value string) {
+			writeNotificationType(w, n, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range m.ObjectGroups {
+		if err := queue(g.Name, g.Assignment, g.OID, func(w *mibWriter, value string) {
+			writeGroup(w, g.Name, "OBJECT-GROUP", "OBJECTS", g.Objects, g.Status, g.Description, g.Reference, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range m.NotificationGroups {
+		if err := queue(g.Name, g.Assignment, g.OID, func(w *mibWriter, value string) {
+			writeGroup(w, g.Name, "NOTIFICATION-GROUP", "NOTIFICATIONS", g.Notifications, g.Status, g.Description, g.Reference, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range m.ModuleCompliances {
+		if err := queue(c.Name, c.Assignment, c.OID, func(w *mibWriter, value string) {
+			writeModuleCompliance(w, c, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range m.AgentCapabilities {
+		if err := queue(c.Name, c.Assignment, c.OID, func(w *mibWriter, value string) {
+			writeAgentCapabilities(w, c, value)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		a, b := defs[i], defs[j]
+		if a.root != b.root {
+			return a.root < b.root
+		}
+		if c := a.arcs.Compare(b.arcs); c != 0 {
+			return c < 0
+		}
+		return a.name < b.name
+	})
+	return slices.Concat(head, tcs, types, defs), nil
+}
+
+// oidValue renders the "{ parent n }" value of a definition, falling back to
+// the numeric OID when no symbolic assignment was recorded.
+func oidValue(name string, a OIDAssignment, oid OID) (string, error) {
+	parts := []string{}
+	if a.Parent != "" {
+		parts = append(parts, a.Parent)
+	}
+	for _, n := range a.SubIDs {
+		parts = append(parts, fmt.Sprintf("%d", n))
+	}
+	if len(a.SubIDs) == 0 {
+		if a.Parent != "" || len(oid) == 0 {
+			return "", fmt.Errorf("format: %s has no OID value", name)
+		}
+		parts = parts[:0]
+		for _, n := range oid {
+			parts = append(parts, fmt.Sprintf("%d", n))
+		}
+	}
+	return "{ " + strings.Join(parts, " ") + " }", nil
+}
+
+func (f *formatter) writeImports(w *mibWriter) {
+	byModule := map[string]map[string]struct{}{}
+	for _, imp := range f.mod.Imports {
+		if byModule[imp.Module] == nil {
+			byModule[imp.Module] = map[string]struct{}{}
+		}
+		for _, s := range imp.Symbols {
+			byModule[imp.Module][s] = struct{}{}
+		}
+	}
+	if len(byModule) == 0 {
+		return
+	}
+	modules := make([]string, 0, len(byModule))
+	for name := range byModule {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+	w.printf("\nIMPORTS\n")
+	for i, name := range modules {
+		symbols := make([]string, 0, len(byModule[name]))
+		for s := range byModule[name] {
+			symbols = append(symbols, s)
+		}
+		sort.Strings(symbols)
+		w.list("    ", symbols)
+		w.printf("        FROM %s", name)
+		if i == len(modules)-1 {
+			w.printf(";")
+		}
+		w.printf("\n")
+	}
+}
+
+// rowSequence returns the SEQUENCE type definition for a conceptual row,
+// built from the row's columns, or "" when row is not a conceptual row.
+func (f *formatter) rowSequence(row *ObjectType) string {
+	if row.Assignment.Parent == "" {
+		return ""
+	}
+	table, ok := f.mod.ObjectsByName[row.Assignment.Parent]
+	if !ok || strings.TrimPrefix(table.Syntax, "SEQUENCE OF ") != row.Syntax || table.Syntax == row.Syntax {
+		return ""
+	}
+	if _, isTC := f.mod.TextualConventions[row.Syntax]; isTC {
+		return ""
+	}
+	var cols []*ObjectType
+	for _, o := range f.mod.ObjectsByName {
+		if o.Assignment.Parent == row.Name && len(o.Assignment.SubIDs) > 0 {
+			cols = append(cols, o)
+		}
+	}
+	if len(cols) == 0 {
+		return ""
+	}
+	sort.Slice(cols, func(i, j int) bool {
+		return OID(cols[i].Assignment.SubIDs).Compare(cols[j].Assignment.SubIDs) < 0
+	})
+	width := 0
+	for _, c := range cols {
+		if len(c.Name) > width {
+			width = len(c.Name)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s ::= SEQUENCE {\n", row.Syntax)
+	for i, c := range cols {
+		sep := ","
+		if i == len(cols)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(&b, "    %-*s  %s%s\n", width, c.Name, baseTypeName(c.Syntax), sep)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// baseTypeName strips enumerations and constraints from a SYNTAX string, as
+// required for SEQUENCE member types.
+func baseTypeName(syntax string) string {
+	if i := strings.IndexAny(syntax, "{("); i >= 0 {
+		syntax = syntax[:i]
+	}
+	return strings.TrimSpace(syntax)
+}
+
+func writeModuleIdentity(w *mibWriter, mi *ModuleIdentity, value string) {
+	w.printf("%s MODULE-IDENTITY\n", mi.Name)
+	w.clause("LAST-UPDATED", quote(mi.LastUpdated))
+	w.textLines("ORGANIZATION", mi.Organization)
+	w.textLines("CONTACT-INFO", mi.ContactInfo)
+	w.text("DESCRIPTION", mi.Description)
+	for _, rev := range mi.Revisions {
+		w.clause("REVISION", quote(rev.Date))
+		w.text("DESCRIPTION", rev.Description)
+	}
+	w.printf("    ::= %s\n", value)
+}
+
+func writeTextualConvention(w *mibWriter, tc *TextualConvention) {
+	w.printf("%s ::= TEXTUAL-CONVENTION\n", tc.Name)
+	if tc.DisplayHint != "" {
+		w.clause("DISPLAY-HINT", quote(tc.DisplayHint))
+	}
+	w.clause("STATUS", tc.Status)
+	w.text("DESCRIPTION", tc.Description)
+	w.text("REFERENCE", tc.Reference)
+	w.clause("SYNTAX", formatSyntax(tc.Syntax, 4+clauseWidth))
+}
+
+func writeObjectIdentity(w *mibWriter, o *ObjectIdentity, value string) {
+	w.printf("%s OBJECT-IDENTITY\n", o.Name)
+	w.clause("STATUS", o.Status)
+	w.text("DESCRIPTION", o.Description)
+	w.text("REFERENCE", o.Reference)
+	w.printf("    ::= %s\n", value)
+}
+
+func writeObjectType(w *mibWriter, o *ObjectType, value string) {
+	w.printf("%s OBJECT-TYPE\n", o.Name)
+	w.clause("SYNTAX", formatSyntax(o.Syntax, 4+clauseWidth))
+	if o.Units != "" {
+		w.clause("UNITS", quote(o.Units))
+	}
+	w.clause("MAX-ACCESS", o.Access)
+	w.clause("STATUS", o.Status)
+	w.text("DESCRIPTION", o.Description)
+	w.text("REFERENCE", o.Reference)
+	if len(o.Index) > 0 {
+		idx := append([]string(nil), o.Index...)
+		if o.Implied {
+			idx[len(idx)-1] = "IMPLIED " + idx[len(idx)-1]
+		}
+		w.clause("INDEX", "{ "+strings.Join(idx, ", ")+" }")
+	}
+	if o.Augments != "" {
+		w.clause("AUGMENTS", "{ "+o.Augments+" }")
+	}
+	if o.DefVal != "" {
+		w.clause("DEFVAL", "{ "+formatSyntax(o.DefVal, 0)+" }")
+	}
+	w.printf("    ::= %s\n", value)
+}
+
+func writeNotificationType(w *mibWriter, n *NotificationType, value string) {
+	if a := n.Assignment; n.TrapType && a.Parent != "" && len(a.SubIDs) == 2 && a.SubIDs[0] == 0 {
+		writeTrapType(w, n)
+		return
+	}
+	w.printf("%s NOTIFICATION-TYPE\n", n.Name)
+	w.nameList("OBJECTS", n.Objects)
+	w.clause("STATUS", n.Status)
+	w.text("DESCRIPTION", n.Description)
+	w.text("REFERENCE", n.Reference)
+	w.printf("    ::= %s\n", value)
+}
+
+// writeTrapType writes a notification parsed from an SMIv1 TRAP-TYPE back
+// in that form, which has no STATUS and is numbered below its ENTERPRISE.
+func writeTrapType(w *mibWriter, n *NotificationType) {
+	w.printf("%s TRAP-TYPE\n", n.Name)
+	w.clause("ENTERPRISE", n.Assignment.Parent)
+	w.nameList("VARIABLES", n.Objects)
+	w.text("DESCRIPTION", n.Description)
+	w.text("REFERENCE", n.Reference)
+	w.printf("    ::= %d\n", n.Assignment.SubIDs[1])
+}
+
+func writeGroup(w *mibWriter, name, macro, keyword string, members []string, status, description, reference, value string) {
+	w.printf("%s %s\n", name, macro)
+	w.nameList(keyword, members)
+	w.clause("STATUS", status)
+	w.text("DESCRIPTION", description)
+	w.text("REFERENCE", reference)
+	w.printf("    ::= %s\n", value)
+}
+
+func writeModuleCompliance(w *mibWriter, c *ModuleCompliance, value string) {
+	w.printf("%s MODULE-COMPLIANCE\n", c.Name)
+	w.clause("STATUS", c.Status)
+	w.text("DESCRIPTION", c.Description)
+	w.text("REFERENCE", c.Reference)
+	for _, cm := range c.Modules {
+		if cm.Module == "" {
+			w.printf("\n    MODULE -- this module\n")
+		} else {
+			w.printf("\n    MODULE %s\n", cm.Module)
+		}
+		w.indent = "        "
+		w.nameList("MANDATORY-GROUPS", cm.MandatoryGroups)
+		for _, g := range cm.Groups {
+			w.printf("\n")
+			w.clause("GROUP", g.Name)
+			w.text("DESCRIPTION", g.Description)
+		}
+		for _, o := range cm.Objects {
+			w.printf("\n")
+			w.clause("OBJECT", o.Name)
+			w.clause("SYNTAX", formatSyntax(o.Syntax, len(w.indent)+clauseWidth))
+			w.clause("WRITE-SYNTAX", formatSyntax(o.WriteSyntax, len(w.indent)+clauseWidth))
+			w.clause("MIN-ACCESS", o.MinAccess)
+			w.text("DESCRIPTION", o.Description)
+		}
+		w.indent = "    "
+	}
+	w.printf("    ::= %s\n", value)
+}
+
+func writeAgentCapabilities(w *mibWriter, c *AgentCapabilities, value string) {
+	w.printf("%s AGENT-CAPABILITIES\n", c.Name)
+	w.text("PRODUCT-RELEASE", c.ProductRelease)
+	w.clause("STATUS", c.Status)
+	w.text("DESCRIPTION", c.Description)
+	w.text("REFERENCE", c.Reference)
+	for _, cm := range c.Supports {
+		w.printf("\n    SUPPORTS %s\n", cm.Module)
+		w.indent = "        "
+		w.nameList("INCLUDES", cm.Includes)
+		for _, v := range cm.Variations {
+			w.printf("\n")
+			w.clause("VARIATION", v.Name)
+			w.clause("SYNTAX", formatSyntax(v.Syntax, len(w.indent)+clauseWidth))
+			w.clause("WRITE-SYNTAX", formatSyntax(v.WriteSyntax, len(w.indent)+clauseWidth))
+			w.clause("ACCESS", v.Access)
+			w.nameList("CREATION-REQUIRES", v.CreationRequires)
+			if v.DefVal != "" {
+				w.clause("DEFVAL", "{ "+formatSyntax(v.DefVal, 0)+" }")
+			}
+			w.text("DESCRIPTION", v.Description)
+		}
+		w.indent = "    "
+	}
+	w.printf("    ::= %s\n", value)
+}
+
+// clauseWidth is the column width reserved for clause keywords so their
+// values line up.
+const clauseWidth = 13
+
+type mibWriter struct {
+	b      strings.Builder
+	indent string
+}
+
+func (w *mibWriter) printf(format string, args ...any) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+// clause writes "    KEYWORD      value", skipping empty values.
+func (w *mibWriter) clause(keyword, value string) {
+	if value == "" {
+		return
+	}
+	w.printf("%s%-*s%s\n", w.indent, clauseWidth, keyword, value)
+}
+
+// nameList writes "KEYWORD { a, b, c }", wrapping long lists one level
+// deeper than the clause.
+func (w *mibWriter) nameList(keyword string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	one := "{ " + strings.Join(names, ", ") + " }"
+	width := clauseWidth
+	if len(keyword) >= width {
+		width = len(keyword) + 1
+	}
+	if len(w.indent)+width+len(one) <= formatWidth {
+		w.printf("%s%-*s%s\n", w.indent, width, keyword, one)
+		return
+	}
+	w.printf("%s%s {\n", w.indent, keyword)
+	w.list(w.indent+"    ", names)
+	w.printf("%s}\n", w.indent)
+}
+
+// list writes comma-separated items wrapped at formatWidth, each line
+// starting with indent.
+func (w *mibWriter) list(indent string, items []string) {
+	line := indent
+	for i, item := range items {
+		if i < len(items)-1 {
+			item += ","
+		}
+		if line != indent && len(line)+1+len(item) > formatWidth {
+			w.printf("%s\n", line)
+			line = indent
+		}
+		if line != indent {
+			line += " "
+		}
+		line += item
+	}
+	w.printf("%s\n", line)
+}
+
+// text writes a DESCRIPTION-like clause with its string on the following
+// lines, re-flowing each paragraph to formatWidth.
+func (w *mibWriter) text(keyword, s string) {
+	if s == "" {
+		return
+	}
+	var lines []string
+	for i, para := range paragraphs(s) {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, wrapWords(strings.Fields(para), formatWidth-len(w.indent)-5)...)
+	}
+	w.quoted(keyword, lines)
+}
+
+// textLines writes a clause whose string keeps its original line breaks, as
+// is customary for ORGANIZATION and CONTACT-INFO.
+func (w *mibWriter) textLines(keyword, s string) {
+	if s == "" {
+		return
+	}
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(s, "\r", ""), "\n") {
+		lines = append(lines, strings.TrimSpace(l))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	w.quoted(keyword, lines)
+}
+
+func (w *mibWriter) quoted(keyword string, lines []string) {
+	w.printf("%s%s\n", w.indent, keyword)
+	for i, l := range lines {
+		prefix := w.indent + "     "
+		if i == 0 {
+			prefix = w.indent + "    \""
+		}
+		if l == "" {
+			prefix = ""
+		}
+		w.printf("%s%s", prefix, l)
+		if i == len(lines)-1 {
+			w.printf("\"")
+		}
+		w.printf("\n")
+	}
+	if len(lines) == 0 {
+		w.printf("%s    \"\"\n", w.indent)
+	}
+}
+
+// paragraphs splits text on blank lines.
+func paragraphs(s string) []string {
+	var out []string
+	var cur []string
+	for _, l := range strings.Split(strings.ReplaceAll(s, "\r", ""), "\n") {
+		if strings.TrimSpace(l) == "" {
+			if len(cur) > 0 {
+				out = append(out, strings.Join(cur, " "))
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, l)
+	}
+	if len(cur) > 0 {
+		out = append(out, strings.Join(cur, " "))
+	}
+	return out
+}
+
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range words {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func quote(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "\"" + s + "\""
+}
+
+// syntaxTokens splits a parsed SYNTAX (or DEFVAL) string back into its
+// tokens; the parser joins tokens with single spaces and quotes strings.
+func syntaxTokens(s string) []string {
+	var toks []string
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			break
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				toks = append(toks, s)
+				break
+			}
+			toks = append(toks, s[:end+2])
+			s = s[end+2:]
+			continue
+		}
+		end := strings.IndexByte(s, ' ')
+		if end < 0 {
+			end = len(s)
+		}
+		toks = append(toks, s[:end])
+		s = s[end:]
+	}
+	return toks
+}
+
+// formatSyntax renders a SYNTAX string in canonical spacing, e.g.
+// "INTEGER { up(1), down(2) }" or "OCTET STRING (SIZE (0..255))". When the
+// one-line form does not fit after column indent, the enumeration is broken
+// into one label per line.
+func formatSyntax(s string, indent int) string {
+	toks := syntaxTokens(s)
+	one := joinSyntax(toks, 0)
+	open := -1
+	for i, t := range toks {
+		if t == "{" {
+			open = i
+			break
+		}
+	}
+	if indent == 0 || open < 0 || indent+len(one) <= formatWidth {
+		return one
+	}
+	close := len(toks) - 1
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i] {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		if depth == 0 {
+			close = i
+			break
+		}
+	}
+	pad := strings.Repeat(" ", indent)
+	var b strings.Builder
+	b.WriteString(joinSyntax(toks[:open+1], 0))
+	var item []string
+	flush := func(last bool) {
+		if len(item) == 0 {
+			return
+		}
+		b.WriteString("\n" + pad + "    " + joinSyntax(item, 1))
+		if !last {
+			b.WriteString(",")
+		}
+		item = nil
+	}
+	depth = 0
+	for _, t := range toks[open+1 : close] {
+		switch t {
+		case "(", "{":
+			depth++
+		case ")", "}":
+			depth--
+		}
+		if t == "," && depth == 0 {
+			flush(false)
+			continue
+		}
+		item = append(item, t)
+	}
+	flush(true)
+	b.WriteString("\n" + pad + "}")
+	if rest := toks[close+1:]; len(rest) > 0 {
+		b.WriteString(" " + joinSyntax(rest, 0))
+	}
+	return b.String()
+}
+
+// joinSyntax joins tokens with canonical spacing; braces is the enumeration
+// nesting depth the tokens start at.
+func joinSyntax(toks []string, braces int) string {
+	var b strings.Builder
+	prev := ""
+	for i, t := range toks {
+		if i > 0 && syntaxSpace(prev, t, braces) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t)
+		switch t {
+		case "{":
+			braces++
+		case "}":
+			braces--
+		}
+		prev = t
+	}
+	return b.String()
+}
+
+func syntaxSpace(prev, t string, braces int) bool {
+	switch {
+	case prev == "(" || prev == ".." || t == ")" || t == "," || t == "..":
+		return false
+	case t == "(":
+		// enumeration labels bind to their value: up(1)
+		return braces == 0
+	}
+	return true
+}
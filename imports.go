@@ -0,0 +1,197 @@
+package mib_parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ImportProblemKind classifies a problem found by CheckImports.
+type ImportProblemKind string
+
+const (
+	// ImportMissing is a descriptor, type or macro that is used but neither
+	// defined in the module nor imported.
+	ImportMissing ImportProblemKind = "missing-import"
+	// ImportUnknownSymbol is a symbol imported from a loaded module that
+	// does not define it.
+	ImportUnknownSymbol ImportProblemKind = "unknown-symbol"
+	// ImportAmbiguous is a used name that is not imported and that several
+	// loaded modules define, so that the module it refers to is a guess.
+	ImportAmbiguous ImportProblemKind = "ambiguous"
+)
+
+// ImportProblem is a reference that IMPORTS do not account for.
+type ImportProblem struct {
+	Kind ImportProblemKind
+	// Module is the module with the problem.
+	Module string
+	// Name is the descriptor, type or macro name.
+	Name string
+	// Definition is the definition that refers to Name, or for a macro the
+	// first definition written with it; empty for imports.
+	Definition string
+	// Pos is the source position of Definition, when known.
+	Pos Position
+	// From is the module an unknown symbol is imported from.
+	From string
+	// Candidates lists the modules that define Name, sorted by name. When
+	// no loaded module does, it holds the SMIv2 core module that does.
+	Candidates []string
+}
+
+func (p ImportProblem) String() string {
+	loc := p.Module
+	if p.Pos.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", p.Module, p.Pos.Line, p.Pos.Column)
+	}
+	user := "the module"
+	if p.Definition != "" {
+		user = p.Definition
+	}
+	var msg string
+	switch p.Kind {
+	case ImportMissing:
+		msg = fmt.Sprintf("%s uses %s, which is neither defined nor imported", user, p.Name)
+		if len(p.Candidates) == 1 {
+			msg += "; import it from " + p.Candidates[0]
+		}
+	case ImportUnknownSymbol:
+		msg = fmt.Sprintf("%s is imported from %s, which does not define it", p.Name, p.From)
+		if len(p.Candidates) > 0 {
+			msg += "; it is defined in " + strings.Join(p.Candidates, ", ")
+		}
+	case ImportAmbiguous:
+		msg = fmt.Sprintf("%s uses %s, which is not imported and is defined in %s", user, p.Name, strings.Join(p.Candidates, ", "))
+	}
+	return fmt.Sprintf("%s: %s [%s]", loc, msg, p.Kind)
+}
+
+// asn1Values are the OID roots ASN.1 itself defines; they need no import.
+var asn1Values = map[string]bool{
+	"ccitt":           true,
+	"iso":             true,
+	"joint-iso-ccitt": true,
+}
+
+// CheckImports checks that every descriptor, type and macro name a module
+// uses is defined in the module or imported from a module that defines it.
+// It reports names that are missing from IMPORTS, imported symbols the
+// named module does not define, and unimported names that several loaded
+// modules define. Imports from modules that are not loaded are not checked;
+// see DependencyGraph for those. The problems are sorted by module, source
+// position and name.
+func (r *Registry) CheckImports() []ImportProblem {
+	defined := map[string]map[string]bool{}
+	for name, m := range r.modules {
+		defined[name] = m.definedNames()
+	}
+	// candidates returns the modules that define name.
+	candidates := func(name string) []string {
+		var out []string
+		for _, mod := range sortedKeys(defined) {
+			if defined[mod][name] {
+				out = append(out, mod)
+			}
+		}
+		if len(out) == 0 && wellKnownSymbols[name] != "" {
+			out = []string{wellKnownSymbols[name]}
+		}
+		return out
+	}
+
+	var out []ImportProblem
+	for _, m := range r.Modules() {
+		local := defined[m.Name]
+		imported := map[string]bool{}
+		for _, imp := range m.Imports {
+			for _, s := range imp.Symbols {
+				imported[s] = true
+				exports, loaded := defined[imp.Module]
+				if !loaded || exports[s] || wellKnownSymbols[s] == imp.Module {
+					continue
+				}
+				var others []string
+				for _, c := range candidates(s) {
+					if c != m.Name {
+						others = append(others, c)
+					}
+				}
+				out = append(out, ImportProblem{Kind: ImportUnknownSymbol, Module: m.Name, Name: s, From: imp.Module, Candidates: others})
+			}
+		}
+		reported := map[[2]string]bool{}
+		for _, ref := range m.references() {
+			if local[ref.name] || imported[ref.name] || builtinTypes[ref.name] || asn1Values[ref.name] || wellKnownSymbols[ref.name] == m.Name {
+				continue
+			}
+			if ref.macro || ref.defVal && !r.isOIDDefVal(m, ref) {
+				continue
+			}
+			if reported[[2]string{ref.from, ref.name}] {
+				continue
+			}
+			reported[[2]string{ref.from, ref.name}] = true
+			p := ImportProblem{Kind: ImportMissing, Module: m.Name, Name: ref.name, Definition: ref.from, Candidates: candidates(ref.name)}
+			if len(p.Candidates) > 1 {
+				p.Kind = ImportAmbiguous
+			}
+			if o, ok := m.Object(ref.from); ok {
+				p.Pos = o.ObjectPos()
+			} else if tc, ok := m.TextualConventions[ref.from]; ok {
+				p.Pos = tc.Pos
+			}
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		if a.Pos.Line != b.Pos.Line {
+			return a.Pos.Line < b.Pos.Line
+		}
+		if a.Pos.Column != b.Pos.Column {
+			return a.Pos.Column < b.Pos.Column
+		}
+		return a.Name < b.Name
+	})
+	return out
+}
+
+// isOIDDefVal reports whether a DEFVAL identifier names an OID value rather
+// than an enumeration label, judging by the SYNTAX of the object.
+func (r *Registry) isOIDDefVal(m *Module, ref reference) bool {
+	o, ok := m.ObjectsByName[ref.from]
+	if !ok {
+		return false
+	}
+	syn := r.ResolveSyntax(m, o.Syntax)
+	for _, n := range syn.NamedNumbers {
+		if n.Name == ref.name {
+			return false
+		}
+	}
+	return syn.Base == "OBJECT IDENTIFIER"
+}
+
+// definedNames returns every name module m defines and so exports:
+// definitions with an OID, textual conventions, other type assignments and
+// macros.
+func (m *Module) definedNames() map[string]bool {
+	out := map[string]bool{}
+	for _, o := range m.Objects() {
+		out[o.ObjectName()] = true
+	}
+	for name := range m.TextualConventions {
+		out[name] = true
+	}
+	for _, name := range m.Types {
+		out[name] = true
+	}
+	for _, name := range m.Macros {
+		out[name] = true
+	}
+	return out
+}
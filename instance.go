@@ -0,0 +1,129 @@
+package mib_parser
+
+// Instance is an instance OID broken down through the MIB: the object it
+// belongs to and, for a column, the values of its row's INDEX objects.
+type Instance struct {
+	// Symbol is the longest known prefix of the OID.
+	Symbol Symbol
+	// Module and Object are the OBJECT-TYPE behind Symbol; nil when Symbol
+	// is some other kind of node.
+	Module *Module
+	Object *ObjectType
+	// Suffix is the rest of the OID after Symbol, e.g. [0] for a scalar.
+	Suffix OID
+	// Row is the conceptual row of a column, and Index the values its
+	// INDEX (or the INDEX of the row it AUGMENTS) encodes in Suffix. Both
+	// are nil when the object is not a column or Suffix does not decode.
+	Row   *ObjectType
+	Index []IndexValue
+}
+
+// IndexValue is the value of one INDEX object in an instance suffix.
+type IndexValue struct {
+	// Module and Object are the index object.
+	Module *Module
+	Object *ObjectType
+	// Arcs is the part of the suffix that encodes the value, including
+	// any length prefix.
+	Arcs []int
+	// Value is the decoded value, typed by the object's SYNTAX.
+	Value Value
+}
+
+// Instance breaks oid down through the registry. It reports false when no
+// prefix of oid is known.
+func (r *Registry) Instance(oid OID) (*Instance, bool) {
+	sym, rest, ok := r.SymbolAt(oid)
+	if !ok {
+		return nil, false
+	}
+	in := &Instance{Symbol: sym, Suffix: rest}
+	m, ok := r.modules[sym.Module]
+	if !ok || m.ObjectsByName[sym.Name] == nil {
+		return in, true
+	}
+	in.Module, in.Object = m, m.ObjectsByName[sym.Name]
+	index := r.indexOf(sym)
+	if index == nil {
+		return in, true
+	}
+	parts, ok := splitIndex(index, rest)
+	if !ok {
+		return in, true
+	}
+	in.Row = m.ObjectsByName[in.Object.Assignment.Parent]
+	for i, idx := range index.objects {
+		in.Index = append(in.Index, IndexValue{
+			Module: idx.module,
+			Object: idx.obj,
+			Arcs:   parts[i].arcs,
+			Value:  indexValue(idx.syntax, parts[i].value),
+		})
+	}
+	return in, true
+}
+
+// indexPart is the encoding of one INDEX object in an instance suffix and
+// the value it carries without length prefix.
+type indexPart struct {
+	arcs  []int
+	value []int
+}
+
+// splitIndex cuts an instance suffix into one part per INDEX object
+// (RFC 2578 section 7.7). It fails unless the suffix is consumed exactly.
+func splitIndex(index *rowIndex, arcs []int) ([]indexPart, bool) {
+	var parts []indexPart
+	for i, idx := range index.objects {
+		implied := index.implied && i == len(index.objects)-1
+		n, start := 1, 0
+		switch idx.syntax.Base {
+		case "IpAddress", "NetworkAddress":
+			n = 4
+		case "OCTET STRING", "Opaque", "BITS", "OBJECT IDENTIFIER":
+			size, fixed := idx.syntax.FixedSize()
+			switch {
+			case fixed && idx.syntax.Base != "OBJECT IDENTIFIER":
+				n = size
+			case implied:
+				n = len(arcs)
+			default:
+				if len(arcs) == 0 {
+					return nil, false
+				}
+				n, start = arcs[0], 1
+			}
+		}
+		if n < 0 || start+n > len(arcs) {
+			return nil, false
+		}
+		parts = append(parts, indexPart{arcs: arcs[:start+n], value: arcs[start : start+n]})
+		arcs = arcs[start+n:]
+	}
+	if len(arcs) > 0 {
+		return nil, false
+	}
+	return parts, true
+}
+
+// indexValue types the value arcs of an INDEX object by its syntax.
+func indexValue(syn ResolvedSyntax, arcs []int) Value {
+	switch syn.Base {
+	case "OCTET STRING", "Opaque", "BITS", "IpAddress", "NetworkAddress":
+		b := make([]byte, len(arcs))
+		for i, arc := range arcs {
+			b[i] = byte(arc)
+		}
+		if typ, _ := syn.ValueType(); typ == TypeIpAddress {
+			return NewValue(TypeIpAddress, b)
+		}
+		return NewValue(TypeOctetString, b)
+	case "OBJECT IDENTIFIER":
+		return NewValue(TypeObjectIdentifier, append(OID(nil), arcs...))
+	}
+	typ, ok := syn.ValueType()
+	if !ok {
+		typ = TypeInteger
+	}
+	return NewValue(typ, int64(arcs[0]))
+}
@@ -0,0 +1,521 @@
+package mib_parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// JSON schema identification. The version is bumped whenever a field changes
+// meaning or is removed; new optional fields do not bump it.
+const (
+	JSONSchemaName    = "go-mib-parser/module"
+	JSONSchemaVersion = 1
+)
+
+// Definition classes used in the "class" field of the JSON schema. They
+// follow the naming of pysmi's JSON output.
+const (
+	classModuleIdentity    = "moduleidentity"
+	classObjectIdentifier  = "objectidentifier"
+	classObjectIdentity    = "objectidentity"
+	classTextualConvention = "textualconvention"
+	classObjectType        = "objecttype"
+	classNotificationType  = "notificationtype"
+	classObjectGroup       = "objectgroup"
+	classNotificationGroup = "notificationgroup"
+	classModuleCompliance  = "modulecompliance"
+	classAgentCapabilities = "agentcapabilities"
+)
+
+// jsonModule is the top-level JSON document. See the README for the schema.
+type jsonModule struct {
+	Schema          string                     `json:"schema"`
+	Version         int                        `json:"version"`
+	Module          string                     `json:"module"`
+	Imports         []jsonImport               `json:"imports,omitempty"`
+	Types           []string                   `json:"types,omitempty"`
+	Macros          []string                   `json:"macros,omitempty"`
+	DefinitionText  map[string]string          `json:"definitiontext,omitempty"`
+	MacroReferences []string                   `json:"macroreferences,omitempty"`
+	Definitions     map[string]*jsonDefinition `json:"definitions"`
+	Tree            []*jsonTreeNode            `json:"tree,omitempty"`
+}
+
+type jsonImport struct {
+	Module  string   `json:"module"`
+	Symbols []string `json:"symbols"`
+}
+
+// jsonDefinition is one named definition; which fields are set depends on
+// its class.
+type jsonDefinition struct {
+	Name          string                 `json:"name"`
+	Class         string                 `json:"class"`
+	OID           string                 `json:"oid,omitempty"`
+	Assignment    *jsonAssignment        `json:"assignment,omitempty"`
+	NodeType      string                 `json:"nodetype,omitempty"`
+	Syntax        *jsonSyntax            `json:"syntax,omitempty"`
+	MaxAccess     string                 `json:"maxaccess,omitempty"`
+	Status        string                 `json:"status,omitempty"`
+	DisplayHint   string                 `json:"displayhint,omitempty"`
+	Units         string                 `json:"units,omitempty"`
+	Description   string                 `json:"description,omitempty"`
+	Reference     string                 `json:"reference,omitempty"`
+	DefVal        string                 `json:"defval,omitempty"`
+	Indices       []jsonIndex            `json:"indices,omitempty"`
+	Augments      string                 `json:"augments,omitempty"`
+	Objects       []string               `json:"objects,omitempty"`
+	Notifications []string               `json:"notifications,omitempty"`
+	LastUpdated   string                 `json:"lastupdated,omitempty"`
+	Organization  string                 `json:"organization,omitempty"`
+	ContactInfo   string                 `json:"contactinfo,omitempty"`
+	Revisions     []jsonRevision         `json:"revisions,omitempty"`
+	Modules       []jsonComplianceModule `json:"modules,omitempty"`
+	TrapType      bool                   `json:"traptype,omitempty"`
+	Release       string                 `json:"productrelease,omitempty"`
+	Supports      []jsonSupportedModule  `json:"supports,omitempty"`
+	Position      *jsonPosition          `json:"position,omitempty"`
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonAssignment struct {
+	Parent string `json:"parent,omitempty"`
+	SubIDs []int  `json:"subids"`
+}
+
+// jsonSyntax carries the SYNTAX text verbatim plus its decoded structure.
+// Loaders only need Text; the rest is for consumers that do not want to
+// parse ASN.1.
+type jsonSyntax struct {
+	Text        string            `json:"text"`
+	Type        string            `json:"type"`
+	Enumeration []jsonNamedNumber `json:"enumeration,omitempty"`
+	Range       []jsonRange       `json:"range,omitempty"`
+	Size        []jsonRange       `json:"size,omitempty"`
+}
+
+type jsonNamedNumber struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+type jsonRange struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+type jsonIndex struct {
+	Object  string `json:"object"`
+	Implied bool   `json:"implied,omitempty"`
+}
+
+type jsonRevision struct {
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
+
+type jsonComplianceModule struct {
+	Module          string                 `json:"module,omitempty"`
+	MandatoryGroups []string               `json:"mandatorygroups,omitempty"`
+	Groups          []jsonComplianceGroup  `json:"groups,omitempty"`
+	Objects         []jsonComplianceObject `json:"objects,omitempty"`
+}
+
+type jsonComplianceGroup struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type jsonComplianceObject struct {
+	Name        string `json:"name"`
+	Syntax      string `json:"syntax,omitempty"`
+	WriteSyntax string `json:"writesyntax,omitempty"`
+	MinAccess   string `json:"minaccess,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type jsonSupportedModule struct {
+	Module     string          `json:"module"`
+	Includes   []string        `json:"includes,omitempty"`
+	Variations []jsonVariation `json:"variations,omitempty"`
+}
+
+type jsonVariation struct {
+	Name             string   `json:"name"`
+	Syntax           string   `json:"syntax,omitempty"`
+	WriteSyntax      string   `json:"writesyntax,omitempty"`
+	Access           string   `json:"access,omitempty"`
+	CreationRequires []string `json:"creationrequires,omitempty"`
+	DefVal           string   `json:"defval,omitempty"`
+	Description      string   `json:"description,omitempty"`
+}
+
+// jsonTreeNode is a node of the module's OID tree. Roots are definitions
+// whose parent is not defined in the module.
+type jsonTreeNode struct {
+	Name     string          `json:"name"`
+	OID      string          `json:"oid,omitempty"`
+	Class    string          `json:"class"`
+	Children []*jsonTreeNode `json:"children,omitempty"`
+
+	subID int
+}
+
+// MarshalModuleJSON serialises a module to the versioned JSON schema
+// documented in the README. The output is deterministic.
+func MarshalModuleJSON(m *Module) ([]byte, error) {
+	doc := jsonModule{
+		Schema:          JSONSchemaName,
+		Version:         JSONSchemaVersion,
+		Module:          m.Name,
+		Types:           m.Types,
+		Macros:          m.Macros,
+		DefinitionText:  m.DefinitionText,
+		MacroReferences: m.MacroReferences,
+		Definitions:     map[string]*jsonDefinition{},
+	}
+	for _, imp := range m.Imports {
+		doc.Imports = append(doc.Imports, jsonImport{Module: imp.Module, Symbols: imp.Symbols})
+	}
+	add := func(d *jsonDefinition, pos Position) {
+		if pos != (Position{}) {
+			d.Position = &jsonPosition{Line: pos.Line, Column: pos.Column}
+		}
+		doc.Definitions[d.Name] = d
+	}
+	if mi := m.ModuleIdentity; mi != nil {
+		d := &jsonDefinition{
+			Name: mi.Name, Class: classModuleIdentity, OID: mi.OID.String(), Assignment: toJSONAssignment(mi.Assignment),
+			LastUpdated: mi.LastUpdated, Organization: mi.Organization, ContactInfo: mi.ContactInfo, Description: mi.Description,
+		}
+		for _, r := range mi.Revisions {
+			d.Revisions = append(d.Revisions, jsonRevision{Date: r.Date, Description: r.Description})
+		}
+		add(d, mi.Pos)
+	}
+	for _, n := range m.ObjectIdentifiers {
+		add(&jsonDefinition{Name: n.Name, Class: classObjectIdentifier, OID: n.OID.String(), Assignment: toJSONAssignment(n.Assignment)}, n.Pos)
+	}
+	for _, o := range m.ObjectIdentities {
+		add(&jsonDefinition{
+			Name: o.Name, Class: classObjectIdentity, OID: o.OID.String(), Assignment: toJSONAssignment(o.Assignment),
+			Status: o.Status, Description: o.Description, Reference: o.Reference,
+		}, o.Pos)
+	}
+	for _, tc := range m.TextualConventions {
+		add(&jsonDefinition{
+			Name: tc.Name, Class: classTextualConvention, Syntax: toJSONSyntax(tc.Syntax), DisplayHint: tc.DisplayHint,
+			Status: tc.Status, Description: tc.Description, Reference: tc.Reference,
+		}, tc.Pos)
+	}
+	for _, o := range m.ObjectsByName {
+		d := &jsonDefinition{
+			Name: o.Name, Class: classObjectType, OID: o.OID.String(), Assignment: toJSONAssignment(o.Assignment),
+			NodeType: nodeType(m, o), Syntax: toJSONSyntax(o.Syntax), MaxAccess: o.Access, Status: o.Status,
+			Units: o.Units, Description: o.Description, Reference: o.Reference, DefVal: o.DefVal, Augments: o.Augments,
+		}
+		for i, idx := range o.Index {
+			d.Indices = append(d.Indices, jsonIndex{Object: idx, Implied: o.Implied && i == len(o.Index)-1})
+		}
+		add(d, o.Pos)
+	}
+	for _, n := range m.NotificationTypes {
+		add(&jsonDefinition{
+			Name: n.Name, Class: classNotificationType, OID: n.OID.String(), Assignment: toJSONAssignment(n.Assignment),
+			Objects: n.Objects, Status: n.Status, Description: n.Description, Reference: n.Reference,
+			TrapType: n.TrapType,
+		}, n.Pos)
+	}
+	for _, g := range m.ObjectGroups {
+		add(&jsonDefinition{
+			Name: g.Name, Class: classObjectGroup, OID: g.OID.String(), Assignment: toJSONAssignment(g.Assignment),
+			Objects: g.Objects, Status: g.Status, Description: g.Description, Reference: g.Reference,
+		}, g.Pos)
+	}
+	for _, g := range m.NotificationGroups {
+		add(&jsonDefinition{
+			Name: g.Name, Class: classNotificationGroup, OID: g.OID.String(), Assignment: toJSONAssignment(g.Assignment),
+			Notifications: g.Notifications, Status: g.Status, Description: g.Description, Reference: g.Reference,
+		}, g.Pos)
+	}
+	for _, mc := range m.ModuleCompliances {
+		d := &jsonDefinition{
+			Name: mc.Name, Class: classModuleCompliance, OID: mc.OID.String(), Assignment: toJSONAssignment(mc.Assignment),
+			Status: mc.Status, Description: mc.Description, Reference: mc.Reference,
+		}
+		for _, cm := range mc.Modules {
+			jm := jsonComplianceModule{Module: cm.Module, MandatoryGroups: cm.MandatoryGroups}
+			for _, g := range cm.Groups {
+				jm.Groups = append(jm.Groups, jsonComplianceGroup(g))
+			}
+			for _, o := range cm.Objects {
+				jm.Objects = append(jm.Objects, jsonComplianceObject(o))
+			}
+			d.Modules = append(d.Modules, jm)
+		}
+		add(d, mc.Pos)
+	}
+	for _, ac := range m.AgentCapabilities {
+		d := &jsonDefinition{
+			Name: ac.Name, Class: classAgentCapabilities, OID: ac.OID.String(), Assignment: toJSONAssignment(ac.Assignment),
+			Release: ac.ProductRelease, Status: ac.Status, Description: ac.Description, Reference: ac.Reference,
+		}
+		for _, cm := range ac.Supports {
+			jm := jsonSupportedModule{Module: cm.Module, Includes: cm.Includes}
+			for _, v := range cm.Variations {
+				jm.Variations = append(jm.Variations, jsonVariation(v))
+			}
+			d.Supports = append(d.Supports, jm)
+		}
+		add(d, ac.Pos)
+	}
+	doc.Tree = buildJSONTree(doc.Definitions)
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// UnmarshalModuleJSON reconstructs a Module from JSON written by
+// MarshalModuleJSON. Documents with a newer schema version are rejected.
+func UnmarshalModuleJSON(data []byte) (*Module, error) {
+	var doc jsonModule
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("json: %w", err)
+	}
+	if doc.Schema != JSONSchemaName {
+		return nil, fmt.Errorf("json: unexpected schema %q", doc.Schema)
+	}
+	if doc.Version < 1 || doc.Version > JSONSchemaVersion {
+		return nil, fmt.Errorf("json: unsupported schema version %d", doc.Version)
+	}
+	m := &Module{
+		Name:               doc.Module,
+		ObjectIdentifiers:  map[string]*ObjectIdentifier{},
+		ObjectsByName:      map[string]*ObjectType{},
+		ObjectIdentities:   map[string]*ObjectIdentity{},
+		TextualConventions: map[string]*TextualConvention{},
+		NotificationTypes:  map[string]*NotificationType{},
+		ObjectGroups:       map[string]*ObjectGroup{},
+		NotificationGroups: map[string]*NotificationGroup{},
+		ModuleCompliances:  map[string]*ModuleCompliance{},
+		AgentCapabilities:  map[string]*AgentCapabilities{},
+	}
+	m.Types = append([]string(nil), doc.Types...)
+	m.Macros = append([]string(nil), doc.Macros...)
+	m.DefinitionText = maps.Clone(doc.DefinitionText)
+	m.MacroReferences = append([]string(nil), doc.MacroReferences...)
+	for _, imp := range doc.Imports {
+		m.Imports = append(m.Imports, Import{Module: imp.Module, Symbols: append([]string(nil), imp.Symbols...)})
+	}
+	for _, name := range sortedKeys(doc.Definitions) {
+		d := doc.Definitions[name]
+		oid, err := parseJSONOID(d.OID)
+		if err != nil {
+			return nil, fmt.Errorf("json: %s: %w", name, err)
+		}
+		var pos Position
+		if d.Position != nil {
+			pos = Position{Line: d.Position.Line, Column: d.Position.Column}
+		}
+		a := fromJSONAssignment(d.Assignment)
+		switch d.Class {
+		case classModuleIdentity:
+			mi := &ModuleIdentity{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a, LastUpdated: d.LastUpdated,
+				Organization: d.Organization, ContactInfo: d.ContactInfo, Description: d.Description,
+			}
+			for _, r := range d.Revisions {
+				mi.Revisions = append(mi.Revisions, Revision{Date: r.Date, Description: r.Description})
+			}
+			m.ModuleIdentity = mi
+		case classObjectIdentifier:
+			m.ObjectIdentifiers[name] = &ObjectIdentifier{Name: d.Name, Pos: pos, OID: oid, Assignment: a}
+		case classObjectIdentity:
+			m.ObjectIdentities[name] = &ObjectIdentity{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a,
+				Status: d.Status, Description: d.Description, Reference: d.Reference,
+			}
+		case classTextualConvention:
+			m.TextualConventions[name] = &TextualConvention{
+				Name: d.Name, Pos: pos, DisplayHint: d.DisplayHint, Status: d.Status,
+				Description: d.Description, Reference: d.Reference, Syntax: syntaxText(d.Syntax),
+			}
+		case classObjectType:
+			o := &ObjectType{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a, Syntax: syntaxText(d.Syntax), Units: d.Units,
+				Access: d.MaxAccess, Status: d.Status, Description: d.Description, Reference: d.Reference,
+				Augments: d.Augments, DefVal: d.DefVal,
+			}
+			for _, idx := range d.Indices {
+				o.Index = append(o.Index, idx.Object)
+				o.Implied = o.Implied || idx.Implied
+			}
+			m.ObjectsByName[name] = o
+		case classNotificationType:
+			m.NotificationTypes[name] = &NotificationType{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a, Objects: append([]string(nil), d.Objects...),
+				Status: d.Status, Description: d.Description, Reference: d.Reference, TrapType: d.TrapType,
+			}
+		case classObjectGroup:
+			m.ObjectGroups[name] = &ObjectGroup{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a, Objects: append([]string(nil), d.Objects...),
+				Status: d.Status, Description: d.Description, Reference: d.Reference,
+			}
+		case classNotificationGroup:
+			m.NotificationGroups[name] = &NotificationGroup{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a, Notifications: append([]string(nil), d.Notifications...),
+				Status: d.Status, Description: d.Description, Reference: d.Reference,
+			}
+		case classModuleCompliance:
+			mc := &ModuleCompliance{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a,
+				Status: d.Status, Description: d.Description, Reference: d.Reference,
+			}
+			for _, jm := range d.Modules {
+				cm := ComplianceModule{Module: jm.Module, MandatoryGroups: append([]string(nil), jm.MandatoryGroups...)}
+				for _, g := range jm.Groups {
+					cm.Groups = append(cm.Groups, ComplianceGroup(g))
+				}
+				for _, o := range jm.Objects {
+					cm.Objects = append(cm.Objects, ComplianceObject(o))
+				}
+				mc.Modules = append(mc.Modules, cm)
+			}
+			m.ModuleCompliances[name] = mc
+		case classAgentCapabilities:
+			ac := &AgentCapabilities{
+				Name: d.Name, Pos: pos, OID: oid, Assignment: a, ProductRelease: d.Release,
+				Status: d.Status, Description: d.Description, Reference: d.Reference,
+			}
+			for _, jm := range d.Supports {
+				cm := CapabilitiesModule{Module: jm.Module, Includes: append([]string(nil), jm.Includes...)}
+				for _, v := range jm.Variations {
+					v.CreationRequires = append([]string(nil), v.CreationRequires...)
+					cm.Variations = append(cm.Variations, Variation(v))
+				}
+				ac.Supports = append(ac.Supports, cm)
+			}
+			m.AgentCapabilities[name] = ac
+		default:
+			return nil, fmt.Errorf("json: %s: unknown class %q", name, d.Class)
+		}
+	}
+	m.bind()
+	return m, nil
+}
+
+// nodeType classifies an OBJECT-TYPE like pysmi does: table, row, column or scalar.
+func nodeType(m *Module, o *ObjectType) string {
+	if strings.HasPrefix(o.Syntax, "SEQUENCE OF") {
+		return "table"
+	}
+	if p, ok := m.ObjectsByName[o.Assignment.Parent]; ok {
+		if strings.HasPrefix(p.Syntax, "SEQUENCE OF") {
+			return "row"
+		}
+		if gp, ok := m.ObjectsByName[p.Assignment.Parent]; ok && strings.HasPrefix(gp.Syntax, "SEQUENCE OF") {
+			return "column"
+		}
+	}
+	return "scalar"
+}
+
+func toJSONAssignment(a OIDAssignment) *jsonAssignment {
+	if a.Parent == "" && len(a.SubIDs) == 0 {
+		return nil
+	}
+	return &jsonAssignment{Parent: a.Parent, SubIDs: a.SubIDs}
+}
+
+func fromJSONAssignment(a *jsonAssignment) OIDAssignment {
+	if a == nil {
+		return OIDAssignment{}
+	}
+	return newAssignment(a.Parent, a.SubIDs)
+}
+
+func toJSONSyntax(s string) *jsonSyntax {
+	if s == "" {
+		return nil
+	}
+	syn := ParseSyntax(s)
+	js := &jsonSyntax{Text: s, Type: syn.Base}
+	for _, n := range syn.NamedNumbers {
+		js.Enumeration = append(js.Enumeration, jsonNamedNumber(n))
+	}
+	for _, r := range syn.Ranges {
+		js.Range = append(js.Range, jsonRange(r))
+	}
+	for _, r := range syn.Sizes {
+		js.Size = append(js.Size, jsonRange(r))
+	}
+	return js
+}
+
+func syntaxText(s *jsonSyntax) string {
+	if s == nil {
+		return ""
+	}
+	return s.Text
+}
+
+func parseJSONOID(s string) (OID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return ParseOID(s)
+}
+
+func buildJSONTree(defs map[string]*jsonDefinition) []*jsonTreeNode {
+	nodes := map[string]*jsonTreeNode{}
+	for name, d := range defs {
+		if d.Class == classTextualConvention {
+			continue
+		}
+		n := &jsonTreeNode{Name: name, OID: d.OID, Class: d.Class}
+		if d.Assignment != nil && len(d.Assignment.SubIDs) > 0 {
+			n.subID = d.Assignment.SubIDs[len(d.Assignment.SubIDs)-1]
+		}
+		nodes[name] = n
+	}
+	var roots []*jsonTreeNode
+	for _, name := range sortedKeys(nodes) {
+		n := nodes[name]
+		a := defs[name].Assignment
+		if a != nil {
+			if parent, ok := nodes[a.Parent]; ok && a.Parent != name {
+				parent.Children = append(parent.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	var sortTree func([]*jsonTreeNode)
+	sortTree = func(list []*jsonTreeNode) {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].OID != "" && list[j].OID != "" {
+				return compareOIDStrings(list[i].OID, list[j].OID) < 0
+			}
+			if list[i].subID != list[j].subID {
+				return list[i].subID < list[j].subID
+			}
+			return list[i].Name < list[j].Name
+		})
+		for _, n := range list {
+			sortTree(n.Children)
+		}
+	}
+	sortTree(roots)
+	return roots
+}
+
+func compareOIDStrings(a, b string) int {
+	x, _ := parseJSONOID(a)
+	y, _ := parseJSONOID(b)
+	return x.Compare(y)
+}
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strconv"
 	"unicode"
 )
 
@@ -17,6 +18,9 @@ const (
 	TokenRParen       // )
 	TokenComma        // ,
 	TokenDot          // .
+	TokenDotDot       // .. (range separator)
+	TokenPipe         // | (constraint alternative)
+	TokenQuoted       // 'ff'H or '0101'B literal, Text keeps the quotes and suffix
 	TokenSemicolon    // ;
 	TokenColonColonEq // ::=
 	TokenAssignEq     // = (rare in MIBs)
@@ -36,6 +40,9 @@ type Lexer struct {
 	line   int
 	col    int
 	peeked *Token
+	// startLine and startCol are where the token being read begins.
+	startLine int
+	startCol  int
 }
 
 func New(input []byte) *Lexer {
@@ -59,6 +66,7 @@ func (l *Lexer) Next() Token {
 		return t
 	}
 	l.skipWhitespaceAndComments()
+	l.startLine, l.startCol = l.line, l.col
 	if l.eof() {
 		return l.mk(TokenEOF, "")
 	}
@@ -80,19 +88,25 @@ func (l *Lexer) Next() Token {
 		}
 		return Token{Type: TokenIdent, Text: string(s), Line: startLine, Col: startCol}
 	}
-	// Numbers
-	if unicode.IsDigit(r) {
+	// Numbers, including negative range bounds such as (-2147483648..2147483647)
+	if unicode.IsDigit(r) || (r == '-' && unicode.IsDigit(l.peekChar())) {
 		startLine, startCol := l.line, l.col
-		n := 0
+		s := []rune{r}
+		l.advance()
 		for !l.eof() && unicode.IsDigit(l.cur()) {
-			n = n*10 + int(l.cur()-'0')
+			s = append(s, l.cur())
 			l.advance()
 		}
-		return Token{Type: TokenNumber, Int: n, Text: "", Line: startLine, Col: startCol}
+		// Int saturates for values such as Counter64's 18446744073709551615;
+		// Text keeps the digits as written.
+		n, _ := strconv.Atoi(string(s))
+		return Token{Type: TokenNumber, Int: n, Text: string(s), Line: startLine, Col: startCol}
 	}
 	switch r {
 	case '"':
 		return l.readString()
+	case '\'':
+		return l.readQuoted()
 	case '{':
 		l.advance()
 		return l.mk(TokenLBrace, "{")
@@ -110,7 +124,14 @@ func (l *Lexer) Next() Token {
 		return l.mk(TokenComma, ",")
 	case '.':
 		l.advance()
+		if !l.eof() && l.cur() == '.' {
+			l.advance()
+			return l.mk(TokenDotDot, "..")
+		}
 		return l.mk(TokenDot, ".")
+	case '|':
+		l.advance()
+		return l.mk(TokenPipe, "|")
 	case ';':
 		l.advance()
 		return l.mk(TokenSemicolon, ";")
@@ -155,6 +176,26 @@ func (l *Lexer) readString() Token {
 	return Token{Type: TokenString, Text: string(s), Line: startLine, Col: startCol}
 }
 
+// readQuoted reads an ASN.1 hex or binary string literal such as 'ff'H.
+func (l *Lexer) readQuoted() Token {
+	startLine, startCol := l.line, l.col
+	s := []rune{l.cur()}
+	l.advance()
+	for !l.eof() && l.cur() != '\'' && l.cur() != '\n' {
+		s = append(s, l.cur())
+		l.advance()
+	}
+	if !l.eof() && l.cur() == '\'' {
+		s = append(s, l.cur())
+		l.advance()
+		if !l.eof() && (l.cur() == 'H' || l.cur() == 'h' || l.cur() == 'B' || l.cur() == 'b') {
+			s = append(s, l.cur())
+			l.advance()
+		}
+	}
+	return Token{Type: TokenQuoted, Text: string(s), Line: startLine, Col: startCol}
+}
+
 func (l *Lexer) readColonAssign() Token {
 	// consume first ':'
 	l.advance()
@@ -170,7 +211,7 @@ func (l *Lexer) readColonAssign() Token {
 }
 
 func (l *Lexer) mk(t TokenType, s string) Token {
-	return Token{Type: t, Text: s, Line: l.line, Col: l.col}
+	return Token{Type: t, Text: s, Line: l.startLine, Col: l.startCol}
 }
 
 func (l *Lexer) skipWhitespaceAndComments() {
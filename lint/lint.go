@@ -0,0 +1,164 @@
+// Package lint checks parsed MIB modules against the SMIv2 rules of
+// RFC 2578, RFC 2579 and RFC 2580, in the spirit of smilint.
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Severity ranks findings.
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity converts "info", "warning" or "error" to a Severity.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{Info, Warning, Error} {
+		if strings.EqualFold(s, sev.String()) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("lint: unknown severity %q", s)
+}
+
+// Finding is a single rule violation.
+type Finding struct {
+	// Rule is the ID of the rule that produced the finding (e.g., "counter-defval").
+	Rule string
+	// Severity is the rule's severity.
+	Severity Severity
+	// Module is the name of the module the finding is about.
+	Module string
+	// Name is the definition the finding is about; empty for module-level findings.
+	Name string
+	// Pos is the source position of that definition, when known.
+	Pos mib_parser.Position
+	// Message describes the violation.
+	Message string
+}
+
+// String formats the finding like a compiler diagnostic:
+// "IF-MIB:120:1: error: message [rule-id]".
+func (f Finding) String() string {
+	loc := f.Module
+	if f.Pos.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", f.Module, f.Pos.Line, f.Pos.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", loc, f.Severity, f.Message, f.Rule)
+}
+
+// Suppression silences findings. Empty fields match anything, so
+// Suppression{Rule: "object-not-in-group"} disables a rule entirely and
+// Suppression{Rule: "counter-defval", Name: "fooCount"} silences a single
+// finding.
+type Suppression struct {
+	Rule   string
+	Module string
+	Name   string
+	// Line matches findings whose definition starts on this line.
+	Line int
+}
+
+func (s Suppression) matches(f Finding) bool {
+	return (s.Rule == "" || s.Rule == f.Rule) &&
+		(s.Module == "" || s.Module == f.Module) &&
+		(s.Name == "" || s.Name == f.Name) &&
+		(s.Line == 0 || s.Line == f.Pos.Line)
+}
+
+// Options configures a lint run.
+type Options struct {
+	// MinSeverity drops findings below this severity.
+	MinSeverity Severity
+	// Suppress lists findings to drop.
+	Suppress []Suppression
+}
+
+// Lint runs all rules against the module and returns the findings that are
+// not suppressed, ordered by position and rule.
+func Lint(m *mib_parser.Module, opts Options) []Finding {
+	c := newContext(m)
+	for _, r := range rules {
+		if c.smiv1 && r.smiv2Only {
+			continue
+		}
+		c.report = func(name string, pos mib_parser.Position, format string, args ...any) {
+			c.findings = append(c.findings, Finding{
+				Rule:     r.ID,
+				Severity: r.Severity,
+				Module:   m.Name,
+				Name:     name,
+				Pos:      pos,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+		r.check(c)
+	}
+	var out []Finding
+next:
+	for _, f := range c.findings {
+		if f.Severity < opts.MinSeverity {
+			continue
+		}
+		for _, s := range opts.Suppress {
+			if s.matches(f) {
+				continue next
+			}
+		}
+		out = append(out, f)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Pos.Line != out[j].Pos.Line {
+			return out[i].Pos.Line < out[j].Pos.Line
+		}
+		if out[i].Rule != out[j].Rule {
+			return out[i].Rule < out[j].Rule
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+var inlineRe = regexp.MustCompile(`--\s*lint:ignore\s+([A-Za-z0-9,\s-]+?)\s*(?:--|$)`)
+
+// InlineSuppressions scans MIB source for "-- lint:ignore <rule>[,<rule>]"
+// comments. A comment silences the listed rules for a definition that
+// starts on the same line or on the line after it.
+func InlineSuppressions(src []byte) []Suppression {
+	var out []Suppression
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	sc.Buffer(make([]byte, 0, 64*1024), len(src)+1)
+	for line := 1; sc.Scan(); line++ {
+		m := inlineRe.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		for _, id := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			out = append(out, Suppression{Rule: id, Line: line}, Suppression{Rule: id, Line: line + 1})
+		}
+	}
+	return out
+}
@@ -0,0 +1,558 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Rule describes a check. The IDs are stable and are what suppressions refer to.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+
+	smiv2Only bool
+	check     func(c *context)
+}
+
+// Rules returns all rules in the order they run.
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+var rules = []Rule{
+	{ID: "module-identity-missing", Severity: Error, smiv2Only: true, check: checkModuleIdentity,
+		Description: "an SMIv2 module must contain exactly one MODULE-IDENTITY (RFC 2578 5)"},
+	{ID: "last-updated-format", Severity: Error, smiv2Only: true, check: checkLastUpdated,
+		Description: "LAST-UPDATED and REVISION dates must be YYYYMMDDHHMMZ (RFC 2578 2)"},
+	{ID: "revision-last-updated", Severity: Warning, smiv2Only: true, check: checkRevisionLastUpdated,
+		Description: "the newest REVISION should match LAST-UPDATED (RFC 2578 5.5)"},
+	{ID: "revision-order", Severity: Warning, smiv2Only: true, check: checkRevisionOrder,
+		Description: "REVISION clauses should be in reverse chronological order (RFC 2578 5.5)"},
+	{ID: "descriptor-chars", Severity: Error, check: checkDescriptorChars,
+		Description: "descriptors consist of letters, digits and hyphens and start with a letter (RFC 2578 3.1)"},
+	{ID: "descriptor-case", Severity: Error, check: checkDescriptorCase,
+		Description: "value descriptors start lower-case, type names upper-case (RFC 2578 3.1)"},
+	{ID: "descriptor-length", Severity: Error, check: checkDescriptorLength(64),
+		Description: "descriptors must not exceed 64 characters (RFC 2578 3.1)"},
+	{ID: "descriptor-length-32", Severity: Warning, check: checkDescriptorLength(32),
+		Description: "descriptors should not exceed 32 characters (RFC 2578 3.1)"},
+	{ID: "descriptor-hyphen", Severity: Warning, smiv2Only: true, check: checkDescriptorHyphen,
+		Description: "hyphens are not allowed in SMIv2 descriptors (RFC 2578 3.1)"},
+	{ID: "status-value", Severity: Error, smiv2Only: true, check: checkStatus,
+		Description: "STATUS must be current, deprecated or obsolete (RFC 2578 7.4)"},
+	{ID: "access-value", Severity: Error, smiv2Only: true, check: checkAccess,
+		Description: "MAX-ACCESS must be one of the SMIv2 values (RFC 2578 7.3)"},
+	{ID: "description-missing", Severity: Error, smiv2Only: true, check: checkDescription,
+		Description: "definitions must carry a DESCRIPTION (RFC 2578 5-8)"},
+	{ID: "table-access", Severity: Error, check: checkTableAccess,
+		Description: "tables and rows must be not-accessible (RFC 2578 7.3)"},
+	{ID: "row-index-missing", Severity: Error, check: checkRowIndex,
+		Description: "a row must have an INDEX or AUGMENTS clause (RFC 2578 7.7, 7.8)"},
+	{ID: "index-unknown", Severity: Error, check: checkIndexKnown,
+		Description: "INDEX and AUGMENTS must name defined or imported objects"},
+	{ID: "index-accessible", Severity: Warning, smiv2Only: true, check: checkIndexAccessible,
+		Description: "index columns of a row should be not-accessible unless every column is an index (RFC 2578 7.7)"},
+	{ID: "not-accessible-misuse", Severity: Error, smiv2Only: true, check: checkNotAccessible,
+		Description: "only tables, rows and index columns may be not-accessible (RFC 2578 7.3)"},
+	{ID: "counter-defval", Severity: Error, check: checkCounterDefVal,
+		Description: "Counter32 and Counter64 objects must not have a DEFVAL (RFC 2578 7.9)"},
+	{ID: "counter-access", Severity: Error, smiv2Only: true, check: checkCounterAccess,
+		Description: "Counter32 and Counter64 objects must be read-only or accessible-for-notify (RFC 2578 7.1.6, 7.1.10)"},
+	{ID: "object-not-in-group", Severity: Warning, smiv2Only: true, check: checkObjectInGroup,
+		Description: "accessible objects should be members of an OBJECT-GROUP (RFC 2580 3.1)"},
+	{ID: "notification-not-in-group", Severity: Warning, smiv2Only: true, check: checkNotificationInGroup,
+		Description: "notifications should be members of a NOTIFICATION-GROUP (RFC 2580 4.1)"},
+	{ID: "group-member-unknown", Severity: Error, check: checkGroupMembers,
+		Description: "group members must be defined or imported (RFC 2580 3.1, 4.1)"},
+	{ID: "group-member-not-accessible", Severity: Error, check: checkGroupMemberAccess,
+		Description: "OBJECT-GROUP members must not be not-accessible (RFC 2580 3.1)"},
+	{ID: "notification-object-not-accessible", Severity: Error, check: checkNotificationObjects,
+		Description: "NOTIFICATION-TYPE OBJECTS must not be not-accessible (RFC 2578 8.1)"},
+	{ID: "table-naming", Severity: Info, check: checkTableNaming,
+		Description: "tables conventionally end in Table and rows in Entry"},
+}
+
+// context holds what rules commonly need to know about a module.
+type context struct {
+	mod      *mib_parser.Module
+	smiv1    bool
+	core     bool
+	imported map[string]bool
+	tables   map[string]*mib_parser.ObjectType
+	rows     map[string]*mib_parser.ObjectType
+	columns  map[string][]*mib_parser.ObjectType // keyed by row name
+	grouped  map[string]bool
+
+	report   func(name string, pos mib_parser.Position, format string, args ...any)
+	findings []Finding
+}
+
+func newContext(m *mib_parser.Module) *context {
+	c := &context{
+		mod:      m,
+		imported: map[string]bool{},
+		tables:   map[string]*mib_parser.ObjectType{},
+		rows:     map[string]*mib_parser.ObjectType{},
+		columns:  map[string][]*mib_parser.ObjectType{},
+		grouped:  map[string]bool{},
+		core:     coreModules[m.Name],
+	}
+	for _, imp := range m.Imports {
+		switch imp.Module {
+		case "RFC1155-SMI", "RFC1065-SMI", "RFC-1212", "RFC-1215":
+			c.smiv1 = true
+		}
+		for _, s := range imp.Symbols {
+			c.imported[s] = true
+		}
+	}
+	for _, o := range c.objects() {
+		if strings.HasPrefix(o.Syntax, "SEQUENCE OF") {
+			c.tables[o.Name] = o
+		}
+	}
+	for _, o := range c.objects() {
+		if _, ok := c.tables[o.Assignment.Parent]; ok {
+			c.rows[o.Name] = o
+		}
+	}
+	for _, o := range c.objects() {
+		if _, ok := c.rows[o.Assignment.Parent]; ok {
+			c.columns[o.Assignment.Parent] = append(c.columns[o.Assignment.Parent], o)
+		}
+	}
+	for _, g := range m.ObjectGroups {
+		for _, name := range g.Objects {
+			c.grouped[name] = true
+		}
+	}
+	for _, g := range m.NotificationGroups {
+		for _, name := range g.Notifications {
+			c.grouped[name] = true
+		}
+	}
+	return c
+}
+
+// objects returns the module's OBJECT-TYPEs sorted by name so that rules
+// report in a stable order.
+func (c *context) objects() []*mib_parser.ObjectType {
+	out := make([]*mib_parser.ObjectType, 0, len(c.mod.ObjectsByName))
+	for _, o := range c.mod.ObjectsByName {
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// definition is the part common to every named definition.
+type definition struct {
+	name, kind, status, description string
+	pos                             mib_parser.Position
+	isType                          bool
+}
+
+func (c *context) definitions() []definition {
+	m := c.mod
+	var out []definition
+	if mi := m.ModuleIdentity; mi != nil {
+		out = append(out, definition{name: mi.Name, kind: "MODULE-IDENTITY", status: "current", description: mi.Description, pos: mi.Pos})
+	}
+	for _, n := range m.ObjectIdentifiers {
+		out = append(out, definition{name: n.Name, kind: "OBJECT IDENTIFIER", pos: n.Pos})
+	}
+	for _, o := range m.ObjectIdentities {
+		out = append(out, definition{name: o.Name, kind: "OBJECT-IDENTITY", status: o.Status, description: o.Description, pos: o.Pos})
+	}
+	for _, tc := range m.TextualConventions {
+		out = append(out, definition{name: tc.Name, kind: "TEXTUAL-CONVENTION", status: tc.Status, description: tc.Description, pos: tc.Pos, isType: true})
+	}
+	for _, o := range m.ObjectsByName {
+		out = append(out, definition{name: o.Name, kind: "OBJECT-TYPE", status: o.Status, description: o.Description, pos: o.Pos})
+	}
+	for _, n := range m.NotificationTypes {
+		out = append(out, definition{name: n.Name, kind: "NOTIFICATION-TYPE", status: n.Status, description: n.Description, pos: n.Pos})
+	}
+	for _, g := range m.ObjectGroups {
+		out = append(out, definition{name: g.Name, kind: "OBJECT-GROUP", status: g.Status, description: g.Description, pos: g.Pos})
+	}
+	for _, g := range m.NotificationGroups {
+		out = append(out, definition{name: g.Name, kind: "NOTIFICATION-GROUP", status: g.Status, description: g.Description, pos: g.Pos})
+	}
+	for _, mc := range m.ModuleCompliances {
+		out = append(out, definition{name: mc.Name, kind: "MODULE-COMPLIANCE", status: mc.Status, description: mc.Description, pos: mc.Pos})
+	}
+	for _, ac := range m.AgentCapabilities {
+		out = append(out, definition{name: ac.Name, kind: "AGENT-CAPABILITIES", status: ac.Status, description: ac.Description, pos: ac.Pos})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// defined reports whether name is defined in the module or imported into it.
+func (c *context) defined(name string) bool {
+	if c.imported[name] {
+		return true
+	}
+	_, ok := c.mod.ObjectsByName[name]
+	return ok
+}
+
+// baseType resolves a SYNTAX to its base type name, following textual
+// conventions defined in the same module.
+func (c *context) baseType(syntax string) string {
+	for range 8 {
+		base := syntax
+		if i := strings.IndexAny(base, "{("); i >= 0 {
+			base = base[:i]
+		}
+		base = strings.TrimSpace(base)
+		tc, ok := c.mod.TextualConventions[base]
+		if !ok {
+			return base
+		}
+		syntax = tc.Syntax
+	}
+	return ""
+}
+
+func (c *context) isCounter(o *mib_parser.ObjectType) bool {
+	base := c.baseType(o.Syntax)
+	return base == "Counter32" || base == "Counter64" || base == "Counter"
+}
+
+// isIndexColumn reports whether o is named in the INDEX of its own row.
+func (c *context) isIndexColumn(o *mib_parser.ObjectType) bool {
+	row, ok := c.rows[o.Assignment.Parent]
+	if !ok {
+		return false
+	}
+	for _, idx := range row.Index {
+		if idx == o.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// coreModules define the SMI itself and predate some of its rules: they
+// have no MODULE-IDENTITY and SNMPv2-SMI keeps the SMIv1 descriptor mib-2.
+var coreModules = map[string]bool{
+	"SNMPv2-SMI":  true,
+	"SNMPv2-TC":   true,
+	"SNMPv2-CONF": true,
+}
+
+func checkModuleIdentity(c *context) {
+	if c.mod.ModuleIdentity == nil && !c.core {
+		c.report("", mib_parser.Position{}, "module %s has no MODULE-IDENTITY", c.mod.Name)
+	}
+}
+
+func checkLastUpdated(c *context) {
+	mi := c.mod.ModuleIdentity
+	if mi == nil {
+		return
+	}
+	if !mib_parser.ValidDate(mi.LastUpdated) {
+		c.report(mi.Name, mi.Pos, "invalid LAST-UPDATED %q", mi.LastUpdated)
+	}
+	for _, r := range mi.Revisions {
+		if !mib_parser.ValidDate(r.Date) {
+			c.report(mi.Name, mi.Pos, "invalid REVISION date %q", r.Date)
+		}
+	}
+}
+
+// fullDate expands two-digit years, which RFC 2578 defines as 19xx.
+func fullDate(d string) string {
+	if len(d) == 11 {
+		return "19" + d
+	}
+	return d
+}
+
+func checkRevisionLastUpdated(c *context) {
+	mi := c.mod.ModuleIdentity
+	if mi == nil {
+		return
+	}
+	if len(mi.Revisions) == 0 {
+		c.report(mi.Name, mi.Pos, "no REVISION clause for LAST-UPDATED %s", mi.LastUpdated)
+		return
+	}
+	for _, r := range mi.Revisions {
+		if fullDate(r.Date) == fullDate(mi.LastUpdated) {
+			return
+		}
+	}
+	c.report(mi.Name, mi.Pos, "no REVISION matches LAST-UPDATED %s", mi.LastUpdated)
+}
+
+func checkRevisionOrder(c *context) {
+	mi := c.mod.ModuleIdentity
+	if mi == nil {
+		return
+	}
+	for i := 1; i < len(mi.Revisions); i++ {
+		if fullDate(mi.Revisions[i].Date) > fullDate(mi.Revisions[i-1].Date) {
+			c.report(mi.Name, mi.Pos, "REVISION %s is listed after older REVISION %s", mi.Revisions[i].Date, mi.Revisions[i-1].Date)
+		}
+	}
+}
+
+func checkDescriptorChars(c *context) {
+	for _, d := range c.definitions() {
+		for i, r := range d.name {
+			letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+			if i == 0 && !letter || !letter && (r < '0' || r > '9') && r != '-' {
+				c.report(d.name, d.pos, "descriptor %q contains invalid character %q", d.name, r)
+				break
+			}
+		}
+		if strings.HasSuffix(d.name, "-") || strings.Contains(d.name, "--") {
+			c.report(d.name, d.pos, "descriptor %q must not end in a hyphen or contain two consecutive hyphens", d.name)
+		}
+	}
+}
+
+func checkDescriptorCase(c *context) {
+	for _, d := range c.definitions() {
+		if d.name == "" {
+			continue
+		}
+		first := d.name[0]
+		switch {
+		case d.isType && !(first >= 'A' && first <= 'Z'):
+			c.report(d.name, d.pos, "type name %s must start with an upper-case letter", d.name)
+		case !d.isType && !(first >= 'a' && first <= 'z'):
+			c.report(d.name, d.pos, "descriptor %s must start with a lower-case letter", d.name)
+		}
+	}
+}
+
+func checkDescriptorLength(max int) func(c *context) {
+	return func(c *context) {
+		for _, d := range c.definitions() {
+			if len(d.name) > max {
+				c.report(d.name, d.pos, "descriptor %s is %d characters long, more than %d", d.name, len(d.name), max)
+			}
+		}
+	}
+}
+
+func checkDescriptorHyphen(c *context) {
+	if c.core {
+		return
+	}
+	for _, d := range c.definitions() {
+		if !d.isType && strings.Contains(d.name, "-") {
+			c.report(d.name, d.pos, "descriptor %s contains a hyphen", d.name)
+		}
+	}
+}
+
+func checkStatus(c *context) {
+	for _, d := range c.definitions() {
+		if d.kind == "MODULE-IDENTITY" || d.kind == "OBJECT IDENTIFIER" {
+			continue
+		}
+		switch d.status {
+		case "current", "deprecated", "obsolete":
+		default:
+			c.report(d.name, d.pos, "%s %s has invalid STATUS %q", d.kind, d.name, d.status)
+		}
+	}
+}
+
+func checkAccess(c *context) {
+	for _, o := range c.objects() {
+		switch o.Access {
+		case "not-accessible", "accessible-for-notify", "read-only", "read-write", "read-create":
+		default:
+			c.report(o.Name, o.Pos, "%s has invalid MAX-ACCESS %q", o.Name, o.Access)
+		}
+	}
+}
+
+func checkDescription(c *context) {
+	for _, d := range c.definitions() {
+		if d.kind == "OBJECT IDENTIFIER" {
+			continue
+		}
+		if strings.TrimSpace(d.description) == "" {
+			c.report(d.name, d.pos, "%s %s has no DESCRIPTION", d.kind, d.name)
+		}
+	}
+}
+
+func checkTableAccess(c *context) {
+	for _, o := range c.objects() {
+		if _, ok := c.tables[o.Name]; ok && o.Access != "not-accessible" {
+			c.report(o.Name, o.Pos, "table %s must be not-accessible, not %s", o.Name, o.Access)
+		}
+		if _, ok := c.rows[o.Name]; ok && o.Access != "not-accessible" {
+			c.report(o.Name, o.Pos, "row %s must be not-accessible, not %s", o.Name, o.Access)
+		}
+	}
+}
+
+func checkRowIndex(c *context) {
+	for _, o := range c.objects() {
+		if _, ok := c.rows[o.Name]; ok && len(o.Index) == 0 && o.Augments == "" {
+			c.report(o.Name, o.Pos, "row %s has neither INDEX nor AUGMENTS", o.Name)
+		}
+	}
+}
+
+func checkIndexKnown(c *context) {
+	for _, o := range c.objects() {
+		for _, idx := range o.Index {
+			if !c.defined(idx) {
+				c.report(o.Name, o.Pos, "INDEX of %s names unknown object %s", o.Name, idx)
+			}
+		}
+		if o.Augments != "" && !c.defined(o.Augments) {
+			c.report(o.Name, o.Pos, "%s AUGMENTS unknown row %s", o.Name, o.Augments)
+		}
+	}
+}
+
+func checkIndexAccessible(c *context) {
+	for _, row := range c.objects() {
+		if _, ok := c.rows[row.Name]; !ok {
+			continue
+		}
+		cols := c.columns[row.Name]
+		allIndex := true
+		for _, col := range cols {
+			if !c.isIndexColumn(col) {
+				allIndex = false
+			}
+		}
+		if allIndex {
+			continue
+		}
+		for _, col := range cols {
+			if c.isIndexColumn(col) && col.Access != "not-accessible" {
+				c.report(col.Name, col.Pos, "index column %s of %s should be not-accessible, not %s", col.Name, row.Name, col.Access)
+			}
+		}
+	}
+}
+
+func checkNotAccessible(c *context) {
+	for _, o := range c.objects() {
+		if o.Access != "not-accessible" {
+			continue
+		}
+		_, table := c.tables[o.Name]
+		_, row := c.rows[o.Name]
+		if table || row || c.isIndexColumn(o) {
+			continue
+		}
+		c.report(o.Name, o.Pos, "%s is not-accessible but is not a table, row or index column", o.Name)
+	}
+}
+
+func checkCounterDefVal(c *context) {
+	for _, o := range c.objects() {
+		if o.DefVal != "" && c.isCounter(o) {
+			c.report(o.Name, o.Pos, "counter %s must not have a DEFVAL", o.Name)
+		}
+	}
+}
+
+func checkCounterAccess(c *context) {
+	for _, o := range c.objects() {
+		if !c.isCounter(o) {
+			continue
+		}
+		if o.Access != "read-only" && o.Access != "accessible-for-notify" {
+			c.report(o.Name, o.Pos, "counter %s must be read-only or accessible-for-notify, not %s", o.Name, o.Access)
+		}
+	}
+}
+
+func checkObjectInGroup(c *context) {
+	for _, o := range c.objects() {
+		if o.Access == "not-accessible" || o.Status == "obsolete" || c.grouped[o.Name] {
+			continue
+		}
+		c.report(o.Name, o.Pos, "%s is not a member of any OBJECT-GROUP", o.Name)
+	}
+}
+
+func checkNotificationInGroup(c *context) {
+	names := make([]string, 0, len(c.mod.NotificationTypes))
+	for name := range c.mod.NotificationTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := c.mod.NotificationTypes[name]
+		if n.Status == "obsolete" || c.grouped[name] {
+			continue
+		}
+		c.report(name, n.Pos, "%s is not a member of any NOTIFICATION-GROUP", name)
+	}
+}
+
+func checkGroupMembers(c *context) {
+	for _, d := range c.definitions() {
+		switch d.kind {
+		case "OBJECT-GROUP":
+			for _, name := range c.mod.ObjectGroups[d.name].Objects {
+				if !c.defined(name) {
+					c.report(d.name, d.pos, "%s names unknown object %s", d.name, name)
+				}
+			}
+		case "NOTIFICATION-GROUP":
+			for _, name := range c.mod.NotificationGroups[d.name].Notifications {
+				if _, ok := c.mod.NotificationTypes[name]; !ok && !c.imported[name] {
+					c.report(d.name, d.pos, "%s names unknown notification %s", d.name, name)
+				}
+			}
+		}
+	}
+}
+
+func checkGroupMemberAccess(c *context) {
+	for _, d := range c.definitions() {
+		if d.kind != "OBJECT-GROUP" {
+			continue
+		}
+		for _, name := range c.mod.ObjectGroups[d.name].Objects {
+			if o, ok := c.mod.ObjectsByName[name]; ok && o.Access == "not-accessible" {
+				c.report(d.name, d.pos, "%s includes not-accessible object %s", d.name, name)
+			}
+		}
+	}
+}
+
+func checkNotificationObjects(c *context) {
+	for _, d := range c.definitions() {
+		if d.kind != "NOTIFICATION-TYPE" {
+			continue
+		}
+		for _, name := range c.mod.NotificationTypes[d.name].Objects {
+			if o, ok := c.mod.ObjectsByName[name]; ok && o.Access == "not-accessible" {
+				c.report(d.name, d.pos, "%s includes not-accessible object %s", d.name, name)
+			}
+		}
+	}
+}
+
+func checkTableNaming(c *context) {
+	for _, o := range c.objects() {
+		if _, ok := c.tables[o.Name]; ok && !strings.HasSuffix(o.Name, "Table") {
+			c.report(o.Name, o.Pos, "table %s does not end in Table", o.Name)
+		}
+		if _, ok := c.rows[o.Name]; ok && !strings.HasSuffix(o.Name, "Entry") {
+			c.report(o.Name, o.Pos, "row %s does not end in Entry", o.Name)
+		}
+	}
+}
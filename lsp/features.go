@@ -0,0 +1,668 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/lexer"
+	"github.com/Olian04/go-mib-parser/lint"
+)
+
+// parseErrorRe finds the position in the parser's error messages.
+var parseErrorRe = regexp.MustCompile(`at (\d+):(\d+): `)
+
+func (s *Server) diagnostics(f *file) []Diagnostic {
+	out := []Diagnostic{}
+	if f.err != nil {
+		d := Diagnostic{Severity: SeverityError, Source: "mib", Message: f.err.Error()}
+		if loc := parseErrorRe.FindStringSubmatchIndex(d.Message); loc != nil {
+			line, _ := strconv.Atoi(d.Message[loc[2]:loc[3]])
+			col, _ := strconv.Atoi(d.Message[loc[4]:loc[5]])
+			d.Range = f.rangeAt(line, col)
+			d.Message = d.Message[loc[1]:]
+		}
+		return append(out, d)
+	}
+	opts := lint.Options{Suppress: lint.InlineSuppressions([]byte(f.text))}
+	for _, finding := range lint.Lint(f.module, opts) {
+		d := Diagnostic{Source: "miblint", Code: finding.Rule, Message: finding.Message}
+		switch finding.Severity {
+		case lint.Error:
+			d.Severity = SeverityError
+		case lint.Warning:
+			d.Severity = SeverityWarning
+		default:
+			d.Severity = SeverityInformation
+		}
+		if finding.Pos.Line > 0 && finding.Name != "" {
+			d.Range = f.nameRange(finding.Pos, finding.Name)
+		} else if toks := f.tokens(); len(toks) > 0 {
+			d.Range = f.tokenRange(toks[0])
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// target is the definition a name refers to: an OID-bearing definition, a
+// textual convention, or, when both are nil, the module itself.
+type target struct {
+	file   *file
+	module *mib_parser.Module
+	obj    mib_parser.Object
+	tc     *mib_parser.TextualConvention
+}
+
+func (t target) name() string {
+	switch {
+	case t.obj != nil:
+		return t.obj.ObjectName()
+	case t.tc != nil:
+		return t.tc.Name
+	}
+	return t.module.Name
+}
+
+// selection returns the range of the name at the definition.
+func (t target) selection() Range {
+	switch {
+	case t.obj != nil:
+		return t.file.nameRange(t.obj.ObjectPos(), t.obj.ObjectName())
+	case t.tc != nil:
+		return t.file.nameRange(t.tc.Pos, t.tc.Name)
+	}
+	if toks := t.file.tokens(); len(toks) > 0 {
+		return t.file.tokenRange(toks[0])
+	}
+	return Range{}
+}
+
+// resolve finds what name refers to in f: a definition of its module, an
+// imported one, a module, or as a last resort any definition of that name.
+func (s *Server) resolve(f *file, name string) (target, bool) {
+	reg := s.registry()
+	if f.module != nil {
+		if t, ok := define(f, name); ok {
+			return t, true
+		}
+		for _, imp := range f.module.Imports {
+			if owner := s.owners[imp.Module]; owner != nil && slices.Contains(imp.Symbols, name) {
+				if t, ok := define(owner, name); ok {
+					return t, true
+				}
+			}
+		}
+	}
+	if owner := s.owners[name]; owner != nil {
+		return target{file: owner, module: owner.module}, true
+	}
+	if sym, ok := reg.LookupSymbol("", name); ok && s.owners[sym.Module] != nil {
+		return define(s.owners[sym.Module], name)
+	}
+	for _, m := range reg.Modules() {
+		if _, ok := m.TextualConventions[name]; ok {
+			return define(s.owners[m.Name], name)
+		}
+	}
+	return target{}, false
+}
+
+// define finds a definition of the module of f.
+func define(f *file, name string) (target, bool) {
+	if obj, ok := f.module.Object(name); ok {
+		return target{file: f, module: f.module, obj: obj}, true
+	}
+	if tc, ok := f.module.TextualConventions[name]; ok {
+		return target{file: f, module: f.module, tc: tc}, true
+	}
+	return target{}, false
+}
+
+func (s *Server) definition(p TextDocumentPositionParams) (*Location, error) {
+	f, err := s.file(p.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := f.tokenAt(p.Position)
+	if !ok {
+		return nil, nil
+	}
+	t, ok := s.resolve(f, tok.Text)
+	if !ok {
+		return nil, nil
+	}
+	return &Location{URI: t.file.uri, Range: t.selection()}, nil
+}
+
+func (s *Server) hover(p TextDocumentPositionParams) (*Hover, error) {
+	f, err := s.file(p.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := f.tokenAt(p.Position)
+	if !ok {
+		return nil, nil
+	}
+	t, ok := s.resolve(f, tok.Text)
+	if !ok {
+		return nil, nil
+	}
+	r := f.tokenRange(tok)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: s.describe(t)}, Range: &r}, nil
+}
+
+// describe renders a definition as Markdown for hovers and completions.
+func (s *Server) describe(t target) string {
+	reg := s.registry()
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "\n\n%s: `%s`", name, value)
+		}
+	}
+	// syntax describes a SYNTAX clause; hint is the DISPLAY-HINT of the
+	// textual convention being described, which takes precedence over
+	// those it refines.
+	syntax := func(text, hint string) {
+		if text == "" {
+			return
+		}
+		field("SYNTAX", mib_parser.ParseSyntax(text).String())
+		res := reg.ResolveSyntax(t.module, text)
+		if len(res.Conventions) > 0 {
+			base := mib_parser.Syntax{Base: res.Base, NamedNumbers: res.NamedNumbers, Ranges: res.Ranges, Sizes: res.Sizes}
+			field("Resolves to", base.String())
+		}
+		if hint == "" {
+			hint = res.DisplayHint()
+		}
+		field("DISPLAY-HINT", hint)
+	}
+	var description string
+	switch {
+	case t.obj != nil:
+		fmt.Fprintf(&b, "**%s::%s** %s", t.module.Name, t.obj.ObjectName(), t.obj.ObjectKind())
+		field("OID", t.obj.OIDString())
+		if o, ok := t.obj.(*mib_parser.ObjectType); ok {
+			syntax(o.Syntax, "")
+			field("MAX-ACCESS", o.Access)
+			field("UNITS", o.Units)
+		}
+		field("STATUS", t.obj.ObjectStatus().String())
+		description = t.obj.ObjectDescription()
+	case t.tc != nil:
+		fmt.Fprintf(&b, "**%s::%s** %s", t.module.Name, t.tc.Name, mib_parser.KindTextualConvention)
+		syntax(t.tc.Syntax, t.tc.DisplayHint)
+		field("STATUS", t.tc.Status)
+		description = t.tc.Description
+	default:
+		fmt.Fprintf(&b, "**%s** module", t.module.Name)
+		if path, ok := uriPath(t.file.uri); ok {
+			field("File", filepath.Base(path))
+		}
+		if mi := t.module.ModuleIdentity; mi != nil {
+			field("LAST-UPDATED", mi.LastUpdated)
+			if mi.Organization != "" {
+				fmt.Fprintf(&b, "\n\nORGANIZATION: %s", mi.Organization)
+			}
+			description = mi.Description
+		}
+	}
+	if description != "" {
+		b.WriteString("\n\n---\n\n" + paragraphs(description))
+	}
+	return b.String()
+}
+
+var blankLineRe = regexp.MustCompile(`\n\s*\n`)
+
+// paragraphs reflows a DESCRIPTION, keeping its blank-line breaks.
+func paragraphs(text string) string {
+	var out []string
+	for _, para := range blankLineRe.Split(text, -1) {
+		if para = strings.Join(strings.Fields(para), " "); para != "" {
+			out = append(out, para)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+func (s *Server) completion(p TextDocumentPositionParams) (*CompletionList, error) {
+	f, err := s.file(p.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+	reg := s.registry()
+	line, col := p.Position.Line+1, f.column(p.Position)
+	toks := f.tokens()
+	// prev is the token before the one being typed.
+	var prev lexer.Token
+	for _, t := range toks {
+		if t.Line > line || t.Line == line && t.Col >= col {
+			break
+		}
+		if t.Type == lexer.TokenIdent && t.Line == line && t.Col+utf8.RuneCountInString(t.Text) >= col {
+			continue
+		}
+		prev = t
+	}
+	imports, semi := importsClause(toks)
+	inImports := imports >= 0 && before(toks[imports], line, col) && (semi < 0 || !before(toks[semi], line, col))
+
+	list := &CompletionList{Items: []CompletionItem{}}
+	switch {
+	case inImports && prev.Type == lexer.TokenIdent && prev.Text == "FROM":
+		for _, m := range reg.Modules() {
+			item := CompletionItem{Label: m.Name, Kind: CompletionModule}
+			if path, ok := uriPath(s.owners[m.Name].uri); ok {
+				item.Detail = filepath.Base(path)
+			}
+			list.Items = append(list.Items, item)
+		}
+	case inImports:
+		for _, m := range reg.Modules() {
+			if f.module != nil && m.Name == f.module.Name {
+				continue
+			}
+			for _, t := range definitions(s.owners[m.Name]) {
+				list.Items = append(list.Items, s.item(t, m.Name, nil))
+			}
+		}
+	default:
+		seen := map[string]bool{}
+		if f.module != nil {
+			for _, t := range definitions(f) {
+				seen[t.name()] = true
+				list.Items = append(list.Items, s.item(t, "", nil))
+			}
+			for _, imp := range f.module.Imports {
+				for _, name := range imp.Symbols {
+					if seen[name] {
+						continue
+					}
+					seen[name] = true
+					item := CompletionItem{Label: name, Kind: CompletionText, Detail: imp.Module}
+					if owner := s.owners[imp.Module]; owner != nil {
+						if t, ok := define(owner, name); ok {
+							item = s.item(t, imp.Module, nil)
+						}
+					}
+					list.Items = append(list.Items, item)
+				}
+			}
+		}
+		// Textual conventions that are not imported yet come with an
+		// edit that imports them.
+		for _, m := range reg.Modules() {
+			for _, name := range sortedKeys(m.TextualConventions) {
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				t := target{file: s.owners[m.Name], module: m, tc: m.TextualConventions[name]}
+				list.Items = append(list.Items, s.item(t, m.Name, importEdit(f, m.Name, name)))
+			}
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) item(t target, detail string, edits []TextEdit) CompletionItem {
+	item := CompletionItem{
+		Label:               t.name(),
+		Detail:              detail,
+		Documentation:       &MarkupContent{Kind: "markdown", Value: s.describe(t)},
+		AdditionalTextEdits: edits,
+	}
+	switch {
+	case t.tc != nil:
+		item.Kind = CompletionClass
+	case t.obj != nil:
+		switch t.obj.ObjectKind() {
+		case mib_parser.KindObjectType:
+			item.Kind = CompletionField
+		case mib_parser.KindNotificationType, mib_parser.KindTrapType:
+			item.Kind = CompletionEvent
+		case mib_parser.KindObjectGroup, mib_parser.KindNotificationGroup, mib_parser.KindModuleCompliance, mib_parser.KindAgentCapabilities:
+			item.Kind = CompletionInterface
+		default:
+			item.Kind = CompletionVariable
+		}
+	}
+	if item.Detail == "" {
+		if t.obj != nil {
+			item.Detail = string(t.obj.ObjectKind())
+		} else {
+			item.Detail = string(mib_parser.KindTextualConvention)
+		}
+	}
+	return item
+}
+
+// definitions lists the objects and textual conventions of the module of f.
+func definitions(f *file) []target {
+	if f == nil || f.module == nil {
+		return nil
+	}
+	var out []target
+	for _, obj := range f.module.Objects() {
+		out = append(out, target{file: f, module: f.module, obj: obj})
+	}
+	for _, name := range sortedKeys(f.module.TextualConventions) {
+		out = append(out, target{file: f, module: f.module, tc: f.module.TextualConventions[name]})
+	}
+	return out
+}
+
+// importEdit adds name to the IMPORTS of f: to the symbols imported from
+// module if there are any, as a new "FROM module" group otherwise, or as a
+// new IMPORTS clause after BEGIN.
+func importEdit(f *file, module, name string) []TextEdit {
+	toks := f.tokens()
+	imports, semi := importsClause(toks)
+	if imports < 0 {
+		for _, t := range toks {
+			if t.Type == lexer.TokenIdent && t.Text == "BEGIN" {
+				end := f.tokenRange(t).End
+				return []TextEdit{{Range: Range{end, end}, NewText: "\n\nIMPORTS\n    " + name + "\n        FROM " + module + ";"}}
+			}
+		}
+		return nil
+	}
+	if semi < 0 {
+		return nil
+	}
+	var last lexer.Token
+	for i := imports + 1; i < semi; i++ {
+		t := toks[i]
+		if t.Type == lexer.TokenIdent && t.Text == "FROM" && i+1 < semi {
+			if toks[i+1].Text == module {
+				end := f.tokenRange(last).End
+				return []TextEdit{{Range: Range{end, end}, NewText: ", " + name}}
+			}
+			i++
+			continue
+		}
+		if t.Type == lexer.TokenIdent {
+			last = t
+		}
+	}
+	start := f.tokenRange(toks[semi]).Start
+	return []TextEdit{{Range: Range{start, start}, NewText: "\n    " + name + "\n        FROM " + module}}
+}
+
+// importsClause returns the indexes of the IMPORTS keyword and the
+// semicolon ending the clause, or -1.
+func importsClause(toks []lexer.Token) (imports, semi int) {
+	imports, semi = -1, -1
+	for i, t := range toks {
+		if imports < 0 && t.Type == lexer.TokenIdent && t.Text == "IMPORTS" {
+			imports = i
+		} else if imports >= 0 && t.Type == lexer.TokenSemicolon {
+			return imports, i
+		}
+	}
+	return imports, semi
+}
+
+// before reports whether t starts before the 1-based line and column.
+func before(t lexer.Token, line, col int) bool {
+	return t.Line < line || t.Line == line && t.Col < col
+}
+
+func (s *Server) documentSymbol(p DocumentSymbolParams) ([]DocumentSymbol, error) {
+	f, err := s.file(p.TextDocument)
+	if err != nil {
+		return nil, err
+	}
+	toks := f.tokens()
+	if f.module == nil || len(toks) == 0 {
+		return []DocumentSymbol{}, nil
+	}
+	defs := definitions(f)
+	// A definition runs until the next one starts, which may be a type
+	// assignment the module does not keep, such as a SEQUENCE.
+	at := map[mib_parser.Position]int{}
+	for i, t := range toks {
+		at[mib_parser.Position{Line: t.Line, Column: t.Col}] = i
+	}
+	starts := []int{}
+	for i, t := range toks {
+		if (t.Type == lexer.TokenIdent && t.Text == "END") || (i+1 < len(toks) && toks[i+1].Type == lexer.TokenColonColonEq && (i == 0 || toks[i-1].Line < t.Line)) {
+			starts = append(starts, i)
+		}
+	}
+	for _, t := range defs {
+		if i, ok := at[pos(t)]; ok {
+			starts = append(starts, i)
+		}
+	}
+	sort.Ints(starts)
+	module := DocumentSymbol{
+		Name:           f.module.Name,
+		Kind:           SymbolModule,
+		Range:          Range{f.tokenRange(toks[0]).Start, f.tokenRange(toks[len(toks)-1]).End},
+		SelectionRange: f.tokenRange(toks[0]),
+		Children:       []DocumentSymbol{},
+	}
+	for _, t := range defs {
+		i, ok := at[pos(t)]
+		if !ok {
+			continue
+		}
+		end := len(toks) - 1
+		if j := sort.SearchInts(starts, i+1); j < len(starts) {
+			end = starts[j] - 1
+		}
+		sym := DocumentSymbol{
+			Name:           t.name(),
+			Range:          Range{f.tokenRange(toks[i]).Start, f.tokenRange(toks[end]).End},
+			SelectionRange: f.tokenRange(toks[i]),
+		}
+		switch {
+		case t.tc != nil:
+			sym.Kind, sym.Detail = SymbolClass, string(mib_parser.KindTextualConvention)
+		default:
+			sym.Kind, sym.Detail = symbolKind(t.obj), t.obj.OIDString()
+			if sym.Detail == "" {
+				sym.Detail = string(t.obj.ObjectKind())
+			}
+		}
+		module.Children = append(module.Children, sym)
+	}
+	sort.SliceStable(module.Children, func(i, j int) bool {
+		a, b := module.Children[i].SelectionRange.Start, module.Children[j].SelectionRange.Start
+		return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+	})
+	return []DocumentSymbol{module}, nil
+}
+
+func pos(t target) mib_parser.Position {
+	if t.tc != nil {
+		return t.tc.Pos
+	}
+	return t.obj.ObjectPos()
+}
+
+func symbolKind(obj mib_parser.Object) int {
+	switch o := obj.(type) {
+	case *mib_parser.ModuleIdentity:
+		return SymbolModule
+	case *mib_parser.ObjectType:
+		switch {
+		case strings.HasPrefix(o.Syntax, "SEQUENCE OF"):
+			return SymbolArray
+		case len(o.Index) > 0 || o.Augments != "":
+			return SymbolStruct
+		}
+		return SymbolField
+	case *mib_parser.NotificationType:
+		return SymbolEvent
+	case *mib_parser.ObjectGroup, *mib_parser.NotificationGroup, *mib_parser.ModuleCompliance, *mib_parser.AgentCapabilities:
+		return SymbolInterface
+	}
+	return SymbolNamespace
+}
+
+// renameTarget finds the definition at a position that can be renamed.
+func (s *Server) renameTarget(p TextDocumentPositionParams) (*file, lexer.Token, target, error) {
+	f, err := s.file(p.TextDocument)
+	if err != nil {
+		return nil, lexer.Token{}, target{}, err
+	}
+	tok, ok := f.tokenAt(p.Position)
+	if !ok {
+		return nil, lexer.Token{}, target{}, &Error{Code: CodeRequestFailed, Message: "no descriptor at the cursor"}
+	}
+	t, ok := s.resolve(f, tok.Text)
+	if !ok || t.obj == nil && t.tc == nil {
+		return nil, lexer.Token{}, target{}, &Error{Code: CodeRequestFailed, Message: tok.Text + " is not defined in a loaded module"}
+	}
+	return f, tok, t, nil
+}
+
+func (s *Server) prepareRename(p TextDocumentPositionParams) (*PrepareRenameResult, error) {
+	f, tok, _, err := s.renameTarget(p)
+	if err != nil {
+		return nil, err
+	}
+	return &PrepareRenameResult{Range: f.tokenRange(tok), Placeholder: tok.Text}, nil
+}
+
+var descriptorRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(-[A-Za-z0-9]+)*$`)
+
+// rename renames a definition where it is defined, where it is used in
+// its module, and in every module that imports it.
+func (s *Server) rename(p RenameParams) (*WorkspaceEdit, error) {
+	_, _, t, err := s.renameTarget(p.TextDocumentPositionParams)
+	if err != nil {
+		return nil, err
+	}
+	old := t.name()
+	switch {
+	case !descriptorRe.MatchString(p.NewName):
+		return nil, &Error{Code: CodeRequestFailed, Message: fmt.Sprintf("%q is not a valid descriptor", p.NewName)}
+	case unicode.IsUpper(rune(old[0])) != unicode.IsUpper(rune(p.NewName[0])):
+		return nil, &Error{Code: CodeRequestFailed, Message: "type names start with an uppercase letter and value names with a lowercase one"}
+	}
+	if _, ok := define(t.file, p.NewName); ok {
+		return nil, &Error{Code: CodeRequestFailed, Message: t.module.Name + " already defines " + p.NewName}
+	}
+	edit := &WorkspaceEdit{Changes: map[string][]TextEdit{}}
+	for _, g := range s.files {
+		if g != t.file && (g.module == nil || !importsFrom(g.module, t.module.Name, old)) {
+			continue
+		}
+		for _, tok := range g.tokens() {
+			if tok.Type == lexer.TokenIdent && tok.Text == old {
+				edit.Changes[g.uri] = append(edit.Changes[g.uri], TextEdit{Range: g.tokenRange(tok), NewText: p.NewName})
+			}
+		}
+	}
+	return edit, nil
+}
+
+func importsFrom(m *mib_parser.Module, module, name string) bool {
+	for _, imp := range m.Imports {
+		if imp.Module == module && slices.Contains(imp.Symbols, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenAt returns the identifier at or just before a position.
+func (f *file) tokenAt(p Position) (lexer.Token, bool) {
+	line, col := p.Line+1, f.column(p)
+	for _, t := range f.tokens() {
+		if t.Line > line {
+			break
+		}
+		if t.Type == lexer.TokenIdent && t.Line == line && t.Col <= col && col <= t.Col+utf8.RuneCountInString(t.Text) {
+			return t, true
+		}
+	}
+	return lexer.Token{}, false
+}
+
+// column converts a position to a 1-based rune column.
+func (f *file) column(p Position) int {
+	if p.Line < 0 || p.Line >= len(f.lines) {
+		return p.Character + 1
+	}
+	n, col := 0, 1
+	for _, r := range f.lines[p.Line] {
+		if n >= p.Character {
+			break
+		}
+		n += utf16.RuneLen(r)
+		col++
+	}
+	return col
+}
+
+// position converts a 1-based line and rune column to a position.
+func (f *file) position(line, col int) Position {
+	if line < 1 {
+		return Position{}
+	}
+	p := Position{Line: line - 1, Character: col - 1}
+	if line <= len(f.lines) {
+		p.Character = 0
+		for i, r := range []rune(f.lines[line-1]) {
+			if i >= col-1 {
+				break
+			}
+			p.Character += utf16.RuneLen(r)
+		}
+	}
+	return p
+}
+
+func (f *file) tokenRange(t lexer.Token) Range {
+	start := f.position(t.Line, t.Col)
+	if t.Type != lexer.TokenString {
+		return Range{start, f.position(t.Line, t.Col+utf8.RuneCountInString(t.Text))}
+	}
+	// The text of a string has lost its quotes; the closing one is last.
+	lines := strings.Split(t.Text, "\n")
+	if len(lines) == 1 {
+		return Range{start, f.position(t.Line, t.Col+utf8.RuneCountInString(t.Text)+2)}
+	}
+	last := t.Line + len(lines) - 1
+	return Range{start, f.position(last, utf8.RuneCountInString(lines[len(lines)-1])+2)}
+}
+
+func (f *file) nameRange(pos mib_parser.Position, name string) Range {
+	return f.tokenRange(lexer.Token{Type: lexer.TokenIdent, Text: name, Line: pos.Line, Col: pos.Column})
+}
+
+// rangeAt returns the range of the token at a 1-based line and column, or
+// an empty range there.
+func (f *file) rangeAt(line, col int) Range {
+	for _, t := range f.tokens() {
+		if t.Line == line && t.Col == col {
+			return f.tokenRange(t)
+		}
+	}
+	p := f.position(line, col)
+	return Range{p, p}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,416 @@
+// Package lsp is a Language Server Protocol server for MIB files. It
+// publishes parse errors and lint findings as diagnostics and answers
+// go-to-definition, hover, completion, document symbol and rename requests
+// from a registry of the open documents, the MIBs of the workspace folders
+// and a library of MIBs given to New.
+//
+// Documents are synchronised in full. Columns are UTF-16 offsets as the
+// protocol requires.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/lexer"
+)
+
+// Server is a language server. It handles one client; see Serve.
+type Server struct {
+	files map[string]*file
+	// reg holds the module of every file that parsed, preferring open
+	// documents, then workspace files, then the library, when several
+	// files define the same module. It is rebuilt after each change.
+	reg    *mib_parser.Registry
+	owners map[string]*file
+	conn   *conn
+	// shutdown is set by the shutdown request.
+	shutdown bool
+}
+
+// file is a MIB the server knows about: one on disk, one opened by the
+// client, or both.
+type file struct {
+	uri  string
+	rank int
+	open bool
+	text string
+	// lines is text split at newlines, for converting columns.
+	lines []string
+	toks  []lexer.Token
+	// module is the last module text parsed into; err is set when the
+	// current text does not parse.
+	module *mib_parser.Module
+	err    error
+}
+
+const (
+	rankWorkspace = iota + 1
+	rankLibrary
+	// rankUnsaved files exist only while the client has them open.
+	rankUnsaved
+)
+
+// New returns a server that resolves IMPORTS against the MIB files at paths
+// as well as the client's workspace folders.
+func New(paths ...string) (*Server, error) {
+	s := &Server{files: map[string]*file{}}
+	for _, path := range paths {
+		f, err := s.load(path, rankLibrary)
+		if err != nil {
+			return nil, err
+		}
+		if f.err != nil {
+			return nil, errors.New(path + ": " + f.err.Error())
+		}
+	}
+	return s, nil
+}
+
+// load reads the MIB at path unless the server has it already.
+func (s *Server) load(path string, rank int) (*file, error) {
+	uri := pathURI(path)
+	if f, ok := s.files[key(uri)]; ok {
+		f.rank = min(f.rank, rank)
+		return f, nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &file{uri: uri, rank: rank}
+	f.set(string(src))
+	s.files[key(uri)] = f
+	s.reg = nil
+	return f, nil
+}
+
+func (f *file) set(text string) {
+	f.text, f.lines, f.toks = text, strings.Split(text, "\n"), nil
+	f.parse()
+}
+
+func (f *file) parse() {
+	m, err := mib_parser.ParseMIB([]byte(f.text))
+	f.err = err
+	if err == nil {
+		f.module = m
+	}
+}
+
+// tokens returns the lexical tokens of the text, without the final EOF.
+func (f *file) tokens() []lexer.Token {
+	if f.toks == nil {
+		l := lexer.New([]byte(f.text))
+		f.toks = []lexer.Token{}
+		for t := l.Next(); t.Type != lexer.TokenEOF; t = l.Next() {
+			f.toks = append(f.toks, t)
+		}
+	}
+	return f.toks
+}
+
+// update replaces the text of f. Modules that import from the module of f
+// are parsed again so that Resolve fills in any OIDs that changed.
+func (s *Server) update(f *file, text string) {
+	old := f.module
+	f.set(text)
+	s.reg = nil
+	if f.err != nil {
+		return
+	}
+	changed := map[string]bool{f.module.Name: true}
+	if old != nil {
+		changed[old.Name] = true
+	}
+	for again := true; again; {
+		again = false
+		for _, g := range s.files {
+			if g == f || g.module == nil || g.err != nil || changed[g.module.Name] || !importsAny(g.module, changed) {
+				continue
+			}
+			g.parse()
+			changed[g.module.Name] = true
+			again = true
+		}
+	}
+}
+
+func importsAny(m *mib_parser.Module, modules map[string]bool) bool {
+	for _, imp := range m.Imports {
+		if modules[imp.Module] {
+			return true
+		}
+	}
+	return false
+}
+
+// registry returns the registry of every file's module.
+func (s *Server) registry() *mib_parser.Registry {
+	if s.reg != nil {
+		return s.reg
+	}
+	files := make([]*file, 0, len(s.files))
+	for _, f := range s.files {
+		if f.module != nil {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if a.open != b.open {
+			return a.open
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		return a.uri < b.uri
+	})
+	s.reg, s.owners = mib_parser.NewRegistry(), map[string]*file{}
+	for _, f := range files {
+		if _, ok := s.owners[f.module.Name]; ok {
+			continue
+		}
+		s.reg.Add(f.module)
+		s.owners[f.module.Name] = f
+	}
+	s.reg.Resolve()
+	return s.reg
+}
+
+// Registry returns the modules the server currently knows.
+func (s *Server) Registry() *mib_parser.Registry {
+	return s.registry()
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client sends exit or closes r. An exit without a preceding
+// shutdown request is reported as an error.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		var rpcErr *Error
+		if errors.As(err, &rpcErr) {
+			if err := s.conn.reply(json.RawMessage("null"), nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if msg.ID == nil {
+			if msg.Method == "exit" {
+				if !s.shutdown {
+					return errors.New("lsp: exit without shutdown")
+				}
+				return nil
+			}
+			if err := s.notification(msg.Method, msg.Params); err != nil {
+				return err
+			}
+			continue
+		}
+		result, err := s.request(msg.Method, msg.Params)
+		if err := s.conn.reply(*msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) request(method string, params json.RawMessage) (any, error) {
+	if s.shutdown {
+		return nil, &Error{Code: CodeInvalidRequest, Message: "server is shutting down"}
+	}
+	switch method {
+	case "initialize":
+		return call(params, s.initialize)
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/definition":
+		return call(params, s.definition)
+	case "textDocument/hover":
+		return call(params, s.hover)
+	case "textDocument/completion":
+		return call(params, s.completion)
+	case "textDocument/documentSymbol":
+		return call(params, s.documentSymbol)
+	case "textDocument/prepareRename":
+		return call(params, s.prepareRename)
+	case "textDocument/rename":
+		return call(params, s.rename)
+	}
+	return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + method}
+}
+
+// call decodes the parameters of a request for its handler.
+func call[P, R any](params json.RawMessage, handle func(P) (R, error)) (any, error) {
+	var p P
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+	}
+	return handle(p)
+}
+
+// notification handles a notification; unknown ones are ignored as the
+// protocol asks.
+func (s *Server) notification(method string, params json.RawMessage) error {
+	switch method {
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if json.Unmarshal(params, &p) != nil {
+			return nil
+		}
+		f, ok := s.files[key(p.TextDocument.URI)]
+		if !ok {
+			f = &file{rank: rankUnsaved}
+			s.files[key(p.TextDocument.URI)] = f
+		}
+		f.uri, f.open = p.TextDocument.URI, true
+		s.update(f, p.TextDocument.Text)
+		return s.publish(f, p.TextDocument.Version)
+	case "textDocument/didChange":
+		var p DidChangeTextDocumentParams
+		if json.Unmarshal(params, &p) != nil || len(p.ContentChanges) == 0 {
+			return nil
+		}
+		f, ok := s.files[key(p.TextDocument.URI)]
+		if !ok || !f.open {
+			return nil
+		}
+		s.update(f, p.ContentChanges[len(p.ContentChanges)-1].Text)
+		return s.publish(f, p.TextDocument.Version)
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if json.Unmarshal(params, &p) != nil {
+			return nil
+		}
+		f, ok := s.files[key(p.TextDocument.URI)]
+		if !ok {
+			return nil
+		}
+		f.open = false
+		src, err := readURI(f.uri)
+		if f.rank == rankUnsaved || err != nil {
+			delete(s.files, key(f.uri))
+			s.reg = nil
+		} else {
+			s.update(f, string(src))
+		}
+		return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: f.uri, Diagnostics: []Diagnostic{}})
+	}
+	return nil
+}
+
+func (s *Server) publish(f *file, version int) error {
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         f.uri,
+		Version:     version,
+		Diagnostics: s.diagnostics(f),
+	})
+}
+
+func (s *Server) initialize(p InitializeParams) (InitializeResult, error) {
+	folders := p.WorkspaceFolders
+	if len(folders) == 0 && p.RootURI != "" {
+		folders = []WorkspaceFolder{{URI: p.RootURI}}
+	}
+	for _, folder := range folders {
+		root, ok := uriPath(folder.URI)
+		if !ok {
+			continue
+		}
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isMIBFile(d.Name()) {
+				s.load(path, rankWorkspace)
+			}
+			return nil
+		})
+	}
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       TextDocumentSyncOptions{OpenClose: true, Change: SyncFull},
+			DefinitionProvider:     true,
+			HoverProvider:          true,
+			CompletionProvider:     CompletionOptions{},
+			DocumentSymbolProvider: true,
+			RenameProvider:         RenameOptions{PrepareProvider: true},
+		},
+		ServerInfo: ServerInfo{Name: "miblsp"},
+	}, nil
+}
+
+// isMIBFile reports whether a workspace file is loaded as a MIB: files
+// named *.mib, *.my or *.smi in any case, or without an extension as in
+// net-snmp's MIB directories. Files that do not parse are ignored.
+func isMIBFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".mib", ".my", ".smi", "":
+		return true
+	}
+	return false
+}
+
+func (s *Server) file(doc TextDocumentIdentifier) (*file, error) {
+	f, ok := s.files[key(doc.URI)]
+	if !ok {
+		return nil, &Error{Code: CodeInvalidParams, Message: "unknown document " + doc.URI}
+	}
+	return f, nil
+}
+
+func pathURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func uriPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+func readURI(uri string) ([]byte, error) {
+	path, ok := uriPath(uri)
+	if !ok {
+		return nil, errors.New("lsp: not a file URI: " + uri)
+	}
+	return os.ReadFile(path)
+}
+
+// key normalises a file URI, whose escaping differs between clients, for
+// use as a map key.
+func key(uri string) string {
+	if path, ok := uriPath(uri); ok {
+		return pathURI(path)
+	}
+	return uri
+}
@@ -0,0 +1,138 @@
+package mib_parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The instances that open every SNMPv2-Trap and InformRequest varbind list
+// (RFC 3416 section 4.2.6).
+var (
+	sysUpTimeInstance   = OID{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	snmpTrapOIDInstance = OID{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}
+)
+
+// VarBind is an instance OID paired with its value.
+type VarBind struct {
+	OID   OID
+	Value Value
+}
+
+// DecodedVarBind is a varbind annotated from the MIB.
+type DecodedVarBind struct {
+	VarBind
+	// Name is the instance OID as a qualified name with its index decoded,
+	// e.g. `ifAdminStatus.2`.
+	Name QualifiedName
+	// Text is the value as rendered by RenderVarBind.
+	Text string
+}
+
+// Notification is an SNMPv2-Trap or InformRequest decoded through the
+// NOTIFICATION-TYPE that snmpTrapOID.0 names.
+type Notification struct {
+	// TrapOID is the value of snmpTrapOID.0.
+	TrapOID OID
+	// Name is TrapOID as a qualified name.
+	Name QualifiedName
+	// Module and Type are the defining module and NOTIFICATION-TYPE; nil
+	// when the notification is not known to the registry, in which case
+	// the whole payload is reported as Unexpected.
+	Module *Module
+	Type   *NotificationType
+	// Uptime is sysUpTime.0 in hundredths of a second, when present.
+	Uptime    uint64
+	HasUptime bool
+	// Objects holds the payload varbinds that match the OBJECTS clause, in
+	// its order.
+	Objects []DecodedVarBind
+	// Missing lists the OBJECTS entries that have no varbind.
+	Missing []string
+	// Unexpected holds payload varbinds that OBJECTS does not declare, as
+	// agents may append to a notification.
+	Unexpected []DecodedVarBind
+	// Translated holds the snmpTrapAddress.0, snmpTrapCommunity.0 and
+	// snmpTrapEnterprise.0 varbinds appended when the notification was
+	// converted from an SNMPv1 trap (RFC 3584 section 3.1).
+	Translated []DecodedVarBind
+}
+
+// DecodeNotification decodes the varbind list of an SNMPv2-Trap or
+// InformRequest PDU: sysUpTime.0, snmpTrapOID.0 and the payload. Each
+// OBJECTS entry of the notification is matched with the first payload
+// varbind that is an instance of it; payload values are rendered with
+// RenderVarBind.
+func (r *Registry) DecodeNotification(vbs []VarBind) (*Notification, error) {
+	n := &Notification{}
+	var payload []VarBind
+	for _, vb := range vbs {
+		switch {
+		case vb.OID.Equal(sysUpTimeInstance) && !n.HasUptime:
+			ticks, ok := valueUint(vb.Value.Data())
+			if !ok {
+				return nil, fmt.Errorf("notification: sysUpTime.0 is %v, not a TimeTicks value", vb.Value.Data())
+			}
+			n.Uptime, n.HasUptime = ticks, true
+		case vb.OID.Equal(snmpTrapOIDInstance) && n.TrapOID == nil:
+			oid, ok := valueOID(vb.Value.Data())
+			if !ok || vb.Value.Type() != TypeObjectIdentifier {
+				return nil, fmt.Errorf("notification: snmpTrapOID.0 is %v, not an OBJECT IDENTIFIER", vb.Value.Data())
+			}
+			n.TrapOID = oid
+		case vb.OID.Equal(snmpTrapAddressInstance),
+			vb.OID.Equal(snmpTrapCommunityInstance),
+			vb.OID.Equal(snmpTrapEnterpriseInstance):
+			n.Translated = append(n.Translated, r.decodeVarBind(vb))
+		default:
+			payload = append(payload, vb)
+		}
+	}
+	if n.TrapOID == nil {
+		return nil, errors.New("notification: varbind list has no snmpTrapOID.0")
+	}
+	n.Name = r.QualifiedNameOf(n.TrapOID)
+	if sym, rest, ok := r.SymbolAt(n.TrapOID); ok && len(rest) == 0 {
+		if m, ok := r.modules[sym.Module]; ok {
+			n.Module, n.Type = m, m.NotificationTypes[sym.Name]
+		}
+	}
+	if n.Type == nil {
+		n.Module = nil
+		for _, vb := range payload {
+			n.Unexpected = append(n.Unexpected, r.decodeVarBind(vb))
+		}
+		return n, nil
+	}
+
+	used := make([]bool, len(payload))
+	for _, name := range n.Type.Objects {
+		_, o, ok := r.object(n.Module, name)
+		match := -1
+		for i, vb := range payload {
+			if ok && !used[i] && len(vb.OID) > len(o.OID) && vb.OID.HasPrefix(o.OID) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			n.Missing = append(n.Missing, name)
+			continue
+		}
+		used[match] = true
+		n.Objects = append(n.Objects, r.decodeVarBind(payload[match]))
+	}
+	for i, vb := range payload {
+		if !used[i] {
+			n.Unexpected = append(n.Unexpected, r.decodeVarBind(vb))
+		}
+	}
+	return n, nil
+}
+
+func (r *Registry) decodeVarBind(vb VarBind) DecodedVarBind {
+	return DecodedVarBind{
+		VarBind: vb,
+		Name:    r.QualifiedNameOf(vb.OID),
+		Text:    r.RenderVarBind(vb.OID, vb.Value),
+	}
+}
@@ -0,0 +1,207 @@
+package mib_parser
+
+import "sort"
+
+// Kind names the macro an Object is defined with. KindTextualConvention
+// is only used by tools that list textual conventions alongside objects;
+// a TextualConvention is not an Object.
+type Kind string
+
+const (
+	KindObjectIdentifier  Kind = "OBJECT IDENTIFIER"
+	KindModuleIdentity    Kind = "MODULE-IDENTITY"
+	KindObjectIdentity    Kind = "OBJECT-IDENTITY"
+	KindObjectType        Kind = "OBJECT-TYPE"
+	KindNotificationType  Kind = "NOTIFICATION-TYPE"
+	KindTrapType          Kind = "TRAP-TYPE"
+	KindObjectGroup       Kind = "OBJECT-GROUP"
+	KindNotificationGroup Kind = "NOTIFICATION-GROUP"
+	KindModuleCompliance  Kind = "MODULE-COMPLIANCE"
+	KindAgentCapabilities Kind = "AGENT-CAPABILITIES"
+	KindTextualConvention Kind = "TEXTUAL-CONVENTION"
+)
+
+// Object returns the OID-bearing definition name of the module.
+func (m *Module) Object(name string) (Object, bool) {
+	if mi := m.ModuleIdentity; mi != nil && mi.Name == name {
+		return mi, true
+	}
+	if o, ok := m.ObjectIdentifiers[name]; ok {
+		return o, true
+	}
+	if o, ok := m.ObjectIdentities[name]; ok {
+		return o, true
+	}
+	if o, ok := m.ObjectsByName[name]; ok {
+		return o, true
+	}
+	if o, ok := m.NotificationTypes[name]; ok {
+		return o, true
+	}
+	if o, ok := m.ObjectGroups[name]; ok {
+		return o, true
+	}
+	if o, ok := m.NotificationGroups[name]; ok {
+		return o, true
+	}
+	if o, ok := m.ModuleCompliances[name]; ok {
+		return o, true
+	}
+	if o, ok := m.AgentCapabilities[name]; ok {
+		return o, true
+	}
+	return nil, false
+}
+
+// Objects returns every OID-bearing definition of the module, sorted by OID
+// and then by name.
+func (m *Module) Objects() []Object {
+	var out []Object
+	for _, name := range sortedKeys(m.oidNodes()) {
+		obj, _ := m.Object(name)
+		out = append(out, obj)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].OIDSlice().Compare(out[j].OIDSlice()) < 0
+	})
+	return out
+}
+
+// ObjectAt returns the definition whose OID is exactly oid. The well-known
+// SNMPv2-SMI nodes have no definition and are not found unless a loaded
+// module defines them.
+func (r *Registry) ObjectAt(oid OID) (Object, bool) {
+	sym, suffix, ok := r.SymbolAt(oid)
+	if !ok || len(suffix) > 0 {
+		return nil, false
+	}
+	m, ok := r.Module(sym.Module)
+	if !ok {
+		return nil, false
+	}
+	return m.Object(sym.Name)
+}
+
+// LookupObject returns the definition named by s: a dotted OID, a
+// "MODULE::name" qualified name or a bare descriptor, which is looked up as
+// LookupSymbol does.
+func (r *Registry) LookupObject(s string) (Object, bool) {
+	if oid, err := ParseOID(s); err == nil {
+		return r.ObjectAt(oid)
+	}
+	module, name := "", s
+	if q, err := ParseQualifiedName(s); err == nil && len(q.Index) == 0 {
+		module, name = q.Module, q.Name
+	}
+	sym, ok := r.LookupSymbol(module, name)
+	if !ok {
+		return nil, false
+	}
+	m, ok := r.Module(sym.Module)
+	if !ok {
+		return nil, false
+	}
+	return m.Object(sym.Name)
+}
+
+// bind records the module's name on each of its definitions so that they can
+// report it through the Object interface.
+func (m *Module) bind() {
+	if mi := m.ModuleIdentity; mi != nil {
+		mi.module = m.Name
+	}
+	for _, o := range m.ObjectIdentifiers {
+		o.module = m.Name
+	}
+	for _, o := range m.ObjectIdentities {
+		o.module = m.Name
+	}
+	for _, o := range m.ObjectsByName {
+		o.module = m.Name
+	}
+	for _, o := range m.NotificationTypes {
+		o.module = m.Name
+	}
+	for _, o := range m.ObjectGroups {
+		o.module = m.Name
+	}
+	for _, o := range m.NotificationGroups {
+		o.module = m.Name
+	}
+	for _, o := range m.ModuleCompliances {
+		o.module = m.Name
+	}
+	for _, o := range m.AgentCapabilities {
+		o.module = m.Name
+	}
+}
+
+func (o *ObjectIdentifier) ObjectName() string        { return o.Name }
+func (o *ObjectIdentifier) ObjectModule() string      { return o.module }
+func (o *ObjectIdentifier) ObjectKind() Kind          { return KindObjectIdentifier }
+func (o *ObjectIdentifier) ObjectStatus() Status      { return StatusUnknown }
+func (o *ObjectIdentifier) ObjectDescription() string { return "" }
+func (o *ObjectIdentifier) ObjectPos() Position       { return o.Pos }
+
+func (o *ModuleIdentity) ObjectName() string        { return o.Name }
+func (o *ModuleIdentity) ObjectModule() string      { return o.module }
+func (o *ModuleIdentity) ObjectKind() Kind          { return KindModuleIdentity }
+func (o *ModuleIdentity) ObjectStatus() Status      { return StatusUnknown }
+func (o *ModuleIdentity) ObjectDescription() string { return o.Description }
+func (o *ModuleIdentity) ObjectPos() Position       { return o.Pos }
+
+func (o *ObjectIdentity) ObjectName() string        { return o.Name }
+func (o *ObjectIdentity) ObjectModule() string      { return o.module }
+func (o *ObjectIdentity) ObjectKind() Kind          { return KindObjectIdentity }
+func (o *ObjectIdentity) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *ObjectIdentity) ObjectDescription() string { return o.Description }
+func (o *ObjectIdentity) ObjectPos() Position       { return o.Pos }
+
+func (o *ObjectType) ObjectName() string        { return o.Name }
+func (o *ObjectType) ObjectModule() string      { return o.module }
+func (o *ObjectType) ObjectKind() Kind          { return KindObjectType }
+func (o *ObjectType) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *ObjectType) ObjectDescription() string { return o.Description }
+func (o *ObjectType) ObjectPos() Position       { return o.Pos }
+
+func (o *NotificationType) ObjectName() string   { return o.Name }
+func (o *NotificationType) ObjectModule() string { return o.module }
+
+// ObjectKind reports KindTrapType for an SMIv1 TRAP-TYPE.
+func (o *NotificationType) ObjectKind() Kind {
+	if o.TrapType {
+		return KindTrapType
+	}
+	return KindNotificationType
+}
+func (o *NotificationType) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *NotificationType) ObjectDescription() string { return o.Description }
+func (o *NotificationType) ObjectPos() Position       { return o.Pos }
+
+func (o *ObjectGroup) ObjectName() string        { return o.Name }
+func (o *ObjectGroup) ObjectModule() string      { return o.module }
+func (o *ObjectGroup) ObjectKind() Kind          { return KindObjectGroup }
+func (o *ObjectGroup) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *ObjectGroup) ObjectDescription() string { return o.Description }
+func (o *ObjectGroup) ObjectPos() Position       { return o.Pos }
+
+func (o *NotificationGroup) ObjectName() string        { return o.Name }
+func (o *NotificationGroup) ObjectModule() string      { return o.module }
+func (o *NotificationGroup) ObjectKind() Kind          { return KindNotificationGroup }
+func (o *NotificationGroup) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *NotificationGroup) ObjectDescription() string { return o.Description }
+func (o *NotificationGroup) ObjectPos() Position       { return o.Pos }
+
+func (o *ModuleCompliance) ObjectName() string        { return o.Name }
+func (o *ModuleCompliance) ObjectModule() string      { return o.module }
+func (o *ModuleCompliance) ObjectKind() Kind          { return KindModuleCompliance }
+func (o *ModuleCompliance) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *ModuleCompliance) ObjectDescription() string { return o.Description }
+func (o *ModuleCompliance) ObjectPos() Position       { return o.Pos }
+
+func (o *AgentCapabilities) ObjectName() string        { return o.Name }
+func (o *AgentCapabilities) ObjectModule() string      { return o.module }
+func (o *AgentCapabilities) ObjectKind() Kind          { return KindAgentCapabilities }
+func (o *AgentCapabilities) ObjectStatus() Status      { return ParseStatus(o.Status) }
+func (o *AgentCapabilities) ObjectDescription() string { return o.Description }
+func (o *AgentCapabilities) ObjectPos() Position       { return o.Pos }
@@ -0,0 +1,175 @@
+package mib_parser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxOIDLength is the largest number of arcs SNMP allows in an OID.
+const MaxOIDLength = 128
+
+// OID is a numeric object identifier such as 1.3.6.1.2.1.1.3.0.
+// It is a plain slice of arcs, so OID values convert freely to and from []int.
+type OID []int
+
+// ParseOID parses a dotted decimal OID, with or without a leading dot
+// (".1.3.6.1" and "1.3.6.1" are the same OID). Each arc must fit in 32 bits.
+func ParseOID(s string) (OID, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	oid := make(OID, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || p[0] == '+' {
+			return nil, fmt.Errorf("invalid OID %q: arc %d is not a number in 0..%d", s, i+1, uint32(math.MaxUint32))
+		}
+		if n > math.MaxInt {
+			// Only possible where int has 32 bits.
+			return nil, fmt.Errorf("invalid OID %q: arc %d does not fit in an int", s, i+1)
+		}
+		oid[i] = int(n)
+	}
+	return oid, nil
+}
+
+// String returns the dotted decimal form without a leading dot.
+func (o OID) String() string {
+	parts := make([]string, len(o))
+	for i, arc := range o {
+		parts[i] = strconv.Itoa(arc)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Compare orders OIDs lexicographically arc by arc, with a prefix sorting
+// before any OID it is a prefix of. This is the order GETNEXT walks in.
+// The result is -1, 0 or +1.
+func (o OID) Compare(other OID) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		if o[i] != other[i] {
+			if o[i] < other[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(o) < len(other):
+		return -1
+	case len(o) > len(other):
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether both OIDs have the same arcs.
+func (o OID) Equal(other OID) bool {
+	return o.Compare(other) == 0
+}
+
+// HasPrefix reports whether the OID lies within the subtree rooted at prefix.
+// Every OID has itself as a prefix.
+func (o OID) HasPrefix(prefix OID) bool {
+	return len(o) >= len(prefix) && o[:len(prefix)].Equal(prefix)
+}
+
+// Parent returns the OID with its last arc removed, or nil for an empty OID.
+// The result shares storage with o.
+func (o OID) Parent() OID {
+	if len(o) == 0 {
+		return nil
+	}
+	return o[:len(o)-1]
+}
+
+// Append returns a new OID with arcs added to the end; o is left untouched.
+func (o OID) Append(arcs ...int) OID {
+	return append(append(make(OID, 0, len(o)+len(arcs)), o...), arcs...)
+}
+
+// Suffix returns the arcs that follow prefix, such as the instance part of
+// a column's OID, and whether o has that prefix at all.
+func (o OID) Suffix(prefix OID) (OID, bool) {
+	if !o.HasPrefix(prefix) {
+		return nil, false
+	}
+	return append(OID{}, o[len(prefix):]...), true
+}
+
+// Validate checks the OID against the limits SNMP places on the wire form:
+// 2 to 128 arcs, each in 0..2^32-1, a first arc of 0, 1 or 2 and, below
+// the first two roots, a second arc under 40.
+func (o OID) Validate() error {
+	switch {
+	case len(o) < 2:
+		return fmt.Errorf("invalid OID %s: at least two arcs are required", o)
+	case len(o) > MaxOIDLength:
+		return fmt.Errorf("invalid OID: %d arcs exceed the limit of %d", len(o), MaxOIDLength)
+	case o[0] < 0 || o[0] > 2:
+		return fmt.Errorf("invalid OID %s: first arc must be 0, 1 or 2", o)
+	case o[0] < 2 && (o[1] < 0 || o[1] >= 40):
+		return fmt.Errorf("invalid OID %s: second arc must be below 40", o)
+	}
+	for i, arc := range o {
+		if arc < 0 || uint64(arc) > math.MaxUint32 {
+			return fmt.Errorf("invalid OID %s: arc %d is out of range", o, i+1)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary returns the BER content octets of the OID, i.e. the value
+// of an OBJECT IDENTIFIER without its tag and length. The OID must pass
+// Validate.
+func (o OID) MarshalBinary() ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	if uint64(o[0])*40+uint64(o[1]) > math.MaxUint32 {
+		return nil, fmt.Errorf("invalid OID %s: second arc is out of range", o)
+	}
+	var b []byte
+	for _, arc := range append(OID{o[0]*40 + o[1]}, o[2:]...) {
+		enc := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			enc = append([]byte{byte(arc&0x7f) | 0x80}, enc...)
+		}
+		b = append(b, enc...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes BER content octets produced by MarshalBinary.
+func (o *OID) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 || b[len(b)-1]&0x80 != 0 {
+		return errors.New("invalid OID encoding: truncated arc")
+	}
+	var oid OID
+	var arc uint64
+	for _, c := range b {
+		if arc > math.MaxUint32>>7 {
+			return errors.New("invalid OID encoding: arc out of range")
+		}
+		arc = arc<<7 | uint64(c&0x7f)
+		if c&0x80 != 0 {
+			continue
+		}
+		if arc > math.MaxInt {
+			return errors.New("invalid OID encoding: arc does not fit in an int")
+		}
+		if oid == nil {
+			first := min(arc/40, 2)
+			oid = append(oid, int(first), int(arc-40*first))
+		} else {
+			oid = append(oid, int(arc))
+		}
+		arc = 0
+	}
+	if len(oid) > MaxOIDLength {
+		return fmt.Errorf("invalid OID encoding: %d arcs exceed the limit of %d", len(oid), MaxOIDLength)
+	}
+	*o = oid
+	return nil
+}
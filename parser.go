@@ -1,6 +1,8 @@
 package mib_parser
 
 import (
+	"maps"
+
 	"github.com/Olian04/go-mib-parser/parser"
 )
 
@@ -13,57 +15,196 @@ func ParseMIB(mib []byte) (*Module, error) {
 	}
 	mod := &Module{
 		Name:               ir.Name,
+		ObjectIdentifiers:  map[string]*ObjectIdentifier{},
 		ObjectsByName:      map[string]*ObjectType{},
 		ObjectIdentities:   map[string]*ObjectIdentity{},
 		TextualConventions: map[string]*TextualConvention{},
 		NotificationTypes:  map[string]*NotificationType{},
+		ObjectGroups:       map[string]*ObjectGroup{},
+		NotificationGroups: map[string]*NotificationGroup{},
+		ModuleCompliances:  map[string]*ModuleCompliance{},
+		AgentCapabilities:  map[string]*AgentCapabilities{},
+	}
+	mod.Types = append([]string(nil), ir.Types...)
+	mod.Macros = append([]string(nil), ir.Macros...)
+	mod.DefinitionText = maps.Clone(ir.DefinitionText)
+	mod.MacroReferences = append([]string(nil), ir.MacroReferences...)
+	for _, imp := range ir.Imports {
+		mod.Imports = append(mod.Imports, Import{
+			Module:  imp.Module,
+			Symbols: append([]string(nil), imp.Symbols...),
+		})
+	}
+	for name, node := range ir.ObjectIdentifiers {
+		mod.ObjectIdentifiers[name] = &ObjectIdentifier{
+			Name:       node.Name,
+			Pos:        newPosition(node.Pos),
+			OID:        append(OID(nil), node.OID...),
+			Assignment: newAssignment(node.Parent, node.SubIDs),
+		}
 	}
 	for name, obj := range ir.ObjectsByName {
 		mod.ObjectsByName[name] = &ObjectType{
 			Name:        obj.Name,
-			OID:         append([]int(nil), obj.OID...),
+			Pos:         newPosition(obj.Pos),
+			OID:         append(OID(nil), obj.OID...),
+			Assignment:  newAssignment(obj.Parent, obj.SubIDs),
 			Syntax:      obj.Syntax,
+			Units:       obj.Units,
 			Access:      obj.Access,
 			Status:      obj.Status,
 			Description: obj.Description,
+			Reference:   obj.Reference,
 			Index:       append([]string(nil), obj.Index...),
+			Implied:     obj.Implied,
+			Augments:    obj.Augments,
+			DefVal:      obj.DefVal,
 		}
 	}
 	if ir.ModuleIdentity != nil {
 		mod.ModuleIdentity = &ModuleIdentity{
 			Name:         ir.ModuleIdentity.Name,
-			OID:          append([]int(nil), ir.ModuleIdentity.OID...),
+			Pos:          newPosition(ir.ModuleIdentity.Pos),
+			OID:          append(OID(nil), ir.ModuleIdentity.OID...),
+			Assignment:   newAssignment(ir.ModuleIdentity.Parent, ir.ModuleIdentity.SubIDs),
 			LastUpdated:  ir.ModuleIdentity.LastUpdated,
 			Organization: ir.ModuleIdentity.Organization,
 			ContactInfo:  ir.ModuleIdentity.ContactInfo,
 			Description:  ir.ModuleIdentity.Description,
 		}
+		for _, rev := range ir.ModuleIdentity.Revisions {
+			mod.ModuleIdentity.Revisions = append(mod.ModuleIdentity.Revisions, Revision{
+				Date:        rev.Date,
+				Description: rev.Description,
+			})
+		}
 	}
 	for name, oi := range ir.ObjectIdentities {
 		mod.ObjectIdentities[name] = &ObjectIdentity{
 			Name:        oi.Name,
-			OID:         append([]int(nil), oi.OID...),
+			Pos:         newPosition(oi.Pos),
+			OID:         append(OID(nil), oi.OID...),
+			Assignment:  newAssignment(oi.Parent, oi.SubIDs),
 			Status:      oi.Status,
 			Description: oi.Description,
+			Reference:   oi.Reference,
 		}
 	}
 	for name, tc := range ir.TextualConventions {
 		mod.TextualConventions[name] = &TextualConvention{
 			Name:        tc.Name,
+			Pos:         newPosition(tc.Pos),
 			DisplayHint: tc.DisplayHint,
 			Status:      tc.Status,
 			Description: tc.Description,
+			Reference:   tc.Reference,
 			Syntax:      tc.Syntax,
 		}
 	}
 	for name, nt := range ir.NotificationTypes {
 		mod.NotificationTypes[name] = &NotificationType{
 			Name:        nt.Name,
-			OID:         append([]int(nil), nt.OID...),
+			Pos:         newPosition(nt.Pos),
+			OID:         append(OID(nil), nt.OID...),
+			Assignment:  newAssignment(nt.Parent, nt.SubIDs),
 			Objects:     append([]string(nil), nt.Objects...),
 			Status:      nt.Status,
 			Description: nt.Description,
+			Reference:   nt.Reference,
+			TrapType:    nt.TrapType,
 		}
 	}
+	for name, g := range ir.ObjectGroups {
+		mod.ObjectGroups[name] = &ObjectGroup{
+			Name:        g.Name,
+			Pos:         newPosition(g.Pos),
+			OID:         append(OID(nil), g.OID...),
+			Assignment:  newAssignment(g.Parent, g.SubIDs),
+			Objects:     append([]string(nil), g.Members...),
+			Status:      g.Status,
+			Description: g.Description,
+			Reference:   g.Reference,
+		}
+	}
+	for name, g := range ir.NotificationGroups {
+		mod.NotificationGroups[name] = &NotificationGroup{
+			Name:          g.Name,
+			Pos:           newPosition(g.Pos),
+			OID:           append(OID(nil), g.OID...),
+			Assignment:    newAssignment(g.Parent, g.SubIDs),
+			Notifications: append([]string(nil), g.Members...),
+			Status:        g.Status,
+			Description:   g.Description,
+			Reference:     g.Reference,
+		}
+	}
+	for name, mc := range ir.ModuleCompliances {
+		c := &ModuleCompliance{
+			Name:        mc.Name,
+			Pos:         newPosition(mc.Pos),
+			OID:         append(OID(nil), mc.OID...),
+			Assignment:  newAssignment(mc.Parent, mc.SubIDs),
+			Status:      mc.Status,
+			Description: mc.Description,
+			Reference:   mc.Reference,
+		}
+		for _, cm := range mc.Modules {
+			m := ComplianceModule{
+				Module:          cm.Module,
+				MandatoryGroups: append([]string(nil), cm.MandatoryGroups...),
+			}
+			for _, g := range cm.Groups {
+				m.Groups = append(m.Groups, ComplianceGroup{Name: g.Name, Description: g.Description})
+			}
+			for _, o := range cm.Objects {
+				m.Objects = append(m.Objects, ComplianceObject{
+					Name:        o.Name,
+					Syntax:      o.Syntax,
+					WriteSyntax: o.WriteSyntax,
+					MinAccess:   o.MinAccess,
+					Description: o.Description,
+				})
+			}
+			c.Modules = append(c.Modules, m)
+		}
+		mod.ModuleCompliances[name] = c
+	}
+	for name, ac := range ir.AgentCapabilities {
+		c := &AgentCapabilities{
+			Name:           ac.Name,
+			Pos:            newPosition(ac.Pos),
+			OID:            append(OID(nil), ac.OID...),
+			Assignment:     newAssignment(ac.Parent, ac.SubIDs),
+			ProductRelease: ac.ProductRelease,
+			Status:         ac.Status,
+			Description:    ac.Description,
+			Reference:      ac.Reference,
+		}
+		for _, cm := range ac.Supports {
+			m := CapabilitiesModule{Module: cm.Module, Includes: append([]string(nil), cm.Includes...)}
+			for _, v := range cm.Variations {
+				m.Variations = append(m.Variations, Variation{
+					Name:             v.Name,
+					Syntax:           v.Syntax,
+					WriteSyntax:      v.WriteSyntax,
+					Access:           v.Access,
+					CreationRequires: append([]string(nil), v.Creation...),
+					DefVal:           v.DefVal,
+					Description:      v.Description,
+				})
+			}
+			c.Supports = append(c.Supports, m)
+		}
+		mod.AgentCapabilities[name] = c
+	}
+	mod.bind()
 	return mod, nil
 }
+
+func newAssignment(parent string, subIDs []int) OIDAssignment {
+	return OIDAssignment{Parent: parent, SubIDs: append([]int(nil), subIDs...)}
+}
+
+func newPosition(pos parser.Pos) Position {
+	return Position{Line: pos.Line, Column: pos.Col}
+}
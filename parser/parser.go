@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Olian04/go-mib-parser/lexer"
@@ -12,63 +13,210 @@ import (
 // It is designed to avoid import cycles with the public package.
 type ModuleIR struct {
 	Name               string
+	Imports            []ImportIR
 	NodesByName        map[string][]int
+	ObjectIdentifiers  map[string]*ObjectIdentifierIR
 	ObjectsByName      map[string]*ObjectTypeIR
 	ModuleIdentity     *ModuleIdentityIR
 	ObjectIdentities   map[string]*ObjectIdentityIR
 	TextualConventions map[string]*TextualConventionIR
 	NotificationTypes  map[string]*NotificationTypeIR
+	ObjectGroups       map[string]*GroupIR
+	NotificationGroups map[string]*GroupIR
+	ModuleCompliances  map[string]*ModuleComplianceIR
+	AgentCapabilities  map[string]*AgentCapabilitiesIR
+	// Types and Macros name the type assignments other than textual
+	// conventions and the MACRO definitions, whose bodies are skipped.
+	Types  []string
+	Macros []string
+	// DefinitionText holds the source text of each entry of Types and
+	// Macros, keyed by name.
+	DefinitionText map[string]string
+	// MacroReferences lists the identifiers used inside MACRO bodies, once
+	// each, in source order.
+	MacroReferences []string
+}
+
+// ImportIR is one "<symbols> FROM <module>" group of the IMPORTS clause.
+type ImportIR struct {
+	Module  string
+	Symbols []string
+}
+
+// ObjectIdentifierIR is a plain "<name> OBJECT IDENTIFIER ::= { ... }" assignment.
+type ObjectIdentifierIR struct {
+	Name   string
+	Pos    Pos
+	OID    []int
+	Parent string
+	SubIDs []int
 }
 
 // ObjectTypeIR is an internal representation of OBJECT-TYPE definitions.
 type ObjectTypeIR struct {
 	Name        string
+	Pos         Pos
 	OID         []int
 	Syntax      string
+	Units       string
 	Access      string
 	Status      string
 	Description string
+	Reference   string
 	Index       []string
+	Implied     bool
+	Augments    string
+	DefVal      string
+	Parent      string
+	SubIDs      []int
 }
 
 type ModuleIdentityIR struct {
 	Name         string
+	Pos          Pos
 	OID          []int
 	LastUpdated  string
 	Organization string
 	ContactInfo  string
 	Description  string
+	Revisions    []RevisionIR
+	Parent       string
+	SubIDs       []int
+}
+
+type RevisionIR struct {
+	Date        string
+	Description string
 }
 
 type ObjectIdentityIR struct {
 	Name        string
+	Pos         Pos
 	OID         []int
 	Status      string
 	Description string
+	Reference   string
+	Parent      string
+	SubIDs      []int
 }
 
 type TextualConventionIR struct {
 	Name        string
+	Pos         Pos
 	DisplayHint string
 	Status      string
 	Description string
+	Reference   string
 	Syntax      string
 }
 
 type NotificationTypeIR struct {
 	Name        string
+	Pos         Pos
 	OID         []int
 	Objects     []string
 	Status      string
 	Description string
+	Reference   string
+	Parent      string
+	SubIDs      []int
+	// TrapType reports an SMIv1 TRAP-TYPE converted to this form.
+	TrapType bool
+}
+
+// GroupIR is an OBJECT-GROUP or NOTIFICATION-GROUP; Members holds the
+// OBJECTS or NOTIFICATIONS list respectively.
+type GroupIR struct {
+	Name        string
+	Pos         Pos
+	OID         []int
+	Members     []string
+	Status      string
+	Description string
+	Reference   string
+	Parent      string
+	SubIDs      []int
+}
+
+type ModuleComplianceIR struct {
+	Name        string
+	Pos         Pos
+	OID         []int
+	Status      string
+	Description string
+	Reference   string
+	Modules     []ComplianceModuleIR
+	Parent      string
+	SubIDs      []int
+}
+
+// ComplianceModuleIR is one MODULE clause; Module is empty for "this module".
+type ComplianceModuleIR struct {
+	Module          string
+	MandatoryGroups []string
+	Groups          []ComplianceGroupIR
+	Objects         []ComplianceObjectIR
+}
+
+type ComplianceGroupIR struct {
+	Name        string
+	Description string
+}
+
+type ComplianceObjectIR struct {
+	Name        string
+	Syntax      string
+	WriteSyntax string
+	MinAccess   string
+	Description string
+}
+
+// AgentCapabilitiesIR is an AGENT-CAPABILITIES statement.
+type AgentCapabilitiesIR struct {
+	Name           string
+	Pos            Pos
+	OID            []int
+	ProductRelease string
+	Status         string
+	Description    string
+	Reference      string
+	Supports       []CapabilitiesModuleIR
+	Parent         string
+	SubIDs         []int
+}
+
+// CapabilitiesModuleIR is one SUPPORTS clause.
+type CapabilitiesModuleIR struct {
+	Module     string
+	Includes   []string
+	Variations []VariationIR
+}
+
+type VariationIR struct {
+	Name        string
+	Syntax      string
+	WriteSyntax string
+	Access      string
+	Creation    []string
+	DefVal      string
+	Description string
+}
+
+// Pos is the source position of a definition's name.
+type Pos struct {
+	Line int
+	Col  int
 }
 
 type rdParser struct {
-	l    *lexer.Lexer
-	tok  lexer.Token
-	mod  *ModuleIR
-	pend []pendingRef
-	src  string
+	defPos Pos
+	l      *lexer.Lexer
+	tok    lexer.Token
+	mod    *ModuleIR
+	pend   []pendingRef
+	src    string
+	// lines is src split into lines, filled in by keepText.
+	lines []string
 }
 
 type pendingRef struct {
@@ -78,7 +226,7 @@ type pendingRef struct {
 }
 
 func Parse(input []byte) (*ModuleIR, error) {
-	p := &rdParser{l: lexer.New(input), src: string(input), mod: &ModuleIR{NodesByName: map[string][]int{}, ObjectsByName: map[string]*ObjectTypeIR{}, ObjectIdentities: map[string]*ObjectIdentityIR{}, TextualConventions: map[string]*TextualConventionIR{}, NotificationTypes: map[string]*NotificationTypeIR{}}}
+	p := &rdParser{l: lexer.New(input), src: string(input), mod: &ModuleIR{NodesByName: map[string][]int{}, ObjectIdentifiers: map[string]*ObjectIdentifierIR{}, ObjectsByName: map[string]*ObjectTypeIR{}, ObjectIdentities: map[string]*ObjectIdentityIR{}, TextualConventions: map[string]*TextualConventionIR{}, NotificationTypes: map[string]*NotificationTypeIR{}, ObjectGroups: map[string]*GroupIR{}, NotificationGroups: map[string]*GroupIR{}, ModuleCompliances: map[string]*ModuleComplianceIR{}, AgentCapabilities: map[string]*AgentCapabilitiesIR{}}}
 	p.next()
 	p.initBaseOids()
 
@@ -132,10 +280,13 @@ func (p *rdParser) parseModule() error {
 		if p.tok.Type == lexer.TokenIdent {
 			// Lookahead for 'OBJECT IDENTIFIER' or 'OBJECT-TYPE'
 			ident := p.tok.Text
+			p.defPos = Pos{Line: p.tok.Line, Col: p.tok.Col}
 			p.next()
 			// If this is a MACRO definition, skip the MACRO body entirely
 			if p.isIdent("MACRO") {
+				p.mod.Macros = append(p.mod.Macros, ident)
 				p.skipDefinition()
+				p.keepText(ident)
 				continue
 			}
 			if p.isIdent("OBJECT") {
@@ -154,13 +305,18 @@ func (p *rdParser) parseModule() error {
 				if !p.accept(lexer.TokenRBrace) {
 					return p.errorf("expected '}' in OBJECT IDENTIFIER assignment")
 				}
+				node := &ObjectIdentifierIR{Name: ident, Pos: p.defPos}
+				node.Parent, node.SubIDs = assignment(parentName, index, abs, hasAbs)
+				p.mod.ObjectIdentifiers[ident] = node
 				if hasAbs {
+					node.OID = append([]int(nil), abs...)
 					p.mod.NodesByName[ident] = append([]int(nil), abs...)
 				} else {
 					// resolve parent (allow forward references)
 					if base, ok := p.resolveOidBase(parentName); ok {
 						oid := append(append([]int(nil), base...), index)
-						p.mod.NodesByName[ident] = oid
+						node.OID = oid
+						p.mod.NodesByName[ident] = append([]int(nil), oid...)
 					} else {
 						// ensure placeholder so presence is recorded
 						if _, exists := p.mod.NodesByName[ident]; !exists {
@@ -172,7 +328,8 @@ func (p *rdParser) parseModule() error {
 							index:  index,
 							apply: func(base []int) {
 								oid := append(append([]int(nil), base...), index)
-								p.mod.NodesByName[name] = oid
+								node.OID = oid
+								p.mod.NodesByName[name] = append([]int(nil), oid...)
 							},
 						})
 					}
@@ -183,7 +340,7 @@ func (p *rdParser) parseModule() error {
 			if p.accept(lexer.TokenColonColonEq) {
 				if p.acceptIdent("TEXTUAL-CONVENTION") {
 					// We have already consumed the name and '::= TEXTUAL-CONVENTION'
-					tc := &TextualConventionIR{Name: ident}
+					tc := &TextualConventionIR{Name: ident, Pos: p.defPos}
 					for {
 						if p.acceptIdent("DISPLAY-HINT") {
 							if p.tok.Type == lexer.TokenString {
@@ -203,6 +360,13 @@ func (p *rdParser) parseModule() error {
 							}
 							continue
 						}
+						if p.acceptIdent("REFERENCE") {
+							if p.tok.Type == lexer.TokenString {
+								tc.Reference = p.tok.Text
+								p.next()
+							}
+							continue
+						}
 						if p.acceptIdent("SYNTAX") {
 							tc.Syntax = p.parseTypeString()
 							p.mod.TextualConventions[tc.Name] = tc
@@ -215,14 +379,16 @@ func (p *rdParser) parseModule() error {
 					}
 					continue
 				}
-				// For other assignments (e.g., ::= SEQUENCE ...), skip definition body
-				p.skipDefinition()
+				// Other type assignments (e.g., ::= SEQUENCE ...) are only recorded by name
+				p.mod.Types = append(p.mod.Types, ident)
+				p.skipType()
+				p.keepText(ident)
 				continue
 			}
 			if p.isIdent("OBJECT-TYPE") {
 				// Parse OBJECT-TYPE block
 				p.next()
-				obj := &ObjectTypeIR{Name: ident}
+				obj := &ObjectTypeIR{Name: ident, Pos: p.defPos}
 				// read clauses until '::=' then '{ parent n }'
 				for {
 					if p.tok.Type == lexer.TokenEOF {
@@ -239,8 +405,15 @@ func (p *rdParser) parseModule() error {
 						obj.Access = p.parseUntilKeywords("STATUS", "DESCRIPTION", "INDEX", "::=")
 						continue
 					}
+					if p.acceptIdent("UNITS") {
+						if p.tok.Type == lexer.TokenString {
+							obj.Units = p.tok.Text
+							p.next()
+						}
+						continue
+					}
 					if p.acceptIdent("STATUS") {
-						obj.Status = p.parseUntilKeywords("DESCRIPTION", "INDEX", "::=")
+						obj.Status = p.parseUntilKeywords("DESCRIPTION", "REFERENCE", "INDEX", "AUGMENTS", "DEFVAL", "::=")
 						continue
 					}
 					if p.acceptIdent("DESCRIPTION") {
@@ -253,6 +426,35 @@ func (p *rdParser) parseModule() error {
 						p.next()
 						continue
 					}
+					if p.acceptIdent("REFERENCE") {
+						if p.tok.Type == lexer.TokenString {
+							obj.Reference = p.tok.Text
+							p.next()
+						}
+						continue
+					}
+					if p.acceptIdent("AUGMENTS") {
+						// AUGMENTS { entry }
+						if !p.accept(lexer.TokenLBrace) {
+							return p.errorf("expected '{' after AUGMENTS")
+						}
+						if p.tok.Type == lexer.TokenIdent {
+							obj.Augments = p.tok.Text
+							p.next()
+						}
+						if !p.accept(lexer.TokenRBrace) {
+							return p.errorf("expected '}' after AUGMENTS entry")
+						}
+						continue
+					}
+					if p.acceptIdent("DEFVAL") {
+						// DEFVAL { value }; kept verbatim without the braces
+						if p.tok.Type != lexer.TokenLBrace {
+							return p.errorf("expected '{' after DEFVAL")
+						}
+						obj.DefVal = p.parseBracedText()
+						continue
+					}
 					if p.acceptIdent("INDEX") {
 						// INDEX { a, b, c }
 						if !p.accept(lexer.TokenLBrace) {
@@ -263,6 +465,7 @@ func (p *rdParser) parseModule() error {
 							if p.tok.Type == lexer.TokenIdent {
 								// Allow optional IMPLIED keyword prefix in SMIv2
 								if equalFold(p.tok.Text, "IMPLIED") {
+									obj.Implied = true
 									p.next()
 									// expect actual identifier next without requiring a comma
 									continue
@@ -304,6 +507,7 @@ func (p *rdParser) parseModule() error {
 						if !p.accept(lexer.TokenRBrace) {
 							return p.errorf("expected '}' after OBJECT-TYPE OID ref")
 						}
+						obj.Parent, obj.SubIDs = assignment(parentName, index, abs, hasAbs)
 						if hasAbs {
 							obj.OID = append([]int(nil), abs...)
 							// store
@@ -349,123 +553,28 @@ func (p *rdParser) parseModule() error {
 				}
 				continue
 			}
-			if p.isIdent("OBJECT-GROUP") {
-				p.next()
-				// Parse until OID assignment
-				for {
-					if p.tok.Type == lexer.TokenEOF {
-						return p.errorf("unexpected EOF in OBJECT-GROUP")
-					}
-					if p.accept(lexer.TokenColonColonEq) {
-						if !p.accept(lexer.TokenLBrace) {
-							return p.errorf("expected '{' after OBJECT-GROUP '::='")
-						}
-						parent, idx := p.parseParentRef()
-						if !p.accept(lexer.TokenRBrace) {
-							return p.errorf("expected '}' after OBJECT-GROUP OID")
-						}
-						if base, ok := p.mod.NodesByName[parent]; ok {
-							p.mod.NodesByName[ident] = append(append([]int(nil), base...), idx)
-						} else {
-							name := ident
-							p.pend = append(p.pend, pendingRef{parent: parent, index: idx, apply: func(base []int) {
-								p.mod.NodesByName[name] = append(append([]int(nil), base...), idx)
-							}})
-						}
-						break
-					}
-					p.next()
-				}
-				continue
-			}
-			if p.isIdent("NOTIFICATION-GROUP") {
-				p.next()
-				for {
-					if p.tok.Type == lexer.TokenEOF {
-						return p.errorf("unexpected EOF in NOTIFICATION-GROUP")
-					}
-					if p.accept(lexer.TokenColonColonEq) {
-						if !p.accept(lexer.TokenLBrace) {
-							return p.errorf("expected '{' after NOTIFICATION-GROUP '::='")
-						}
-						parent, idx := p.parseParentRef()
-						if !p.accept(lexer.TokenRBrace) {
-							return p.errorf("expected '}' after NOTIFICATION-GROUP OID")
-						}
-						if base, ok := p.mod.NodesByName[parent]; ok {
-							p.mod.NodesByName[ident] = append(append([]int(nil), base...), idx)
-						} else {
-							name := ident
-							p.pend = append(p.pend, pendingRef{parent: parent, index: idx, apply: func(base []int) {
-								p.mod.NodesByName[name] = append(append([]int(nil), base...), idx)
-							}})
-						}
-						break
-					}
-					p.next()
+			if p.isIdent("OBJECT-GROUP") || p.isIdent("NOTIFICATION-GROUP") {
+				if err := p.parseGroup(ident); err != nil {
+					return err
 				}
 				continue
 			}
 			if p.isIdent("MODULE-COMPLIANCE") {
-				p.next()
-				for {
-					if p.tok.Type == lexer.TokenEOF {
-						return p.errorf("unexpected EOF in MODULE-COMPLIANCE")
-					}
-					if p.accept(lexer.TokenColonColonEq) {
-						if !p.accept(lexer.TokenLBrace) {
-							return p.errorf("expected '{' after MODULE-COMPLIANCE '::='")
-						}
-						parent, idx := p.parseParentRef()
-						if !p.accept(lexer.TokenRBrace) {
-							return p.errorf("expected '}' after MODULE-COMPLIANCE OID")
-						}
-						if base, ok := p.mod.NodesByName[parent]; ok {
-							p.mod.NodesByName[ident] = append(append([]int(nil), base...), idx)
-						} else {
-							name := ident
-							p.pend = append(p.pend, pendingRef{parent: parent, index: idx, apply: func(base []int) {
-								p.mod.NodesByName[name] = append(append([]int(nil), base...), idx)
-							}})
-						}
-						break
-					}
-					p.next()
+				if err := p.parseModuleCompliance(ident); err != nil {
+					return err
 				}
 				continue
 			}
 			if p.isIdent("AGENT-CAPABILITIES") {
-				p.next()
-				for {
-					if p.tok.Type == lexer.TokenEOF {
-						return p.errorf("unexpected EOF in AGENT-CAPABILITIES")
-					}
-					if p.accept(lexer.TokenColonColonEq) {
-						if !p.accept(lexer.TokenLBrace) {
-							return p.errorf("expected '{' after AGENT-CAPABILITIES '::='")
-						}
-						parent, idx := p.parseParentRef()
-						if !p.accept(lexer.TokenRBrace) {
-							return p.errorf("expected '}' after AGENT-CAPABILITIES OID")
-						}
-						if base, ok := p.mod.NodesByName[parent]; ok {
-							p.mod.NodesByName[ident] = append(append([]int(nil), base...), idx)
-						} else {
-							name := ident
-							p.pend = append(p.pend, pendingRef{parent: parent, index: idx, apply: func(base []int) {
-								p.mod.NodesByName[name] = append(append([]int(nil), base...), idx)
-							}})
-						}
-						break
-					}
-					p.next()
+				if err := p.parseAgentCapabilities(ident); err != nil {
+					return err
 				}
 				continue
 			}
 			if p.isIdent("MODULE-IDENTITY") {
 				p.next()
 				// MODULE-IDENTITY
-				mi := &ModuleIdentityIR{Name: ident}
+				mi := &ModuleIdentityIR{Name: ident, Pos: p.defPos}
 				// record placeholder node name so children can reference immediately
 				if _, exists := p.mod.NodesByName[ident]; !exists {
 					p.mod.NodesByName[ident] = []int{}
@@ -500,6 +609,20 @@ func (p *rdParser) parseModule() error {
 						}
 						continue
 					}
+					if p.acceptIdent("REVISION") {
+						// REVISION "<date>" DESCRIPTION "<text>"
+						rev := RevisionIR{}
+						if p.tok.Type == lexer.TokenString {
+							rev.Date = p.tok.Text
+							p.next()
+						}
+						if p.acceptIdent("DESCRIPTION") && p.tok.Type == lexer.TokenString {
+							rev.Description = p.tok.Text
+							p.next()
+						}
+						mi.Revisions = append(mi.Revisions, rev)
+						continue
+					}
 					if p.accept(lexer.TokenColonColonEq) {
 						if !p.accept(lexer.TokenLBrace) {
 							return p.errorf("expected '{' after MODULE-IDENTITY '::='")
@@ -508,6 +631,7 @@ func (p *rdParser) parseModule() error {
 						if !p.accept(lexer.TokenRBrace) {
 							return p.errorf("expected '}' after MODULE-IDENTITY OID")
 						}
+						mi.Parent, mi.SubIDs = assignment(parent, idx, abs, hasAbs)
 						if hasAbs {
 							mi.OID = append([]int(nil), abs...)
 							p.mod.ModuleIdentity = mi
@@ -541,13 +665,13 @@ func (p *rdParser) parseModule() error {
 			}
 			if p.isIdent("OBJECT-IDENTITY") {
 				p.next()
-				oi := &ObjectIdentityIR{Name: ident}
+				oi := &ObjectIdentityIR{Name: ident, Pos: p.defPos}
 				if _, exists := p.mod.NodesByName[ident]; !exists {
 					p.mod.NodesByName[ident] = []int{}
 				}
 				for {
 					if p.acceptIdent("STATUS") {
-						oi.Status = p.parseUntilKeywords("DESCRIPTION", "::=")
+						oi.Status = p.parseUntilKeywords("DESCRIPTION", "REFERENCE", "::=")
 						continue
 					}
 					if p.acceptIdent("DESCRIPTION") {
@@ -557,6 +681,13 @@ func (p *rdParser) parseModule() error {
 						}
 						continue
 					}
+					if p.acceptIdent("REFERENCE") {
+						if p.tok.Type == lexer.TokenString {
+							oi.Reference = p.tok.Text
+							p.next()
+						}
+						continue
+					}
 					if p.accept(lexer.TokenColonColonEq) {
 						if !p.accept(lexer.TokenLBrace) {
 							return p.errorf("expected '{' after OBJECT-IDENTITY '::='")
@@ -565,6 +696,7 @@ func (p *rdParser) parseModule() error {
 						if !p.accept(lexer.TokenRBrace) {
 							return p.errorf("expected '}' after OBJECT-IDENTITY OID")
 						}
+						oi.Parent, oi.SubIDs = assignment(parent, idx, abs, hasAbs)
 						if hasAbs {
 							oi.OID = append([]int(nil), abs...)
 							p.mod.ObjectIdentities[oi.Name] = oi
@@ -598,7 +730,7 @@ func (p *rdParser) parseModule() error {
 			}
 			if p.isIdent("TEXTUAL-CONVENTION") {
 				p.next()
-				tc := &TextualConventionIR{Name: ident}
+				tc := &TextualConventionIR{Name: ident, Pos: p.defPos}
 				// TEXTUAL-CONVENTION
 				for {
 					if p.acceptIdent("DISPLAY-HINT") {
@@ -619,6 +751,13 @@ func (p *rdParser) parseModule() error {
 						}
 						continue
 					}
+					if p.acceptIdent("REFERENCE") {
+						if p.tok.Type == lexer.TokenString {
+							tc.Reference = p.tok.Text
+							p.next()
+						}
+						continue
+					}
 					if p.acceptIdent("SYNTAX") {
 						tc.Syntax = p.parseTypeString()
 						// end of textual convention
@@ -634,7 +773,7 @@ func (p *rdParser) parseModule() error {
 			}
 			if p.isIdent("NOTIFICATION-TYPE") {
 				p.next()
-				nt := &NotificationTypeIR{Name: ident}
+				nt := &NotificationTypeIR{Name: ident, Pos: p.defPos}
 				for {
 					if p.acceptIdent("OBJECTS") {
 						if !p.accept(lexer.TokenLBrace) {
@@ -660,7 +799,7 @@ func (p *rdParser) parseModule() error {
 						continue
 					}
 					if p.acceptIdent("STATUS") {
-						nt.Status = p.parseUntilKeywords("DESCRIPTION", "::=")
+						nt.Status = p.parseUntilKeywords("DESCRIPTION", "REFERENCE", "::=")
 						continue
 					}
 					if p.acceptIdent("DESCRIPTION") {
@@ -670,6 +809,13 @@ func (p *rdParser) parseModule() error {
 						}
 						continue
 					}
+					if p.acceptIdent("REFERENCE") {
+						if p.tok.Type == lexer.TokenString {
+							nt.Reference = p.tok.Text
+							p.next()
+						}
+						continue
+					}
 					if p.accept(lexer.TokenColonColonEq) {
 						if !p.accept(lexer.TokenLBrace) {
 							return p.errorf("expected '{' after NOTIFICATION-TYPE '::='")
@@ -678,6 +824,7 @@ func (p *rdParser) parseModule() error {
 						if !p.accept(lexer.TokenRBrace) {
 							return p.errorf("expected '}' after NOTIFICATION-TYPE OID")
 						}
+						nt.Parent, nt.SubIDs = assignment(parent, idx, abs, hasAbs)
 						if hasAbs {
 							nt.OID = append([]int(nil), abs...)
 							p.mod.NotificationTypes[nt.Name] = nt
@@ -706,6 +853,12 @@ func (p *rdParser) parseModule() error {
 				}
 				continue
 			}
+			if p.isIdent("TRAP-TYPE") {
+				if err := p.parseTrapType(ident); err != nil {
+					return err
+				}
+				continue
+			}
 			// Unknown top-level construct: skip its definition conservatively
 			p.skipDefinition()
 			continue
@@ -721,7 +874,7 @@ func (p *rdParser) parseModule() error {
 		progressed := false
 		remaining := p.pend[:0]
 		for _, pr := range p.pend {
-			if base, ok := p.mod.NodesByName[pr.parent]; ok {
+			if base, ok := p.resolveOidBase(pr.parent); ok {
 				pr.apply(base)
 				progressed = true
 			} else {
@@ -738,15 +891,392 @@ func (p *rdParser) parseModule() error {
 }
 
 func (p *rdParser) parseImports() error {
-	// IMPORTS ... ;
+	// IMPORTS <symbol>, <symbol> FROM <module> ... ;
 	p.next() // consume IMPORTS
-	// We ignore actual imported names and modules for now and just consume until ';'
+	var symbols []string
 	for p.tok.Type != lexer.TokenEOF && !p.accept(lexer.TokenSemicolon) {
+		if p.acceptIdent("FROM") {
+			if p.tok.Type != lexer.TokenIdent {
+				return p.errorf("expected module name after FROM")
+			}
+			p.mod.Imports = append(p.mod.Imports, ImportIR{Module: p.tok.Text, Symbols: symbols})
+			symbols = nil
+			p.next()
+			continue
+		}
+		if p.tok.Type == lexer.TokenIdent {
+			symbols = append(symbols, p.tok.Text)
+		}
 		p.next()
 	}
 	return nil
 }
 
+// parseTrapType parses an SMIv1 TRAP-TYPE (RFC 1215) into the equivalent
+// NOTIFICATION-TYPE of RFC 3584 section 2.1.2: its OID is the ENTERPRISE
+// followed by 0 and the trap number, and VARIABLES become the OBJECTS.
+// The current token is the macro keyword.
+func (p *rdParser) parseTrapType(name string) error {
+	p.next()
+	nt := &NotificationTypeIR{Name: name, Pos: p.defPos, Status: "current", TrapType: true}
+	var enterprise string
+	for {
+		if p.tok.Type == lexer.TokenEOF {
+			return p.errorf("unexpected EOF in TRAP-TYPE")
+		}
+		if p.acceptIdent("ENTERPRISE") {
+			if p.tok.Type != lexer.TokenIdent {
+				return p.errorf("expected enterprise name after ENTERPRISE")
+			}
+			enterprise = p.tok.Text
+			p.next()
+			continue
+		}
+		if p.acceptIdent("VARIABLES") {
+			objs, err := p.parseNameList()
+			if err != nil {
+				return err
+			}
+			nt.Objects = objs
+			continue
+		}
+		if p.acceptIdent("DESCRIPTION") {
+			nt.Description = p.parseText()
+			continue
+		}
+		if p.acceptIdent("REFERENCE") {
+			nt.Reference = p.parseText()
+			continue
+		}
+		if p.accept(lexer.TokenColonColonEq) {
+			if p.tok.Type != lexer.TokenNumber {
+				return p.errorf("expected trap number after TRAP-TYPE '::='")
+			}
+			number := p.tok.Int
+			p.next()
+			if enterprise == "" {
+				return p.errorf("TRAP-TYPE %s has no ENTERPRISE", name)
+			}
+			nt.Parent, nt.SubIDs = enterprise, []int{0, number}
+			p.mod.NotificationTypes[name] = nt
+			if base, ok := p.resolveOidBase(enterprise); ok {
+				nt.OID = append(append([]int(nil), base...), 0, number)
+				return nil
+			}
+			p.pend = append(p.pend, pendingRef{
+				parent: enterprise,
+				apply: func(base []int) {
+					nt.OID = append(append([]int(nil), base...), 0, number)
+				},
+			})
+			return nil
+		}
+		p.next()
+	}
+}
+
+// parseGroup parses an OBJECT-GROUP or NOTIFICATION-GROUP body; the current
+// token is the macro keyword.
+func (p *rdParser) parseGroup(name string) error {
+	kind := p.tok.Text
+	p.next()
+	g := &GroupIR{Name: name, Pos: p.defPos}
+	if equalFold(kind, "OBJECT-GROUP") {
+		p.mod.ObjectGroups[name] = g
+	} else {
+		p.mod.NotificationGroups[name] = g
+	}
+	for {
+		if p.tok.Type == lexer.TokenEOF {
+			return p.errorf("unexpected EOF in %s", kind)
+		}
+		if p.acceptIdent("OBJECTS") || p.acceptIdent("NOTIFICATIONS") {
+			members, err := p.parseNameList()
+			if err != nil {
+				return err
+			}
+			g.Members = members
+			continue
+		}
+		if p.acceptIdent("STATUS") {
+			g.Status = p.parseUntilKeywords("DESCRIPTION", "REFERENCE", "::=")
+			continue
+		}
+		if p.acceptIdent("DESCRIPTION") {
+			g.Description = p.parseText()
+			continue
+		}
+		if p.acceptIdent("REFERENCE") {
+			g.Reference = p.parseText()
+			continue
+		}
+		if p.tok.Type == lexer.TokenColonColonEq {
+			parent, subIDs, err := p.parseOidValue(kind, name, func(oid []int) { g.OID = oid })
+			g.Parent, g.SubIDs = parent, subIDs
+			return err
+		}
+		p.next()
+	}
+}
+
+// parseModuleCompliance parses a MODULE-COMPLIANCE body; the current token is
+// the macro keyword.
+func (p *rdParser) parseModuleCompliance(name string) error {
+	p.next()
+	mc := &ModuleComplianceIR{Name: name, Pos: p.defPos}
+	p.mod.ModuleCompliances[name] = mc
+	var cur *ComplianceModuleIR
+	for {
+		if p.tok.Type == lexer.TokenEOF {
+			return p.errorf("unexpected EOF in MODULE-COMPLIANCE")
+		}
+		if cur == nil {
+			if p.acceptIdent("STATUS") {
+				mc.Status = p.parseUntilKeywords("DESCRIPTION", "REFERENCE", "MODULE", "::=")
+				continue
+			}
+			if p.acceptIdent("DESCRIPTION") {
+				mc.Description = p.parseText()
+				continue
+			}
+			if p.acceptIdent("REFERENCE") {
+				mc.Reference = p.parseText()
+				continue
+			}
+		}
+		if p.acceptIdent("MODULE") {
+			mc.Modules = append(mc.Modules, ComplianceModuleIR{})
+			cur = &mc.Modules[len(mc.Modules)-1]
+			// optional module name; absent means "this module"
+			if p.tok.Type == lexer.TokenIdent && !p.isIdent("MANDATORY-GROUPS") && !p.isIdent("GROUP") && !p.isIdent("OBJECT") && !p.isIdent("MODULE") {
+				cur.Module = p.tok.Text
+				p.next()
+				if p.tok.Type == lexer.TokenLBrace {
+					p.parseBalanced()
+				}
+			}
+			continue
+		}
+		if cur != nil && p.acceptIdent("MANDATORY-GROUPS") {
+			groups, err := p.parseNameList()
+			if err != nil {
+				return err
+			}
+			cur.MandatoryGroups = groups
+			continue
+		}
+		if cur != nil && p.acceptIdent("GROUP") {
+			g := ComplianceGroupIR{}
+			if p.tok.Type == lexer.TokenIdent {
+				g.Name = p.tok.Text
+				p.next()
+			}
+			if p.acceptIdent("DESCRIPTION") {
+				g.Description = p.parseText()
+			}
+			cur.Groups = append(cur.Groups, g)
+			continue
+		}
+		if cur != nil && p.acceptIdent("OBJECT") {
+			o := ComplianceObjectIR{}
+			if p.tok.Type == lexer.TokenIdent {
+				o.Name = p.tok.Text
+				p.next()
+			}
+			for {
+				if p.acceptIdent("SYNTAX") {
+					o.Syntax = p.parseTypeString()
+					continue
+				}
+				if p.acceptIdent("WRITE-SYNTAX") {
+					o.WriteSyntax = p.parseTypeString()
+					continue
+				}
+				if p.acceptIdent("MIN-ACCESS") {
+					o.MinAccess = p.parseUntilKeywords("DESCRIPTION", "OBJECT", "GROUP", "MODULE", "::=")
+					continue
+				}
+				if p.acceptIdent("DESCRIPTION") {
+					o.Description = p.parseText()
+				}
+				break
+			}
+			cur.Objects = append(cur.Objects, o)
+			continue
+		}
+		if p.tok.Type == lexer.TokenColonColonEq {
+			parent, subIDs, err := p.parseOidValue("MODULE-COMPLIANCE", name, func(oid []int) { mc.OID = oid })
+			mc.Parent, mc.SubIDs = parent, subIDs
+			return err
+		}
+		p.next()
+	}
+}
+
+// parseAgentCapabilities parses an AGENT-CAPABILITIES body (RFC 2580
+// section 6); the current token is the macro keyword.
+func (p *rdParser) parseAgentCapabilities(name string) error {
+	p.next()
+	ac := &AgentCapabilitiesIR{Name: name, Pos: p.defPos}
+	p.mod.AgentCapabilities[name] = ac
+	var cur *CapabilitiesModuleIR
+	for {
+		if p.tok.Type == lexer.TokenEOF {
+			return p.errorf("unexpected EOF in AGENT-CAPABILITIES")
+		}
+		if cur == nil {
+			if p.acceptIdent("PRODUCT-RELEASE") {
+				ac.ProductRelease = p.parseText()
+				continue
+			}
+			if p.acceptIdent("STATUS") {
+				ac.Status = p.parseUntilKeywords("DESCRIPTION", "REFERENCE", "SUPPORTS", "::=")
+				continue
+			}
+			if p.acceptIdent("DESCRIPTION") {
+				ac.Description = p.parseText()
+				continue
+			}
+			if p.acceptIdent("REFERENCE") {
+				ac.Reference = p.parseText()
+				continue
+			}
+		}
+		if p.acceptIdent("SUPPORTS") {
+			ac.Supports = append(ac.Supports, CapabilitiesModuleIR{})
+			cur = &ac.Supports[len(ac.Supports)-1]
+			if p.tok.Type == lexer.TokenIdent && !p.isIdent("INCLUDES") {
+				cur.Module = p.tok.Text
+				p.next()
+				if p.tok.Type == lexer.TokenLBrace {
+					p.parseBalanced()
+				}
+			}
+			continue
+		}
+		if cur != nil && p.acceptIdent("INCLUDES") {
+			groups, err := p.parseNameList()
+			if err != nil {
+				return err
+			}
+			cur.Includes = groups
+			continue
+		}
+		if cur != nil && p.acceptIdent("VARIATION") {
+			v := VariationIR{}
+			if p.tok.Type == lexer.TokenIdent {
+				v.Name = p.tok.Text
+				p.next()
+			}
+			for {
+				if p.acceptIdent("SYNTAX") {
+					v.Syntax = p.parseTypeString()
+					continue
+				}
+				if p.acceptIdent("WRITE-SYNTAX") {
+					v.WriteSyntax = p.parseTypeString()
+					continue
+				}
+				if p.acceptIdent("ACCESS") {
+					v.Access = p.parseUntilKeywords("CREATION-REQUIRES", "DEFVAL", "DESCRIPTION", "VARIATION", "SUPPORTS", "::=")
+					continue
+				}
+				if p.acceptIdent("CREATION-REQUIRES") {
+					names, err := p.parseNameList()
+					if err != nil {
+						return err
+					}
+					v.Creation = names
+					continue
+				}
+				if p.acceptIdent("DEFVAL") {
+					v.DefVal = p.parseBracedText()
+					continue
+				}
+				if p.acceptIdent("DESCRIPTION") {
+					v.Description = p.parseText()
+				}
+				break
+			}
+			cur.Variations = append(cur.Variations, v)
+			continue
+		}
+		if p.tok.Type == lexer.TokenColonColonEq {
+			parent, subIDs, err := p.parseOidValue("AGENT-CAPABILITIES", name, func(oid []int) { ac.OID = oid })
+			ac.Parent, ac.SubIDs = parent, subIDs
+			return err
+		}
+		p.next()
+	}
+}
+
+// parseNameList parses "{ a, b, c }" and returns the names.
+func (p *rdParser) parseNameList() ([]string, error) {
+	if !p.accept(lexer.TokenLBrace) {
+		return nil, p.errorf("expected '{'")
+	}
+	var names []string
+	for p.tok.Type == lexer.TokenIdent {
+		names = append(names, p.tok.Text)
+		p.next()
+		if !p.accept(lexer.TokenComma) {
+			break
+		}
+	}
+	if !p.accept(lexer.TokenRBrace) {
+		return nil, p.errorf("expected ',' or '}' in list")
+	}
+	return names, nil
+}
+
+// parseText returns the current string token, if any, and consumes it.
+func (p *rdParser) parseText() string {
+	if p.tok.Type != lexer.TokenString {
+		return ""
+	}
+	s := p.tok.Text
+	p.next()
+	return s
+}
+
+// parseOidValue parses the "::= { ... }" value of a definition, registers the
+// name as a node and calls set with the OID once it is resolved (immediately
+// or after forward references are resolved). It returns the value as written.
+func (p *rdParser) parseOidValue(kind, name string, set func(oid []int)) (string, []int, error) {
+	if !p.accept(lexer.TokenColonColonEq) {
+		return "", nil, p.errorf("expected '::=' in %s", kind)
+	}
+	if !p.accept(lexer.TokenLBrace) {
+		return "", nil, p.errorf("expected '{' after %s '::='", kind)
+	}
+	parent, idx, abs, hasAbs := p.parseOidAssignmentInsideBraces()
+	if !p.accept(lexer.TokenRBrace) {
+		return "", nil, p.errorf("expected '}' after %s OID", kind)
+	}
+	record := func(oid []int) {
+		set(oid)
+		p.mod.NodesByName[name] = append([]int(nil), oid...)
+	}
+	if hasAbs {
+		record(append([]int(nil), abs...))
+	} else if base, ok := p.resolveOidBase(parent); ok {
+		record(append(append([]int(nil), base...), idx))
+	} else {
+		if _, exists := p.mod.NodesByName[name]; !exists {
+			p.mod.NodesByName[name] = []int{}
+		}
+		p.pend = append(p.pend, pendingRef{
+			parent: parent,
+			index:  idx,
+			apply: func(base []int) {
+				record(append(append([]int(nil), base...), idx))
+			},
+		})
+	}
+	parentName, subIDs := assignment(parent, idx, abs, hasAbs)
+	return parentName, subIDs, nil
+}
+
 func (p *rdParser) parseParentRef() (string, int) {
 	// Parent reference commonly looks like: parentName number
 	// But some MIBs have '( n )' wrappers or include module prefixes; keep minimal.
@@ -798,6 +1328,16 @@ func (p *rdParser) parseOidAssignmentInsideBraces() (string, int, []int, bool) {
 	return parent, idx, nil, false
 }
 
+// assignment returns the OID value of a definition as written: the symbolic
+// parent and the sub-identifiers following it, or only sub-identifiers for a
+// fully numeric value.
+func assignment(parent string, index int, abs []int, hasAbs bool) (string, []int) {
+	if hasAbs {
+		return "", append([]int(nil), abs...)
+	}
+	return parent, []int{index}
+}
+
 // resolveOidBase supports a small aliasing where object names already resolved
 // are considered nodes too.
 func (p *rdParser) resolveOidBase(name string) ([]int, bool) {
@@ -807,10 +1347,73 @@ func (p *rdParser) resolveOidBase(name string) ([]int, bool) {
 	return nil, false
 }
 
-// Gather tokens into a type string until we hit a known next clause keyword
+// parseTypeString gathers the tokens of a SYNTAX type into a string: the type
+// name (including the two-word OCTET STRING / OBJECT IDENTIFIER and
+// SEQUENCE OF forms) followed by any enumeration or constraint groups.
+// It stops at the end of the type so a TEXTUAL-CONVENTION's trailing SYNTAX
+// does not swallow the next definition.
 func (p *rdParser) parseTypeString() string {
-	// Gather tokens into a type string until we hit a known next clause keyword
-	return p.parseUntilKeywords("ACCESS", "MAX-ACCESS", "STATUS", "DESCRIPTION", "INDEX", "::=")
+	acc := []string{}
+	if p.tok.Type == lexer.TokenIdent {
+		switch {
+		case p.isIdent("OCTET"), p.isIdent("OBJECT"):
+			acc = append(acc, p.tok.Text)
+			p.next()
+			if p.tok.Type == lexer.TokenIdent {
+				acc = append(acc, p.tok.Text)
+				p.next()
+			}
+		case p.isIdent("SEQUENCE"):
+			acc = append(acc, p.tok.Text)
+			p.next()
+			if p.isIdent("OF") {
+				acc = append(acc, p.tok.Text)
+				p.next()
+				if p.tok.Type == lexer.TokenIdent {
+					acc = append(acc, p.tok.Text)
+					p.next()
+				}
+			}
+		default:
+			acc = append(acc, p.tok.Text)
+			p.next()
+		}
+	}
+	for p.tok.Type == lexer.TokenLBrace || p.tok.Type == lexer.TokenLParen {
+		acc = append(acc, p.parseBalanced()...)
+	}
+	return strings.Join(acc, " ")
+}
+
+// parseBalanced consumes a balanced "{ ... }" or "( ... )" group and returns
+// its tokens, including the outer delimiters, as text.
+func (p *rdParser) parseBalanced() []string {
+	var acc []string
+	depth := 0
+	for p.tok.Type != lexer.TokenEOF {
+		switch p.tok.Type {
+		case lexer.TokenLBrace, lexer.TokenLParen:
+			depth++
+		case lexer.TokenRBrace, lexer.TokenRParen:
+			depth--
+		}
+		acc = append(acc, tokenText(p.tok))
+		p.next()
+		if depth == 0 {
+			break
+		}
+	}
+	return acc
+}
+
+// parseBracedText consumes a balanced "{ ... }" group and returns its inner
+// tokens joined the same way as parseUntilKeywords.
+func (p *rdParser) parseBracedText() string {
+	acc := p.parseBalanced()
+	if len(acc) >= 2 {
+		acc = acc[1 : len(acc)-1]
+	}
+	return strings.Join(acc, " ")
 }
 
 func (p *rdParser) parseUntilKeywords(stop ...string) string {
@@ -833,20 +1436,21 @@ func (p *rdParser) parseUntilKeywords(stop ...string) string {
 		if acc != "" {
 			acc += " "
 		}
-		if p.tok.Type == lexer.TokenIdent {
-			acc += p.tok.Text
-		} else if p.tok.Type == lexer.TokenNumber {
-			acc += fmt.Sprintf("%d", p.tok.Int)
-		} else if p.tok.Type == lexer.TokenString {
-			acc += fmt.Sprintf("\"%s\"", p.tok.Text)
-		} else {
-			acc += p.tok.Text
-		}
+		acc += tokenText(p.tok)
 		p.next()
 	}
 	return trimSpace(acc)
 }
 
+func tokenText(tok lexer.Token) string {
+	switch tok.Type {
+	case lexer.TokenString:
+		return fmt.Sprintf("\"%s\"", tok.Text)
+	default:
+		return tok.Text
+	}
+}
+
 func (p *rdParser) initBaseOids() {
 	// Standard base OIDs used by many MIBs
 	// iso(1)
@@ -880,12 +1484,16 @@ func (p *rdParser) skipDefinition() {
 	if p.isIdent("MACRO") {
 		p.next()
 		if p.accept(lexer.TokenColonColonEq) && p.acceptIdent("BEGIN") {
-			// consume until we hit an END token belonging to the macro body
+			// consume until we hit an END token belonging to the macro body,
+			// keeping the identifiers it uses
 			for p.tok.Type != lexer.TokenEOF {
 				if p.isIdent("END") {
 					p.next()
 					return
 				}
+				if p.tok.Type == lexer.TokenIdent && !slices.Contains(p.mod.MacroReferences, p.tok.Text) {
+					p.mod.MacroReferences = append(p.mod.MacroReferences, p.tok.Text)
+				}
 				p.next()
 			}
 			return
@@ -912,6 +1520,87 @@ func (p *rdParser) skipDefinition() {
 	}
 }
 
+// keepText records the source text of the definition name, from where it
+// starts up to the current token, without the blank and comment lines that
+// precede the next definition.
+func (p *rdParser) keepText(name string) {
+	if p.lines == nil {
+		p.lines = strings.Split(p.src, "\n")
+	}
+	lines := p.lines
+	var out []string
+	for n := p.defPos.Line; n <= p.tok.Line && n <= len(lines); n++ {
+		line := []rune(strings.TrimSuffix(lines[n-1], "\r"))
+		start, end := 0, len(line)
+		if n == p.tok.Line && p.tok.Type != lexer.TokenEOF {
+			end = min(p.tok.Col-1, end)
+		}
+		if n == p.defPos.Line {
+			start = min(p.defPos.Col-1, end)
+		}
+		out = append(out, strings.TrimRight(string(line[start:end]), " \t"))
+	}
+	for len(out) > 1 {
+		last := strings.TrimSpace(out[len(out)-1])
+		if last != "" && !strings.HasPrefix(last, "--") {
+			break
+		}
+		out = out[:len(out)-1]
+	}
+	if p.mod.DefinitionText == nil {
+		p.mod.DefinitionText = map[string]string{}
+	}
+	p.mod.DefinitionText[name] = strings.Join(out, "\n")
+}
+
+// skipType consumes the body of a type assignment "<Name> ::= <type>". The
+// body has no terminator, so skipping stops before the next definition: an
+// identifier outside braces and parentheses followed by '::=' or MACRO, or a
+// descriptor followed by OBJECT IDENTIFIER or a macro such as OBJECT-TYPE.
+// It also stops at END.
+func (p *rdParser) skipType() {
+	depth := 0
+	for p.tok.Type != lexer.TokenEOF {
+		switch p.tok.Type {
+		case lexer.TokenLBrace, lexer.TokenLParen:
+			depth++
+		case lexer.TokenRBrace, lexer.TokenRParen:
+			if depth > 0 {
+				depth--
+			}
+		case lexer.TokenIdent:
+			if depth == 0 && (p.isIdent("END") || p.startsDefinition()) {
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// startsDefinition reports whether the current identifier begins a
+// definition, judging by the token after it.
+func (p *rdParser) startsDefinition() bool {
+	peek := p.l.Peek()
+	if peek.Type == lexer.TokenColonColonEq {
+		return true
+	}
+	if peek.Type != lexer.TokenIdent {
+		return false
+	}
+	if peek.Text == "MACRO" {
+		return true
+	}
+	if c := p.tok.Text[0]; c < 'a' || c > 'z' {
+		return false
+	}
+	switch peek.Text {
+	case "OBJECT", "OBJECT-TYPE", "OBJECT-IDENTITY", "MODULE-IDENTITY", "NOTIFICATION-TYPE", "TRAP-TYPE",
+		"OBJECT-GROUP", "NOTIFICATION-GROUP", "MODULE-COMPLIANCE", "AGENT-CAPABILITIES":
+		return true
+	}
+	return false
+}
+
 func (p *rdParser) next() { p.tok = p.l.Next() }
 func (p *rdParser) accept(t lexer.TokenType) bool {
 	if p.tok.Type == t {
@@ -1018,8 +1707,8 @@ func (p *rdParser) augmentFromSource() {
 			p.mod.NodesByName[name] = []int{}
 		}
 	}
-	// NOTIFICATION-TYPE names
-	reNotif := regexp.MustCompile(`(?m)^\s*([A-Za-z][A-Za-z0-9-]*)\s+NOTIFICATION-TYPE\b`)
+	// NOTIFICATION-TYPE and TRAP-TYPE names
+	reNotif := regexp.MustCompile(`(?m)^\s*([A-Za-z][A-Za-z0-9-]*)\s+(?:NOTIFICATION-TYPE|TRAP-TYPE)\b`)
 	for _, m := range reNotif.FindAllStringSubmatch(clean, -1) {
 		name := m[1]
 		if isReservedName(name) {
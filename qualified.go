@@ -0,0 +1,504 @@
+package mib_parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QualifiedName is an object reference in net-snmp notation, such as
+// "IF-MIB::ifDescr.2", "sysUpTime.0", `ifName."eth0"` or a numeric
+// ".1.3.6.1.2.1.1.3.0".
+type QualifiedName struct {
+	// Module is the module prefix before "::"; empty when not given.
+	Module string
+	// Name is the descriptor; empty for a purely numeric reference, whose
+	// arcs are then held in Index.
+	Name string
+	// Index is the instance suffix, one entry per component as written.
+	Index []IndexComponent
+}
+
+// IndexComponent is one component of an instance suffix. Exactly one of
+// Arcs, Quoted or Ref is set.
+type IndexComponent struct {
+	// Arcs holds a numeric component: ".3" gives [3] and a bracketed
+	// "[192.168.1.1]" gives four arcs.
+	Arcs []int
+	// Quoted reports a quoted string component; String holds its text.
+	Quoted bool
+	String string
+	// Implied reports single quotes, net-snmp's notation for an IMPLIED
+	// string index that carries no length prefix.
+	Implied bool
+	// Ref is a nested object reference in brackets, as used for OBJECT
+	// IDENTIFIER indexes, e.g. "[SNMPv2-MIB::snmpMIB]".
+	Ref *QualifiedName
+}
+
+// String formats the name back into net-snmp notation.
+func (q QualifiedName) String() string {
+	var b strings.Builder
+	if q.Module != "" {
+		b.WriteString(q.Module + "::")
+	}
+	b.WriteString(q.Name)
+	for _, c := range q.Index {
+		switch {
+		case c.Ref != nil:
+			b.WriteString("[" + c.Ref.String() + "]")
+		case c.Quoted:
+			quote := `"`
+			if c.Implied {
+				quote = "'"
+			}
+			b.WriteString("." + quote)
+			for _, r := range c.String {
+				if string(r) == quote || r == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteRune(r)
+			}
+			b.WriteString(quote)
+		case len(c.Arcs) == 1:
+			b.WriteString("." + strconv.Itoa(c.Arcs[0]))
+		default:
+			b.WriteString("[" + OID(c.Arcs).String() + "]")
+		}
+	}
+	return b.String()
+}
+
+// ParseQualifiedName parses an object reference. It only checks the
+// notation; Registry.ResolveQualifiedName gives it meaning.
+func ParseQualifiedName(s string) (QualifiedName, error) {
+	var q QualifiedName
+	rest := s
+	if module, after, ok := strings.Cut(s, "::"); ok && !strings.ContainsAny(module, `."'[`) {
+		if module == "" {
+			return q, fmt.Errorf("qualified name %q: empty module name", s)
+		}
+		q.Module, rest = module, after
+	}
+	if rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+		rest = "." + rest
+	}
+	if rest != "" && rest[0] != '.' {
+		end := 0
+		for end < len(rest) && isDescriptorChar(rest[end]) {
+			end++
+		}
+		q.Name, rest = rest[:end], rest[end:]
+		if q.Name == "" || !isLetter(q.Name[0]) {
+			return q, fmt.Errorf("qualified name %q: invalid descriptor", s)
+		}
+	}
+	for rest != "" {
+		var c IndexComponent
+		var err error
+		switch rest[0] {
+		case '.':
+			c, rest, err = parseDottedComponent(rest[1:])
+		case '[':
+			c, rest, err = parseBracketComponent(rest[1:])
+		default:
+			err = fmt.Errorf("unexpected %q", rest[0])
+		}
+		if err != nil {
+			return QualifiedName{}, fmt.Errorf("qualified name %q: %w", s, err)
+		}
+		q.Index = append(q.Index, c)
+	}
+	if q.Name == "" && len(q.Index) == 0 {
+		return q, fmt.Errorf("qualified name %q: empty", s)
+	}
+	return q, nil
+}
+
+func parseDottedComponent(s string) (IndexComponent, string, error) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		text, rest, err := parseQuoted(s)
+		return IndexComponent{Quoted: true, String: text, Implied: s[0] == '\''}, rest, err
+	}
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return IndexComponent{}, "", fmt.Errorf("expected a number after '.'")
+	}
+	return IndexComponent{Arcs: []int{n}}, s[end:], nil
+}
+
+func parseBracketComponent(s string) (IndexComponent, string, error) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		text, rest, err := parseQuoted(s)
+		if err != nil {
+			return IndexComponent{}, "", err
+		}
+		if !strings.HasPrefix(rest, "]") {
+			return IndexComponent{}, "", errors.New("missing ']'")
+		}
+		return IndexComponent{Quoted: true, String: text, Implied: s[0] == '\''}, rest[1:], nil
+	}
+	depth, end := 1, -1
+	for i := 0; i < len(s) && end < 0; i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				end = i
+			}
+		case '"', '\'':
+			_, rest, err := parseQuoted(s[i:])
+			if err != nil {
+				return IndexComponent{}, "", err
+			}
+			i = len(s) - len(rest) - 1
+		}
+	}
+	if end < 0 {
+		return IndexComponent{}, "", errors.New("missing ']'")
+	}
+	inner := s[:end]
+	if arcs, err := ParseOID(inner); err == nil {
+		return IndexComponent{Arcs: arcs}, s[end+1:], nil
+	}
+	ref, err := ParseQualifiedName(inner)
+	if err != nil {
+		return IndexComponent{}, "", err
+	}
+	return IndexComponent{Ref: &ref}, s[end+1:], nil
+}
+
+// parseQuoted reads a string in double or single quotes with backslash
+// escapes and returns its text and the input after the closing quote.
+func parseQuoted(s string) (string, string, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case quote:
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDescriptorChar(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-'
+}
+
+// ResolveName parses and resolves an object reference to its numeric OID.
+func (r *Registry) ResolveName(s string) (OID, error) {
+	q, err := ParseQualifiedName(s)
+	if err != nil {
+		return nil, err
+	}
+	return r.ResolveQualifiedName(q)
+}
+
+// ResolveQualifiedName returns the numeric OID of q. The instance suffix of
+// a column is encoded according to the SYNTAX of the row's INDEX objects
+// (RFC 2578 section 7.7): strings and OIDs get a length prefix unless they
+// have a fixed size or are IMPLIED, and IP addresses take four arcs.
+func (r *Registry) ResolveQualifiedName(q QualifiedName) (OID, error) {
+	if q.Name == "" {
+		var oid OID
+		for _, c := range q.Index {
+			if c.Arcs == nil {
+				return nil, errors.New("numeric OIDs cannot contain strings or references")
+			}
+			oid = append(oid, c.Arcs...)
+		}
+		return oid, nil
+	}
+	sym, ok := r.LookupSymbol(q.Module, q.Name)
+	if !ok {
+		return nil, fmt.Errorf("unknown object %s", qualify(q.Module, q.Name))
+	}
+	oid := sym.OID.Append()
+	if len(q.Index) == 0 {
+		return oid, nil
+	}
+	index := r.indexOf(sym)
+	if index == nil {
+		for _, c := range q.Index {
+			if c.Arcs == nil {
+				return nil, fmt.Errorf("%s is not a column, so its instance must be numeric", sym.Name)
+			}
+			oid = append(oid, c.Arcs...)
+		}
+		return oid, nil
+	}
+	comps := q.Index
+	for i, idx := range index.objects {
+		implied := index.implied && i == len(index.objects)-1
+		var arcs []int
+		var err error
+		arcs, comps, err = r.encodeIndex(idx, implied, comps)
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", idx.obj.Name, err)
+		}
+		oid = append(oid, arcs...)
+	}
+	if len(comps) > 0 {
+		return nil, errors.New("too many index components")
+	}
+	return oid, nil
+}
+
+// encodeIndex encodes the leading components for one INDEX object and
+// returns the remaining components.
+func (r *Registry) encodeIndex(idx indexObject, implied bool, comps []IndexComponent) ([]int, []IndexComponent, error) {
+	if len(comps) == 0 {
+		return nil, nil, errors.New("missing")
+	}
+	c := comps[0]
+	switch idx.syntax.Base {
+	case "IpAddress", "NetworkAddress":
+		if len(c.Arcs) == 4 {
+			return c.Arcs, comps[1:], nil
+		}
+		return takeNumbers(comps, 4)
+	case "OCTET STRING", "Opaque", "BITS":
+		size, fixed := idx.syntax.FixedSize()
+		if c.Quoted {
+			arcs := make([]int, 0, len(c.String)+1)
+			if fixed && len(c.String) != size {
+				return nil, nil, fmt.Errorf("%q is not %d octets long", c.String, size)
+			}
+			if !fixed && !implied {
+				arcs = append(arcs, len(c.String))
+			}
+			for i := 0; i < len(c.String); i++ {
+				arcs = append(arcs, int(c.String[i]))
+			}
+			return arcs, comps[1:], nil
+		}
+		return takeVariable(comps, fixed, size, implied)
+	case "OBJECT IDENTIFIER":
+		var value []int
+		switch {
+		case c.Ref != nil:
+			v, err := r.ResolveQualifiedName(*c.Ref)
+			if err != nil {
+				return nil, nil, err
+			}
+			value = v
+		case len(c.Arcs) > 1:
+			value = c.Arcs
+		default:
+			return takeVariable(comps, false, 0, implied)
+		}
+		if implied {
+			return value, comps[1:], nil
+		}
+		return append([]int{len(value)}, value...), comps[1:], nil
+	}
+	return takeNumbers(comps, 1)
+}
+
+// takeNumbers takes n single-arc numeric components.
+func takeNumbers(comps []IndexComponent, n int) ([]int, []IndexComponent, error) {
+	var arcs []int
+	for len(arcs) < n {
+		if len(comps) == 0 || len(comps[0].Arcs) != 1 {
+			return nil, nil, fmt.Errorf("expected %d numeric components", n)
+		}
+		arcs = append(arcs, comps[0].Arcs[0])
+		comps = comps[1:]
+	}
+	return arcs, comps, nil
+}
+
+// takeVariable takes a string or OID index written as raw numbers: a length
+// and that many arcs, a fixed number of arcs, or all remaining arcs when
+// IMPLIED.
+func takeVariable(comps []IndexComponent, fixed bool, size int, implied bool) ([]int, []IndexComponent, error) {
+	switch {
+	case fixed:
+		return takeNumbers(comps, size)
+	case implied:
+		return takeNumbers(comps, len(comps))
+	}
+	head, rest, err := takeNumbers(comps, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, rest, err := takeNumbers(rest, head[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(head, body...), rest, nil
+}
+
+// FormatOID returns the shortest unambiguous qualified name of oid: the
+// module prefix is omitted when the descriptor is unique, and the instance
+// suffix is rendered through the INDEX syntax, e.g. `ifName."eth0"`. OIDs
+// outside the known tree are returned in numeric form.
+func (r *Registry) FormatOID(oid OID) string {
+	return r.QualifiedNameOf(oid).String()
+}
+
+// QualifiedNameOf is FormatOID returning the structured name.
+func (r *Registry) QualifiedNameOf(oid OID) QualifiedName {
+	sym, rest, ok := r.SymbolAt(oid)
+	if !ok {
+		var q QualifiedName
+		for _, arc := range oid {
+			q.Index = append(q.Index, IndexComponent{Arcs: []int{arc}})
+		}
+		return q
+	}
+	q := QualifiedName{Name: sym.Name}
+	if !r.uniqueName(sym) {
+		q.Module = sym.Module
+	}
+	if len(rest) == 0 {
+		return q
+	}
+	if index := r.indexOf(sym); index != nil {
+		if comps, ok := r.decodeIndex(index, rest); ok {
+			q.Index = comps
+			return q
+		}
+	}
+	for _, arc := range rest {
+		q.Index = append(q.Index, IndexComponent{Arcs: []int{arc}})
+	}
+	return q
+}
+
+// uniqueName reports whether every symbol named like sym has sym's OID, so
+// that the module prefix can be left out.
+func (r *Registry) uniqueName(sym Symbol) bool {
+	for _, s := range r.Symbols() {
+		if s.Name == sym.Name && !s.OID.Equal(sym.OID) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Registry) decodeIndex(index *rowIndex, arcs []int) ([]IndexComponent, bool) {
+	parts, ok := splitIndex(index, arcs)
+	if !ok {
+		return nil, false
+	}
+	var comps []IndexComponent
+	for i, idx := range index.objects {
+		part := parts[i]
+		switch idx.syntax.Base {
+		case "OCTET STRING", "Opaque", "BITS":
+			implied := index.implied && i == len(index.objects)-1
+			if text, ok := printable(part.value); ok {
+				comps = append(comps, IndexComponent{Quoted: true, String: text, Implied: implied})
+				continue
+			}
+		case "OBJECT IDENTIFIER":
+			ref := r.QualifiedNameOf(part.value)
+			comps = append(comps, IndexComponent{Ref: &ref})
+			continue
+		}
+		for _, arc := range part.arcs {
+			comps = append(comps, IndexComponent{Arcs: []int{arc}})
+		}
+	}
+	return comps, true
+}
+
+// printable returns arcs as text when they are all printable ASCII.
+func printable(arcs []int) (string, bool) {
+	b := make([]byte, len(arcs))
+	for i, arc := range arcs {
+		if arc < 0x20 || arc > 0x7e {
+			return "", false
+		}
+		b[i] = byte(arc)
+	}
+	return string(b), true
+}
+
+// rowIndex is the INDEX of the row a column belongs to.
+type rowIndex struct {
+	objects []indexObject
+	implied bool
+}
+
+type indexObject struct {
+	module *Module
+	obj    *ObjectType
+	syntax ResolvedSyntax
+}
+
+// indexOf returns the INDEX that applies to instances of sym, following
+// AUGMENTS, or nil when sym is not a column.
+func (r *Registry) indexOf(sym Symbol) *rowIndex {
+	m, ok := r.modules[sym.Module]
+	if !ok {
+		return nil
+	}
+	col, ok := m.ObjectsByName[sym.Name]
+	if !ok {
+		return nil
+	}
+	row, ok := m.ObjectsByName[col.Assignment.Parent]
+	if !ok {
+		return nil
+	}
+	if row.Augments != "" {
+		rm, base, ok := r.object(m, row.Augments)
+		if !ok {
+			return nil
+		}
+		m, row = rm, base
+	}
+	if len(row.Index) == 0 {
+		return nil
+	}
+	index := &rowIndex{implied: row.Implied}
+	for _, name := range row.Index {
+		om, o, ok := r.object(m, name)
+		if !ok {
+			return nil
+		}
+		index.objects = append(index.objects, indexObject{om, o, r.ResolveSyntax(om, o.Syntax)})
+	}
+	return index
+}
+
+// object finds the OBJECT-TYPE name as seen from module m: defined there,
+// or anywhere in the registry.
+func (r *Registry) object(m *Module, name string) (*Module, *ObjectType, bool) {
+	if o, ok := m.ObjectsByName[name]; ok {
+		return m, o, true
+	}
+	if s, ok := r.LookupSymbol("", name); ok {
+		if om, ok := r.modules[s.Module]; ok && om.ObjectsByName[name] != nil {
+			return om, om.ObjectsByName[name], true
+		}
+	}
+	return nil, nil, false
+}
+
+func qualify(module, name string) string {
+	if module == "" {
+		return name
+	}
+	return module + "::" + name
+}
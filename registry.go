@@ -0,0 +1,279 @@
+package mib_parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Registry is a set of modules that can refer to each other through their
+// IMPORTS. OIDs that ParseMIB could not resolve because a parent node lives
+// in another module are resolved by Resolve once that module is added.
+type Registry struct {
+	modules map[string]*Module
+	// mu guards symbols, which Symbols fills in lazily, possibly from
+	// several goroutines at once.
+	mu sync.Mutex
+	// symbols caches Symbols until the next Add or Resolve.
+	symbols []Symbol
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{modules: map[string]*Module{}}
+}
+
+// Add adds a parsed module. Adding a second module with the same name is an
+// error. Call Resolve after adding modules to link OIDs across modules.
+func (r *Registry) Add(m *Module) error {
+	if _, ok := r.modules[m.Name]; ok {
+		return fmt.Errorf("registry: module %s already loaded", m.Name)
+	}
+	m.bind()
+	r.modules[m.Name] = m
+	r.clearSymbols()
+	return nil
+}
+
+// LoadFiles parses the given MIB files, adds them and resolves OIDs.
+func (r *Registry) LoadFiles(paths ...string) error {
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		m, err := ParseMIB(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := r.Add(m); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	r.Resolve()
+	return nil
+}
+
+// LoadDir loads every regular file in dir as a MIB module.
+func (r *Registry) LoadDir(dir string) error {
+	paths, err := mibFiles(dir)
+	if err != nil {
+		return err
+	}
+	return r.LoadFiles(paths...)
+}
+
+// Module returns the module with the given name.
+func (r *Registry) Module(name string) (*Module, bool) {
+	m, ok := r.modules[name]
+	return m, ok
+}
+
+// Modules returns all modules sorted by name.
+func (r *Registry) Modules() []*Module {
+	out := make([]*Module, 0, len(r.modules))
+	for _, name := range sortedKeys(r.modules) {
+		out = append(out, r.modules[name])
+	}
+	return out
+}
+
+// TextualConvention finds the textual convention name as seen from module m:
+// one defined in m or one m imports from a loaded module. It also returns
+// the module that defines the convention, against which its own SYNTAX must
+// be resolved.
+func (r *Registry) TextualConvention(m *Module, name string) (*TextualConvention, *Module, bool) {
+	if tc, ok := m.TextualConventions[name]; ok {
+		return tc, m, true
+	}
+	if r == nil {
+		return nil, nil, false
+	}
+	for _, imp := range m.Imports {
+		if !contains(imp.Symbols, name) {
+			continue
+		}
+		if owner, ok := r.modules[imp.Module]; ok {
+			if tc, ok := owner.TextualConventions[name]; ok {
+				return tc, owner, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// ResolvedSyntax is a SYNTAX followed through textual conventions down to
+// its SMI base type.
+type ResolvedSyntax struct {
+	// Base is the SMI base type, e.g. "OCTET STRING" or "Counter32". It is
+	// the last type name reached when a convention is not loaded.
+	Base string
+	// NamedNumbers, Ranges and Sizes come from the outermost refinement
+	// that declares them.
+	NamedNumbers []NamedNumber
+	Ranges       []Range
+	Sizes        []Range
+	// Conventions lists the textual conventions passed through, outermost
+	// first.
+	Conventions []*TextualConvention
+}
+
+// DisplayHint returns the DISPLAY-HINT of the outermost convention that
+// has one.
+func (s ResolvedSyntax) DisplayHint() string {
+	for _, tc := range s.Conventions {
+		if tc.DisplayHint != "" {
+			return tc.DisplayHint
+		}
+	}
+	return ""
+}
+
+// ValueType returns the type values of the syntax are sent as, e.g.
+// TypeGauge32 for Unsigned32 and TypeIpAddress for SMIv1 NetworkAddress.
+func (s ResolvedSyntax) ValueType() (ValueType, bool) {
+	t, ok := wireTypes[s.Base]
+	return t, ok
+}
+
+// FixedSize returns the length of a SIZE constraint that allows a single
+// value, as for MacAddress's SIZE (6).
+func (s ResolvedSyntax) FixedSize() (int, bool) {
+	if len(s.Sizes) == 1 && s.Sizes[0].Min == s.Sizes[0].Max {
+		return int(s.Sizes[0].Min), true
+	}
+	return 0, false
+}
+
+// ResolveSyntax resolves a SYNTAX clause of module m through the textual
+// conventions it refers to.
+func (r *Registry) ResolveSyntax(m *Module, syntax string) ResolvedSyntax {
+	var out ResolvedSyntax
+	syn := ParseSyntax(syntax)
+	for depth := 0; ; depth++ {
+		if out.NamedNumbers == nil {
+			out.NamedNumbers = syn.NamedNumbers
+		}
+		if out.Ranges == nil {
+			out.Ranges = syn.Ranges
+		}
+		if out.Sizes == nil {
+			out.Sizes = syn.Sizes
+		}
+		tc, owner, ok := r.TextualConvention(m, syn.Base)
+		if !ok || depth == 8 {
+			break
+		}
+		out.Conventions = append(out.Conventions, tc)
+		m, syn = owner, ParseSyntax(tc.Syntax)
+	}
+	out.Base = syn.Base
+	return out
+}
+
+// Resolve fills in the OIDs of definitions whose parent node is imported
+// from another module in the registry (or is one of the well-known
+// SNMPv2-SMI nodes). It is safe to call repeatedly, e.g. after adding
+// more modules.
+func (r *Registry) Resolve() {
+	r.clearSymbols()
+	nodes := map[string]map[string]oidNode{}
+	for name, m := range r.modules {
+		nodes[name] = m.oidNodes()
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, modName := range sortedKeys(r.modules) {
+			m := r.modules[modName]
+			for _, name := range sortedKeys(nodes[modName]) {
+				n := nodes[modName][name]
+				if len(*n.oid) > 0 || n.assignment.Parent == "" {
+					continue
+				}
+				base, ok := r.lookupNode(nodes, m, n.assignment.Parent)
+				if !ok {
+					continue
+				}
+				*n.oid = base.Append(n.assignment.SubIDs...)
+				changed = true
+			}
+		}
+	}
+}
+
+// lookupNode finds the OID of a node visible in module m: one defined in m,
+// one imported from a loaded module, or a well-known SNMPv2-SMI node.
+func (r *Registry) lookupNode(nodes map[string]map[string]oidNode, m *Module, name string) (OID, bool) {
+	if n, ok := nodes[m.Name][name]; ok {
+		return *n.oid, len(*n.oid) > 0
+	}
+	for _, imp := range m.Imports {
+		for _, s := range imp.Symbols {
+			if s != name {
+				continue
+			}
+			if n, ok := nodes[imp.Module][name]; ok && len(*n.oid) > 0 {
+				return *n.oid, true
+			}
+		}
+	}
+	oid, ok := wellKnownNodes[name]
+	return oid, ok
+}
+
+// oidNode points at the OID field of a definition so it can be filled in.
+type oidNode struct {
+	oid        *OID
+	assignment OIDAssignment
+}
+
+// oidNodes returns every OID-bearing definition of the module keyed by name.
+func (m *Module) oidNodes() map[string]oidNode {
+	out := map[string]oidNode{}
+	if mi := m.ModuleIdentity; mi != nil {
+		out[mi.Name] = oidNode{&mi.OID, mi.Assignment}
+	}
+	for name, n := range m.ObjectIdentifiers {
+		out[name] = oidNode{&n.OID, n.Assignment}
+	}
+	for name, o := range m.ObjectIdentities {
+		out[name] = oidNode{&o.OID, o.Assignment}
+	}
+	for name, o := range m.ObjectsByName {
+		out[name] = oidNode{&o.OID, o.Assignment}
+	}
+	for name, n := range m.NotificationTypes {
+		out[name] = oidNode{&n.OID, n.Assignment}
+	}
+	for name, g := range m.ObjectGroups {
+		out[name] = oidNode{&g.OID, g.Assignment}
+	}
+	for name, g := range m.NotificationGroups {
+		out[name] = oidNode{&g.OID, g.Assignment}
+	}
+	for name, mc := range m.ModuleCompliances {
+		out[name] = oidNode{&mc.OID, mc.Assignment}
+	}
+	for name, ac := range m.AgentCapabilities {
+		out[name] = oidNode{&ac.OID, ac.Assignment}
+	}
+	return out
+}
+
+// mibFiles lists the regular files of dir in name order.
+func mibFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
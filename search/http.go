@@ -0,0 +1,51 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ServeHTTP answers GET requests with the Results of a query as JSON. The
+// "q" parameter is read with ParseQuery; the "kind", "module", "syntax",
+// "access" and "status" parameters add filters and may be repeated or hold
+// comma-separated values; "limit" caps the hits (default 20).
+func (ix *Index) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+	q := ParseQuery(params.Get("q"))
+	values := func(name string) []string {
+		var out []string
+		for _, v := range params[name] {
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					out = append(out, s)
+				}
+			}
+		}
+		return out
+	}
+	q.Kinds = append(q.Kinds, values(FacetKind)...)
+	q.Modules = append(q.Modules, values(FacetModule)...)
+	q.Syntaxes = append(q.Syntaxes, values(FacetSyntax)...)
+	q.Access = append(q.Access, values(FacetAccess)...)
+	q.Statuses = append(q.Statuses, values(FacetStatus)...)
+	q.Limit = 20
+	if s := params.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		q.Limit = n
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(ix.Search(q))
+}
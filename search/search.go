@@ -0,0 +1,429 @@
+// Package search is a full-text and faceted search index over the
+// definitions of a registry. Names, descriptions, units, references and
+// enumeration labels are indexed, matches are ranked with BM25 and can be
+// filtered by kind, module, base syntax, access and status, so an object
+// can be found from what it counts rather than what it is called.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Field weights: a term in a definition's name counts as much as five in
+// its description.
+const (
+	weightName        = 5
+	weightLabel       = 2
+	weightUnits       = 2
+	weightSyntax      = 1
+	weightDescription = 1
+	weightReference   = 0.5
+)
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Facet names, used as keys of Results.Facets and in ParseQuery.
+const (
+	FacetKind   = "kind"
+	FacetModule = "module"
+	FacetSyntax = "syntax"
+	FacetAccess = "access"
+	FacetStatus = "status"
+)
+
+// Index is a search index over the definitions of a registry. It is not
+// updated when modules are added to the registry later. Its methods are
+// safe for concurrent use.
+type Index struct {
+	docs []*document
+	// df counts the documents each term occurs in.
+	df     map[string]int
+	avgLen float64
+}
+
+type document struct {
+	hit Hit
+	// syntaxes holds the base syntax and the textual conventions passed
+	// through, any of which the syntax filter matches.
+	syntaxes []string
+	terms    map[string]float64
+	length   float64
+}
+
+// Hit is one matching definition.
+type Hit struct {
+	Module string `json:"module"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	OID    string `json:"oid,omitempty"`
+	// Syntax is the SMI base type, e.g. "Counter32".
+	Syntax      string  `json:"syntax,omitempty"`
+	Access      string  `json:"access,omitempty"`
+	Status      string  `json:"status,omitempty"`
+	Units       string  `json:"units,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Score       float64 `json:"score"`
+}
+
+// Query is a search. Text is matched against the indexed fields; an empty
+// Text matches every definition. Each filter keeps definitions whose value
+// equals one of the listed values, ignoring case; an empty filter keeps
+// all.
+type Query struct {
+	Text string
+	// Kinds filters by macro, e.g. "OBJECT-TYPE". Spaces and hyphens are
+	// interchangeable, so "object-identifier" matches OBJECT IDENTIFIER.
+	Kinds   []string
+	Modules []string
+	// Syntaxes filters by base syntax or by a textual convention the
+	// syntax refines, e.g. "Counter32" or "DisplayString".
+	Syntaxes []string
+	Access   []string
+	Statuses []string
+	// Limit caps the number of hits returned; zero returns all.
+	Limit int
+}
+
+// Results are the hits of a query, best first, and the facet counts over
+// every match.
+type Results struct {
+	// Total is the number of matches before Limit.
+	Total int   `json:"total"`
+	Hits  []Hit `json:"hits"`
+	// Facets maps each facet name to the number of matches per value.
+	Facets map[string]map[string]int `json:"facets"`
+}
+
+// New indexes every object and textual convention of reg's modules.
+func New(reg *mib_parser.Registry) *Index {
+	ix := &Index{df: map[string]int{}}
+	for _, m := range reg.Modules() {
+		for _, obj := range m.Objects() {
+			ix.add(reg, m, obj)
+		}
+		names := make([]string, 0, len(m.TextualConventions))
+		for name := range m.TextualConventions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			tc := m.TextualConventions[name]
+			d := &document{hit: Hit{
+				Module: m.Name, Name: tc.Name, Kind: string(mib_parser.KindTextualConvention),
+				Status: mib_parser.ParseStatus(tc.Status).String(), Description: tc.Description,
+			}}
+			ix.addSyntax(reg, m, d, tc.Syntax)
+			d.addText(tc.Name, weightName)
+			d.addText(tc.Description, weightDescription)
+			d.addText(tc.Reference, weightReference)
+			ix.finish(d)
+		}
+	}
+	var total float64
+	for _, d := range ix.docs {
+		total += d.length
+	}
+	if len(ix.docs) > 0 {
+		ix.avgLen = total / float64(len(ix.docs))
+	}
+	return ix
+}
+
+func (ix *Index) add(reg *mib_parser.Registry, m *mib_parser.Module, obj mib_parser.Object) {
+	d := &document{hit: Hit{
+		Module: m.Name, Name: obj.ObjectName(), Kind: string(obj.ObjectKind()), OID: obj.OIDString(),
+		Status: obj.ObjectStatus().String(), Description: obj.ObjectDescription(),
+	}}
+	d.addText(obj.ObjectName(), weightName)
+	d.addText(obj.ObjectDescription(), weightDescription)
+	switch o := obj.(type) {
+	case *mib_parser.ObjectType:
+		d.hit.Access, d.hit.Units = o.Access, o.Units
+		ix.addSyntax(reg, m, d, o.Syntax)
+		d.addText(o.Units, weightUnits)
+		d.addText(o.Reference, weightReference)
+	case *mib_parser.ObjectIdentity:
+		d.addText(o.Reference, weightReference)
+	case *mib_parser.NotificationType:
+		d.addText(o.Reference, weightReference)
+	case *mib_parser.ObjectGroup:
+		d.addText(o.Reference, weightReference)
+	case *mib_parser.NotificationGroup:
+		d.addText(o.Reference, weightReference)
+	case *mib_parser.ModuleCompliance:
+		d.addText(o.Reference, weightReference)
+	case *mib_parser.AgentCapabilities:
+		d.addText(o.ProductRelease, weightLabel)
+		d.addText(o.Reference, weightReference)
+	}
+	ix.finish(d)
+}
+
+// addSyntax records the resolved base syntax of d and indexes its
+// enumeration labels.
+func (ix *Index) addSyntax(reg *mib_parser.Registry, m *mib_parser.Module, d *document, syntax string) {
+	if syntax == "" {
+		return
+	}
+	syn := reg.ResolveSyntax(m, syntax)
+	d.hit.Syntax = syn.Base
+	d.syntaxes = append(d.syntaxes, syn.Base)
+	for _, tc := range syn.Conventions {
+		d.syntaxes = append(d.syntaxes, tc.Name)
+	}
+	d.addText(syn.Base, weightSyntax)
+	for _, nn := range syn.NamedNumbers {
+		d.addText(nn.Name, weightLabel)
+	}
+}
+
+func (d *document) addText(s string, weight float64) {
+	for _, t := range terms(s) {
+		if d.terms == nil {
+			d.terms = map[string]float64{}
+		}
+		d.terms[t] += weight
+		d.length += weight
+	}
+}
+
+func (ix *Index) finish(d *document) {
+	for t := range d.terms {
+		ix.df[t]++
+	}
+	ix.docs = append(ix.docs, d)
+}
+
+// Search runs q against the index.
+func (ix *Index) Search(q Query) Results {
+	res := Results{Hits: []Hit{}, Facets: map[string]map[string]int{
+		FacetKind: {}, FacetModule: {}, FacetSyntax: {}, FacetAccess: {}, FacetStatus: {},
+	}}
+	query := uniqueTerms(q.Text)
+	for _, d := range ix.docs {
+		if !q.keeps(d) {
+			continue
+		}
+		score, matched := ix.score(d, query)
+		if len(query) > 0 && matched == 0 {
+			continue
+		}
+		hit := d.hit
+		if len(query) > 0 {
+			// Favour definitions that match more of the query.
+			hit.Score = score * float64(matched) / float64(len(query))
+		}
+		res.Hits = append(res.Hits, hit)
+		for facet, value := range map[string]string{
+			FacetKind: hit.Kind, FacetModule: hit.Module, FacetSyntax: hit.Syntax, FacetAccess: hit.Access, FacetStatus: hit.Status,
+		} {
+			if value != "" {
+				res.Facets[facet][value]++
+			}
+		}
+	}
+	sort.SliceStable(res.Hits, func(i, j int) bool {
+		a, b := res.Hits[i], res.Hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		return a.Name < b.Name
+	})
+	res.Total = len(res.Hits)
+	if q.Limit > 0 && len(res.Hits) > q.Limit {
+		res.Hits = res.Hits[:q.Limit]
+	}
+	return res
+}
+
+// score returns the BM25 score of d and how many query terms it contains.
+func (ix *Index) score(d *document, query []string) (float64, int) {
+	var score float64
+	matched := 0
+	n := float64(len(ix.docs))
+	for _, t := range query {
+		tf := d.terms[t]
+		if tf == 0 {
+			continue
+		}
+		matched++
+		df := float64(ix.df[t])
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*d.length/ix.avgLen))
+	}
+	return score, matched
+}
+
+func (q Query) keeps(d *document) bool {
+	return matchAny(q.Kinds, []string{d.hit.Kind}) &&
+		matchAny(q.Modules, []string{d.hit.Module}) &&
+		matchAny(q.Syntaxes, d.syntaxes) &&
+		matchAny(q.Access, []string{d.hit.Access}) &&
+		matchAny(q.Statuses, []string{d.hit.Status})
+}
+
+func matchAny(filter, values []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		for _, v := range values {
+			if normalize(f) == normalize(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", "-"))
+}
+
+// ParseQuery splits s into query text and "facet:value" filters, e.g.
+// "discarded packets kind:object-type module:IF-MIB". A facet may be given
+// more than once.
+func ParseQuery(s string) Query {
+	var q Query
+	var text []string
+	for _, word := range strings.Fields(s) {
+		facet, value, ok := strings.Cut(word, ":")
+		if !ok || value == "" {
+			text = append(text, word)
+			continue
+		}
+		switch strings.ToLower(facet) {
+		case FacetKind:
+			q.Kinds = append(q.Kinds, value)
+		case FacetModule:
+			q.Modules = append(q.Modules, value)
+		case FacetSyntax:
+			q.Syntaxes = append(q.Syntaxes, value)
+		case FacetAccess:
+			q.Access = append(q.Access, value)
+		case FacetStatus:
+			q.Statuses = append(q.Statuses, value)
+		default:
+			text = append(text, word)
+		}
+	}
+	q.Text = strings.Join(text, " ")
+	return q
+}
+
+// uniqueTerms returns the distinct terms of s in order.
+func uniqueTerms(s string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range terms(s) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// terms splits s into stemmed, lower-case terms. Identifiers are split at
+// case and digit boundaries and also kept whole and as adjacent pairs, so
+// "sysUpTime" yields "sysuptime", "sysup", "uptim", "sys", "up" and "tim".
+func terms(s string) []string {
+	var out []string
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	}) {
+		word = strings.Trim(word, "-")
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			out = append(out, stem(strings.ToLower(strings.ReplaceAll(word, "-", ""))))
+		}
+		for i := 1; len(parts) > 2 && i < len(parts); i++ {
+			out = append(out, stem(strings.ToLower(parts[i-1]+parts[i])))
+		}
+		for _, p := range parts {
+			if p = strings.ToLower(p); !stopWords[p] {
+				out = append(out, stem(p))
+			}
+		}
+	}
+	return out
+}
+
+// splitIdentifier splits at hyphens, lower-to-upper case changes, before
+// the last capital of an acronym ("IPAddress") and between letters and
+// digits.
+func splitIdentifier(word string) []string {
+	var parts []string
+	rs := []rune(word)
+	start := 0
+	for i := 1; i <= len(rs); i++ {
+		if i < len(rs) && rs[i] != '-' && rs[i-1] != '-' && !boundary(rs, i) {
+			continue
+		}
+		if part := strings.Trim(string(rs[start:i]), "-"); part != "" {
+			parts = append(parts, part)
+		}
+		start = i
+	}
+	return parts
+}
+
+func boundary(rs []rune, i int) bool {
+	prev, cur := rs[i-1], rs[i]
+	switch {
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(cur):
+		return i+1 < len(rs) && unicode.IsLower(rs[i+1])
+	}
+	return unicode.IsDigit(prev) != unicode.IsDigit(cur)
+}
+
+// stem strips common English inflections so that "discarded", "discards"
+// and "discard" share a term.
+func stem(w string) string {
+	if len(w) <= 3 || strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies"):
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+	switch {
+	case len(w) > 5 && strings.HasSuffix(w, "ing"):
+		w = w[:len(w)-3]
+	case len(w) > 4 && strings.HasSuffix(w, "ed"):
+		w = w[:len(w)-2]
+	}
+	if n := len(w); n > 3 && w[n-1] == w[n-2] && !strings.ContainsRune("lsz", rune(w[n-1])) {
+		w = w[:n-1]
+	}
+	if len(w) > 4 && strings.HasSuffix(w, "e") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "has": true, "have": true, "is": true, "it": true, "its": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "were": true, "which": true, "with": true,
+}
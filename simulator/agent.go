@@ -0,0 +1,479 @@
+// Package simulator is an SNMP agent for integration tests. It serves GET,
+// GETNEXT, GETBULK and SET requests of SNMPv1 and SNMPv2c over UDP from
+// recorded dumps or generator functions, and uses the parsed MIB modules
+// to enforce MAX-ACCESS, SYNTAX constraints and RowStatus semantics the
+// way a device would.
+package simulator
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"sync"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/ber"
+	"github.com/Olian04/go-mib-parser/walk"
+)
+
+// maxMessageSize is the largest response the agent sends, the most a UDP
+// datagram carries.
+const maxMessageSize = 65507
+
+// RowStatus values of RFC 2579.
+const (
+	rowActive        = 1
+	rowNotInService  = 2
+	rowNotReady      = 3
+	rowCreateAndGo   = 4
+	rowCreateAndWait = 5
+	rowDestroy       = 6
+)
+
+// Agent is a simulated SNMP agent. Its methods are safe for concurrent
+// use; generator functions are called with the agent locked and must not
+// call back into it.
+type Agent struct {
+	// Community is the only community string the agent answers. Requests
+	// with another one are dropped, as a real agent does. An empty
+	// Community accepts any.
+	Community string
+
+	reg     *mib_parser.Registry
+	mu      sync.Mutex
+	oids    []mib_parser.OID
+	entries map[string]*entry
+}
+
+type entry struct {
+	value mib_parser.Value
+	gen   func() mib_parser.Value
+}
+
+func (e *entry) get() mib_parser.Value {
+	if e.gen != nil {
+		return e.gen()
+	}
+	return e.value
+}
+
+// New returns an agent without data that checks requests against the
+// modules of reg.
+func New(reg *mib_parser.Registry) *Agent {
+	return &Agent{reg: reg, entries: map[string]*entry{}}
+}
+
+// Load adds the varbinds of a dump, e.g. as returned by walk.Parse.
+// Exception values such as noSuchInstance are skipped.
+func (a *Agent) Load(vbs []mib_parser.VarBind) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, vb := range vbs {
+		switch vb.Value.Type() {
+		case mib_parser.TypeNoSuchObject, mib_parser.TypeNoSuchInstance, mib_parser.TypeEndOfMibView:
+			continue
+		}
+		a.put(vb.OID, &entry{value: vb.Value})
+	}
+}
+
+// LoadDump reads an `snmpwalk -On` or snmprec dump and loads it.
+func (a *Agent) LoadDump(r io.Reader) error {
+	vbs, err := walk.Parse(r)
+	if err != nil {
+		return err
+	}
+	a.Load(vbs)
+	return nil
+}
+
+// Put stores v at the instance oid, bypassing the checks applied to SET
+// requests.
+func (a *Agent) Put(oid mib_parser.OID, v mib_parser.Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.put(oid, &entry{value: v})
+}
+
+// Func serves the instance oid from f, which is called on every request
+// that reads it, e.g. to make sysUpTime.0 advance. A SET of the instance
+// replaces f with the value set.
+func (a *Agent) Func(oid mib_parser.OID, f func() mib_parser.Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.put(oid, &entry{gen: f})
+}
+
+// Get returns the current value of the instance oid.
+func (a *Agent) Get(oid mib_parser.OID) (mib_parser.Value, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	e, ok := a.entries[oid.String()]
+	if !ok {
+		return nil, false
+	}
+	return e.get(), true
+}
+
+// ListenAndServe listens on the UDP address addr, e.g. "127.0.0.1:1161",
+// and serves requests until an error occurs.
+func (a *Agent) ListenAndServe(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return a.Serve(conn)
+}
+
+// Serve answers the requests arriving on conn. It returns nil once conn
+// is closed. Malformed requests and requests for another community are
+// dropped.
+func (a *Agent) Serve(conn net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resp := a.handle(buf[:n]); resp != nil {
+			if _, err := conn.WriteTo(resp, addr); errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+		}
+	}
+}
+
+// handle returns the encoded response to the request req, or nil when
+// req is to be dropped.
+func (a *Agent) handle(req []byte) []byte {
+	msg, err := ber.Unmarshal(req)
+	if err != nil {
+		return nil
+	}
+	if a.Community != "" && msg.Community != a.Community {
+		return nil
+	}
+	v1 := msg.Version == ber.Version1
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var vbs []mib_parser.VarBind
+	status, index := ber.NoError, 0
+	switch msg.PDU.Type {
+	case ber.GetRequest:
+		vbs = a.getRequest(msg.PDU.VarBinds)
+	case ber.GetNextRequest:
+		vbs = a.getNextRequest(msg.PDU.VarBinds, v1)
+	case ber.GetBulkRequest:
+		vbs = a.getBulkRequest(msg.PDU.VarBinds, msg.PDU.NonRepeaters, msg.PDU.MaxRepetitions, len(msg.Community))
+	case ber.SetRequest:
+		status, index = a.setRequest(msg.PDU.VarBinds)
+		vbs = msg.PDU.VarBinds
+	default:
+		return nil
+	}
+	if v1 {
+		status, index = v1Error(vbs, status, index)
+	}
+	if status != ber.NoError {
+		vbs = msg.PDU.VarBinds
+	}
+	resp := &ber.Message{
+		Version:   msg.Version,
+		Community: msg.Community,
+		PDU: ber.PDU{
+			Type:        ber.Response,
+			RequestID:   msg.PDU.RequestID,
+			ErrorStatus: status,
+			ErrorIndex:  index,
+			VarBinds:    vbs,
+		},
+	}
+	out, err := ber.Marshal(resp, ber.Options{})
+	if err != nil {
+		resp.PDU.ErrorStatus, resp.PDU.ErrorIndex, resp.PDU.VarBinds = ber.GenErr, 0, msg.PDU.VarBinds
+		out, _ = ber.Marshal(resp, ber.Options{})
+	}
+	if len(out) > maxMessageSize {
+		resp.PDU.ErrorStatus, resp.PDU.ErrorIndex, resp.PDU.VarBinds = ber.TooBig, 0, nil
+		if v1 {
+			resp.PDU.VarBinds = msg.PDU.VarBinds
+		}
+		out, _ = ber.Marshal(resp, ber.Options{})
+	}
+	return out
+}
+
+// v1Error turns the outcome of a request into its SNMPv1 form (RFC 3584
+// section 4): exceptions and Counter64 values, which SNMPv1 cannot carry,
+// become noSuchName, and SNMPv2 error-status values are mapped onto the
+// SNMPv1 ones.
+func v1Error(vbs []mib_parser.VarBind, status ber.ErrorStatus, index int) (ber.ErrorStatus, int) {
+	switch status {
+	case ber.NoError:
+		for i, vb := range vbs {
+			switch vb.Value.Type() {
+			case mib_parser.TypeNoSuchObject, mib_parser.TypeNoSuchInstance,
+				mib_parser.TypeEndOfMibView, mib_parser.TypeCounter64:
+				return ber.NoSuchName, i + 1
+			}
+		}
+		return ber.NoError, 0
+	case ber.WrongValue, ber.WrongEncoding, ber.WrongType, ber.WrongLength, ber.InconsistentValue:
+		return ber.BadValue, index
+	case ber.NoAccess, ber.NotWritable, ber.NoCreation, ber.InconsistentName, ber.AuthorizationError:
+		return ber.NoSuchName, index
+	case ber.TooBig, ber.NoSuchName, ber.BadValue, ber.ReadOnly:
+		return status, index
+	}
+	return ber.GenErr, index
+}
+
+func (a *Agent) getRequest(req []mib_parser.VarBind) []mib_parser.VarBind {
+	vbs := make([]mib_parser.VarBind, len(req))
+	for i, vb := range req {
+		vbs[i] = mib_parser.VarBind{OID: vb.OID, Value: a.get(vb.OID)}
+	}
+	return vbs
+}
+
+func (a *Agent) getNextRequest(req []mib_parser.VarBind, v1 bool) []mib_parser.VarBind {
+	vbs := make([]mib_parser.VarBind, len(req))
+	for i, vb := range req {
+		vbs[i] = a.next(vb.OID, v1)
+	}
+	return vbs
+}
+
+// getBulkRequest answers a GetBulkRequest (RFC 3416 section 4.2.3),
+// leaving out repetitions that would not fit into a response.
+func (a *Agent) getBulkRequest(req []mib_parser.VarBind, nonRepeaters, maxRepetitions, overhead int) []mib_parser.VarBind {
+	nonRepeaters = min(max(nonRepeaters, 0), len(req))
+	var vbs []mib_parser.VarBind
+	size := overhead + 64
+	add := func(vb mib_parser.VarBind) bool {
+		enc, err := ber.MarshalVarBind(vb)
+		if err != nil || size+len(enc) > maxMessageSize {
+			return false
+		}
+		size += len(enc)
+		vbs = append(vbs, vb)
+		return true
+	}
+	for _, vb := range req[:nonRepeaters] {
+		add(a.next(vb.OID, false))
+	}
+	cursors := make([]mib_parser.OID, 0, len(req)-nonRepeaters)
+	for _, vb := range req[nonRepeaters:] {
+		cursors = append(cursors, vb.OID)
+	}
+	for rep := 0; rep < maxRepetitions && len(cursors) > 0; rep++ {
+		done := true
+		for i, oid := range cursors {
+			vb := a.next(oid, false)
+			if vb.Value.Type() != mib_parser.TypeEndOfMibView {
+				done = false
+			}
+			if !add(vb) {
+				return vbs
+			}
+			cursors[i] = vb.OID
+		}
+		if done {
+			break
+		}
+	}
+	return vbs
+}
+
+// get returns the value of the instance oid, or the exception a GET of it
+// yields.
+func (a *Agent) get(oid mib_parser.OID) mib_parser.Value {
+	if e, ok := a.entries[oid.String()]; ok && a.readable(oid) {
+		return e.get()
+	}
+	if in, ok := a.reg.Instance(oid); ok && in.Object != nil && readable(in.Object.Access) {
+		return mib_parser.NewValue(mib_parser.TypeNoSuchInstance, nil)
+	}
+	return mib_parser.NewValue(mib_parser.TypeNoSuchObject, nil)
+}
+
+// next returns the first readable instance after oid in lexicographic
+// order. SNMPv1 requests skip Counter64 values.
+func (a *Agent) next(oid mib_parser.OID, v1 bool) mib_parser.VarBind {
+	i := sort.Search(len(a.oids), func(i int) bool { return a.oids[i].Compare(oid) > 0 })
+	for ; i < len(a.oids); i++ {
+		next := a.oids[i]
+		if !a.readable(next) {
+			continue
+		}
+		v := a.entries[next.String()].get()
+		if v1 && v.Type() == mib_parser.TypeCounter64 {
+			continue
+		}
+		return mib_parser.VarBind{OID: next, Value: v}
+	}
+	return mib_parser.VarBind{OID: oid, Value: mib_parser.NewValue(mib_parser.TypeEndOfMibView, nil)}
+}
+
+// readable reports whether the instance oid may be read. Instances of
+// objects the registry does not know are served as recorded.
+func (a *Agent) readable(oid mib_parser.OID) bool {
+	in, ok := a.reg.Instance(oid)
+	if !ok || in.Object == nil {
+		return true
+	}
+	return readable(in.Object.Access)
+}
+
+func readable(access string) bool {
+	switch access {
+	case "read-only", "read-write", "read-create":
+		return true
+	}
+	return false
+}
+
+// setRequest applies a SetRequest as a whole or not at all (RFC 3416
+// section 4.2.5) and returns its error-status and error-index.
+func (a *Agent) setRequest(req []mib_parser.VarBind) (ber.ErrorStatus, int) {
+	type change struct {
+		oid     mib_parser.OID
+		value   mib_parser.Value
+		entry   mib_parser.OID
+		destroy bool
+	}
+	instances := make([]*mib_parser.Instance, len(req))
+	creating := map[string]bool{}
+	for i, vb := range req {
+		in, ok := a.reg.Instance(vb.OID)
+		if !ok || in.Object == nil {
+			if _, ok := a.entries[vb.OID.String()]; ok {
+				return ber.NotWritable, i + 1
+			}
+			return ber.NoCreation, i + 1
+		}
+		instances[i] = in
+		if n, ok := a.rowStatus(in, vb.Value); ok && (n == rowCreateAndGo || n == rowCreateAndWait) {
+			creating[rowKey(in)] = true
+		}
+	}
+	var changes []change
+	for i, vb := range req {
+		in := instances[i]
+		if err := a.reg.ValidateSet(in.Module, in.Object, vb.Value); err != nil {
+			var se *mib_parser.SetError
+			if errors.As(err, &se) {
+				if status, err := ber.ParseErrorStatus(se.Status); err == nil {
+					return status, i + 1
+				}
+			}
+			return ber.GenErr, i + 1
+		}
+		_, exists := a.entries[vb.OID.String()]
+		if in.Row == nil {
+			if !exists {
+				return ber.NoCreation, i + 1
+			}
+			changes = append(changes, change{oid: vb.OID, value: vb.Value})
+			continue
+		}
+		entryOID := in.Object.OID[:len(in.Object.OID)-1]
+		rowExists := a.rowExists(entryOID, in.Suffix)
+		if n, ok := a.rowStatus(in, vb.Value); ok {
+			switch {
+			case n == rowNotReady:
+				return ber.WrongValue, i + 1
+			case (n == rowCreateAndGo || n == rowCreateAndWait) && rowExists,
+				(n == rowActive || n == rowNotInService) && !rowExists:
+				return ber.InconsistentValue, i + 1
+			case n == rowCreateAndGo:
+				vb.Value = mib_parser.NewValue(mib_parser.TypeInteger, int64(rowActive))
+			case n == rowCreateAndWait:
+				vb.Value = mib_parser.NewValue(mib_parser.TypeInteger, int64(rowNotInService))
+			case n == rowDestroy:
+				changes = append(changes, change{oid: vb.OID, entry: entryOID, destroy: true})
+				continue
+			}
+		} else if !exists && (in.Object.Access != "read-create" || !rowExists && !creating[rowKey(in)]) {
+			return ber.NoCreation, i + 1
+		}
+		changes = append(changes, change{oid: vb.OID, value: vb.Value})
+	}
+	for _, c := range changes {
+		if c.destroy {
+			a.destroyRow(c.entry, c.oid[len(c.entry)+1:])
+			continue
+		}
+		a.put(c.oid, &entry{value: c.value})
+	}
+	return ber.NoError, 0
+}
+
+// rowStatus returns the value v sets when in is a RowStatus column.
+func (a *Agent) rowStatus(in *mib_parser.Instance, v mib_parser.Value) (int64, bool) {
+	if in.Row == nil || v.Type() != mib_parser.TypeInteger {
+		return 0, false
+	}
+	for _, tc := range a.reg.ResolveSyntax(in.Module, in.Object.Syntax).Conventions {
+		if tc.Name == "RowStatus" {
+			n, ok := v.Data().(int64)
+			return n, ok
+		}
+	}
+	return 0, false
+}
+
+// rowKey identifies the conceptual row of the column instance in.
+func rowKey(in *mib_parser.Instance) string {
+	return in.Module.Name + "::" + in.Row.Name + "." + in.Suffix.String()
+}
+
+// rowExists reports whether any column of the row with the given entry
+// OID and instance suffix has a value.
+func (a *Agent) rowExists(entryOID, suffix mib_parser.OID) bool {
+	return len(a.rowInstances(entryOID, suffix)) > 0
+}
+
+func (a *Agent) destroyRow(entryOID, suffix mib_parser.OID) {
+	for _, oid := range a.rowInstances(entryOID, suffix) {
+		a.remove(oid)
+	}
+}
+
+func (a *Agent) rowInstances(entryOID, suffix mib_parser.OID) []mib_parser.OID {
+	var out []mib_parser.OID
+	i := sort.Search(len(a.oids), func(i int) bool { return a.oids[i].Compare(entryOID) >= 0 })
+	for ; i < len(a.oids) && a.oids[i].HasPrefix(entryOID); i++ {
+		oid := a.oids[i]
+		if len(oid) == len(entryOID)+1+len(suffix) && oid[len(entryOID)+1:].Equal(suffix) {
+			out = append(out, oid)
+		}
+	}
+	return out
+}
+
+func (a *Agent) put(oid mib_parser.OID, e *entry) {
+	key := oid.String()
+	if _, ok := a.entries[key]; !ok {
+		i := sort.Search(len(a.oids), func(i int) bool { return a.oids[i].Compare(oid) >= 0 })
+		a.oids = append(a.oids, nil)
+		copy(a.oids[i+1:], a.oids[i:])
+		a.oids[i] = oid.Append()
+	}
+	a.entries[key] = e
+}
+
+func (a *Agent) remove(oid mib_parser.OID) {
+	key := oid.String()
+	if _, ok := a.entries[key]; !ok {
+		return
+	}
+	delete(a.entries, key)
+	i := sort.Search(len(a.oids), func(i int) bool { return a.oids[i].Compare(oid) >= 0 })
+	a.oids = append(a.oids[:i], a.oids[i+1:]...)
+}
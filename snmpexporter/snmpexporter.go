@@ -0,0 +1,387 @@
+// Package snmpexporter derives Prometheus snmp_exporter module
+// configuration (the snmp.yml that snmp_exporter's generator produces) from
+// parsed MIB modules.
+package snmpexporter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Options describes one exporter module, like a module entry of
+// snmp_exporter's generator.yml.
+type Options struct {
+	// Walk lists the subtrees to export, as descriptors (e.g., "ifXTable")
+	// or dotted OIDs. Scalars are fetched with GET instead of walked.
+	Walk []string
+	// Lookups replace or extend index labels with the value of another
+	// object sharing those indexes.
+	Lookups []Lookup
+}
+
+// Lookup adds the value of Lookup, indexed by SourceIndexes, as a label to
+// every metric with those indexes.
+type Lookup struct {
+	// SourceIndexes are the index objects the lookup object is indexed by.
+	SourceIndexes []string
+	// Lookup is the object whose value becomes the label.
+	Lookup string
+	// DropSourceIndexes removes the source index labels afterwards.
+	DropSourceIndexes bool
+}
+
+// Module is the generated configuration of one exporter module.
+type Module struct {
+	Walk    []string
+	Get     []string
+	Metrics []*Metric
+}
+
+// Metric is one exported object.
+type Metric struct {
+	Name       string
+	OID        string
+	Type       string
+	Help       string
+	Indexes    []*Index
+	Lookups    []*MetricLookup
+	EnumValues map[int64]string
+}
+
+// Index describes how one INDEX component is turned into a label.
+type Index struct {
+	Labelname string
+	Type      string
+	// FixedSize is the length of a fixed-size OCTET STRING index.
+	FixedSize int
+	Implied   bool
+}
+
+// MetricLookup is a lookup as snmp_exporter applies it to a metric. A
+// lookup without OID drops the label.
+type MetricLookup struct {
+	Labels    []string
+	Labelname string
+	OID       string
+	Type      string
+}
+
+// Generate builds the exporter module for opts from the modules in reg.
+func Generate(reg *mib_parser.Registry, opts Options) (*Module, error) {
+	g := &generator{reg: reg}
+	g.index()
+	out := &Module{}
+	var roots []mib_parser.OID
+	for _, w := range opts.Walk {
+		oid, ok := g.node(w)
+		if !ok {
+			return nil, fmt.Errorf("snmpexporter: unknown walk root %q", w)
+		}
+		if o, ok := g.objectAt(oid); ok && g.isScalar(o) {
+			out.Get = append(out.Get, oid.String()+".0")
+		} else {
+			out.Walk = append(out.Walk, oid.String())
+		}
+		roots = append(roots, oid)
+	}
+
+	for _, o := range g.objects {
+		if !under(o.obj.OID, roots) || !accessible(o.obj.Access) {
+			continue
+		}
+		if !g.isScalar(o) && g.rowOf(o) == nil {
+			continue
+		}
+		t, enums := g.metricType(o)
+		if t == "" {
+			continue
+		}
+		m := &Metric{
+			Name:       metricName(o.obj.Name),
+			OID:        o.obj.OID.String(),
+			Type:       t,
+			Help:       help(o.obj),
+			EnumValues: enums,
+		}
+		if !g.isScalar(o) {
+			if err := g.addIndexes(m, o, opts, out); err != nil {
+				return nil, err
+			}
+		}
+		out.Metrics = append(out.Metrics, m)
+	}
+	return out, nil
+}
+
+// object is an OBJECT-TYPE together with its defining module.
+type object struct {
+	obj *mib_parser.ObjectType
+	mod *mib_parser.Module
+}
+
+type generator struct {
+	reg     *mib_parser.Registry
+	objects []object
+	byName  map[string]object
+}
+
+func (g *generator) index() {
+	g.byName = map[string]object{}
+	for _, m := range g.reg.Modules() {
+		for _, name := range sortedKeys(m.ObjectsByName) {
+			o := object{m.ObjectsByName[name], m}
+			if len(o.obj.OID) == 0 {
+				continue
+			}
+			g.objects = append(g.objects, o)
+			g.byName[name] = o
+		}
+	}
+	sort.SliceStable(g.objects, func(i, j int) bool {
+		return g.objects[i].obj.OID.Compare(g.objects[j].obj.OID) < 0
+	})
+}
+
+// node resolves a descriptor or dotted OID.
+func (g *generator) node(s string) (mib_parser.OID, bool) {
+	if oid, err := mib_parser.ParseOID(s); err == nil {
+		return oid, true
+	}
+	if o, ok := g.byName[s]; ok {
+		return o.obj.OID, true
+	}
+	for _, m := range g.reg.Modules() {
+		if n, ok := m.ObjectIdentifiers[s]; ok && len(n.OID) > 0 {
+			return n.OID, true
+		}
+		if n, ok := m.ObjectIdentities[s]; ok && len(n.OID) > 0 {
+			return n.OID, true
+		}
+		if mi := m.ModuleIdentity; mi != nil && mi.Name == s && len(mi.OID) > 0 {
+			return mi.OID, true
+		}
+	}
+	return nil, false
+}
+
+func (g *generator) objectAt(oid mib_parser.OID) (object, bool) {
+	for _, o := range g.objects {
+		if o.obj.OID.Equal(oid) {
+			return o, true
+		}
+	}
+	return object{}, false
+}
+
+// rowOf returns the conceptual row a column belongs to.
+func (g *generator) rowOf(o object) *mib_parser.ObjectType {
+	row, ok := o.mod.ObjectsByName[o.obj.Assignment.Parent]
+	if !ok || (len(row.Index) == 0 && row.Augments == "") {
+		return nil
+	}
+	return row
+}
+
+// isScalar reports whether o is neither a table, a row nor a column.
+func (g *generator) isScalar(o object) bool {
+	if len(o.obj.Index) > 0 || o.obj.Augments != "" || strings.HasPrefix(o.obj.Syntax, "SEQUENCE") {
+		return false
+	}
+	return g.rowOf(o) == nil
+}
+
+func (g *generator) addIndexes(m *Metric, o object, opts Options, out *Module) error {
+	row := g.rowOf(o)
+	if row.Augments != "" {
+		base, ok := g.byName[row.Augments]
+		if !ok {
+			return fmt.Errorf("snmpexporter: %s augments unknown row %s", row.Name, row.Augments)
+		}
+		row = base.obj
+	}
+	for i, name := range row.Index {
+		idx, ok := g.byName[name]
+		if !ok {
+			return fmt.Errorf("snmpexporter: %s: unknown index object %s", row.Name, name)
+		}
+		t, size := g.indexType(idx)
+		m.Indexes = append(m.Indexes, &Index{
+			Labelname: name,
+			Type:      t,
+			FixedSize: size,
+			Implied:   row.Implied && i == len(row.Index)-1,
+		})
+	}
+	var drop []string
+	for _, l := range opts.Lookups {
+		if !hasIndexes(m.Indexes, l.SourceIndexes) {
+			continue
+		}
+		lookup, ok := g.byName[l.Lookup]
+		if !ok {
+			return fmt.Errorf("snmpexporter: unknown lookup object %s", l.Lookup)
+		}
+		t, _ := g.indexType(lookup)
+		m.Lookups = append(m.Lookups, &MetricLookup{
+			Labels:    l.SourceIndexes,
+			Labelname: l.Lookup,
+			OID:       lookup.obj.OID.String(),
+			Type:      t,
+		})
+		if !walked(lookup.obj.OID, out.Walk) {
+			out.Walk = append(out.Walk, lookup.obj.OID.String())
+		}
+		if l.DropSourceIndexes {
+			drop = append(drop, l.SourceIndexes...)
+		}
+	}
+	for _, name := range drop {
+		m.Lookups = append(m.Lookups, &MetricLookup{Labels: []string{}, Labelname: name})
+	}
+	return nil
+}
+
+func hasIndexes(indexes []*Index, names []string) bool {
+	for _, name := range names {
+		found := false
+		for _, idx := range indexes {
+			found = found || idx.Labelname == name
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(names) > 0
+}
+
+// metricType returns the snmp_exporter type of an object and its enum
+// values; the type is empty for objects snmp_exporter cannot export.
+func (g *generator) metricType(o object) (string, map[int64]string) {
+	r := g.reg.ResolveSyntax(o.mod, o.obj.Syntax)
+	var enums map[int64]string
+	if len(r.NamedNumbers) > 0 {
+		enums = map[int64]string{}
+		for _, n := range r.NamedNumbers {
+			enums[n.Value] = n.Name
+		}
+	}
+	switch r.Base {
+	case "Counter32", "Counter64", "Counter":
+		return "counter", nil
+	case "INTEGER", "Integer32", "Unsigned32", "Gauge32", "Gauge", "TimeTicks":
+		return "gauge", enums
+	case "BITS":
+		return "Bits", enums
+	case "OCTET STRING", "IpAddress", "NetworkAddress":
+		return octetType(r), nil
+	}
+	return "", nil
+}
+
+// indexType returns the snmp_exporter type of an index or lookup object and
+// the fixed size of an OCTET STRING index.
+func (g *generator) indexType(o object) (string, int) {
+	r := g.reg.ResolveSyntax(o.mod, o.obj.Syntax)
+	switch r.Base {
+	case "OCTET STRING":
+		t := octetType(r)
+		if size, ok := r.FixedSize(); ok && t == "OctetString" {
+			return t, size
+		}
+		return t, 0
+	case "IpAddress", "NetworkAddress":
+		return "IpAddr", 0
+	case "OBJECT IDENTIFIER":
+		return "ObjectIdentifier", 0
+	}
+	return "gauge", 0
+}
+
+// octetType picks the snmp_exporter type of an OCTET STRING from its textual
+// convention name or DISPLAY-HINT, as snmp_exporter's generator does.
+func octetType(r mib_parser.ResolvedSyntax) string {
+	if r.Base == "IpAddress" || r.Base == "NetworkAddress" {
+		return "IpAddr"
+	}
+	for _, tc := range r.Conventions {
+		switch tc.Name {
+		case "DisplayString", "SnmpAdminString":
+			return "DisplayString"
+		case "PhysAddress", "MacAddress":
+			return "PhysAddress48"
+		case "DateAndTime", "InetAddress", "InetAddressIPv4", "InetAddressIPv6":
+			return tc.Name
+		}
+		if textHintRe.MatchString(tc.DisplayHint) {
+			return "DisplayString"
+		}
+		if size, _ := r.FixedSize(); tc.DisplayHint == "1x:" && size == 6 {
+			return "PhysAddress48"
+		}
+	}
+	return "OctetString"
+}
+
+// textHintRe matches DISPLAY-HINTs that render octets as text, such as
+// DisplayString's "255a" and SnmpAdminString's "255t".
+var textHintRe = regexp.MustCompile(`^\d+[at]$`)
+
+func accessible(access string) bool {
+	switch access {
+	case "read-only", "read-write", "read-create":
+		return true
+	}
+	return false
+}
+
+// metricName sanitises a descriptor into a Prometheus metric name.
+func metricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == ':' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// help is the first sentence of the description followed by the OID, the
+// way snmp_exporter's generator writes it.
+func help(o *mib_parser.ObjectType) string {
+	desc := strings.Join(strings.Fields(o.Description), " ")
+	if i := strings.Index(desc, ". "); i >= 0 {
+		desc = desc[:i+1]
+	}
+	return desc + " - " + o.OID.String()
+}
+
+func under(oid mib_parser.OID, roots []mib_parser.OID) bool {
+	for _, root := range roots {
+		if oid.HasPrefix(root) {
+			return true
+		}
+	}
+	return false
+}
+
+func walked(oid mib_parser.OID, walk []string) bool {
+	for _, w := range walk {
+		root, err := mib_parser.ParseOID(w)
+		if err == nil && oid.HasPrefix(root) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package snmpexporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// WriteYAML writes modules, keyed by exporter module name, in the layout of
+// snmp_exporter's snmp.yml.
+func WriteYAML(w io.Writer, modules map[string]*Module) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "modules:")
+	for _, name := range sortedKeys(modules) {
+		m := modules[name]
+		fmt.Fprintf(bw, "  %s:\n", yamlString(name))
+		writeList(bw, "    ", "walk", m.Walk)
+		writeList(bw, "    ", "get", m.Get)
+		if len(m.Metrics) == 0 {
+			fmt.Fprintln(bw, "    metrics: []")
+			continue
+		}
+		fmt.Fprintln(bw, "    metrics:")
+		for _, metric := range m.Metrics {
+			writeMetric(bw, metric)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeMetric(w *bufio.Writer, m *Metric) {
+	fmt.Fprintf(w, "    - name: %s\n", yamlString(m.Name))
+	fmt.Fprintf(w, "      oid: %s\n", m.OID)
+	fmt.Fprintf(w, "      type: %s\n", m.Type)
+	fmt.Fprintf(w, "      help: %s\n", yamlString(m.Help))
+	if len(m.Indexes) > 0 {
+		fmt.Fprintln(w, "      indexes:")
+		for _, idx := range m.Indexes {
+			fmt.Fprintf(w, "      - labelname: %s\n", yamlString(idx.Labelname))
+			fmt.Fprintf(w, "        type: %s\n", idx.Type)
+			if idx.FixedSize > 0 {
+				fmt.Fprintf(w, "        fixed_size: %d\n", idx.FixedSize)
+			}
+			if idx.Implied {
+				fmt.Fprintln(w, "        implied: true")
+			}
+		}
+	}
+	if len(m.Lookups) > 0 {
+		fmt.Fprintln(w, "      lookups:")
+		for _, l := range m.Lookups {
+			if len(l.Labels) == 0 {
+				fmt.Fprintln(w, "      - labels: []")
+			} else {
+				fmt.Fprintln(w, "      - labels:")
+				for _, label := range l.Labels {
+					fmt.Fprintf(w, "        - %s\n", yamlString(label))
+				}
+			}
+			fmt.Fprintf(w, "        labelname: %s\n", yamlString(l.Labelname))
+			if l.OID != "" {
+				fmt.Fprintf(w, "        oid: %s\n", l.OID)
+				fmt.Fprintf(w, "        type: %s\n", l.Type)
+			}
+		}
+	}
+	if len(m.EnumValues) > 0 {
+		fmt.Fprintln(w, "      enum_values:")
+		values := make([]int64, 0, len(m.EnumValues))
+		for v := range m.EnumValues {
+			values = append(values, v)
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		for _, v := range values {
+			fmt.Fprintf(w, "        %d: %s\n", v, yamlString(m.EnumValues[v]))
+		}
+	}
+}
+
+func writeList(w *bufio.Writer, indent, key string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s%s:\n", indent, key)
+	for _, item := range items {
+		fmt.Fprintf(w, "%s- %s\n", indent, item)
+	}
+}
+
+// plainRe matches strings that YAML reads back unchanged without quotes.
+var plainRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.()/,' -]*$`)
+
+// yamlString quotes s unless it is safe as a plain scalar.
+func yamlString(s string) string {
+	if plainRe.MatchString(s) && s[len(s)-1] != ' ' {
+		switch s {
+		case "true", "false", "yes", "no", "on", "off", "null", "True", "False", "Yes", "No", "On", "Off", "Null":
+		default:
+			return s
+		}
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,191 @@
+package mib_parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// Status is the normalised STATUS clause of a definition. The SMIv1 values
+// mandatory and optional are kept apart from the SMIv2 current.
+type Status int
+
+const (
+	// StatusUnknown is used for definitions without a STATUS clause and for
+	// unrecognised values.
+	StatusUnknown Status = iota
+	StatusCurrent
+	StatusMandatory
+	StatusOptional
+	StatusDeprecated
+	StatusObsolete
+)
+
+var statusNames = map[Status]string{
+	StatusCurrent:    "current",
+	StatusMandatory:  "mandatory",
+	StatusOptional:   "optional",
+	StatusDeprecated: "deprecated",
+	StatusObsolete:   "obsolete",
+}
+
+// ParseStatus normalises the text of a STATUS clause, ignoring case and
+// surrounding white space.
+func ParseStatus(s string) Status {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for status, name := range statusNames {
+		if name == s {
+			return status
+		}
+	}
+	return StatusUnknown
+}
+
+// String returns the STATUS keyword; empty for StatusUnknown.
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// ViewOptions selects the definitions a status-aware view hides.
+type ViewOptions struct {
+	// HideDeprecated hides definitions whose status is deprecated.
+	HideDeprecated bool
+	// HideObsolete hides definitions whose status is obsolete.
+	HideObsolete bool
+}
+
+func (o ViewOptions) hides(s Status) bool {
+	switch s {
+	case StatusDeprecated:
+		return o.HideDeprecated
+	case StatusObsolete:
+		return o.HideObsolete
+	}
+	return false
+}
+
+// View returns a copy of the module without the definitions opts hides.
+// The definitions themselves are shared with m and not modified, so lists
+// such as a group's OBJECTS may still name hidden definitions. Textual
+// conventions are kept because visible objects may still use them.
+func (m *Module) View(opts ViewOptions) *Module {
+	v := *m
+	v.ObjectsByName = filterStatus(m.ObjectsByName, opts)
+	v.ObjectIdentities = filterStatus(m.ObjectIdentities, opts)
+	v.NotificationTypes = filterStatus(m.NotificationTypes, opts)
+	v.ObjectGroups = filterStatus(m.ObjectGroups, opts)
+	v.NotificationGroups = filterStatus(m.NotificationGroups, opts)
+	v.ModuleCompliances = filterStatus(m.ModuleCompliances, opts)
+	v.AgentCapabilities = filterStatus(m.AgentCapabilities, opts)
+	return &v
+}
+
+func filterStatus[T Object](defs map[string]T, opts ViewOptions) map[string]T {
+	if defs == nil {
+		return nil
+	}
+	out := make(map[string]T, len(defs))
+	for name, d := range defs {
+		if !opts.hides(d.ObjectStatus()) {
+			out[name] = d
+		}
+	}
+	return out
+}
+
+// View returns a registry holding the View of each of r's modules, so that
+// symbol lookups and OID translation skip hidden definitions.
+func (r *Registry) View(opts ViewOptions) *Registry {
+	v := NewRegistry()
+	for name, m := range r.modules {
+		v.modules[name] = m.View(opts)
+	}
+	return v
+}
+
+// StaleReference is a current definition that still refers to a deprecated
+// or obsolete one.
+type StaleReference struct {
+	// From is the current group, notification or compliance.
+	From Object
+	// To is the deprecated or obsolete definition it refers to.
+	To Object
+}
+
+// StaleReferences reports the deprecated and obsolete definitions that are
+// still referenced by current (or SMIv1 mandatory) object and notification
+// groups, notifications and module compliances. The references are sorted
+// by the module and name of From and then of To.
+func (r *Registry) StaleReferences() []StaleReference {
+	var out []StaleReference
+	add := func(from Object, m *Module, names ...string) {
+		for _, name := range names {
+			to, ok := r.visibleObject(m, name)
+			if !ok {
+				continue
+			}
+			if s := to.ObjectStatus(); s == StatusDeprecated || s == StatusObsolete {
+				out = append(out, StaleReference{From: from, To: to})
+			}
+		}
+	}
+	for _, m := range r.Modules() {
+		for _, from := range m.Objects() {
+			if s := from.ObjectStatus(); s != StatusCurrent && s != StatusMandatory {
+				continue
+			}
+			switch d := from.(type) {
+			case *ObjectGroup:
+				add(d, m, d.Objects...)
+			case *NotificationGroup:
+				add(d, m, d.Notifications...)
+			case *NotificationType:
+				add(d, m, d.Objects...)
+			case *ModuleCompliance:
+				for _, cm := range d.Modules {
+					target := m
+					if cm.Module != "" && cm.Module != m.Name {
+						if target = r.modules[cm.Module]; target == nil {
+							continue
+						}
+					}
+					add(d, target, cm.MandatoryGroups...)
+					for _, g := range cm.Groups {
+						add(d, target, g.Name)
+					}
+					for _, o := range cm.Objects {
+						add(d, target, o.Name)
+					}
+				}
+			}
+		}
+	}
+	key := func(o Object) string { return o.ObjectModule() + "::" + o.ObjectName() }
+	sort.SliceStable(out, func(i, j int) bool {
+		if a, b := key(out[i].From), key(out[j].From); a != b {
+			return a < b
+		}
+		return key(out[i].To) < key(out[j].To)
+	})
+	return out
+}
+
+// visibleObject finds the definition name as seen from module m: defined
+// there, imported from a loaded module, or defined anywhere in the registry.
+func (r *Registry) visibleObject(m *Module, name string) (Object, bool) {
+	if o, ok := m.Object(name); ok {
+		return o, true
+	}
+	for _, imp := range m.Imports {
+		if owner, ok := r.modules[imp.Module]; ok && contains(imp.Symbols, name) {
+			if o, ok := owner.Object(name); ok {
+				return o, true
+			}
+		}
+	}
+	if s, ok := r.LookupSymbol("", name); ok {
+		if owner, ok := r.modules[s.Module]; ok {
+			return owner.Object(name)
+		}
+	}
+	return nil, false
+}
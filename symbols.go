@@ -0,0 +1,105 @@
+package mib_parser
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Symbol is a named OID node of a registry: any definition with an OID, or
+// one of the well-known SNMPv2-SMI nodes.
+type Symbol struct {
+	// Module is the module defining the node.
+	Module string
+	// Name is the node's descriptor.
+	Name string
+	// OID is the node's numeric OID.
+	OID OID
+}
+
+// String returns the symbol as "MODULE::name".
+func (s Symbol) String() string {
+	return s.Module + "::" + s.Name
+}
+
+// Symbols returns every named node with a resolved OID, sorted by OID and
+// then by module name. Once the modules are loaded and resolved, it and the
+// lookups built on it are safe for concurrent use.
+func (r *Registry) Symbols() []Symbol {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.symbols != nil {
+		return r.symbols
+	}
+	symbols := []Symbol{}
+	defined := map[string]bool{}
+	for _, m := range r.Modules() {
+		nodes := m.oidNodes()
+		for _, name := range sortedKeys(nodes) {
+			if oid := *nodes[name].oid; len(oid) > 0 {
+				symbols = append(symbols, Symbol{Module: m.Name, Name: name, OID: oid})
+				defined[name] = true
+			}
+		}
+	}
+	for _, name := range sortedKeys(wellKnownNodes) {
+		if !defined[name] {
+			symbols = append(symbols, Symbol{Module: "SNMPv2-SMI", Name: name, OID: wellKnownNodes[name]})
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if c := symbols[i].OID.Compare(symbols[j].OID); c != 0 {
+			return c < 0
+		}
+		return symbols[i].Module < symbols[j].Module
+	})
+	r.symbols = symbols
+	return symbols
+}
+
+// clearSymbols drops the cache Symbols keeps.
+func (r *Registry) clearSymbols() {
+	r.mu.Lock()
+	r.symbols = nil
+	r.mu.Unlock()
+}
+
+// LookupSymbol finds the node name defined by module. An empty module
+// searches all modules, as net-snmp's random access lookup (-IR) does, and
+// picks the first match in OID order.
+func (r *Registry) LookupSymbol(module, name string) (Symbol, bool) {
+	for _, s := range r.Symbols() {
+		if s.Name == name && (module == "" || s.Module == module) {
+			return s, true
+		}
+	}
+	return Symbol{}, false
+}
+
+// SymbolAt returns the deepest named node that is oid or one of its
+// ancestors, together with the remaining sub-identifiers (e.g., ifInOctets
+// and [3] for 1.3.6.1.2.1.2.2.1.10.3).
+func (r *Registry) SymbolAt(oid OID) (Symbol, OID, bool) {
+	symbols := r.Symbols()
+	for n := len(oid); n > 0; n-- {
+		prefix := oid[:n]
+		i := sort.Search(len(symbols), func(i int) bool {
+			return symbols[i].OID.Compare(prefix) >= 0
+		})
+		if i < len(symbols) && symbols[i].OID.Equal(prefix) {
+			return symbols[i], append(OID(nil), oid[n:]...), true
+		}
+	}
+	return Symbol{}, nil, false
+}
+
+// SearchSymbols returns the symbols whose descriptor matches re, in OID
+// order.
+func (r *Registry) SearchSymbols(re *regexp.Regexp) []Symbol {
+	var out []Symbol
+	for _, s := range r.Symbols() {
+		if re.MatchString(s.Name) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
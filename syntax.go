@@ -0,0 +1,257 @@
+package mib_parser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Syntax is the structure of a SYNTAX clause such as
+// "INTEGER { up(1), down(2) }" or "OCTET STRING (SIZE (0..255))".
+type Syntax struct {
+	// Base is the type the clause refines (e.g., "INTEGER", "OCTET STRING",
+	// "Integer32", a textual convention name, or "SEQUENCE OF IfEntry").
+	Base string
+	// NamedNumbers lists INTEGER enumerations or BITS labels in source order.
+	NamedNumbers []NamedNumber
+	// Ranges is the value range constraint, e.g. (1..10 | 20).
+	Ranges []Range
+	// Sizes is the SIZE constraint, e.g. (SIZE (0..255)).
+	Sizes []Range
+}
+
+// NamedNumber is one "label(value)" item of an enumeration or BITS clause.
+type NamedNumber struct {
+	Name  string
+	Value int64
+}
+
+// Range is an inclusive interval of a range or SIZE constraint. A single
+// value has Min == Max; MIN and MAX map to the int64 limits.
+type Range struct {
+	Min int64
+	Max int64
+}
+
+// ParseSyntax breaks a SYNTAX string into its base type and constraints.
+// Unrecognised parts are ignored, so the result is best-effort for exotic
+// ASN.1 constructs.
+func ParseSyntax(s string) Syntax {
+	toks := lexSyntax(s)
+	var syn Syntax
+	i := 0
+	var base []string
+	for i < len(toks) && toks[i] != "{" && toks[i] != "(" {
+		base = append(base, toks[i])
+		i++
+	}
+	syn.Base = strings.Join(base, " ")
+	for i < len(toks) {
+		switch toks[i] {
+		case "{":
+			i = syn.parseNamedNumbers(toks, i+1)
+		case "(":
+			if i+1 < len(toks) && toks[i+1] == "SIZE" && i+2 < len(toks) && toks[i+2] == "(" {
+				var r []Range
+				r, i = parseRanges(toks, i+3)
+				syn.Sizes = append(syn.Sizes, r...)
+				if i < len(toks) && toks[i] == ")" {
+					i++
+				}
+			} else {
+				var r []Range
+				r, i = parseRanges(toks, i+1)
+				syn.Ranges = append(syn.Ranges, r...)
+			}
+		default:
+			i++
+		}
+	}
+	return syn
+}
+
+// Enum returns the label for value, if the syntax enumerates it.
+func (s Syntax) Enum(value int64) (string, bool) {
+	for _, n := range s.NamedNumbers {
+		if n.Value == value {
+			return n.Name, true
+		}
+	}
+	return "", false
+}
+
+// EnumValue returns the value of an enumeration label.
+func (s Syntax) EnumValue(name string) (int64, bool) {
+	for _, n := range s.NamedNumbers {
+		if n.Name == name {
+			return n.Value, true
+		}
+	}
+	return 0, false
+}
+
+// String renders the syntax in its conventional compact form, e.g.
+// "INTEGER { up(1), down(2) }" or "OCTET STRING (SIZE (0..255))".
+func (s Syntax) String() string {
+	var b strings.Builder
+	b.WriteString(s.Base)
+	if len(s.NamedNumbers) > 0 {
+		items := make([]string, len(s.NamedNumbers))
+		for i, n := range s.NamedNumbers {
+			items[i] = n.Name + "(" + strconv.FormatInt(n.Value, 10) + ")"
+		}
+		b.WriteString(" { " + strings.Join(items, ", ") + " }")
+	}
+	if len(s.Ranges) > 0 {
+		b.WriteString(" (" + formatRanges(s.Ranges) + ")")
+	}
+	if len(s.Sizes) > 0 {
+		b.WriteString(" (SIZE (" + formatRanges(s.Sizes) + "))")
+	}
+	return b.String()
+}
+
+func formatRanges(ranges []Range) string {
+	items := make([]string, len(ranges))
+	for i, r := range ranges {
+		items[i] = formatSyntaxValue(r.Min)
+		if r.Max != r.Min {
+			items[i] += ".." + formatSyntaxValue(r.Max)
+		}
+	}
+	return strings.Join(items, " | ")
+}
+
+func formatSyntaxValue(v int64) string {
+	switch v {
+	case math.MinInt64:
+		return "MIN"
+	case math.MaxInt64:
+		return "MAX"
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func (s *Syntax) parseNamedNumbers(toks []string, i int) int {
+	for i < len(toks) && toks[i] != "}" {
+		if i+3 < len(toks) && toks[i+1] == "(" && toks[i+3] == ")" {
+			if v, ok := parseSyntaxValue(toks[i+2]); ok {
+				s.NamedNumbers = append(s.NamedNumbers, NamedNumber{Name: toks[i], Value: v})
+			}
+			i += 4
+			continue
+		}
+		i++
+	}
+	return i + 1
+}
+
+// parseRanges reads "a..b | c" up to and including the closing parenthesis.
+func parseRanges(toks []string, i int) ([]Range, int) {
+	var out []Range
+	for i < len(toks) && toks[i] != ")" {
+		lo, ok := parseSyntaxValue(toks[i])
+		if !ok {
+			i++
+			continue
+		}
+		hi := lo
+		if i+2 < len(toks) && toks[i+1] == ".." {
+			if v, ok := parseSyntaxValue(toks[i+2]); ok {
+				hi = v
+			}
+			i += 2
+		}
+		out = append(out, Range{Min: lo, Max: hi})
+		i++
+		if i < len(toks) && toks[i] == "|" {
+			i++
+		}
+	}
+	return out, i + 1
+}
+
+func parseSyntaxValue(tok string) (int64, bool) {
+	switch tok {
+	case "MIN":
+		return math.MinInt64, true
+	case "MAX":
+		return math.MaxInt64, true
+	}
+	if strings.HasPrefix(tok, "'") {
+		end := strings.LastIndexByte(tok, '\'')
+		if end <= 0 || end+1 >= len(tok) {
+			return 0, false
+		}
+		base := 16
+		if tok[end+1] == 'B' || tok[end+1] == 'b' {
+			base = 2
+		}
+		v, err := strconv.ParseUint(tok[1:end], base, 64)
+		if err != nil || v > math.MaxInt64 {
+			return math.MaxInt64, err == nil
+		}
+		return int64(v), true
+	}
+	v, err := strconv.ParseInt(tok, 10, 64)
+	if err != nil {
+		if u, uerr := strconv.ParseUint(tok, 10, 64); uerr == nil && u > math.MaxInt64 {
+			return math.MaxInt64, true
+		}
+		return 0, false
+	}
+	return v, true
+}
+
+// lexSyntax splits a SYNTAX string into identifiers, numbers, quoted
+// literals and the punctuation { } ( ) , | and "..".
+func lexSyntax(s string) []string {
+	var toks []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.' && i+1 < len(s) && s[i+1] == '.':
+			toks = append(toks, "..")
+			i += 2
+		case strings.IndexByte("{}(),|", c) >= 0:
+			toks = append(toks, string(c))
+			i++
+		case c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != '\'' {
+				j++
+			}
+			if j < len(s) {
+				j++
+			}
+			if j < len(s) && strings.IndexByte("HhBb", s[j]) >= 0 {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		case c == '-' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9', c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		case isSyntaxIdent(c):
+			j := i + 1
+			for j < len(s) && (isSyntaxIdent(s[j]) || s[j] >= '0' && s[j] <= '9' || s[j] == '-' && !(j+1 < len(s) && s[j+1] == '-')) {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		default:
+			i++
+		}
+	}
+	return toks
+}
+
+func isSyntaxIdent(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"encoding/hex"
+	"math"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/ber"
+)
+
+func TestBER(t *testing.T) {
+	get := &ber.Message{
+		Version:   ber.Version2c,
+		Community: "public",
+		PDU:       ber.PDU{Type: ber.GetRequest, RequestID: 1, VarBinds: []mib_parser.VarBind{null("1.3.6.1.2.1.1.1.0")}},
+	}
+	b, err := ber.Marshal(get, ber.Options{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := "302602010104067075626c6963a019020101020100020100300e300c06082b060102010101000500"
+	if got := hex.EncodeToString(b); got != want {
+		t.Errorf("GetRequest encoded as %s, want %s", got, want)
+	}
+
+	resp := &ber.Message{
+		Version:   ber.Version2c,
+		Community: "public",
+		PDU: ber.PDU{Type: ber.Response, RequestID: -2, ErrorStatus: ber.NoError, VarBinds: []mib_parser.VarBind{
+			varBind("1.3.6.1.2.1.1.1.0", mib_parser.TypeOctetString, strings.Repeat("x", 300)),
+			varBind("1.3.6.1.2.1.1.2.0", mib_parser.TypeObjectIdentifier, mib_parser.OID{1, 3, 6, 1, 4, 1, 4294967295}),
+			varBind("1.3.6.1.2.1.1.3.0", mib_parser.TypeTimeTicks, uint32(math.MaxUint32)),
+			varBind("1.3.6.1.2.1.4.20.1.1.10.0.0.1", mib_parser.TypeIpAddress, net.ParseIP("10.0.0.1")),
+			varBind("1.3.6.1.2.1.2.2.1.10.1", mib_parser.TypeCounter32, 128),
+			varBind("1.3.6.1.2.1.2.2.1.5.1", mib_parser.TypeGauge32, uint32(1e9)),
+			varBind("1.3.6.1.2.1.31.1.1.1.6.1", mib_parser.TypeCounter64, uint64(math.MaxUint64)),
+			varBind("1.3.6.1.4.1.99990.1.0", mib_parser.TypeInteger, math.MinInt32),
+			varBind("1.3.6.1.4.1.99990.2.0", mib_parser.TypeOpaque, []byte{0x9f, 0x78}),
+			varBind("1.3.6.1.4.1.99990.3.0", mib_parser.TypeNoSuchInstance, nil),
+			varBind("1.3.6.1.4.1.99990.4.0", mib_parser.TypeEndOfMibView, nil),
+		}},
+	}
+	b, err = ber.Marshal(resp, ber.Options{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	decoded, err := ber.Unmarshal(b)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Version != resp.Version || decoded.Community != resp.Community || decoded.PDU.Type != ber.Response ||
+		decoded.PDU.RequestID != -2 || len(decoded.PDU.VarBinds) != len(resp.PDU.VarBinds) {
+		t.Fatalf("round trip gave %+v", decoded)
+	}
+	for i, vb := range decoded.PDU.VarBinds {
+		if got, want := formatVarBind(vb), formatVarBind(resp.PDU.VarBinds[i]); got != want {
+			t.Errorf("round trip of varbind %d gave %s, want %s", i+1, got, want)
+		}
+	}
+	if _, ok := decoded.PDU.VarBinds[3].Value.Data().(net.IP); !ok {
+		t.Errorf("IpAddress decoded as %T, want net.IP", decoded.PDU.VarBinds[3].Value.Data())
+	}
+
+	bulk := &ber.Message{Version: ber.Version2c, Community: "c", PDU: ber.PDU{
+		Type: ber.GetBulkRequest, RequestID: 7, NonRepeaters: 1, MaxRepetitions: 25,
+		VarBinds: []mib_parser.VarBind{null("1.3.6.1.2.1.1.3"), null("1.3.6.1.2.1.2.2.1.2")},
+	}}
+	b, err = ber.Marshal(bulk, ber.Options{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if decoded, err = ber.Unmarshal(b); err != nil || decoded.PDU.NonRepeaters != 1 || decoded.PDU.MaxRepetitions != 25 || decoded.PDU.ErrorStatus != 0 {
+		t.Errorf("GetBulkRequest round trip gave %+v, %v", decoded, err)
+	}
+
+	trap := &mib_parser.TrapV1{
+		Enterprise:   []int{1, 3, 6, 1, 4, 1, 99992},
+		AgentAddress: net.ParseIP("192.0.2.1").To4(),
+		GenericTrap:  mib_parser.GenericEnterpriseSpecific,
+		SpecificTrap: 3,
+		Timestamp:    99,
+		Community:    "traps",
+		VarBinds:     []mib_parser.VarBind{varBind("1.3.6.1.4.1.99992.1.0", mib_parser.TypeInteger, int64(71))},
+	}
+	b, err = ber.Marshal(&ber.Message{Version: ber.Version1, Community: "traps", PDU: ber.PDU{Type: ber.TrapV1, Trap: trap}}, ber.Options{})
+	if err != nil {
+		t.Fatalf("Marshal of a Trap failed: %v", err)
+	}
+	if decoded, err = ber.Unmarshal(b); err != nil || !reflect.DeepEqual(decoded.PDU.Trap, trap) {
+		t.Errorf("Trap round trip gave %+v, %v", decoded.PDU.Trap, err)
+	}
+
+	for _, msg := range []*ber.Message{
+		{Version: ber.Version1, PDU: bulk.PDU},
+		{Version: ber.Version2c, PDU: ber.PDU{Type: ber.TrapV1, Trap: trap}},
+		{Version: 3, PDU: get.PDU},
+		{Version: ber.Version2c, PDU: ber.PDU{Type: ber.SetRequest, VarBinds: []mib_parser.VarBind{varBind("1.3.6.1.2.1.1.5.0", mib_parser.TypeInteger, int64(1)<<31)}}},
+		{Version: ber.Version2c, PDU: ber.PDU{Type: ber.SetRequest, VarBinds: []mib_parser.VarBind{varBind("1.3.6.1.2.1.1.5.0", mib_parser.TypeIpAddress, "10.0.0.1")}}},
+		{Version: ber.Version2c, PDU: ber.PDU{Type: ber.GetRequest, VarBinds: []mib_parser.VarBind{null("1.40.1")}}},
+	} {
+		if b, err := ber.Marshal(msg, ber.Options{}); err == nil {
+			t.Errorf("Marshal(%+v) = % x, want an error", msg, b)
+		}
+	}
+	for _, bad := range []string{
+		"",
+		"3026020101",
+		"302602010104067075626c6963a019020101020100020100300e300c06082b060102010101000500ff"[:70],
+		"300d020103040130a0050201000201",
+		"301802010104067075626c6963a40b0201010201000201003000",
+	} {
+		b, _ := hex.DecodeString(bad)
+		if msg, err := ber.Unmarshal(b); err == nil {
+			t.Errorf("Unmarshal(%s) = %+v, want an error", bad, msg)
+		}
+	}
+
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	set := func(vbs ...mib_parser.VarBind) error {
+		_, err := ber.Marshal(&ber.Message{Version: ber.Version2c, Community: "private", PDU: ber.PDU{Type: ber.SetRequest, VarBinds: vbs}}, ber.Options{Registry: reg})
+		return err
+	}
+	if err := set(integer("1.3.6.1.2.1.2.2.1.7.1", 2), octets("1.3.6.1.2.1.31.1.1.1.18.1", "uplink"), integer("1.3.6.1.4.1.99990.1.0", 1234)); err != nil {
+		t.Errorf("Marshal of a valid SetRequest failed: %v", err)
+	}
+	for _, tt := range []struct {
+		vb   mib_parser.VarBind
+		want string
+	}{
+		{integer("1.3.6.1.2.1.2.2.1.7.1", 7), "ber: varbind 1: value 7 not in enumeration {up(1), down(2), testing(3)} for ifAdminStatus"},
+		{octets("1.3.6.1.2.1.31.1.1.1.18.1", strings.Repeat("x", 65)), "ber: varbind 1: length 65 not in SIZE (0..64) for ifAlias"},
+		{varBind("1.3.6.1.2.1.2.2.1.10.1", mib_parser.TypeGauge32, 1), "ber: varbind 1: ifInOctets expects Counter32, got Gauge32"},
+	} {
+		if err := set(tt.vb); err == nil || err.Error() != tt.want {
+			t.Errorf("Marshal of %s = %v, want %q", formatVarBind(tt.vb), err, tt.want)
+		}
+	}
+
+	m, _ := reg.Module("IF-MIB")
+	syn := reg.ResolveSyntax(m, m.ObjectsByName["ifInOctets"].Syntax)
+	if typ, ok := syn.ValueType(); !ok || typ != mib_parser.TypeCounter32 {
+		t.Errorf("ifInOctets is sent as %s, want Counter32", typ)
+	} else if tag, _ := ber.Tag(typ); tag != 0x41 {
+		t.Errorf("Counter32 is tagged 0x%02x, want 0x41", tag)
+	}
+	if typ, ok := ber.TypeOf(0x46); !ok || typ != mib_parser.TypeCounter64 {
+		t.Errorf("tag 0x46 is %s, want Counter64", typ)
+	}
+	if s, err := ber.ParseErrorStatus("notWritable"); err != nil || s != ber.NotWritable || s.String() != "notWritable" {
+		t.Errorf("ParseErrorStatus(notWritable) = %v, %v", s, err)
+	}
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Olian04/go-mib-parser/browser"
+)
+
+func TestBrowser(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "mibs", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := browser.Load(paths...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	get := func(path string, want int, v any) string {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("GET %s returned %s, want %d: %s", path, resp.Status, want, body)
+		}
+		if v != nil {
+			if err := json.Unmarshal(body, v); err != nil {
+				t.Fatalf("GET %s: %v", path, err)
+			}
+		}
+		return string(body)
+	}
+
+	var modules []browser.ModuleSummary
+	get("/api/modules", http.StatusOK, &modules)
+	if len(modules) != len(paths) {
+		t.Errorf("/api/modules listed %d modules, want %d", len(modules), len(paths))
+	}
+
+	var ifMIB browser.Module
+	get("/api/modules/IF-MIB", http.StatusOK, &ifMIB)
+	if ifMIB.File != "IF-MIB.MIB" || ifMIB.Identity == nil || ifMIB.Identity.Name != "ifMIB" {
+		t.Errorf("IF-MIB = %s, %+v", ifMIB.File, ifMIB.Identity)
+	}
+	i := slices.IndexFunc(ifMIB.Imports, func(imp browser.Import) bool { return imp.Module == "SNMPv2-TC" })
+	if i < 0 || !ifMIB.Imports[i].Loaded || !slices.Contains(ifMIB.Imports[i].Symbols, "DisplayString") {
+		t.Errorf("IF-MIB imports = %+v", ifMIB.Imports)
+	}
+	if !slices.Contains(ifMIB.ImportedBy, "IP-MIB") {
+		t.Errorf("IF-MIB is imported by %v, want IP-MIB among them", ifMIB.ImportedBy)
+	}
+
+	var def browser.Definition
+	get("/api/definitions/IF-MIB/ifAdminStatus", http.StatusOK, &def)
+	if def.Kind != "OBJECT-TYPE" || def.OID != "1.3.6.1.2.1.2.2.1.7" || def.Access != "read-write" || def.Status != "current" {
+		t.Errorf("ifAdminStatus = %+v", def.Ref)
+	}
+	if def.Syntax == nil || def.Syntax.Base != "INTEGER" || len(def.Syntax.Enumeration) != 3 || def.Syntax.Enumeration[1].Name != "down" {
+		t.Errorf("ifAdminStatus syntax = %+v", def.Syntax)
+	}
+	if len(def.Path) == 0 || def.Path[0].Name != "iso" || def.Path[len(def.Path)-1].Name != "ifEntry" {
+		t.Errorf("ifAdminStatus path = %+v", def.Path)
+	}
+	if src := def.Source; src == nil || src.File != "IF-MIB.MIB" || !strings.HasPrefix(src.Text, "ifAdminStatus OBJECT-TYPE") || !strings.HasSuffix(src.Text, "::= { ifEntry 7 }") {
+		t.Errorf("ifAdminStatus source = %+v", def.Source)
+	}
+
+	def = browser.Definition{}
+	get("/api/definitions/IF-MIB/ifPhysAddress", http.StatusOK, &def)
+	if def.Syntax == nil || len(def.Syntax.Conventions) != 1 || def.Syntax.Conventions[0] != (browser.Ref{Module: "SNMPv2-TC", Name: "PhysAddress", Kind: "TEXTUAL-CONVENTION"}) || def.Syntax.Base != "OCTET STRING" {
+		t.Errorf("ifPhysAddress syntax = %+v", def.Syntax)
+	}
+	def = browser.Definition{}
+	get("/api/definitions/IF-MIB/ifStackEntry", http.StatusOK, &def)
+	if len(def.Index) != 2 || def.Index[0].Name != "ifStackHigherLayer" || def.Index[0].Module != "IF-MIB" {
+		t.Errorf("ifStackEntry index = %+v", def.Index)
+	}
+	def = browser.Definition{}
+	get("/api/definitions/SNMPv2-TC/DisplayString", http.StatusOK, &def)
+	if def.Syntax == nil || def.Syntax.DisplayHint != "255a" || def.Source == nil || !strings.HasPrefix(def.Source.Text, "DisplayString ::= TEXTUAL-CONVENTION") {
+		t.Errorf("DisplayString = %+v, %+v", def.Syntax, def.Source)
+	}
+
+	var node browser.TreeNode
+	get("/api/tree?oid=1.3.6.1.2.1", http.StatusOK, &node)
+	if node.Name != "mib-2" {
+		t.Errorf("tree node = %+v", node.Ref)
+	}
+	var names []string
+	for _, c := range node.Children {
+		names = append(names, c.Name)
+	}
+	for _, want := range []string{"system", "interfaces", "ip", "ifMIB"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("children of mib-2 = %v, want %s among them", names, want)
+		}
+	}
+	node = browser.TreeNode{}
+	get("/api/tree", http.StatusOK, &node)
+	if len(node.Children) != 2 || node.Children[1].Name != "iso" || node.Children[1].Arc != 1 {
+		t.Errorf("tree root = %+v", node.Children)
+	}
+	node = browser.TreeNode{}
+	get("/api/tree?oid=1.3.6.1.2.1.2.2.1.7", http.StatusOK, &node)
+	if len(node.Children) != 0 {
+		t.Errorf("ifAdminStatus has children %+v", node.Children)
+	}
+
+	var lookup browser.Lookup
+	get("/api/lookup?q=IF-MIB::ifInOctets.3", http.StatusOK, &lookup)
+	if lookup.Name != "ifInOctets" || lookup.Suffix != "3" {
+		t.Errorf("lookup = %+v", lookup)
+	}
+	lookup = browser.Lookup{}
+	get("/api/lookup?q=1.3.6.1.2.1.1.3.0", http.StatusOK, &lookup)
+	if lookup.Module != "SNMPv2-MIB" || lookup.Name != "sysUpTime" || lookup.Suffix != "0" {
+		t.Errorf("lookup = %+v", lookup)
+	}
+
+	var res struct {
+		Hits []browser.Ref `json:"hits"`
+	}
+	get("/api/search?q=discarded+inbound+packets&module=IF-MIB", http.StatusOK, &res)
+	if len(res.Hits) == 0 || res.Hits[0].Name != "ifInDiscards" {
+		t.Errorf("search = %+v", res.Hits)
+	}
+
+	if body := get("/", http.StatusOK, nil); !strings.Contains(body, "<title>MIB browser</title>") {
+		t.Errorf("GET / did not serve the UI")
+	}
+	get("/app.js", http.StatusOK, nil)
+	for _, path := range []string{"/api/modules/NO-SUCH-MIB", "/api/definitions/IF-MIB/noSuchThing", "/api/lookup?q=noSuchThing", "/api/nothing"} {
+		var e struct{ Error string }
+		if get(path, http.StatusNotFound, &e); e.Error == "" {
+			t.Errorf("GET %s returned no error message", path)
+		}
+	}
+	get("/api/tree?oid=1.x", http.StatusBadRequest, nil)
+
+	resp, err := http.Post(srv.URL+"/api/modules", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST returned %s, want 405", resp.Status)
+	}
+}
+
+// The server shares one registry between requests; run with -race.
+func TestBrowserConcurrentRequests(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "mibs", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := browser.Load(paths...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	codes := make([]int, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/lookup?q=1.3.6.1.2.1.2.2.1.8.3", nil))
+			codes[i] = rec.Code
+		}()
+	}
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d returned %d, want %d", i, code, http.StatusOK)
+		}
+	}
+}
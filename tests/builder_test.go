@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func newExampleBuilder(t *testing.T) *mib_parser.ModuleBuilder {
+	t.Helper()
+	b, err := mib_parser.NewModuleBuilder("ACME-WIDGET-MIB")
+	if err != nil {
+		t.Fatalf("NewModuleBuilder failed: %v", err)
+	}
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(b.SetModuleIdentity(mib_parser.ModuleIdentity{
+		Name:         "acmeWidgetMIB",
+		LastUpdated:  "202601010000Z",
+		Organization: "ACME Corp.",
+		ContactInfo:  "noc@acme.example",
+		Description:  "Widget management.",
+		Revisions:    []mib_parser.Revision{{Date: "202601010000Z", Description: "Initial version."}},
+		Assignment:   mib_parser.OIDAssignment{Parent: "enterprises", SubIDs: []int{99999}},
+	}))
+	must(b.AddNode("acmeWidgetObjects", "acmeWidgetMIB", 1))
+	must(b.AddNode("acmeWidgetNotifications", "acmeWidgetMIB", 0))
+	must(b.AddNode("acmeWidgetConformance", "acmeWidgetMIB", 2))
+	must(b.AddTextualConvention(mib_parser.TextualConvention{
+		Name:        "WidgetState",
+		Status:      "current",
+		Description: "Operational state of a widget.",
+		Syntax:      "INTEGER { idle(1), busy(2), broken(3) }",
+	}))
+	must(b.AddTable(
+		mib_parser.ObjectType{
+			Name: "widgetTable", Status: "current", Description: "All widgets.",
+			Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetObjects", SubIDs: []int{1}},
+		},
+		mib_parser.ObjectType{
+			Name: "widgetEntry", Status: "current", Description: "One widget.",
+			Index: []string{"widgetIndex"},
+		},
+		mib_parser.ObjectType{Name: "widgetIndex", Syntax: "Integer32 (1..65535)", Access: "not-accessible", Status: "current", Description: "Widget number."},
+		mib_parser.ObjectType{Name: "widgetName", Syntax: "DisplayString", Access: "read-create", Status: "current", Description: "Widget name."},
+		mib_parser.ObjectType{Name: "widgetState", Syntax: "WidgetState", Access: "read-only", Status: "current", Description: "Widget state."},
+		mib_parser.ObjectType{Name: "widgetRowStatus", Syntax: "RowStatus", Access: "read-create", Status: "current", Description: "Row status."},
+	))
+	must(b.AddNotificationType(mib_parser.NotificationType{
+		Name: "widgetBroken", Objects: []string{"widgetState"}, Status: "current",
+		Description: "A widget broke.",
+		Assignment:  mib_parser.OIDAssignment{Parent: "acmeWidgetNotifications", SubIDs: []int{1}},
+	}))
+	must(b.AddObjectGroup(mib_parser.ObjectGroup{
+		Name: "widgetGroup", Objects: []string{"widgetName", "widgetState", "widgetRowStatus"},
+		Status: "current", Description: "Widget objects.",
+		Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetConformance", SubIDs: []int{1}},
+	}))
+	must(b.AddNotificationGroup(mib_parser.NotificationGroup{
+		Name: "widgetNotificationGroup", Notifications: []string{"widgetBroken"},
+		Status: "current", Description: "Widget notifications.",
+		Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetConformance", SubIDs: []int{2}},
+	}))
+	must(b.AddModuleCompliance(mib_parser.ModuleCompliance{
+		Name: "widgetCompliance", Status: "current", Description: "Widget agents.",
+		Modules: []mib_parser.ComplianceModule{{
+			MandatoryGroups: []string{"widgetGroup", "widgetNotificationGroup"},
+			Objects:         []mib_parser.ComplianceObject{{Name: "widgetName", MinAccess: "read-only", Description: "Write access is optional."}},
+		}},
+		Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetConformance", SubIDs: []int{3}},
+	}))
+	return b
+}
+
+func TestBuilderRoundTrip(t *testing.T) {
+	out, err := newExampleBuilder(t).MIB()
+	if err != nil {
+		t.Fatalf("MIB failed: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB(out)
+	if err != nil {
+		t.Fatalf("generated MIB does not parse: %v\n%s", err, out)
+	}
+	for name, oid := range map[string]string{
+		"widgetTable":     "1.3.6.1.4.1.99999.1.1",
+		"widgetEntry":     "1.3.6.1.4.1.99999.1.1.1",
+		"widgetIndex":     "1.3.6.1.4.1.99999.1.1.1.1",
+		"widgetRowStatus": "1.3.6.1.4.1.99999.1.1.1.4",
+	} {
+		obj, ok := mod.GetObjectByName(name)
+		if !ok {
+			t.Errorf("%s missing from generated MIB", name)
+			continue
+		}
+		if obj.OIDString() != oid {
+			t.Errorf("%s OID = %s, want %s", name, obj.OIDString(), oid)
+		}
+	}
+	if entry := mod.ObjectsByName["widgetEntry"]; entry == nil || len(entry.Index) != 1 || entry.Index[0] != "widgetIndex" {
+		t.Errorf("widgetEntry INDEX not preserved: %+v", entry)
+	}
+	if g := mod.ObjectGroups["widgetGroup"]; g == nil || g.OIDString() != "1.3.6.1.4.1.99999.2.1" {
+		t.Errorf("widgetGroup not preserved: %+v", g)
+	}
+	if mc := mod.ModuleCompliances["widgetCompliance"]; mc == nil || len(mc.Modules) != 1 || len(mc.Modules[0].MandatoryGroups) != 2 {
+		t.Errorf("widgetCompliance not preserved: %+v", mc)
+	}
+	imports := map[string]string{}
+	for _, imp := range mod.Imports {
+		for _, s := range imp.Symbols {
+			imports[s] = imp.Module
+		}
+	}
+	for symbol, from := range map[string]string{
+		"enterprises":        "SNMPv2-SMI",
+		"Integer32":          "SNMPv2-SMI",
+		"RowStatus":          "SNMPv2-TC",
+		"TEXTUAL-CONVENTION": "SNMPv2-TC",
+		"OBJECT-GROUP":       "SNMPv2-CONF",
+	} {
+		if imports[symbol] != from {
+			t.Errorf("%s imported from %q, want %q", symbol, imports[symbol], from)
+		}
+	}
+}
+
+func TestBuilderValidation(t *testing.T) {
+	b := newExampleBuilder(t)
+	valid := mib_parser.ObjectType{
+		Name: "widgetCount", Syntax: "Gauge32", Access: "read-only", Status: "current",
+		Description: "Number of widgets.",
+		Assignment:  mib_parser.OIDAssignment{Parent: "acmeWidgetObjects", SubIDs: []int{2}},
+	}
+	cases := map[string]func(o *mib_parser.ObjectType){
+		"already defined":     func(o *mib_parser.ObjectType) { o.Name = "widgetTable" },
+		"lower-case letter":   func(o *mib_parser.ObjectType) { o.Name = "WidgetCount" },
+		"hyphen":              func(o *mib_parser.ObjectType) { o.Name = "widget-count" },
+		"invalid MAX-ACCESS":  func(o *mib_parser.ObjectType) { o.Access = "write-only" },
+		"invalid STATUS":      func(o *mib_parser.ObjectType) { o.Status = "mandatory" },
+		"DESCRIPTION":         func(o *mib_parser.ObjectType) { o.Description = "" },
+		"unknown SYNTAX":      func(o *mib_parser.ObjectType) { o.Syntax = "Widget32" },
+		"unknown parent":      func(o *mib_parser.ObjectType) { o.Assignment.Parent = "acmeNothing" },
+		"is already assigned": func(o *mib_parser.ObjectType) { o.Assignment.SubIDs = []int{1} },
+		"mutually exclusive":  func(o *mib_parser.ObjectType) { o.Index = []string{"widgetIndex"}; o.Augments = "widgetEntry" },
+		"IMPLIED requires":    func(o *mib_parser.ObjectType) { o.Implied = true },
+		"OID value must be":   func(o *mib_parser.ObjectType) { o.Assignment.SubIDs = []int{-1} },
+		"OID value needs":     func(o *mib_parser.ObjectType) { o.Assignment = mib_parser.OIDAssignment{} },
+	}
+	for want, mutate := range cases {
+		o := valid
+		mutate(&o)
+		err := b.AddObjectType(o)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v", want, err)
+		}
+	}
+	if err := b.AddObjectType(valid); err != nil {
+		t.Fatalf("valid object rejected: %v", err)
+	}
+
+	if err := b.AddTable(
+		mib_parser.ObjectType{Name: "gadgetTable", Status: "current", Description: "x",
+			Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetObjects", SubIDs: []int{3}}},
+		mib_parser.ObjectType{Name: "gadgetEntry", Status: "current", Description: "x", Index: []string{"gadgetIndex"}},
+		mib_parser.ObjectType{Name: "gadgetIndex", Syntax: "Integer32", Access: "not-accessible", Status: "current", Description: "x"},
+		mib_parser.ObjectType{Name: "gadgetName", Syntax: "NoSuchType", Access: "read-only", Status: "current", Description: "x"},
+	); err == nil {
+		t.Fatalf("table with an unknown column type accepted")
+	}
+	if _, err := b.Build(); err != nil {
+		t.Fatalf("rejected table left the module broken: %v", err)
+	}
+	if err := b.AddNode("gadgetTable", "acmeWidgetObjects", 3); err != nil {
+		t.Errorf("rejected table was not rolled back: %v", err)
+	}
+
+	if err := b.SetModuleIdentity(mib_parser.ModuleIdentity{Name: "again"}); err == nil {
+		t.Errorf("second MODULE-IDENTITY accepted")
+	}
+	if err := b.AddObjectGroup(mib_parser.ObjectGroup{
+		Name: "brokenGroup", Objects: []string{"noSuchObject"}, Status: "current", Description: "x",
+		Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetConformance", SubIDs: []int{4}},
+	}); err != nil {
+		t.Fatalf("AddObjectGroup failed: %v", err)
+	}
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), "unknown object noSuchObject") {
+		t.Errorf("Build did not report the unknown group member: %v", err)
+	}
+}
+
+func TestBuilderModuleIdentity(t *testing.T) {
+	b, err := mib_parser.NewModuleBuilder("ACME-MIB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mi := mib_parser.ModuleIdentity{
+		Name: "acmeMIB", LastUpdated: "2026-01-01", Organization: "ACME", ContactInfo: "noc",
+		Description: "x", Assignment: mib_parser.OIDAssignment{Parent: "enterprises", SubIDs: []int{1}},
+	}
+	if err := b.SetModuleIdentity(mi); err == nil || !strings.Contains(err.Error(), "LAST-UPDATED") {
+		t.Errorf("bad LAST-UPDATED accepted: %v", err)
+	}
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), "no MODULE-IDENTITY") {
+		t.Errorf("module without MODULE-IDENTITY built: %v", err)
+	}
+	if _, err := mib_parser.NewModuleBuilder("acme-mib"); err == nil {
+		t.Errorf("lower-case module name accepted")
+	}
+}
+
+func TestValidDate(t *testing.T) {
+	for s, want := range map[string]bool{
+		"202601010000Z": true,
+		"9901010000Z":   true,
+		"202402290000Z": true,
+		"2026-01-01":    false,
+		"202601010000":  false,
+		"20260101000Z":  false,
+		"202699990000Z": false,
+		"202602300000Z": false,
+		"202601012400Z": false,
+		"202601010060Z": false,
+		"9913010000Z":   false,
+		"":              false,
+	} {
+		if got := mib_parser.ValidDate(s); got != want {
+			t.Errorf("ValidDate(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
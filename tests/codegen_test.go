@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/codegen"
+)
+
+func TestGenerateIfMib(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, _ := reg.Module("IF-MIB")
+	src, err := codegen.Generate(mod, codegen.Options{Registry: reg})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	typeCheck(t, "ifmib.go", src)
+	out := collapseBlanks(string(src))
+	for _, want := range []string{
+		"package ifmib",
+		`IfIndexOID OID = "1.3.6.1.2.1.2.2.1.1"`,
+		"IfOperStatusUp IfOperStatus = 1",
+		"func (v IfOperStatus) String() string",
+		"type IfEntry struct",
+		"IfDescr string",
+		"IfType IANAifType",
+		"func DecodeIfTable(vbs []VarBind) ([]*IfEntry, error)",
+		"if r.IfIndex, arcs, err = indexInt32(arcs); err != nil",
+	} {
+		if !strings.Contains(out, collapseBlanks(want)) {
+			t.Errorf("generated code lacks %q", want)
+		}
+	}
+	// ifXEntry AUGMENTS ifEntry, so its rows carry ifIndex as well.
+	if !strings.Contains(out, "Instance string\n IfIndex int32\n IfName string") {
+		t.Errorf("IfXEntry lacks the augmented index")
+	}
+}
+
+func TestGenerateBits(t *testing.T) {
+	b := newExampleBuilder(t)
+	if err := b.AddObjectType(mib_parser.ObjectType{
+		Name: "widgetFeatures", Syntax: "BITS { fast(0), quiet(9) }", Access: "read-only",
+		Status: "current", Description: "Supported features.",
+		Assignment: mib_parser.OIDAssignment{Parent: "acmeWidgetObjects", SubIDs: []int{2}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	src, err := codegen.Generate(mod, codegen.Options{Package: "widgets"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	typeCheck(t, "widgets.go", src)
+	out := collapseBlanks(string(src))
+	for _, want := range []string{
+		"package widgets",
+		"WidgetFeaturesQuiet WidgetFeatures = 1 << 9",
+		"func WidgetFeaturesFromBytes(b []byte) WidgetFeatures",
+		"WidgetStateBusy WidgetState = 2",
+		// DisplayString is imported and no registry was given.
+		"WidgetName any",
+	} {
+		if !strings.Contains(out, collapseBlanks(want)) {
+			t.Errorf("generated code lacks %q", want)
+		}
+	}
+	if got := codegen.PackageName("SNMPv2-MIB"); got != "snmpv2mib" {
+		t.Errorf("PackageName = %q", got)
+	}
+}
+
+// TestGenerateTypeChecks generates code for every bundled module, which
+// between them use textual conventions, augmenting tables, InetAddress and
+// IMPLIED indexes, and type-checks it.
+func TestGenerateTypeChecks(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	for _, mod := range reg.Modules() {
+		t.Run(mod.Name, func(t *testing.T) {
+			src, err := codegen.Generate(mod, codegen.Options{Registry: reg})
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			typeCheck(t, codegen.PackageName(mod.Name)+".go", src)
+		})
+	}
+}
+
+// typeCheck parses and type-checks generated code, importing the standard
+// library from source.
+func typeCheck(t *testing.T, name string, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v", err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(f.Name.Name, fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("generated code does not type-check: %v", err)
+	}
+}
+
+// collapseBlanks folds gofmt's column alignment into single spaces.
+func collapseBlanks(s string) string {
+	return regexp.MustCompile(`[ \t]+`).ReplaceAllString(s, " ")
+}
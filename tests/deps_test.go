@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const depsTestMIBA = `ACME-DEPS-A-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, Integer32, Counter32, enterprises, zeroDotZero
+        FROM SNMPv2-SMI
+    DisplayString
+        FROM SNMPv2-TC
+    depsB
+        FROM ACME-DEPS-B-MIB
+    VendorString
+        FROM VENDOR-TC-MIB;
+
+depsA OBJECT IDENTIFIER ::= { enterprises 99996 }
+
+depsAName OBJECT-TYPE
+    SYNTAX      VendorString
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The vendor's name for the device."
+    ::= { depsA 1 }
+
+depsALoad OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Current load."
+    ::= { depsB 1 }
+
+depsAType OBJECT-TYPE
+    SYNTAX      OBJECT IDENTIFIER
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The device type."
+    DEFVAL      { zeroDotZero }
+    ::= { depsA 2 }
+
+END
+`
+
+const depsTestMIBB = `ACME-DEPS-B-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises
+        FROM SNMPv2-SMI
+    depsA
+        FROM ACME-DEPS-A-MIB;
+
+depsB OBJECT IDENTIFIER ::= { enterprises 99997 }
+depsBExtra OBJECT IDENTIFIER ::= { depsA 3 }
+
+END
+`
+
+func TestDependencyGraph(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	for _, src := range []string{depsTestMIBA, depsTestMIBB} {
+		mod, err := mib_parser.ParseMIB([]byte(src))
+		if err != nil {
+			t.Fatalf("Failed to parse test MIB: %v", err)
+		}
+		if err := reg.Add(mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg.Resolve()
+	g := reg.DependencyGraph()
+
+	if len(g.Modules) != 14 || len(g.LoadOrder) != 14 {
+		t.Errorf("graph has %d modules and a load order of %d, want 14", len(g.Modules), len(g.LoadOrder))
+	}
+	wantCycles := [][]string{{"ACME-DEPS-A-MIB", "ACME-DEPS-B-MIB"}}
+	if !reflect.DeepEqual(g.Cycles, wantCycles) {
+		t.Errorf("Cycles = %v, want %v", g.Cycles, wantCycles)
+	}
+	wantMissing := []mib_parser.MissingModule{{Module: "VENDOR-TC-MIB", ImportedBy: []string{"ACME-DEPS-A-MIB"}}}
+	if !reflect.DeepEqual(g.Missing, wantMissing) {
+		t.Errorf("Missing = %+v, want %+v", g.Missing, wantMissing)
+	}
+
+	var unused []string
+	for _, u := range g.UnusedImports() {
+		unused = append(unused, u.String())
+	}
+	// Imports used only inside MACRO bodies, like SNMPv2-CONF's ObjectName,
+	// count as used. The bundled SNMPv2-TC has its MACRO removed, so its
+	// ObjectSyntax import really is unused.
+	wantUnused := []string{
+		"ACME-DEPS-A-MIB: Counter32 imported from SNMPv2-SMI is not used",
+		"ACME-DEPS-A-MIB: DisplayString imported from SNMPv2-TC is not used",
+		"SNMPv2-TC: ObjectSyntax imported from SNMPv2-SMI is not used",
+	}
+	if !reflect.DeepEqual(unused, wantUnused) {
+		t.Errorf("UnusedImports() =\n%v\nwant\n%v", unused, wantUnused)
+	}
+
+	// Every module comes after the modules it imports, except within a cycle.
+	pos := map[string]int{}
+	for i, m := range g.LoadOrder {
+		pos[m] = i
+	}
+	for _, e := range g.Edges {
+		to, loaded := pos[e.To]
+		if loaded && e.From != "ACME-DEPS-A-MIB" && e.From != "ACME-DEPS-B-MIB" && pos[e.From] < to {
+			t.Errorf("LoadOrder has %s before its import %s", e.From, e.To)
+		}
+	}
+	if g.LoadOrder[0] != "SNMPv2-SMI" {
+		t.Errorf("LoadOrder starts with %s, want SNMPv2-SMI", g.LoadOrder[0])
+	}
+	if a, b := pos["ACME-DEPS-A-MIB"], pos["ACME-DEPS-B-MIB"]; b != a+1 {
+		t.Errorf("cycle members are at %d and %d of the load order, want them together", a, b)
+	}
+
+	wantRequires := []string{"IANAifType-MIB", "IF-MIB", "INET-ADDRESS-MIB", "SNMPv2-CONF", "SNMPv2-MIB", "SNMPv2-SMI", "SNMPv2-TC"}
+	if got := g.Requires("IP-MIB"); !reflect.DeepEqual(got, wantRequires) {
+		t.Errorf("Requires(IP-MIB) = %v, want %v", got, wantRequires)
+	}
+	wantRequiredBy := []string{"IF-MIB", "IP-MIB"}
+	if got := g.RequiredBy("IANAifType-MIB"); !reflect.DeepEqual(got, wantRequiredBy) {
+		t.Errorf("RequiredBy(IANAifType-MIB) = %v, want %v", got, wantRequiredBy)
+	}
+	if got := g.Requires("ACME-DEPS-B-MIB"); !slices.Contains(got, "ACME-DEPS-B-MIB") || !slices.Contains(got, "VENDOR-TC-MIB") {
+		t.Errorf("Requires(ACME-DEPS-B-MIB) = %v, want the cycle and the missing module", got)
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph imports {",
+		`"VENDOR-TC-MIB" [style=dashed];`,
+		`"ACME-DEPS-A-MIB" -> "ACME-DEPS-B-MIB" [label=1, color=red];`,
+		`"ACME-DEPS-A-MIB" -> "SNMPv2-TC" [label=1, style=dotted];`,
+		`"IP-MIB" -> "IF-MIB" [label=1];`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("DOT output lacks %s:\n%s", want, dot.String())
+		}
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back mib_parser.DependencyGraph
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&back, g) {
+		t.Errorf("JSON round trip changed the graph:\n%s", data)
+	}
+	if !bytes.Contains(data, []byte(`{"from":"ACME-DEPS-A-MIB","to":"SNMPv2-SMI","symbols":["OBJECT-TYPE","Integer32","Counter32","enterprises","zeroDotZero"],"unused":["Counter32"]}`)) {
+		t.Errorf("JSON output lacks the SNMPv2-SMI edge of ACME-DEPS-A-MIB:\n%s", data)
+	}
+}
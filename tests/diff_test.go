@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const diffOldMIB = `DIFF-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS MODULE-IDENTITY, OBJECT-TYPE, Integer32, enterprises FROM SNMPv2-SMI;
+
+diffTestMIB MODULE-IDENTITY
+    LAST-UPDATED "202501010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "noc"
+    DESCRIPTION  "Diff test."
+    ::= { enterprises 99997 }
+
+diffState OBJECT-TYPE
+    SYNTAX      INTEGER { up(1), down(2), testing(3) }
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "State."
+    ::= { diffTestMIB 1 }
+
+diffLimit OBJECT-TYPE
+    SYNTAX      Integer32 (1..100)
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Limit."
+    ::= { diffTestMIB 2 }
+
+diffName OBJECT-TYPE
+    SYNTAX      OCTET STRING (SIZE (0..32))
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Name."
+    ::= { diffTestMIB 3 }
+
+diffOld OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Old."
+    ::= { diffTestMIB 4 }
+
+diffGone OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Gone."
+    ::= { diffTestMIB 5 }
+
+diffMode OBJECT-TYPE
+    SYNTAX      INTEGER { on(1), off(2) }
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Mode."
+    ::= { diffTestMIB 6 }
+
+END
+`
+
+const diffNewMIB = `DIFF-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS MODULE-IDENTITY, OBJECT-TYPE, Integer32, enterprises FROM SNMPv2-SMI;
+
+diffTestMIB MODULE-IDENTITY
+    LAST-UPDATED "202601010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "noc"
+    DESCRIPTION  "Diff test."
+    ::= { enterprises 99997 }
+
+diffState OBJECT-TYPE
+    SYNTAX      INTEGER { up(1), down(2), testing(3), unknown(4) }
+    MAX-ACCESS  read-only
+    STATUS      deprecated
+    DESCRIPTION "State."
+    ::= { diffTestMIB 1 }
+
+diffLimit OBJECT-TYPE
+    SYNTAX      Integer32 (1..1000)
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Limit."
+    ::= { diffTestMIB 2 }
+
+diffName OBJECT-TYPE
+    SYNTAX      OCTET STRING (SIZE (0..16))
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Name."
+    ::= { diffTestMIB 3 }
+
+diffRenamed OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Old."
+    ::= { diffTestMIB 4 }
+
+diffMode OBJECT-TYPE
+    SYNTAX      INTEGER { on(1) }
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Mode."
+    ::= { diffTestMIB 6 }
+
+diffNew OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "New."
+    ::= { diffTestMIB 7 }
+
+END
+`
+
+func TestDiffModules(t *testing.T) {
+	oldMod, err := mib_parser.ParseMIB([]byte(diffOldMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse old MIB: %v", err)
+	}
+	newMod, err := mib_parser.ParseMIB([]byte(diffNewMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse new MIB: %v", err)
+	}
+	d := mib_parser.DiffModules(oldMod, newMod)
+	got := map[string]bool{}
+	for _, c := range d.Changes {
+		got[string(c.Kind)+"/"+c.Name] = c.Breaking
+	}
+	want := map[string]bool{
+		"last-updated-changed/diffTestMIB": false,
+		"enum-changed/diffState":           false,
+		"status-changed/diffState":         false,
+		"syntax-widened/diffLimit":         true,
+		"access-changed/diffLimit":         true,
+		"syntax-narrowed/diffName":         true,
+		"renamed/diffRenamed":              true,
+		"removed/diffGone":                 true,
+		"enum-changed/diffMode":            true,
+		"added/diffNew":                    false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected changes:\n got  %v\n want %v", got, want)
+	}
+	if !d.Breaking() {
+		t.Errorf("diff should be breaking")
+	}
+
+	src, err := os.ReadFile(filepath.Join("..", "mibs", "IF-MIB.MIB"))
+	if err != nil {
+		t.Fatalf("Failed to read IF-MIB: %v", err)
+	}
+	ifMib, err := mib_parser.ParseMIB(src)
+	if err != nil {
+		t.Fatalf("Failed to parse IF-MIB: %v", err)
+	}
+	if d := mib_parser.DiffModules(ifMib, ifMib); len(d.Changes) != 0 {
+		t.Errorf("diff of IF-MIB with itself reported %v", d.Changes)
+	}
+	reformatted, err := mib_parser.FormatMIB(ifMib)
+	if err != nil {
+		t.Fatalf("FormatMIB failed: %v", err)
+	}
+	again, err := mib_parser.ParseMIB(reformatted)
+	if err != nil {
+		t.Fatalf("Failed to reparse IF-MIB: %v", err)
+	}
+	if d := mib_parser.DiffModules(ifMib, again); len(d.Changes) != 0 {
+		t.Errorf("reformatting IF-MIB reported changes: %v", d.Changes)
+	}
+}
+
+func TestParseSyntax(t *testing.T) {
+	for in, want := range map[string]mib_parser.Syntax{
+		"INTEGER { up(1), down(2) }": {
+			Base:         "INTEGER",
+			NamedNumbers: []mib_parser.NamedNumber{{Name: "up", Value: 1}, {Name: "down", Value: 2}},
+		},
+		"OCTET STRING ( SIZE ( 0 .. 255 ) )": {Base: "OCTET STRING", Sizes: []mib_parser.Range{{Min: 0, Max: 255}}},
+		"Integer32 (-1..10 | 20)":            {Base: "Integer32", Ranges: []mib_parser.Range{{Min: -1, Max: 10}, {Min: 20, Max: 20}}},
+		"OCTET STRING (SIZE(6))":             {Base: "OCTET STRING", Sizes: []mib_parser.Range{{Min: 6, Max: 6}}},
+		"SEQUENCE OF IfEntry":                {Base: "SEQUENCE OF IfEntry"},
+	} {
+		if got := mib_parser.ParseSyntax(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseSyntax(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+	if s := mib_parser.ParseSyntax("BITS { a(0), b(1) }"); !strings.EqualFold(s.Base, "BITS") || len(s.NamedNumbers) != 2 {
+		t.Errorf("BITS not parsed: %+v", s)
+	}
+	for in, want := range map[string]string{
+		"INTEGER { up ( 1 ) , down ( 2 ) }":  "INTEGER { up(1), down(2) }",
+		"OCTET STRING ( SIZE ( 0 .. 255 ) )": "OCTET STRING (SIZE (0..255))",
+		"Integer32 (MIN..-1 | 20)":           "Integer32 (MIN..-1 | 20)",
+	} {
+		if got := mib_parser.ParseSyntax(in).String(); got != want {
+			t.Errorf("ParseSyntax(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+
+	mod, err := mib_parser.ParseMIB([]byte(`WIDE-MIB DEFINITIONS ::= BEGIN
+IMPORTS OBJECT-TYPE, Counter64, Integer32, enterprises FROM SNMPv2-SMI;
+wide OBJECT IDENTIFIER ::= { enterprises 99992 }
+wideOctets OBJECT-TYPE
+    SYNTAX      Counter64 (0..18446744073709551615)
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Octets."
+    ::= { wide 1 }
+wideOffset OBJECT-TYPE
+    SYNTAX      Integer32 (-2147483648..2147483647)
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Offset."
+    ::= { wide 2 }
+END
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	for name, want := range map[string]mib_parser.Range{
+		"wideOctets": {Min: 0, Max: math.MaxInt64},
+		"wideOffset": {Min: math.MinInt32, Max: math.MaxInt32},
+	} {
+		obj := mod.ObjectsByName[name]
+		if got := mib_parser.ParseSyntax(obj.Syntax).Ranges; !reflect.DeepEqual(got, []mib_parser.Range{want}) {
+			t.Errorf("ranges of %s (SYNTAX %q) = %+v, want %+v", name, obj.Syntax, got, want)
+		}
+	}
+	if syn := mod.ObjectsByName["wideOctets"].Syntax; !strings.Contains(syn, "18446744073709551615") {
+		t.Errorf("SYNTAX of wideOctets = %q, want the upper bound as written", syn)
+	}
+}
@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	entries, err := os.ReadDir(filepath.Join("..", "mibs"))
+	if err != nil {
+		t.Fatalf("Failed to list mibs directory: %v", err)
+	}
+	sources := map[string][]byte{
+		// SMIv1 traps are written back as TRAP-TYPE.
+		"trapTestMIB": []byte(trapTestMIB),
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.ToLower(filepath.Ext(name)) != ".mib" {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join("..", "mibs", name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		sources[name] = src
+	}
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			orig, err := mib_parser.ParseMIB(src)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", name, err)
+			}
+			out, err := mib_parser.FormatMIB(orig)
+			if err != nil {
+				t.Fatalf("FormatMIB failed for %s: %v", name, err)
+			}
+			again, err := mib_parser.ParseMIB(out)
+			if err != nil {
+				t.Fatalf("Failed to reparse formatted %s: %v\n%s", name, err, out)
+			}
+			a, b := normalizeModule(orig), normalizeModule(again)
+			if !reflect.DeepEqual(a, b) {
+				t.Fatalf("round trip of %s is not equivalent:\noriginal: %+v\nreparsed: %+v", name, a, b)
+			}
+			out2, err := mib_parser.FormatMIB(again)
+			if err != nil {
+				t.Fatalf("FormatMIB failed on reparsed %s: %v", name, err)
+			}
+			if string(out) != string(out2) {
+				t.Errorf("formatting %s is not idempotent", name)
+			}
+		})
+	}
+}
+
+func TestFormatLayout(t *testing.T) {
+	mib, err := os.ReadFile(filepath.Join("..", "mibs", "IF-MIB.MIB"))
+	if err != nil {
+		t.Fatalf("Failed to read IF-MIB: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB(mib)
+	if err != nil {
+		t.Fatalf("Failed to parse IF-MIB: %v", err)
+	}
+	out, err := mib_parser.FormatMIB(mod)
+	if err != nil {
+		t.Fatalf("FormatMIB failed: %v", err)
+	}
+	text := string(out)
+	for _, want := range []string{
+		"IMPORTS\n    IANAifType\n        FROM IANAifType-MIB\n",
+		"IfEntry ::= SEQUENCE {\n    ifIndex            InterfaceIndex,\n",
+		"    SYNTAX       INTEGER { up(1), down(2), testing(3) }\n",
+		"    AUGMENTS     { ifEntry }\n",
+		"    ::= { snmpTraps 3 }\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatted IF-MIB is missing %q", want)
+		}
+	}
+	if !strings.HasPrefix(text, "IF-MIB DEFINITIONS ::= BEGIN\n") || !strings.HasSuffix(text, "\nEND\n") {
+		t.Errorf("formatted IF-MIB has unexpected module header or trailer")
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "\"") && len(line) > 80 {
+			t.Errorf("DESCRIPTION line not wrapped: %q", line)
+		}
+	}
+}
+
+// normalizeModule flattens a module into comparable values: text fields are
+// whitespace-collapsed (the formatter re-wraps them), source positions are
+// dropped and imports are merged per source module. Row types, which the
+// formatter regenerates in OID order, are compared by name only. Every other
+// exported field is compared as is, so that new fields are covered.
+func normalizeModule(m *mib_parser.Module) map[string]any {
+	out := map[string]any{"name": m.Name}
+	imports := map[string][]string{}
+	for _, imp := range m.Imports {
+		imports[imp.Module] = append(imports[imp.Module], imp.Symbols...)
+	}
+	for k := range imports {
+		sort.Strings(imports[k])
+	}
+	out["imports"] = imports
+	for name, n := range m.ObjectIdentifiers {
+		c := *n
+		c.Pos = mib_parser.Position{}
+		out["oid:"+name] = c
+	}
+	for name, o := range m.ObjectsByName {
+		c := *o
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		out["obj:"+name] = c
+	}
+	if mi := m.ModuleIdentity; mi != nil {
+		c := *mi
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Organization = collapse(c.Organization)
+		c.ContactInfo = collapse(c.ContactInfo)
+		c.Revisions = nil
+		for _, r := range mi.Revisions {
+			c.Revisions = append(c.Revisions, mib_parser.Revision{Date: r.Date, Description: collapse(r.Description)})
+		}
+		out["mi"] = c
+	}
+	for name, o := range m.ObjectIdentities {
+		c := *o
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		out["oi:"+name] = c
+	}
+	for name, tc := range m.TextualConventions {
+		c := *tc
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		out["tc:"+name] = c
+	}
+	for name, n := range m.NotificationTypes {
+		c := *n
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		out["nt:"+name] = c
+	}
+	for name, g := range m.ObjectGroups {
+		c := *g
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		out["og:"+name] = c
+	}
+	for name, g := range m.NotificationGroups {
+		c := *g
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		out["ng:"+name] = c
+	}
+	for name, mc := range m.ModuleCompliances {
+		c := *mc
+		c.Pos = mib_parser.Position{}
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		c.Modules = nil
+		for _, cm := range mc.Modules {
+			n := cm
+			n.Groups, n.Objects = nil, nil
+			for _, g := range cm.Groups {
+				g.Description = collapse(g.Description)
+				n.Groups = append(n.Groups, g)
+			}
+			for _, o := range cm.Objects {
+				o.Description = collapse(o.Description)
+				n.Objects = append(n.Objects, o)
+			}
+			c.Modules = append(c.Modules, n)
+		}
+		out["mc:"+name] = c
+	}
+	for name, ac := range m.AgentCapabilities {
+		c := *ac
+		c.Pos = mib_parser.Position{}
+		c.ProductRelease = collapse(c.ProductRelease)
+		c.Description = collapse(c.Description)
+		c.Reference = collapse(c.Reference)
+		c.Supports = nil
+		for _, cm := range ac.Supports {
+			n := cm
+			n.Variations = nil
+			for _, v := range cm.Variations {
+				v.Description = collapse(v.Description)
+				n.Variations = append(n.Variations, v)
+			}
+			c.Supports = append(c.Supports, n)
+		}
+		out["ac:"+name] = c
+	}
+
+	rowTypes := map[string]bool{}
+	for _, o := range m.ObjectsByName {
+		if table, ok := m.ObjectsByName[o.Assignment.Parent]; ok && table.Syntax == "SEQUENCE OF "+o.Syntax {
+			rowTypes[o.Syntax] = true
+		}
+	}
+	out["types"] = slices.Sorted(slices.Values(m.Types))
+	text := map[string]string{}
+	for name, t := range m.DefinitionText {
+		if !rowTypes[name] {
+			text[name] = t
+		}
+	}
+	out["definitiontext"] = text
+
+	handled := map[string]bool{
+		"Name": true, "Imports": true, "ObjectIdentifiers": true, "ObjectsByName": true,
+		"ModuleIdentity": true, "ObjectIdentities": true, "TextualConventions": true,
+		"NotificationTypes": true, "ObjectGroups": true, "NotificationGroups": true,
+		"ModuleCompliances": true, "AgentCapabilities": true, "Types": true, "DefinitionText": true,
+	}
+	v := reflect.ValueOf(*m)
+	for i := range v.NumField() {
+		if f := v.Type().Field(i); f.IsExported() && !handled[f.Name] {
+			out[f.Name] = v.Field(i).Interface()
+		}
+	}
+	return out
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const importsTestMIB = `ACME-IMP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, Integer32, enterprises
+        FROM SNMPv2-SMI
+    DisplayString, InterfaceIndex, acmeNoSuchThing
+        FROM SNMPv2-TC;
+
+impRoot OBJECT IDENTIFIER ::= { enterprises 99998 }
+
+impIfIndex OBJECT-TYPE
+    SYNTAX      InterfaceIndex
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The interface."
+    ::= { impRoot 1 }
+
+impName OBJECT-TYPE
+    SYNTAX      DisplayString
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The name."
+    ::= { impRoot 2 }
+
+impPackets OBJECT-TYPE
+    SYNTAX      Counter32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Packets seen."
+    ::= { mib-2 99998 }
+
+impTable OBJECT-TYPE
+    SYNTAX      SEQUENCE OF ImpEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Per-interface settings."
+    ::= { impRoot 3 }
+
+impEntry OBJECT-TYPE
+    SYNTAX      ImpEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Settings of one interface."
+    INDEX       { ifIndex }
+    ::= { impTable 1 }
+
+ImpEntry ::= SEQUENCE {
+    impType     OBJECT IDENTIFIER,
+    impState    INTEGER
+}
+
+impType OBJECT-TYPE
+    SYNTAX      OBJECT IDENTIFIER
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The interface type."
+    DEFVAL      { zeroDotZero }
+    ::= { impEntry 1 }
+
+impState OBJECT-TYPE
+    SYNTAX      INTEGER { up(1), down(2) }
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "The configured state."
+    DEFVAL      { up }
+    ::= { impEntry 2 }
+
+impGroup OBJECT-GROUP
+    OBJECTS     { impIfIndex, impName, impPackets, impType, impState }
+    STATUS      current
+    DESCRIPTION "All objects."
+    ::= { impRoot 4 }
+
+END
+`
+
+const importsTestDupMIB = `ACME-IMP-DUP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, Integer32, enterprises
+        FROM SNMPv2-SMI;
+
+dupRoot OBJECT IDENTIFIER ::= { enterprises 99993 }
+
+ifIndex OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "A second ifIndex."
+    ::= { dupRoot 1 }
+
+END
+`
+
+func TestCheckImports(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if problems := reg.CheckImports(); len(problems) != 0 {
+		t.Errorf("CheckImports() on the bundled MIBs = %v, want none", problems)
+	}
+
+	smi, _ := reg.Module("SNMPv2-SMI")
+	if !slices.Contains(smi.Types, "Counter64") || !slices.Contains(smi.Macros, "OBJECT-TYPE") {
+		t.Errorf("SNMPv2-SMI types %v and macros %v lack Counter64 and OBJECT-TYPE", smi.Types, smi.Macros)
+	}
+
+	// FormatMIB writes type assignments and MACROs back, so the formatted
+	// modules define the same symbols. Row types follow their rows.
+	formatted := mib_parser.NewRegistry()
+	for _, mod := range reg.Modules() {
+		out, err := mib_parser.FormatMIB(mod)
+		if err != nil {
+			t.Fatalf("FormatMIB(%s) failed: %v", mod.Name, err)
+		}
+		again, err := mib_parser.ParseMIB(out)
+		if err != nil {
+			t.Fatalf("Failed to reparse formatted %s: %v", mod.Name, err)
+		}
+		if !reflect.DeepEqual(slices.Sorted(slices.Values(again.Types)), slices.Sorted(slices.Values(mod.Types))) || !reflect.DeepEqual(again.Macros, mod.Macros) {
+			t.Errorf("formatted %s has types %v and macros %v, want %v and %v", mod.Name, again.Types, again.Macros, mod.Types, mod.Macros)
+		}
+		if err := formatted.Add(again); err != nil {
+			t.Fatal(err)
+		}
+	}
+	formatted.Resolve()
+	if problems := formatted.CheckImports(); len(problems) != 0 {
+		t.Errorf("CheckImports() on the formatted MIBs = %v, want none", problems)
+	}
+
+	for _, src := range []string{importsTestMIB, importsTestDupMIB} {
+		mod, err := mib_parser.ParseMIB([]byte(src))
+		if err != nil {
+			t.Fatalf("Failed to parse test MIB: %v", err)
+		}
+		if err := reg.Add(mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg.Resolve()
+	if imp, _ := reg.Module("ACME-IMP-MIB"); !reflect.DeepEqual(imp.Types, []string{"ImpEntry"}) {
+		t.Errorf("Types = %v, want [ImpEntry]", imp.Types)
+	}
+
+	var got []string
+	for _, p := range reg.CheckImports() {
+		got = append(got, fmt.Sprintf("%s %s %s %s %v", p.Kind, p.Definition, p.Name, p.From, p.Candidates))
+	}
+	want := []string{
+		"unknown-symbol  InterfaceIndex SNMPv2-TC [IF-MIB]",
+		"unknown-symbol  acmeNoSuchThing SNMPv2-TC []",
+		"missing-import impPackets Counter32  [SNMPv2-SMI]",
+		"missing-import impPackets mib-2  [SNMPv2-SMI]",
+		"ambiguous impEntry ifIndex  [ACME-IMP-DUP-MIB IF-MIB]",
+		"missing-import impType zeroDotZero  [SNMPv2-SMI]",
+		"missing-import impGroup OBJECT-GROUP  [SNMPv2-CONF]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckImports() =\n%v\nwant\n%v", got, want)
+	}
+
+	problems := reg.CheckImports()
+	for _, tt := range []struct {
+		i    int
+		want string
+	}{
+		{0, "ACME-IMP-MIB: InterfaceIndex is imported from SNMPv2-TC, which does not define it; it is defined in IF-MIB [unknown-symbol]"},
+		{2, "ACME-IMP-MIB:24:1: impPackets uses Counter32, which is neither defined nor imported; import it from SNMPv2-SMI [missing-import]"},
+		{4, "ACME-IMP-MIB:38:1: impEntry uses ifIndex, which is not imported and is defined in ACME-IMP-DUP-MIB, IF-MIB [ambiguous]"},
+	} {
+		if s := problems[tt.i].String(); s != tt.want {
+			t.Errorf("problem %d = %q, want %q", tt.i, s, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	entries, err := os.ReadDir(filepath.Join("..", "mibs"))
+	if err != nil {
+		t.Fatalf("Failed to list mibs directory: %v", err)
+	}
+	for _, e := range entries {
+		t.Run(e.Name(), func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join("..", "mibs", e.Name()))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", e.Name(), err)
+			}
+			orig, err := mib_parser.ParseMIB(src)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", e.Name(), err)
+			}
+			data, err := mib_parser.MarshalModuleJSON(orig)
+			if err != nil {
+				t.Fatalf("MarshalModuleJSON failed: %v", err)
+			}
+			loaded, err := mib_parser.UnmarshalModuleJSON(data)
+			if err != nil {
+				t.Fatalf("UnmarshalModuleJSON failed: %v", err)
+			}
+			if !reflect.DeepEqual(orig, loaded) {
+				t.Errorf("JSON round trip of %s is not identical", e.Name())
+			}
+			again, err := mib_parser.MarshalModuleJSON(loaded)
+			if err != nil {
+				t.Fatalf("MarshalModuleJSON failed on loaded module: %v", err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("JSON output of %s is not deterministic", e.Name())
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("..", "mibs", "IF-MIB.MIB"))
+	if err != nil {
+		t.Fatalf("Failed to read IF-MIB: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB(src)
+	if err != nil {
+		t.Fatalf("Failed to parse IF-MIB: %v", err)
+	}
+	data, err := mib_parser.MarshalModuleJSON(mod)
+	if err != nil {
+		t.Fatalf("MarshalModuleJSON failed: %v", err)
+	}
+	var doc struct {
+		Schema      string `json:"schema"`
+		Version     int    `json:"version"`
+		Module      string `json:"module"`
+		Definitions map[string]struct {
+			Class    string `json:"class"`
+			OID      string `json:"oid"`
+			NodeType string `json:"nodetype"`
+			Syntax   struct {
+				Type        string `json:"type"`
+				Enumeration []struct {
+					Name  string `json:"name"`
+					Value int    `json:"value"`
+				} `json:"enumeration"`
+			} `json:"syntax"`
+			Indices []struct {
+				Object string `json:"object"`
+			} `json:"indices"`
+		} `json:"definitions"`
+		Tree []struct {
+			Name     string            `json:"name"`
+			Children []json.RawMessage `json:"children"`
+		} `json:"tree"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if doc.Schema != mib_parser.JSONSchemaName || doc.Version != mib_parser.JSONSchemaVersion || doc.Module != "IF-MIB" {
+		t.Errorf("unexpected header: %s v%d %s", doc.Schema, doc.Version, doc.Module)
+	}
+	ifIndex := doc.Definitions["ifIndex"]
+	if ifIndex.Class != "objecttype" || ifIndex.NodeType != "column" || ifIndex.OID != "1.3.6.1.2.1.2.2.1.1" || ifIndex.Syntax.Type != "InterfaceIndex" {
+		t.Errorf("unexpected ifIndex definition: %+v", ifIndex)
+	}
+	if entry := doc.Definitions["ifEntry"]; entry.NodeType != "row" || len(entry.Indices) != 1 || entry.Indices[0].Object != "ifIndex" {
+		t.Errorf("unexpected ifEntry definition: %+v", entry)
+	}
+	if status := doc.Definitions["ifOperStatus"].Syntax.Enumeration; len(status) != 7 || status[0].Name != "up" || status[0].Value != 1 {
+		t.Errorf("unexpected ifOperStatus enumeration: %+v", status)
+	}
+	if len(doc.Tree) == 0 {
+		t.Errorf("OID tree is empty")
+	}
+
+	newer := strings.Replace(string(data), `"version": 1`, `"version": 99`, 1)
+	if _, err := mib_parser.UnmarshalModuleJSON([]byte(newer)); err == nil {
+		t.Errorf("newer schema version accepted")
+	}
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/lint"
+)
+
+const lintTestMIB = `LINT-TEST-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Counter32, Integer32, enterprises
+        FROM SNMPv2-SMI
+    OBJECT-GROUP
+        FROM SNMPv2-CONF;
+
+lintTestMIB MODULE-IDENTITY
+    LAST-UPDATED "202601010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "noc"
+    DESCRIPTION  "Lint test module."
+    REVISION     "202501010000Z"
+    DESCRIPTION  "Older revision."
+    ::= { enterprises 99998 }
+
+lintObjects OBJECT IDENTIFIER ::= { lintTestMIB 1 }
+
+lintPackets OBJECT-TYPE
+    SYNTAX      Counter32
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Packets."
+    DEFVAL      { 0 }
+    ::= { lintObjects 1 }
+
+-- lint:ignore counter-defval
+lintDrops OBJECT-TYPE
+    SYNTAX      Counter32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Drops."
+    DEFVAL      { 0 }
+    ::= { lintObjects 2 }
+
+lintTable OBJECT-TYPE
+    SYNTAX      SEQUENCE OF LintEntry
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Table."
+    ::= { lintObjects 3 }
+
+lintEntry OBJECT-TYPE
+    SYNTAX      LintEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Row."
+    ::= { lintTable 1 }
+
+LintEntry ::= SEQUENCE { lintValue Integer32 }
+
+lintValue OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  not-accessible
+    STATUS      mandatory
+    DESCRIPTION "Value."
+    ::= { lintEntry 1 }
+
+lint-scalar OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Scalar."
+    ::= { lintObjects 4 }
+
+lintGroup OBJECT-GROUP
+    OBJECTS     { lintPackets, lintValue, lintMissing }
+    STATUS      current
+    DESCRIPTION "Group."
+    ::= { lintTestMIB 2 }
+
+END
+`
+
+func TestLintFindings(t *testing.T) {
+	src := []byte(lintTestMIB)
+	mod, err := mib_parser.ParseMIB(src)
+	if err != nil {
+		t.Fatalf("Failed to parse lint test MIB: %v", err)
+	}
+	findings := lint.Lint(mod, lint.Options{Suppress: lint.InlineSuppressions(src)})
+	got := map[string]lint.Finding{}
+	for _, f := range findings {
+		got[f.Rule+"/"+f.Name] = f
+	}
+	for _, want := range []string{
+		"revision-last-updated/lintTestMIB",
+		"counter-defval/lintPackets",
+		"counter-access/lintPackets",
+		"table-access/lintTable",
+		"row-index-missing/lintEntry",
+		"status-value/lintValue",
+		"not-accessible-misuse/lintValue",
+		"descriptor-hyphen/lint-scalar",
+		"object-not-in-group/lint-scalar",
+		"group-member-unknown/lintGroup",
+		"group-member-not-accessible/lintGroup",
+	} {
+		if _, ok := got[want]; !ok {
+			t.Errorf("missing finding %s", want)
+		}
+	}
+	if _, ok := got["counter-defval/lintDrops"]; ok {
+		t.Errorf("inline lint:ignore did not suppress counter-defval on lintDrops")
+	}
+	if f := got["counter-defval/lintPackets"]; f.Severity != lint.Error || f.Pos.Line != 20 {
+		t.Errorf("counter-defval finding = %+v, want error at line 20", f)
+	}
+	if f := got["counter-defval/lintPackets"]; !strings.HasPrefix(f.String(), "LINT-TEST-MIB:20:1: error: ") {
+		t.Errorf("unexpected finding format %q", f.String())
+	}
+
+	findings = lint.Lint(mod, lint.Options{
+		MinSeverity: lint.Error,
+		Suppress: []lint.Suppression{
+			{Rule: "counter-defval"},
+			{Rule: "status-value", Name: "lintValue"},
+		},
+	})
+	for _, f := range findings {
+		if f.Severity < lint.Error || f.Rule == "counter-defval" || f.Rule == "status-value" {
+			t.Errorf("finding not filtered: %s", f)
+		}
+	}
+}
+
+func TestLintBundledMibs(t *testing.T) {
+	entries, err := os.ReadDir(filepath.Join("..", "mibs"))
+	if err != nil {
+		t.Fatalf("Failed to list mibs directory: %v", err)
+	}
+	for _, e := range entries {
+		src, err := os.ReadFile(filepath.Join("..", "mibs", e.Name()))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", e.Name(), err)
+		}
+		mod, err := mib_parser.ParseMIB(src)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", e.Name(), err)
+		}
+		for _, f := range lint.Lint(mod, lint.Options{MinSeverity: lint.Error}) {
+			t.Errorf("%s: unexpected error finding: %s", e.Name(), f)
+		}
+	}
+}
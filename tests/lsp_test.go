@@ -0,0 +1,393 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Olian04/go-mib-parser/lsp"
+)
+
+const lspTestMIB = `ACME-LSP-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Counter32, enterprises
+        FROM SNMPv2-SMI
+    DisplayString
+        FROM SNMPv2-TC
+    ifIndex
+        FROM IF-MIB;
+
+acmeLspMIB MODULE-IDENTITY
+    LAST-UPDATED "202601010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "ops@acme.example"
+    DESCRIPTION "Test module for the language server."
+    ::= { enterprises 99995 }
+
+acmeLspObjects OBJECT IDENTIFIER ::= { acmeLspMIB 1 }
+
+acmeName OBJECT-TYPE
+    SYNTAX      DisplayString (SIZE (0..32))
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The name."
+    ::= { acmeLspObjects 1 }
+
+acmePackets OBJECT-TYPE
+    SYNTAX      Counter32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Packets seen on the interface named by ifIndex."
+    ::= { acmeLspObjects 2 }
+
+END
+`
+
+const lspTestExtMIB = `ACME-LSP-EXT-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    OBJECT-TYPE
+        FROM SNMPv2-SMI
+    acmeLspObjects, acmePackets
+        FROM ACME-LSP-MIB;
+
+acmeExtPackets OBJECT-TYPE
+    SYNTAX      INTEGER
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "More than acmePackets."
+    ::= { acmeLspObjects 3 }
+
+END
+`
+
+// lspClient speaks to a server over pipes. Messages from the server are
+// read as they come so that it never blocks writing them.
+type lspClient struct {
+	t     *testing.T
+	in    io.Writer
+	msgs  chan map[string]json.RawMessage
+	id    int
+	notes []json.RawMessage
+}
+
+func newLSPClient(t *testing.T, in io.Writer, out io.Reader) *lspClient {
+	c := &lspClient{t: t, in: in, msgs: make(chan map[string]json.RawMessage, 100)}
+	go func() {
+		defer close(c.msgs)
+		r := textproto.NewReader(bufio.NewReader(out))
+		for {
+			header, err := r.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(header.Get("Content-Length"))
+			body := make([]byte, n)
+			if _, err := io.ReadFull(r.R, body); err != nil {
+				return
+			}
+			var msg map[string]json.RawMessage
+			if json.Unmarshal(body, &msg) == nil {
+				c.msgs <- msg
+			}
+		}
+	}()
+	return c
+}
+
+func (c *lspClient) send(v any) {
+	c.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *lspClient) read() map[string]json.RawMessage {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.msgs:
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		return msg
+	case <-time.After(10 * time.Second):
+		c.t.Fatal("timed out waiting for the server")
+	}
+	return nil
+}
+
+func (c *lspClient) notify(method string, params any) {
+	c.t.Helper()
+	c.send(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// call sends a request and decodes its result into result. It returns
+// the error message of an error response.
+func (c *lspClient) call(method string, params, result any) string {
+	c.t.Helper()
+	c.id++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	for {
+		msg := c.read()
+		if _, ok := msg["id"]; !ok {
+			c.notes = append(c.notes, msg["params"])
+			continue
+		}
+		if e, ok := msg["error"]; ok {
+			var rpcErr lsp.Error
+			json.Unmarshal(e, &rpcErr)
+			return rpcErr.Message
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg["result"], result); err != nil {
+				c.t.Fatalf("%s: %v", method, err)
+			}
+		}
+		return ""
+	}
+}
+
+// diagnostics returns the next published diagnostics.
+func (c *lspClient) diagnostics() lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+	var raw json.RawMessage
+	if len(c.notes) > 0 {
+		raw, c.notes = c.notes[0], c.notes[1:]
+	} else {
+		raw = c.read()["params"]
+	}
+	var p lsp.PublishDiagnosticsParams
+	if err := json.Unmarshal(raw, &p); err != nil {
+		c.t.Fatal(err)
+	}
+	return p
+}
+
+// at returns the position of the n-th occurrence of word in text.
+func at(text, word string, n int) lsp.Position {
+	i := -1
+	for ; n > 0; n-- {
+		i += 1 + strings.Index(text[i+1:], word)
+	}
+	return lsp.Position{Line: strings.Count(text[:i], "\n"), Character: i - strings.LastIndex(text[:i], "\n") - 1}
+}
+
+func TestLSP(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "mibs", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := lsp.New(paths...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	dir := t.TempDir()
+	mainPath, extPath := filepath.Join(dir, "ACME-LSP-MIB.mib"), filepath.Join(dir, "ACME-LSP-EXT-MIB.mib")
+	os.WriteFile(mainPath, []byte(lspTestMIB), 0o644)
+	os.WriteFile(extPath, []byte(lspTestExtMIB), 0o644)
+	os.WriteFile(filepath.Join(dir, "README"), []byte("not a MIB"), 0o644)
+	uri := func(path string) string { return "file://" + filepath.ToSlash(path) }
+	mainURI, extURI := uri(mainPath), uri(extPath)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := newLSPClient(t, inW, outR)
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(inR, outW); outW.Close() }()
+
+	var init lsp.InitializeResult
+	c.call("initialize", lsp.InitializeParams{RootURI: uri(dir)}, &init)
+	if !init.Capabilities.HoverProvider || !init.Capabilities.RenameProvider.PrepareProvider || init.Capabilities.TextDocumentSync.Change != lsp.SyncFull {
+		t.Errorf("capabilities = %+v", init.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+	if _, ok := srv.Registry().Module("ACME-LSP-EXT-MIB"); !ok {
+		t.Fatalf("workspace module was not loaded")
+	}
+
+	// A parse error, then lint findings once it is fixed.
+	broken := strings.Replace(lspTestMIB, "::= { acmeLspObjects 2 }", "::= { acmeLspObjects 2", 1)
+	c.notify("textDocument/didOpen", lsp.DidOpenTextDocumentParams{TextDocument: lsp.TextDocumentItem{URI: mainURI, LanguageID: "mib", Version: 1, Text: broken}})
+	diags := c.diagnostics()
+	if diags.URI != mainURI || len(diags.Diagnostics) != 1 || diags.Diagnostics[0].Severity != lsp.SeverityError || diags.Diagnostics[0].Range.Start.Line == 0 {
+		t.Errorf("diagnostics of a broken MIB = %+v", diags)
+	}
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: mainURI, Version: 2},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: lspTestMIB}},
+	})
+	diags = c.diagnostics()
+	if diags.Version != 2 || len(diags.Diagnostics) != 3 {
+		t.Fatalf("diagnostics of the fixed MIB = %+v", diags)
+	}
+	if d := diags.Diagnostics[2]; d.Code != "object-not-in-group" || d.Source != "miblint" || d.Severity != lsp.SeverityWarning || d.Range.Start != at(lspTestMIB, "acmePackets", 1) {
+		t.Errorf("lint diagnostic = %+v", d)
+	}
+
+	doc := lsp.TextDocumentIdentifier{URI: mainURI}
+	pos := func(word string, n int) lsp.TextDocumentPositionParams {
+		return lsp.TextDocumentPositionParams{TextDocument: doc, Position: at(lspTestMIB, word, n)}
+	}
+
+	var loc lsp.Location
+	c.call("textDocument/definition", pos("DisplayString", 2), &loc)
+	if !strings.HasSuffix(loc.URI, "/mibs/SNMPV2-TC.mib") || loc.Range.Start.Line != 24 {
+		t.Errorf("definition of DisplayString = %+v", loc)
+	}
+	c.call("textDocument/definition", pos("acmeLspObjects", 3), &loc)
+	if loc.URI != mainURI || loc.Range.Start != at(lspTestMIB, "acmeLspObjects", 1) {
+		t.Errorf("definition of acmeLspObjects = %+v", loc)
+	}
+	c.call("textDocument/definition", lsp.TextDocumentPositionParams{TextDocument: lsp.TextDocumentIdentifier{URI: extURI}, Position: at(lspTestExtMIB, "acmePackets", 1)}, &loc)
+	if loc.URI != mainURI || loc.Range.Start != at(lspTestMIB, "acmePackets", 1) {
+		t.Errorf("definition of the imported acmePackets = %+v", loc)
+	}
+	c.call("textDocument/definition", pos("IF-MIB", 1), &loc)
+	if !strings.HasSuffix(loc.URI, "/mibs/IF-MIB.MIB") || loc.Range.Start.Line != 0 {
+		t.Errorf("definition of IF-MIB = %+v", loc)
+	}
+
+	for _, tt := range []struct {
+		pos  lsp.TextDocumentPositionParams
+		want []string
+	}{
+		{pos("acmeName", 1), []string{"**ACME-LSP-MIB::acmeName** OBJECT-TYPE", "OID: `1.3.6.1.4.1.99995.1.1`", "SYNTAX: `DisplayString (SIZE (0..32))`", "Resolves to: `OCTET STRING (SIZE (0..32))`", "DISPLAY-HINT: `255a`", "The name."}},
+		{pos("DisplayString", 2), []string{"**SNMPv2-TC::DisplayString** TEXTUAL-CONVENTION", "SYNTAX: `OCTET STRING (SIZE (0..255))`", "DISPLAY-HINT: `255a`"}},
+		{pos("ifIndex", 1), []string{"**IF-MIB::ifIndex** OBJECT-TYPE", "OID: `1.3.6.1.2.1.2.2.1.1`", "Resolves to: `Integer32 (1..2147483647)`"}},
+		{pos("IF-MIB", 1), []string{"**IF-MIB** module", "File: `IF-MIB.MIB`"}},
+	} {
+		var hover lsp.Hover
+		c.call("textDocument/hover", tt.pos, &hover)
+		for _, want := range tt.want {
+			if !strings.Contains(hover.Contents.Value, want) {
+				t.Errorf("hover at %v = %q, want %q in it", tt.pos.Position, hover.Contents.Value, want)
+			}
+		}
+	}
+	var hover *lsp.Hover
+	if c.call("textDocument/hover", pos("MAX-ACCESS", 1), &hover); hover != nil {
+		t.Errorf("hover over a keyword = %+v", hover)
+	}
+
+	complete := func(text string, p lsp.Position) map[string]lsp.CompletionItem {
+		t.Helper()
+		c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+			TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: mainURI, Version: 3},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: text}},
+		})
+		c.diagnostics()
+		var list lsp.CompletionList
+		c.call("textDocument/completion", lsp.TextDocumentPositionParams{TextDocument: doc, Position: p}, &list)
+		items := map[string]lsp.CompletionItem{}
+		for _, item := range list.Items {
+			items[item.Label] = item
+		}
+		return items
+	}
+	p := at(lspTestMIB, "SNMPv2-TC", 1)
+	p.Character += 3
+	items := complete(lspTestMIB, p)
+	if items["SNMPv2-TC"].Kind != lsp.CompletionModule || items["IF-MIB"].Detail != "IF-MIB.MIB" || len(items) != len(srv.Registry().Modules()) {
+		t.Errorf("completion after FROM = %v", items)
+	}
+	items = complete(lspTestMIB, at(lspTestMIB, "ifIndex", 1))
+	if items["ifInOctets"].Detail != "IF-MIB" || items["TruthValue"].Kind != lsp.CompletionClass {
+		t.Errorf("completion in IMPORTS = %d items, ifInOctets %+v", len(items), items["ifInOctets"])
+	}
+	if _, ok := items["acmeName"]; ok {
+		t.Errorf("completion in IMPORTS offers the module's own acmeName")
+	}
+	typing := strings.Replace(lspTestMIB, "SYNTAX      Counter32", "SYNTAX      Tr", 1)
+	p = at(typing, "Tr", 1)
+	p.Character += 2
+	items = complete(typing, p)
+	if items["acmePackets"].Kind != lsp.CompletionField || items["ifIndex"].Detail != "IF-MIB" || items["Counter32"].Label == "" {
+		t.Errorf("completion of defined and imported names = %v", items)
+	}
+	if doc := items["ifIndex"].Documentation; doc == nil || !strings.Contains(doc.Value, "1.3.6.1.2.1.2.2.1.1") {
+		t.Errorf("ifIndex completion documentation = %+v", doc)
+	}
+	if len(items["DisplayString"].AdditionalTextEdits) != 0 {
+		t.Errorf("imported DisplayString comes with edits %+v", items["DisplayString"].AdditionalTextEdits)
+	}
+	if edits := items["TruthValue"].AdditionalTextEdits; len(edits) != 1 || edits[0].NewText != ", TruthValue" || edits[0].Range.Start != at(typing, "\n        FROM SNMPv2-TC", 1) {
+		t.Errorf("TruthValue completion edits = %+v", edits)
+	}
+	if edits := items["InetAddress"].AdditionalTextEdits; len(edits) != 1 || edits[0].NewText != "\n    InetAddress\n        FROM INET-ADDRESS-MIB" || edits[0].Range.Start != at(typing, ";", 1) {
+		t.Errorf("InetAddress completion edits = %+v", edits)
+	}
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: mainURI, Version: 4},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: lspTestMIB}},
+	})
+	c.diagnostics()
+
+	var symbols []lsp.DocumentSymbol
+	c.call("textDocument/documentSymbol", lsp.DocumentSymbolParams{TextDocument: doc}, &symbols)
+	if len(symbols) != 1 || symbols[0].Name != "ACME-LSP-MIB" || len(symbols[0].Children) != 4 {
+		t.Fatalf("document symbols = %+v", symbols)
+	}
+	name := symbols[0].Children[2]
+	if name.Name != "acmeName" || name.Kind != lsp.SymbolField || name.Detail != "1.3.6.1.4.1.99995.1.1" ||
+		name.Range.Start != at(lspTestMIB, "acmeName", 1) || name.Range.End != at(lspTestMIB, "\n\nacmePackets", 1) {
+		t.Errorf("acmeName symbol = %+v", name)
+	}
+	if symbols[0].Children[0].Kind != lsp.SymbolModule || symbols[0].Children[1].Kind != lsp.SymbolNamespace {
+		t.Errorf("document symbols = %+v", symbols[0].Children)
+	}
+
+	var prep lsp.PrepareRenameResult
+	if msg := c.call("textDocument/prepareRename", pos("acmePackets", 1), &prep); msg != "" || prep.Placeholder != "acmePackets" {
+		t.Errorf("prepareRename = %+v, %s", prep, msg)
+	}
+	if msg := c.call("textDocument/prepareRename", pos("MAX-ACCESS", 1), nil); msg == "" {
+		t.Errorf("prepareRename of a keyword succeeded")
+	}
+	for _, bad := range []string{"acme_packets", "AcmePackets", "acmeName"} {
+		if msg := c.call("textDocument/rename", lsp.RenameParams{TextDocumentPositionParams: pos("acmePackets", 1), NewName: bad}, nil); msg == "" {
+			t.Errorf("rename to %s succeeded", bad)
+		}
+	}
+	var edit lsp.WorkspaceEdit
+	if msg := c.call("textDocument/rename", lsp.RenameParams{TextDocumentPositionParams: pos("acmePackets", 1), NewName: "acmeInPackets"}, &edit); msg != "" {
+		t.Fatal(msg)
+	}
+	if len(edit.Changes) != 2 || len(edit.Changes[mainURI]) != 1 || len(edit.Changes[extURI]) != 1 || edit.Changes[extURI][0].Range.Start != at(lspTestExtMIB, "acmePackets", 1) {
+		t.Errorf("rename edits = %+v", edit.Changes)
+	}
+	edit = lsp.WorkspaceEdit{}
+	c.call("textDocument/rename", lsp.RenameParams{TextDocumentPositionParams: pos("acmeLspObjects", 1), NewName: "acmeObjects"}, &edit)
+	if len(edit.Changes[mainURI]) != 3 || len(edit.Changes[extURI]) != 2 {
+		t.Errorf("rename edits = %+v", edit.Changes)
+	}
+
+	if msg := c.call("textDocument/formatting", struct{}{}, nil); !strings.Contains(msg, "not found") {
+		t.Errorf("unknown method returned %q", msg)
+	}
+	c.notify("textDocument/didClose", lsp.DidCloseTextDocumentParams{TextDocument: doc})
+	if diags := c.diagnostics(); diags.URI != mainURI || len(diags.Diagnostics) != 0 {
+		t.Errorf("diagnostics after close = %+v", diags)
+	}
+	if _, ok := srv.Registry().Module("ACME-LSP-MIB"); !ok {
+		t.Errorf("closing a workspace file dropped its module")
+	}
+
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned %v", err)
+	}
+}
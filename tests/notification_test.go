@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func TestDecodeNotification(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	varbind := func(name string, typ mib_parser.ValueType, data any) mib_parser.VarBind {
+		oid, err := reg.ResolveName(name)
+		if err != nil {
+			t.Fatalf("ResolveName(%q) failed: %v", name, err)
+		}
+		return mib_parser.VarBind{OID: oid, Value: mib_parser.NewValue(typ, data)}
+	}
+	linkDown := []int{1, 3, 6, 1, 6, 3, 1, 1, 5, 3}
+
+	n, err := reg.DecodeNotification([]mib_parser.VarBind{
+		varbind("sysUpTime.0", mib_parser.TypeTimeTicks, uint32(12345)),
+		varbind("snmpTrapOID.0", mib_parser.TypeObjectIdentifier, linkDown),
+		varbind("ifIndex.2", mib_parser.TypeInteger, 2),
+		varbind("ifDescr.2", mib_parser.TypeOctetString, "eth1"),
+		varbind("ifOperStatus.2", mib_parser.TypeInteger, 2),
+	})
+	if err != nil {
+		t.Fatalf("DecodeNotification failed: %v", err)
+	}
+	if n.Type == nil || n.Type.Name != "linkDown" || n.Module.Name != "IF-MIB" || n.Name.String() != "linkDown" {
+		t.Fatalf("notification not identified as IF-MIB::linkDown: %+v", n)
+	}
+	if !n.HasUptime || n.Uptime != 12345 {
+		t.Errorf("Uptime = %d, %v, want 12345", n.Uptime, n.HasUptime)
+	}
+	var objects []string
+	for _, vb := range n.Objects {
+		objects = append(objects, vb.Name.String()+" = "+vb.Text)
+	}
+	if want := []string{"ifIndex.2 = 2", "ifOperStatus.2 = down(2)"}; !reflect.DeepEqual(objects, want) {
+		t.Errorf("Objects = %q, want %q", objects, want)
+	}
+	if want := []string{"ifAdminStatus"}; !reflect.DeepEqual(n.Missing, want) {
+		t.Errorf("Missing = %q, want %q", n.Missing, want)
+	}
+	if len(n.Unexpected) != 1 || n.Unexpected[0].Name.String() != "ifDescr.2" || n.Unexpected[0].Text != "eth1" {
+		t.Errorf("Unexpected = %+v, want ifDescr.2", n.Unexpected)
+	}
+
+	n, err = reg.DecodeNotification([]mib_parser.VarBind{
+		varbind("snmpTrapOID.0", mib_parser.TypeObjectIdentifier, []int{1, 3, 6, 1, 4, 1, 99993, 0, 1}),
+		varbind("sysDescr.0", mib_parser.TypeOctetString, "box"),
+	})
+	if err != nil {
+		t.Fatalf("DecodeNotification of an unknown trap failed: %v", err)
+	}
+	if n.Type != nil || n.HasUptime || len(n.Unexpected) != 1 || n.Name.String() != "enterprises.99993.0.1" {
+		t.Errorf("unknown notification decoded as %+v", n)
+	}
+
+	if _, err := reg.DecodeNotification([]mib_parser.VarBind{varbind("sysUpTime.0", mib_parser.TypeTimeTicks, 1)}); err == nil {
+		t.Errorf("a varbind list without snmpTrapOID.0 should fail")
+	}
+	if _, err := reg.DecodeNotification([]mib_parser.VarBind{varbind("snmpTrapOID.0", mib_parser.TypeInteger, 1)}); err == nil {
+		t.Errorf("a non-OID snmpTrapOID.0 should fail")
+	}
+}
+
+// Well-known nodes such as enterprises resolve even when the module that
+// defines them is not loaded; naming one in OBJECTS must not panic.
+func TestDecodeNotificationWellKnownObject(t *testing.T) {
+	mod, err := mib_parser.ParseMIB([]byte(`ACME-NOTIFY-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    NOTIFICATION-TYPE, enterprises FROM SNMPv2-SMI;
+
+acmeNotify OBJECT IDENTIFIER ::= { enterprises 99994 }
+acmeTraps  OBJECT IDENTIFIER ::= { acmeNotify 0 }
+
+acmeEvent NOTIFICATION-TYPE
+    OBJECTS { enterprises }
+    STATUS current
+    DESCRIPTION "Names a node that is not an OBJECT-TYPE."
+    ::= { acmeTraps 1 }
+END
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	reg := mib_parser.NewRegistry()
+	if err := reg.Add(mod); err != nil {
+		t.Fatal(err)
+	}
+	n, err := reg.DecodeNotification([]mib_parser.VarBind{
+		{OID: mib_parser.OID{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, Value: mib_parser.NewValue(mib_parser.TypeObjectIdentifier, []int{1, 3, 6, 1, 4, 1, 99994, 0, 1})},
+	})
+	if err != nil {
+		t.Fatalf("DecodeNotification failed: %v", err)
+	}
+	if n.Type == nil || n.Type.Name != "acmeEvent" {
+		t.Fatalf("notification not identified as acmeEvent: %+v", n)
+	}
+	if want := []string{"enterprises"}; !reflect.DeepEqual(n.Missing, want) {
+		t.Errorf("Missing = %q, want %q", n.Missing, want)
+	}
+}
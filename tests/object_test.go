@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const objectTestMIB = `ACME-AGENT-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, OBJECT-IDENTITY, NOTIFICATION-TYPE,
+    Integer32, enterprises
+        FROM SNMPv2-SMI
+    OBJECT-GROUP, NOTIFICATION-GROUP, MODULE-COMPLIANCE, AGENT-CAPABILITIES
+        FROM SNMPv2-CONF;
+
+acmeAgentMIB MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Acme"
+    CONTACT-INFO "ops@acme.example"
+    DESCRIPTION  "Acme agent MIB."
+    ::= { enterprises 99993 }
+
+acmeObjects OBJECT IDENTIFIER ::= { acmeAgentMIB 1 }
+
+acmeProducts OBJECT-IDENTITY
+    STATUS      current
+    DESCRIPTION "Product registrations."
+    ::= { acmeAgentMIB 2 }
+
+acmeLoad OBJECT-TYPE
+    SYNTAX      Integer32 (0..100)
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Current load."
+    ::= { acmeObjects 1 }
+
+acmeOldLoad OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      deprecated
+    DESCRIPTION "Load as reported by older agents."
+    ::= { acmeObjects 2 }
+
+acmeNotifications OBJECT IDENTIFIER ::= { acmeAgentMIB 0 }
+
+acmeOverload NOTIFICATION-TYPE
+    OBJECTS     { acmeLoad }
+    STATUS      current
+    DESCRIPTION "The agent is overloaded."
+    ::= { acmeNotifications 1 }
+
+acmeConformance OBJECT IDENTIFIER ::= { acmeAgentMIB 3 }
+
+acmeLoadGroup OBJECT-GROUP
+    OBJECTS     { acmeLoad, acmeOldLoad }
+    STATUS      current
+    DESCRIPTION "Load objects."
+    ::= { acmeConformance 1 }
+
+acmeNotificationGroup NOTIFICATION-GROUP
+    NOTIFICATIONS { acmeOverload }
+    STATUS      current
+    DESCRIPTION "Load notifications."
+    ::= { acmeConformance 2 }
+
+acmeCompliance MODULE-COMPLIANCE
+    STATUS      current
+    DESCRIPTION "Agents implementing load reporting."
+    MODULE -- this module
+        MANDATORY-GROUPS { acmeLoadGroup, acmeNotificationGroup }
+    ::= { acmeConformance 3 }
+
+acmeAgentV1 AGENT-CAPABILITIES
+    PRODUCT-RELEASE "Acme agent release 1.0"
+    STATUS      current
+    DESCRIPTION "Acme agent capabilities."
+    REFERENCE   "Acme agent manual."
+
+    SUPPORTS    ACME-AGENT-MIB
+    INCLUDES    { acmeLoadGroup, acmeNotificationGroup }
+
+    VARIATION   acmeLoad
+    SYNTAX      Integer32 (0..50)
+    ACCESS      read-only
+    DESCRIPTION "Load is capped and cannot be set."
+
+    SUPPORTS    IF-MIB
+    INCLUDES    { ifGeneralInformationGroup }
+
+    VARIATION   ifAdminStatus
+    WRITE-SYNTAX INTEGER { up(1), down(2) }
+    DEFVAL      { up }
+    DESCRIPTION "Testing is not supported."
+    ::= { acmeProducts 1 }
+
+END
+`
+
+func TestObjects(t *testing.T) {
+	mod, err := mib_parser.ParseMIB([]byte(objectTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	trapMod, err := mib_parser.ParseMIB([]byte(trapTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse trap MIB: %v", err)
+	}
+
+	caps, ok := mod.AgentCapabilities["acmeAgentV1"]
+	if !ok {
+		t.Fatalf("AGENT-CAPABILITIES acmeAgentV1 was not parsed")
+	}
+	want := []mib_parser.CapabilitiesModule{
+		{
+			Module:   "ACME-AGENT-MIB",
+			Includes: []string{"acmeLoadGroup", "acmeNotificationGroup"},
+			Variations: []mib_parser.Variation{
+				{Name: "acmeLoad", Syntax: "Integer32 ( 0 .. 50 )", Access: "read-only", Description: "Load is capped and cannot be set."},
+			},
+		},
+		{
+			Module:   "IF-MIB",
+			Includes: []string{"ifGeneralInformationGroup"},
+			Variations: []mib_parser.Variation{
+				{Name: "ifAdminStatus", WriteSyntax: "INTEGER { up ( 1 ) , down ( 2 ) }", DefVal: "up", Description: "Testing is not supported."},
+			},
+		},
+	}
+	if caps.ProductRelease != "Acme agent release 1.0" || caps.Reference != "Acme agent manual." || !reflect.DeepEqual(caps.Supports, want) {
+		t.Errorf("acmeAgentV1 parsed as %+v", caps)
+	}
+
+	for _, tt := range []struct {
+		mod         *mib_parser.Module
+		name        string
+		kind        mib_parser.Kind
+		status      mib_parser.Status
+		description string
+	}{
+		{mod, "acmeAgentMIB", mib_parser.KindModuleIdentity, mib_parser.StatusUnknown, "Acme agent MIB."},
+		{mod, "acmeObjects", mib_parser.KindObjectIdentifier, mib_parser.StatusUnknown, ""},
+		{mod, "acmeProducts", mib_parser.KindObjectIdentity, mib_parser.StatusCurrent, "Product registrations."},
+		{mod, "acmeOldLoad", mib_parser.KindObjectType, mib_parser.StatusDeprecated, "Load as reported by older agents."},
+		{mod, "acmeOverload", mib_parser.KindNotificationType, mib_parser.StatusCurrent, "The agent is overloaded."},
+		{mod, "acmeLoadGroup", mib_parser.KindObjectGroup, mib_parser.StatusCurrent, "Load objects."},
+		{mod, "acmeNotificationGroup", mib_parser.KindNotificationGroup, mib_parser.StatusCurrent, "Load notifications."},
+		{mod, "acmeCompliance", mib_parser.KindModuleCompliance, mib_parser.StatusCurrent, "Agents implementing load reporting."},
+		{mod, "acmeAgentV1", mib_parser.KindAgentCapabilities, mib_parser.StatusCurrent, "Acme agent capabilities."},
+		{trapMod, "acmeOverheat", mib_parser.KindTrapType, mib_parser.StatusCurrent, "The box is too hot."},
+	} {
+		obj, ok := tt.mod.Object(tt.name)
+		if !ok {
+			t.Errorf("Object(%q) not found", tt.name)
+			continue
+		}
+		if obj.ObjectName() != tt.name || obj.ObjectModule() != tt.mod.Name || obj.ObjectKind() != tt.kind ||
+			obj.ObjectStatus() != tt.status || obj.ObjectDescription() != tt.description {
+			t.Errorf("Object(%q) = %s %s::%s status %s description %q", tt.name, obj.ObjectKind(), obj.ObjectModule(),
+				obj.ObjectName(), obj.ObjectStatus(), obj.ObjectDescription())
+		}
+	}
+	if _, ok := mod.Object("Integer32"); ok {
+		t.Errorf("Object found the imported Integer32")
+	}
+	objs := mod.Objects()
+	if len(objs) != 12 || objs[0].ObjectName() != "acmeAgentMIB" || objs[2].ObjectName() != "acmeOverload" {
+		var names []string
+		for _, o := range objs {
+			names = append(names, o.ObjectName())
+		}
+		t.Errorf("Objects() = %v", names)
+	}
+
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	for _, m := range []*mib_parser.Module{mod, trapMod} {
+		if err := reg.Add(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg.Resolve()
+	for _, tt := range []struct {
+		lookup, module, name string
+		kind                 mib_parser.Kind
+	}{
+		{"1.3.6.1.4.1.99993.2.1", "ACME-AGENT-MIB", "acmeAgentV1", mib_parser.KindAgentCapabilities},
+		{".1.3.6.1.4.1.99992.0.3", "ACME-TRAP-MIB", "acmeOverheat", mib_parser.KindTrapType},
+		{"ACME-AGENT-MIB::acmeLoadGroup", "ACME-AGENT-MIB", "acmeLoadGroup", mib_parser.KindObjectGroup},
+		{"ifCompliance3", "IF-MIB", "ifCompliance3", mib_parser.KindModuleCompliance},
+		{"1.3.6.1.2.1.2.2.1.10", "IF-MIB", "ifInOctets", mib_parser.KindObjectType},
+		{"snmpTraps", "SNMPv2-MIB", "snmpTraps", mib_parser.KindObjectIdentifier},
+	} {
+		obj, ok := reg.LookupObject(tt.lookup)
+		if !ok {
+			t.Errorf("LookupObject(%q) not found", tt.lookup)
+			continue
+		}
+		if obj.ObjectModule() != tt.module || obj.ObjectName() != tt.name || obj.ObjectKind() != tt.kind {
+			t.Errorf("LookupObject(%q) = %s %s::%s, want %s %s::%s", tt.lookup, obj.ObjectKind(), obj.ObjectModule(),
+				obj.ObjectName(), tt.kind, tt.module, tt.name)
+		}
+	}
+	for _, missing := range []string{"1.3.6.1.2.1.2.2.1.10.3", "IF-MIB::ifInOctets.3", "NO-SUCH-MIB::x", "noSuchName"} {
+		if obj, ok := reg.LookupObject(missing); ok {
+			t.Errorf("LookupObject(%q) = %s, want none", missing, obj.ObjectName())
+		}
+	}
+	if obj, ok := reg.ObjectAt(mib_parser.OID{1, 3, 6, 1, 4, 1, 99993, 0, 1}); !ok || obj.ObjectName() != "acmeOverload" {
+		t.Errorf("ObjectAt(acmeOverload) = %v, %v", obj, ok)
+	}
+
+	data, err := mib_parser.MarshalModuleJSON(mod)
+	if err != nil {
+		t.Fatalf("MarshalModuleJSON failed: %v", err)
+	}
+	back, err := mib_parser.UnmarshalModuleJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalModuleJSON failed: %v", err)
+	}
+	if !reflect.DeepEqual(back, mod) {
+		t.Errorf("JSON round trip of the capabilities MIB is not identical")
+	}
+	data, err = mib_parser.MarshalModuleJSON(trapMod)
+	if err != nil {
+		t.Fatalf("MarshalModuleJSON failed: %v", err)
+	}
+	if back, err := mib_parser.UnmarshalModuleJSON(data); err != nil || !back.NotificationTypes["acmeOverheat"].TrapType {
+		t.Errorf("JSON round trip lost the TRAP-TYPE origin of acmeOverheat: %v", err)
+	}
+
+	out, err := mib_parser.FormatMIB(mod)
+	if err != nil {
+		t.Fatalf("FormatMIB failed: %v", err)
+	}
+	again, err := mib_parser.ParseMIB(out)
+	if err != nil {
+		t.Fatalf("Failed to reparse formatted MIB: %v\n%s", err, out)
+	}
+	if !reflect.DeepEqual(normalizeModule(again), normalizeModule(mod)) {
+		t.Errorf("format round trip of the capabilities MIB is not equivalent:\n%s", out)
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/hex"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func TestOID(t *testing.T) {
+	for _, s := range []string{"1.3.6.1.2.1", ".1.3.6.1.2.1"} {
+		oid, err := mib_parser.ParseOID(s)
+		if err != nil || oid.String() != "1.3.6.1.2.1" {
+			t.Errorf("ParseOID(%q) = %v, %v", s, oid, err)
+		}
+	}
+	for _, s := range []string{"", ".", "1..3", "1.3.", "1.-3", "1.+3", "1.x", "1.3.4294967296"} {
+		if oid, err := mib_parser.ParseOID(s); err == nil {
+			t.Errorf("ParseOID(%q) = %v, want an error", s, oid)
+		}
+	}
+
+	ifInOctets := mib_parser.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 10}
+	oid := ifInOctets.Append(3)
+	if oid.String() != "1.3.6.1.2.1.2.2.1.10.3" || len(ifInOctets) != 10 {
+		t.Errorf("Append gave %s and changed its receiver to %s", oid, ifInOctets)
+	}
+	if !oid.HasPrefix(ifInOctets) || !oid.HasPrefix(oid) || ifInOctets.HasPrefix(oid) || oid.HasPrefix(mib_parser.OID{1, 3, 6, 1, 2, 1, 2, 2, 1, 1}) {
+		t.Errorf("HasPrefix is wrong for %s and %s", oid, ifInOctets)
+	}
+	if suffix, ok := oid.Suffix(ifInOctets); !ok || suffix.String() != "3" {
+		t.Errorf("Suffix = %v, %v, want 3", suffix, ok)
+	}
+	if suffix, ok := ifInOctets.Suffix(oid); ok {
+		t.Errorf("Suffix of a longer prefix = %v, want false", suffix)
+	}
+	if !oid.Parent().Equal(ifInOctets) || mib_parser.OID(nil).Parent() != nil {
+		t.Errorf("Parent of %s = %s", oid, oid.Parent())
+	}
+
+	walk := []mib_parser.OID{
+		{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 10},
+		{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 2},
+		{1, 3, 6, 1, 2, 1, 2, 2, 1, 2, 1},
+		{1, 3, 6, 1, 2, 1, 2, 2, 1, 10},
+		{1, 3, 6, 1, 2, 1, 1, 3, 0},
+		{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 2},
+	}
+	sort.Slice(walk, func(i, j int) bool { return walk[i].Compare(walk[j]) < 0 })
+	var got []string
+	for _, o := range walk {
+		got = append(got, o.String())
+	}
+	want := "1.3.6.1.2.1.1.3.0 1.3.6.1.2.1.2.2.1.2.1 1.3.6.1.2.1.2.2.1.10 1.3.6.1.2.1.2.2.1.10.2 1.3.6.1.2.1.2.2.1.10.2 1.3.6.1.2.1.2.2.1.10.10"
+	if strings.Join(got, " ") != want {
+		t.Errorf("GETNEXT order is %s, want %s", strings.Join(got, " "), want)
+	}
+	if c := walk[4].Compare(walk[3]); c != 0 {
+		t.Errorf("Compare of equal OIDs = %d", c)
+	}
+
+	long := make(mib_parser.OID, 129)
+	long[0] = 1
+	for _, tt := range []struct {
+		oid mib_parser.OID
+		ok  bool
+	}{
+		{mib_parser.OID{0, 0}, true},
+		{mib_parser.OID{2, 999, 3}, true},
+		{mib_parser.OID{1, 3, 6, 1, 4, 1, math.MaxUint32}, true},
+		{long[:128], true},
+		{mib_parser.OID{1}, false},
+		{long, false},
+		{mib_parser.OID{3, 1}, false},
+		{mib_parser.OID{1, 40}, false},
+		{mib_parser.OID{1, 3, -1}, false},
+		{mib_parser.OID{1, 3, 6, 1, 4, 1, math.MaxUint32 + 1}, false},
+	} {
+		if err := tt.oid.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%s) = %v, want ok=%v", tt.oid, err, tt.ok)
+		}
+	}
+
+	for _, tt := range []struct {
+		oid mib_parser.OID
+		hex string
+	}{
+		{mib_parser.OID{1, 3, 6, 1, 2, 1, 1, 1, 0}, "2b06010201010100"},
+		{mib_parser.OID{1, 3, 6, 1, 4, 1, 4294967295}, "2b060104018fffffff7f"},
+		{mib_parser.OID{2, 999, 3}, "883703"},
+		{mib_parser.OID{0, 0}, "00"},
+	} {
+		b, err := tt.oid.MarshalBinary()
+		if err != nil || hex.EncodeToString(b) != tt.hex {
+			t.Errorf("MarshalBinary(%s) = %x, %v, want %s", tt.oid, b, err, tt.hex)
+			continue
+		}
+		var back mib_parser.OID
+		if err := back.UnmarshalBinary(b); err != nil || !back.Equal(tt.oid) {
+			t.Errorf("UnmarshalBinary(%x) = %s, %v, want %s", b, back, err, tt.oid)
+		}
+	}
+	if b, err := (mib_parser.OID{1, 40}).MarshalBinary(); err == nil {
+		t.Errorf("MarshalBinary(1.40) = %x, want an error", b)
+	}
+	for _, bad := range []string{"", "2b86", "2b8fffffffff7f"} {
+		b, _ := hex.DecodeString(bad)
+		var oid mib_parser.OID
+		if err := oid.UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%s) = %s, want an error", bad, oid)
+		}
+	}
+
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	m, _ := reg.Module("IF-MIB")
+	obj := m.ObjectsByName["ifInOctets"]
+	if !obj.OID.Equal(ifInOctets) || obj.OIDString() != ifInOctets.String() {
+		t.Errorf("ifInOctets has OID %s", obj.OID)
+	}
+	resolved, err := reg.ResolveName("IF-MIB::ifInOctets.3")
+	if err != nil || !resolved.Equal(oid) {
+		t.Errorf("ResolveName gave %s, %v, want %s", resolved, err, oid)
+	}
+	if sym, suffix, ok := reg.SymbolAt(oid); !ok || sym.Name != "ifInOctets" || !sym.OID.Append(suffix...).Equal(oid) {
+		t.Errorf("SymbolAt(%s) = %v, %s, %v", oid, sym, suffix, ok)
+	}
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const qualifiedTestMIB = `QUALIFIED-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS MODULE-IDENTITY, OBJECT-TYPE, Integer32, enterprises FROM SNMPv2-SMI;
+
+qualifiedTestMIB MODULE-IDENTITY
+    LAST-UPDATED "202601010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "noc"
+    DESCRIPTION  "Qualified name test."
+    ::= { enterprises 99996 }
+
+capTable OBJECT-TYPE
+    SYNTAX      SEQUENCE OF CapEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Capabilities by OID."
+    ::= { qualifiedTestMIB 1 }
+
+capEntry OBJECT-TYPE
+    SYNTAX      CapEntry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "A capability."
+    INDEX       { capID, capMac }
+    ::= { capTable 1 }
+
+CapEntry ::= SEQUENCE { capID OBJECT IDENTIFIER, capMac OCTET STRING, capLevel Integer32 }
+
+capID OBJECT-TYPE
+    SYNTAX      OBJECT IDENTIFIER
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Capability."
+    ::= { capEntry 1 }
+
+capMac OBJECT-TYPE
+    SYNTAX      OCTET STRING (SIZE (6))
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "Station."
+    ::= { capEntry 2 }
+
+capLevel OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Level."
+    ::= { capEntry 3 }
+
+END
+`
+
+func TestParseQualifiedName(t *testing.T) {
+	tests := map[string]mib_parser.QualifiedName{
+		"IF-MIB::ifDescr.2": {Module: "IF-MIB", Name: "ifDescr", Index: []mib_parser.IndexComponent{{Arcs: []int{2}}}},
+		"sysUpTime.0":       {Name: "sysUpTime", Index: []mib_parser.IndexComponent{{Arcs: []int{0}}}},
+		`ifName."eth0"`:     {Name: "ifName", Index: []mib_parser.IndexComponent{{Quoted: true, String: "eth0"}}},
+		`x.'a\'b'.7`: {Name: "x", Index: []mib_parser.IndexComponent{
+			{Quoted: true, String: "a'b", Implied: true}, {Arcs: []int{7}},
+		}},
+		"x[10.0.0.1]": {Name: "x", Index: []mib_parser.IndexComponent{{Arcs: []int{10, 0, 0, 1}}}},
+		"x[SNMPv2-MIB::snmpMIB.1]": {Name: "x", Index: []mib_parser.IndexComponent{{Ref: &mib_parser.QualifiedName{
+			Module: "SNMPv2-MIB", Name: "snmpMIB", Index: []mib_parser.IndexComponent{{Arcs: []int{1}}},
+		}}}},
+		".1.3.6": {Index: []mib_parser.IndexComponent{{Arcs: []int{1}}, {Arcs: []int{3}}, {Arcs: []int{6}}}},
+	}
+	for in, want := range tests {
+		got, err := mib_parser.ParseQualifiedName(in)
+		if err != nil {
+			t.Errorf("ParseQualifiedName(%q) failed: %v", in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseQualifiedName(%q) = %+v, want %+v", in, got, want)
+		}
+		again, err := mib_parser.ParseQualifiedName(got.String())
+		if err != nil || !reflect.DeepEqual(again, got) {
+			t.Errorf("%q does not round trip through %q", in, got.String())
+		}
+	}
+	for _, bad := range []string{"", "::x", "x.", `x."open`, "x[1.2", "9abc-", "x!"} {
+		if q, err := mib_parser.ParseQualifiedName(bad); err == nil {
+			t.Errorf("ParseQualifiedName(%q) = %+v, want error", bad, q)
+		}
+	}
+}
+
+func TestResolveQualifiedName(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB([]byte(qualifiedTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	if err := reg.Add(mod); err != nil {
+		t.Fatal(err)
+	}
+	reg.Resolve()
+
+	tests := []struct {
+		name      string
+		oid       string
+		canonical string
+	}{
+		{"IF-MIB::ifDescr.2", "1.3.6.1.2.1.2.2.1.2.2", "ifDescr.2"},
+		{"SNMPv2-MIB::sysUpTime.0", "1.3.6.1.2.1.1.3.0", "sysUpTime.0"},
+		{".1.3.6.1.2.1.1.3.0", "1.3.6.1.2.1.1.3.0", "sysUpTime.0"},
+		// ifRcvAddressTable is indexed by { ifIndex, ifRcvAddressAddress }.
+		{`ifRcvAddressStatus.2."abc"`, "1.3.6.1.2.1.31.1.4.1.2.2.3.97.98.99", `ifRcvAddressStatus.2."abc"`},
+		{"ifRcvAddressStatus.2.3.97.98.99", "1.3.6.1.2.1.31.1.4.1.2.2.3.97.98.99", `ifRcvAddressStatus.2."abc"`},
+		// snmpTargetAddrName is IMPLIED.
+		{`snmpTargetAddrTDomain."t1"`, "1.3.6.1.6.3.12.1.2.1.2.116.49", "snmpTargetAddrTDomain.'t1'"},
+		{"ipAdEntAddr.10.0.0.1", "1.3.6.1.2.1.4.20.1.1.10.0.0.1", "ipAdEntAddr.10.0.0.1"},
+		{"ipAdEntAddr[10.0.0.1]", "1.3.6.1.2.1.4.20.1.1.10.0.0.1", "ipAdEntAddr.10.0.0.1"},
+		// Nested OID index followed by a fixed-size, unprintable string.
+		{"capLevel[sysUpTime.0].0.1.2.3.4.5", "1.3.6.1.4.1.99996.1.1.3.9.1.3.6.1.2.1.1.3.0.0.1.2.3.4.5", "capLevel[sysUpTime.0].0.1.2.3.4.5"},
+		{`capLevel[1.3.6.1].":ab:cd"`, "1.3.6.1.4.1.99996.1.1.3.4.1.3.6.1.58.97.98.58.99.100", `capLevel[internet].":ab:cd"`},
+		{".1.3.6.1.4.1.99996.9.9", "1.3.6.1.4.1.99996.9.9", "qualifiedTestMIB.9.9"},
+		{".2.99", "2.99", ".2.99"},
+	}
+	for _, tt := range tests {
+		oid, err := reg.ResolveName(tt.name)
+		if err != nil {
+			t.Errorf("ResolveName(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if got := oidString(oid); got != tt.oid {
+			t.Errorf("ResolveName(%q) = %s, want %s", tt.name, got, tt.oid)
+		}
+		if got := reg.FormatOID(oid); got != tt.canonical {
+			t.Errorf("FormatOID(%s) = %q, want %q", tt.oid, got, tt.canonical)
+		}
+	}
+
+	for _, bad := range []string{"noSuchObject.1", `sysUpTime."x"`, "ipAdEntAddr.10.0", "capLevel[sysUpTime.0].1.2", "ifDescr.1.2"} {
+		if oid, err := reg.ResolveName(bad); err == nil {
+			t.Errorf("ResolveName(%q) = %v, want error", bad, oid)
+		}
+	}
+	// Callers add the name they resolved, so the error does not repeat it.
+	if _, err := reg.ResolveName("IF-MIB::nosuch"); err == nil || err.Error() != "unknown object IF-MIB::nosuch" {
+		t.Errorf("ResolveName(IF-MIB::nosuch) error = %v, want unknown object IF-MIB::nosuch", err)
+	}
+}
+
+func oidString(oid []int) string {
+	parts := make([]string, len(oid))
+	for i, arc := range oid {
+		parts[i] = strconv.Itoa(arc)
+	}
+	return strings.Join(parts, ".")
+}
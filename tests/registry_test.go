@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func TestRegistryResolvesImportedParents(t *testing.T) {
+	ifMib, err := os.ReadFile(filepath.Join("..", "mibs", "IF-MIB.MIB"))
+	if err != nil {
+		t.Fatalf("Failed to read IF-MIB: %v", err)
+	}
+	alone, err := mib_parser.ParseMIB(ifMib)
+	if err != nil {
+		t.Fatalf("Failed to parse IF-MIB: %v", err)
+	}
+	if oid := alone.NotificationTypes["linkDown"].OIDString(); oid != "" {
+		t.Fatalf("linkDown resolved without SNMPv2-MIB: %s", oid)
+	}
+
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, ok := reg.Module("IF-MIB")
+	if !ok {
+		t.Fatalf("IF-MIB not in registry")
+	}
+	if oid := mod.NotificationTypes["linkDown"].OIDString(); oid != "1.3.6.1.6.3.1.1.5.3" {
+		t.Errorf("linkDown OID = %q, want 1.3.6.1.6.3.1.1.5.3", oid)
+	}
+	if err := reg.Add(mod); err == nil {
+		t.Errorf("adding IF-MIB twice succeeded")
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	mibDir := t.TempDir()
+	entries, err := os.ReadDir(filepath.Join("..", "mibs"))
+	if err != nil {
+		t.Fatalf("Failed to list mibs directory: %v", err)
+	}
+	for _, e := range entries {
+		src, err := os.ReadFile(filepath.Join("..", "mibs", e.Name()))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", e.Name(), err)
+		}
+		if err := os.WriteFile(filepath.Join(mibDir, e.Name()), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cachePath := filepath.Join(t.TempDir(), "mibs.cache")
+
+	first, stats, err := mib_parser.LoadCachedDir(cachePath, mibDir)
+	if err != nil {
+		t.Fatalf("LoadCachedDir failed: %v", err)
+	}
+	if stats.Hits != 0 || stats.Misses != len(entries) || !stats.Written {
+		t.Errorf("cold load stats = %+v", stats)
+	}
+	second, stats, err := mib_parser.LoadCachedDir(cachePath, mibDir)
+	if err != nil {
+		t.Fatalf("LoadCachedDir failed: %v", err)
+	}
+	if stats.Hits != len(entries) || stats.Misses != 0 || stats.Written {
+		t.Errorf("warm load stats = %+v", stats)
+	}
+	if !reflect.DeepEqual(first.Modules(), second.Modules()) {
+		t.Errorf("cached registry differs from parsed registry")
+	}
+
+	reference := mib_parser.NewRegistry()
+	if err := reference.LoadDir(mibDir); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if !reflect.DeepEqual(reference.Modules(), second.Modules()) {
+		t.Errorf("cached registry differs from LoadDir")
+	}
+
+	ifPath := filepath.Join(mibDir, "IF-MIB.MIB")
+	src, err := os.ReadFile(ifPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ifPath, append(src, "\n-- edited\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, stats, err = mib_parser.LoadCachedDir(cachePath, mibDir)
+	if err != nil {
+		t.Fatalf("LoadCachedDir failed: %v", err)
+	}
+	if stats.Misses != 1 || stats.Hits != len(entries)-1 || !stats.Written {
+		t.Errorf("load after edit stats = %+v", stats)
+	}
+
+	if err := os.WriteFile(cachePath, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, stats, err = mib_parser.LoadCachedDir(cachePath, mibDir); err != nil || stats.Misses != len(entries) {
+		t.Errorf("corrupt cache not rebuilt: %+v, %v", stats, err)
+	}
+}
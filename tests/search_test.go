@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/search"
+)
+
+func TestSearch(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	ix := search.New(reg)
+
+	top := func(q search.Query) string {
+		q.Limit = 1
+		res := ix.Search(q)
+		if len(res.Hits) == 0 {
+			return ""
+		}
+		return res.Hits[0].Module + "::" + res.Hits[0].Name
+	}
+	for _, tt := range []struct {
+		query search.Query
+		want  string
+	}{
+		{search.Query{Text: "the counter for discarded inbound packets"}, "IF-MIB::ifInDiscards"},
+		{search.Query{Text: "ifHCInOctets"}, "IF-MIB::ifHCInOctets"},
+		{search.Query{Text: "uptime", Modules: []string{"SNMPv2-MIB"}, Kinds: []string{"object-type"}}, "SNMPv2-MIB::sysORUpTime"},
+		{search.Query{Text: "MAC address"}, "SNMPv2-TC::MacAddress"},
+		{search.Query{Text: "softwareLoopback", Kinds: []string{"OBJECT-TYPE"}}, "IF-MIB::ifType"},
+		{search.Query{Text: "interface speed", Syntaxes: []string{"Gauge32"}}, "IF-MIB::ifSpeed"},
+		{search.Query{Text: "interface name", Syntaxes: []string{"DisplayString"}, Access: []string{"read-only"}}, "IF-MIB::ifName"},
+		{search.Query{Text: "discards", Modules: []string{"IP-MIB"}, Statuses: []string{"deprecated"}}, "IP-MIB::ipInDiscards"},
+	} {
+		if got := top(tt.query); got != tt.want {
+			t.Errorf("top hit of %+v = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+	if res := ix.Search(search.Query{Text: "xyzzy"}); res.Total != 0 || len(res.Hits) != 0 {
+		t.Errorf("nonsense query matched %d definitions", res.Total)
+	}
+
+	res := ix.Search(search.Query{Modules: []string{"IF-MIB"}, Kinds: []string{"object-identifier", "TEXTUAL-CONVENTION"}, Limit: 2})
+	if len(res.Hits) != 2 || res.Total <= 2 {
+		t.Fatalf("filter-only search returned %d of %d hits", len(res.Hits), res.Total)
+	}
+	wantKinds := map[string]int{"OBJECT IDENTIFIER": 0, "TEXTUAL-CONVENTION": 0}
+	for k := range res.Facets[search.FacetKind] {
+		if _, ok := wantKinds[k]; !ok {
+			t.Errorf("kind facet has %q, which the filter excludes", k)
+		}
+	}
+	if got := res.Facets[search.FacetKind]["OBJECT IDENTIFIER"] + res.Facets[search.FacetKind]["TEXTUAL-CONVENTION"]; got != res.Total {
+		t.Errorf("kind facet counts %d matches, want %d", got, res.Total)
+	}
+	if res.Facets[search.FacetModule]["IF-MIB"] != res.Total {
+		t.Errorf("module facet = %v, want IF-MIB: %d", res.Facets[search.FacetModule], res.Total)
+	}
+
+	q := search.ParseQuery("discarded packets kind:OBJECT-TYPE module:IF-MIB status:current foo:bar")
+	want := search.Query{Text: "discarded packets foo:bar", Kinds: []string{"OBJECT-TYPE"}, Modules: []string{"IF-MIB"}, Statuses: []string{"current"}}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("ParseQuery = %+v, want %+v", q, want)
+	}
+
+	srv := httptest.NewServer(ix)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "?q=discarded+inbound+packets+syntax:Counter32&module=IF-MIB,IP-MIB&limit=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got search.Results
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET returned %s: %v", resp.Status, err)
+	}
+	if len(got.Hits) != 3 || got.Hits[0].Name != "ifInDiscards" || got.Hits[0].Syntax != "Counter32" || got.Hits[0].OID != "1.3.6.1.2.1.2.2.1.13" {
+		t.Errorf("GET returned %+v", got.Hits)
+	}
+	for module := range got.Facets[search.FacetModule] {
+		if module != "IF-MIB" && module != "IP-MIB" {
+			t.Errorf("GET matched module %s outside the filter", module)
+		}
+	}
+	for _, bad := range []string{"?q=x&limit=-1", "?limit=ten"} {
+		resp, err := http.Get(srv.URL + bad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET %s returned %s, want 400", bad, resp.Status)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package tests
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/ber"
+	"github.com/Olian04/go-mib-parser/simulator"
+)
+
+const simulatorTestSnmprec = `1.3.6.1.2.1.1.1.0|4|Simulated router
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.8072.3.2.10
+1.3.6.1.2.1.1.4.0|4|ops
+1.3.6.1.2.1.1.5.0|4|sim1
+1.3.6.1.2.1.2.1.0|2|2
+1.3.6.1.2.1.2.2.1.1.1|2|1
+1.3.6.1.2.1.2.2.1.1.2|2|2
+1.3.6.1.2.1.2.2.1.2.1|4|lo
+1.3.6.1.2.1.2.2.1.2.2|4|eth0
+1.3.6.1.2.1.2.2.1.7.1|2|1
+1.3.6.1.2.1.2.2.1.7.2|2|1
+1.3.6.1.2.1.2.2.1.8.1|2|1
+1.3.6.1.2.1.2.2.1.8.2|2|2
+1.3.6.1.2.1.31.1.1.1.6.1|70|1099511627776
+1.3.6.1.2.1.31.1.4.1.2.1.6.0.17.34.51.68.85|2|1
+1.3.6.1.2.1.31.1.4.1.3.1.6.0.17.34.51.68.85|2|3
+1.3.6.1.2.1.47.1.1.1.1.2.1|4|Chassis
+`
+
+func null(oid string) mib_parser.VarBind { return varBind(oid, mib_parser.TypeNull, nil) }
+
+func integer(oid string, n int) mib_parser.VarBind { return varBind(oid, mib_parser.TypeInteger, n) }
+
+func octets(oid, s string) mib_parser.VarBind { return varBind(oid, mib_parser.TypeOctetString, s) }
+
+func varBind(oid string, t mib_parser.ValueType, data any) mib_parser.VarBind {
+	var arcs []int
+	for _, p := range strings.Split(oid, ".") {
+		n, _ := strconv.Atoi(p)
+		arcs = append(arcs, n)
+	}
+	return mib_parser.VarBind{OID: arcs, Value: mib_parser.NewValue(t, data)}
+}
+
+// formatVarBind prints vb as "oid = TYPE: data", with octets as text.
+func formatVarBind(vb mib_parser.VarBind) string {
+	data := vb.Value.Data()
+	if b, ok := data.([]byte); ok {
+		data = string(b)
+	}
+	return fmt.Sprintf("%s = %s: %v", oidString(vb.OID), vb.Value.Type(), data)
+}
+
+type snmpClient struct {
+	t         *testing.T
+	conn      net.Conn
+	version   int
+	community string
+	requestID int32
+}
+
+// do sends one request and returns the response PDU, or nil when no
+// response arrives.
+func (c *snmpClient) do(pdu ber.PDU) *ber.PDU {
+	c.t.Helper()
+	c.requestID++
+	pdu.RequestID = c.requestID
+	req, err := ber.Marshal(&ber.Message{Version: c.version, Community: c.community, PDU: pdu}, ber.Options{})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := c.conn.Write(req); err != nil {
+		c.t.Fatal(err)
+	}
+	c.conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	buf := make([]byte, 65535)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		return nil
+	}
+	resp, err := ber.Unmarshal(buf[:n])
+	if err != nil || resp.PDU.Type != ber.Response || resp.PDU.RequestID != c.requestID {
+		c.t.Fatalf("unexpected response % x: %v", buf[:n], err)
+	}
+	return &resp.PDU
+}
+
+func TestSimulator(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	agent := simulator.New(reg)
+	agent.Community = "public"
+	if err := agent.LoadDump(strings.NewReader(simulatorTestSnmprec)); err != nil {
+		t.Fatalf("LoadDump failed: %v", err)
+	}
+	agent.Func([]int{1, 3, 6, 1, 2, 1, 1, 3, 0}, func() mib_parser.Value {
+		return mib_parser.NewValue(mib_parser.TypeTimeTicks, uint32(4242))
+	})
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- agent.Serve(conn) }()
+	defer func() {
+		conn.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	}()
+	client := func(version int, community string) *snmpClient {
+		c, err := net.Dial("udp", conn.LocalAddr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return &snmpClient{t: t, conn: c, version: version, community: community}
+	}
+	v1, v2 := client(ber.Version1, "public"), client(ber.Version2c, "public")
+
+	const (
+		get     = ber.GetRequest
+		getNext = ber.GetNextRequest
+		set     = ber.SetRequest
+		getBulk = ber.GetBulkRequest
+	)
+	sysDescr := "1.3.6.1.2.1.1.1.0"
+	sysName := "1.3.6.1.2.1.1.5.0"
+	ifAdminStatus1 := "1.3.6.1.2.1.2.2.1.7.1"
+	ifHCInOctets1 := "1.3.6.1.2.1.31.1.1.1.6.1"
+	rcvStatus := "1.3.6.1.2.1.31.1.4.1.2.2.6.0.170.187.204.221.238"
+	rcvType := "1.3.6.1.2.1.31.1.4.1.3.2.6.0.170.187.204.221.238"
+
+	tests := []struct {
+		name   string
+		client *snmpClient
+		pdu    ber.PDUType
+		// bulk holds non-repeaters and max-repetitions.
+		bulk   [2]int
+		req    []mib_parser.VarBind
+		status ber.ErrorStatus
+		index  int
+		want   []string
+	}{
+		{
+			name: "get", client: v2, pdu: get,
+			req: []mib_parser.VarBind{null(sysDescr), null("1.3.6.1.2.1.1.3.0"), null("1.3.6.1.2.1.2.2.1.2.9"), null("1.3.6.1.4.1.99990.1.0")},
+			want: []string{
+				sysDescr + ` = OCTET STRING: Simulated router`,
+				`1.3.6.1.2.1.1.3.0 = TimeTicks: 4242`,
+				`1.3.6.1.2.1.2.2.1.2.9 = noSuchInstance: <nil>`,
+				`1.3.6.1.4.1.99990.1.0 = noSuchObject: <nil>`,
+			},
+		},
+		{
+			name: "v1 get of Counter64", client: v1, pdu: get,
+			req: []mib_parser.VarBind{null(sysDescr), null(ifHCInOctets1)}, status: ber.NoSuchName, index: 2,
+			want: []string{sysDescr + ` = NULL: <nil>`, ifHCInOctets1 + ` = NULL: <nil>`},
+		},
+		{
+			name: "getnext across columns and tables", client: v2, pdu: getNext,
+			req: []mib_parser.VarBind{null("1.3.6.1.2.1.2.2.1.2.2"), null("1.3.6.1.2.1.2.2.1.8.2"), null("1.3.6.1.2.1.1.3"), null("1.3.6.1.2.1.47.1.1.1.1.2.1")},
+			want: []string{
+				`1.3.6.1.2.1.2.2.1.7.1 = INTEGER: 1`,
+				ifHCInOctets1 + ` = Counter64: 1099511627776`,
+				`1.3.6.1.2.1.1.3.0 = TimeTicks: 4242`,
+				`1.3.6.1.2.1.47.1.1.1.1.2.1 = endOfMibView: <nil>`,
+			},
+		},
+		{
+			name: "v1 getnext skips Counter64", client: v1, pdu: getNext,
+			req:  []mib_parser.VarBind{null("1.3.6.1.2.1.2.2.1.8.2")},
+			want: []string{`1.3.6.1.2.1.31.1.4.1.2.1.6.0.17.34.51.68.85 = INTEGER: 1`},
+		},
+		{
+			name: "v1 getnext past the end", client: v1, pdu: getNext,
+			req: []mib_parser.VarBind{null("1.3.6.1.2.1.47.1.1.1.1.2.1")}, status: ber.NoSuchName, index: 1,
+			want: []string{`1.3.6.1.2.1.47.1.1.1.1.2.1 = NULL: <nil>`},
+		},
+		{
+			name: "getbulk", client: v2, pdu: getBulk, bulk: [2]int{1, 3},
+			req: []mib_parser.VarBind{null("1.3.6.1.2.1.1.1"), null("1.3.6.1.2.1.2.2.1.2"), null("1.3.6.1.2.1.2.2.1.8")},
+			want: []string{
+				sysDescr + ` = OCTET STRING: Simulated router`,
+				`1.3.6.1.2.1.2.2.1.2.1 = OCTET STRING: lo`,
+				`1.3.6.1.2.1.2.2.1.8.1 = INTEGER: 1`,
+				`1.3.6.1.2.1.2.2.1.2.2 = OCTET STRING: eth0`,
+				`1.3.6.1.2.1.2.2.1.8.2 = INTEGER: 2`,
+				ifAdminStatus1 + ` = INTEGER: 1`,
+				ifHCInOctets1 + ` = Counter64: 1099511627776`,
+			},
+		},
+		{
+			name: "set read-write scalar", client: v2, pdu: set,
+			req:  []mib_parser.VarBind{octets(sysName, "sim2")},
+			want: []string{sysName + ` = OCTET STRING: sim2`},
+		},
+		{
+			name: "set read-only", client: v2, pdu: set,
+			req: []mib_parser.VarBind{octets(sysDescr, "x")}, status: ber.NotWritable, index: 1,
+			want: []string{sysDescr + ` = OCTET STRING: x`},
+		},
+		{
+			name: "v1 set read-only", client: v1, pdu: set,
+			req: []mib_parser.VarBind{octets(sysDescr, "x")}, status: ber.NoSuchName, index: 1,
+			want: []string{sysDescr + ` = OCTET STRING: x`},
+		},
+		{
+			name: "set outside enumeration", client: v2, pdu: set,
+			req: []mib_parser.VarBind{octets("1.3.6.1.2.1.1.4.0", "noc"), integer(ifAdminStatus1, 7)}, status: ber.WrongValue, index: 2,
+			want: []string{`1.3.6.1.2.1.1.4.0 = OCTET STRING: noc`, ifAdminStatus1 + ` = INTEGER: 7`},
+		},
+		{
+			name: "set wrong type", client: v2, pdu: set,
+			req: []mib_parser.VarBind{octets(ifAdminStatus1, "up")}, status: ber.WrongType, index: 1,
+			want: []string{ifAdminStatus1 + ` = OCTET STRING: up`},
+		},
+		{
+			name: "v1 set wrong type", client: v1, pdu: set,
+			req: []mib_parser.VarBind{octets(ifAdminStatus1, "up")}, status: ber.BadValue, index: 1,
+			want: []string{ifAdminStatus1 + ` = OCTET STRING: up`},
+		},
+		{
+			name: "set column of missing row", client: v2, pdu: set,
+			req: []mib_parser.VarBind{integer(rcvType, 2)}, status: ber.NoCreation, index: 1,
+			want: []string{rcvType + ` = INTEGER: 2`},
+		},
+		{
+			name: "createAndGo", client: v2, pdu: set,
+			req:  []mib_parser.VarBind{integer(rcvType, 2), integer(rcvStatus, 4)},
+			want: []string{rcvType + ` = INTEGER: 2`, rcvStatus + ` = INTEGER: 4`},
+		},
+		{
+			name: "created row", client: v2, pdu: get,
+			req:  []mib_parser.VarBind{null(rcvStatus), null(rcvType)},
+			want: []string{rcvStatus + ` = INTEGER: 1`, rcvType + ` = INTEGER: 2`},
+		},
+		{
+			name: "createAndGo of existing row", client: v2, pdu: set,
+			req: []mib_parser.VarBind{integer(rcvStatus, 4)}, status: ber.InconsistentValue, index: 1,
+			want: []string{rcvStatus + ` = INTEGER: 4`},
+		},
+		{
+			name: "set notReady", client: v2, pdu: set,
+			req: []mib_parser.VarBind{integer(rcvStatus, 3)}, status: ber.WrongValue, index: 1,
+			want: []string{rcvStatus + ` = INTEGER: 3`},
+		},
+		{
+			name: "destroy", client: v2, pdu: set,
+			req:  []mib_parser.VarBind{integer(rcvStatus, 6)},
+			want: []string{rcvStatus + ` = INTEGER: 6`},
+		},
+		{
+			name: "destroyed row", client: v2, pdu: get,
+			req:  []mib_parser.VarBind{null(rcvStatus), null(rcvType)},
+			want: []string{rcvStatus + ` = noSuchInstance: <nil>`, rcvType + ` = noSuchInstance: <nil>`},
+		},
+		{
+			name: "activate missing row", client: v2, pdu: set,
+			req: []mib_parser.VarBind{integer(rcvStatus, 1)}, status: ber.InconsistentValue, index: 1,
+			want: []string{rcvStatus + ` = INTEGER: 1`},
+		},
+	}
+	for _, tt := range tests {
+		resp := tt.client.do(ber.PDU{Type: tt.pdu, NonRepeaters: tt.bulk[0], MaxRepetitions: tt.bulk[1], VarBinds: tt.req})
+		if resp == nil {
+			t.Errorf("%s: no response", tt.name)
+			continue
+		}
+		var got []string
+		for _, vb := range resp.VarBinds {
+			got = append(got, formatVarBind(vb))
+		}
+		if resp.ErrorStatus != tt.status || resp.ErrorIndex != tt.index || strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: %s index %d varbinds\n%s\nwant %s index %d varbinds\n%s",
+				tt.name, resp.ErrorStatus, resp.ErrorIndex, strings.Join(got, "\n"), tt.status, tt.index, strings.Join(tt.want, "\n"))
+		}
+	}
+
+	if v, ok := agent.Get([]int{1, 3, 6, 1, 2, 1, 1, 5, 0}); !ok || string(v.Data().([]byte)) != "sim2" {
+		t.Errorf("sysName.0 = %v after SET, want sim2", v)
+	}
+	if v, ok := agent.Get([]int{1, 3, 6, 1, 2, 1, 1, 4, 0}); !ok || string(v.Data().([]byte)) != "ops" {
+		t.Errorf("sysContact.0 = %v after a failed SET, want it unchanged", v)
+	}
+	if resp := client(ber.Version2c, "private").do(ber.PDU{Type: get, VarBinds: []mib_parser.VarBind{null(sysDescr)}}); resp != nil {
+		t.Errorf("request with a wrong community was answered")
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/snmpexporter"
+)
+
+func TestSnmpExporterGenerate(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, err := snmpexporter.Generate(reg, snmpexporter.Options{
+		Walk:    []string{"ifTable", "ifXTable", "sysUpTime"},
+		Lookups: []snmpexporter.Lookup{{SourceIndexes: []string{"ifIndex"}, Lookup: "ifAlias"}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(mod.Walk) != 2 || mod.Walk[0] != "1.3.6.1.2.1.2.2" || mod.Walk[1] != "1.3.6.1.2.1.31.1.1" {
+		t.Errorf("walk = %v", mod.Walk)
+	}
+	if len(mod.Get) != 1 || mod.Get[0] != "1.3.6.1.2.1.1.3.0" {
+		t.Errorf("get = %v", mod.Get)
+	}
+	metrics := map[string]*snmpexporter.Metric{}
+	for _, m := range mod.Metrics {
+		metrics[m.Name] = m
+	}
+	tests := map[string]string{
+		"ifInOctets":    "counter",
+		"ifHCInOctets":  "counter",
+		"ifMtu":         "gauge",
+		"ifOperStatus":  "gauge",
+		"ifDescr":       "DisplayString",
+		"ifPhysAddress": "PhysAddress48",
+		"sysUpTime":     "gauge",
+	}
+	for name, want := range tests {
+		m, ok := metrics[name]
+		if !ok {
+			t.Errorf("no metric %s", name)
+			continue
+		}
+		if m.Type != want {
+			t.Errorf("%s type = %s, want %s", name, m.Type, want)
+		}
+	}
+	if _, ok := metrics["ifTable"]; ok {
+		t.Errorf("table exported as a metric")
+	}
+	status := metrics["ifOperStatus"]
+	if status.EnumValues[1] != "up" || status.EnumValues[7] != "lowerLayerDown" {
+		t.Errorf("ifOperStatus enum values = %v", status.EnumValues)
+	}
+	// ifXEntry AUGMENTS ifEntry and takes its index.
+	hc := metrics["ifHCInOctets"]
+	if len(hc.Indexes) != 1 || hc.Indexes[0].Labelname != "ifIndex" || hc.Indexes[0].Type != "gauge" {
+		t.Errorf("ifHCInOctets indexes = %+v", hc.Indexes)
+	}
+	if len(hc.Lookups) != 1 || hc.Lookups[0].OID != "1.3.6.1.2.1.31.1.1.1.18" || hc.Lookups[0].Type != "DisplayString" {
+		t.Errorf("ifHCInOctets lookups = %+v", hc.Lookups)
+	}
+	if !strings.HasSuffix(hc.Help, " - 1.3.6.1.2.1.31.1.1.1.6") {
+		t.Errorf("ifHCInOctets help = %q", hc.Help)
+	}
+
+	var buf bytes.Buffer
+	if err := snmpexporter.WriteYAML(&buf, map[string]*snmpexporter.Module{"if_mib": mod}); err != nil {
+		t.Fatalf("WriteYAML failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"modules:\n  if_mib:\n    walk:\n    - 1.3.6.1.2.1.2.2\n",
+		"    - name: ifOperStatus\n      oid: 1.3.6.1.2.1.2.2.1.8\n      type: gauge\n",
+		"      enum_values:\n        1: up\n",
+		"      lookups:\n      - labels:\n        - ifIndex\n        labelname: ifAlias\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("YAML lacks %q", want)
+		}
+	}
+
+	if _, err := snmpexporter.Generate(reg, snmpexporter.Options{Walk: []string{"noSuchObject"}}); err == nil {
+		t.Errorf("unknown walk root accepted")
+	}
+}
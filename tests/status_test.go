@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const statusTestMIB = `STALE-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, NOTIFICATION-TYPE, Integer32, enterprises
+        FROM SNMPv2-SMI
+    OBJECT-GROUP, MODULE-COMPLIANCE
+        FROM SNMPv2-CONF
+    ifIndex, ifOutQLen
+        FROM IF-MIB;
+
+stale OBJECT IDENTIFIER ::= { enterprises 99994 }
+
+staleLoad OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Current load."
+    ::= { stale 1 }
+
+staleOldLoad OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      obsolete
+    DESCRIPTION "Load as reported by the first release."
+    ::= { stale 2 }
+
+staleNotification NOTIFICATION-TYPE
+    OBJECTS     { ifIndex, ifOutQLen }
+    STATUS      current
+    DESCRIPTION "An interface queue is full."
+    ::= { stale 3 }
+
+staleGroup OBJECT-GROUP
+    OBJECTS     { staleLoad, staleOldLoad }
+    STATUS      current
+    DESCRIPTION "Load objects."
+    ::= { stale 4 }
+
+staleOldGroup OBJECT-GROUP
+    OBJECTS     { staleOldLoad }
+    STATUS      deprecated
+    DESCRIPTION "Objects of the first release."
+    ::= { stale 5 }
+
+staleCompliance MODULE-COMPLIANCE
+    STATUS      current
+    DESCRIPTION "Agents reporting load."
+    MODULE IF-MIB
+        MANDATORY-GROUPS { ifGeneralGroup, ifGeneralInformationGroup }
+        GROUP       ifStackGroup
+        DESCRIPTION "Only for stacked interfaces."
+    MODULE -- this module
+        MANDATORY-GROUPS { staleGroup }
+        GROUP       staleOldGroup
+        DESCRIPTION "Only for old agents."
+    ::= { stale 6 }
+
+END
+`
+
+func TestStatus(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want mib_parser.Status
+	}{
+		{"current", mib_parser.StatusCurrent},
+		{" Deprecated\n", mib_parser.StatusDeprecated},
+		{"obsolete", mib_parser.StatusObsolete},
+		{"mandatory", mib_parser.StatusMandatory},
+		{"optional", mib_parser.StatusOptional},
+		{"", mib_parser.StatusUnknown},
+		{"experimental", mib_parser.StatusUnknown},
+	} {
+		if got := mib_parser.ParseStatus(tt.text); got != tt.want {
+			t.Errorf("ParseStatus(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	if s := mib_parser.StatusDeprecated.String(); s != "deprecated" {
+		t.Errorf("StatusDeprecated.String() = %q", s)
+	}
+
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB([]byte(statusTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	if err := reg.Add(mod); err != nil {
+		t.Fatal(err)
+	}
+	reg.Resolve()
+
+	var got []string
+	for _, s := range reg.StaleReferences() {
+		got = append(got, fmt.Sprintf("%s::%s -> %s::%s (%s)", s.From.ObjectModule(), s.From.ObjectName(),
+			s.To.ObjectModule(), s.To.ObjectName(), s.To.ObjectStatus()))
+	}
+	want := []string{
+		"STALE-MIB::staleCompliance -> IF-MIB::ifGeneralGroup (deprecated)",
+		"STALE-MIB::staleCompliance -> IF-MIB::ifStackGroup (deprecated)",
+		"STALE-MIB::staleCompliance -> STALE-MIB::staleOldGroup (deprecated)",
+		"STALE-MIB::staleGroup -> STALE-MIB::staleOldLoad (obsolete)",
+		"STALE-MIB::staleNotification -> IF-MIB::ifOutQLen (deprecated)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StaleReferences() =\n%v\nwant\n%v", got, want)
+	}
+
+	view := mod.View(mib_parser.ViewOptions{HideObsolete: true})
+	if _, ok := view.ObjectsByName["staleOldLoad"]; ok {
+		t.Errorf("module view kept the obsolete staleOldLoad")
+	}
+	if _, ok := view.ObjectGroups["staleOldGroup"]; !ok {
+		t.Errorf("module view hid the deprecated staleOldGroup")
+	}
+	if _, ok := mod.ObjectsByName["staleOldLoad"]; !ok || len(mod.ObjectsByName) != 2 {
+		t.Errorf("View changed the original module")
+	}
+
+	for _, tt := range []struct {
+		opts    mib_parser.ViewOptions
+		name    string
+		visible bool
+	}{
+		{mib_parser.ViewOptions{}, "IF-MIB::ifOutQLen", true},
+		{mib_parser.ViewOptions{HideObsolete: true}, "IF-MIB::ifOutQLen", true},
+		{mib_parser.ViewOptions{HideDeprecated: true}, "IF-MIB::ifOutQLen", false},
+		{mib_parser.ViewOptions{HideDeprecated: true}, "IF-MIB::ifGeneralGroup", false},
+		{mib_parser.ViewOptions{HideDeprecated: true}, "STALE-MIB::staleOldLoad", true},
+		{mib_parser.ViewOptions{HideDeprecated: true, HideObsolete: true}, "STALE-MIB::staleOldLoad", false},
+		{mib_parser.ViewOptions{HideDeprecated: true, HideObsolete: true}, "IF-MIB::ifInOctets", true},
+		{mib_parser.ViewOptions{HideDeprecated: true, HideObsolete: true}, "IF-MIB::ifMIB", true},
+	} {
+		if _, ok := reg.View(tt.opts).LookupObject(tt.name); ok != tt.visible {
+			t.Errorf("View(%+v).LookupObject(%s) found = %v, want %v", tt.opts, tt.name, ok, tt.visible)
+		}
+	}
+	if _, ok := reg.LookupObject("IF-MIB::ifOutQLen"); !ok {
+		t.Errorf("View changed the original registry")
+	}
+}
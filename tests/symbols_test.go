@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+func TestRegistrySymbols(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	s, ok := reg.LookupSymbol("IF-MIB", "ifInOctets")
+	if !ok || s.String() != "IF-MIB::ifInOctets" {
+		t.Fatalf("LookupSymbol(IF-MIB, ifInOctets) = %v, %v", s, ok)
+	}
+	if _, ok := reg.LookupSymbol("SNMPv2-MIB", "ifInOctets"); ok {
+		t.Errorf("ifInOctets found in the wrong module")
+	}
+	if sym, ok := reg.LookupSymbol("", "ifInOctets"); !ok || sym.Module != "IF-MIB" {
+		t.Errorf("random access lookup = %v, %v", sym, ok)
+	}
+	if iso, ok := reg.LookupSymbol("", "iso"); !ok || len(iso.OID) != 1 || iso.OID[0] != 1 {
+		t.Errorf("well-known node iso = %v, %v", iso, ok)
+	}
+
+	at, rest, ok := reg.SymbolAt([]int{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, 3})
+	if !ok || at.Name != "ifInOctets" || len(rest) != 1 || rest[0] != 3 {
+		t.Errorf("SymbolAt = %v, %v, %v", at, rest, ok)
+	}
+	if at, rest, ok := reg.SymbolAt([]int{1, 3, 6, 1, 4, 1, 99999, 7}); !ok || at.Name != "enterprises" || len(rest) != 2 {
+		t.Errorf("SymbolAt under enterprises = %v, %v, %v", at, rest, ok)
+	}
+	if _, _, ok := reg.SymbolAt([]int{2, 5}); ok {
+		t.Errorf("SymbolAt found a symbol outside the tree")
+	}
+
+	found := reg.SearchSymbols(regexp.MustCompile(`^ifHC(In|Out)Octets$`))
+	if len(found) != 2 || found[0].Name != "ifHCInOctets" || found[1].Name != "ifHCOutOctets" {
+		t.Errorf("SearchSymbols = %v", found)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const trapTestMIB = `ACME-TRAP-MIB DEFINITIONS ::= BEGIN
+IMPORTS enterprises FROM RFC1155-SMI
+        OBJECT-TYPE FROM RFC-1212
+        TRAP-TYPE FROM RFC-1215;
+
+acme OBJECT IDENTIFIER ::= { enterprises 99992 }
+
+acmeTemp OBJECT-TYPE
+    SYNTAX      INTEGER
+    ACCESS      read-only
+    STATUS      mandatory
+    DESCRIPTION "Temperature."
+    ::= { acme 1 }
+
+acmeOverheat TRAP-TYPE
+    ENTERPRISE  acme
+    VARIABLES   { acmeTemp }
+    DESCRIPTION "The box is too hot."
+    REFERENCE   "Operations manual."
+    ::= 3
+
+END
+`
+
+func TestTrapConversion(t *testing.T) {
+	mod, err := mib_parser.ParseMIB([]byte(trapTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	trap, ok := mod.NotificationTypes["acmeOverheat"]
+	if !ok {
+		t.Fatalf("TRAP-TYPE acmeOverheat was not parsed")
+	}
+	if got := oidString(trap.OID); got != "1.3.6.1.4.1.99992.0.3" {
+		t.Errorf("acmeOverheat OID = %s, want 1.3.6.1.4.1.99992.0.3", got)
+	}
+	if !reflect.DeepEqual(trap.Objects, []string{"acmeTemp"}) || trap.Description != "The box is too hot." || trap.Reference != "Operations manual." {
+		t.Errorf("acmeOverheat parsed as %+v", trap)
+	}
+
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if err := reg.Add(mod); err != nil {
+		t.Fatal(err)
+	}
+	reg.Resolve()
+	ifIndex2 := []int{1, 3, 6, 1, 2, 1, 2, 2, 1, 1, 2}
+
+	tests := []struct {
+		trap    *mib_parser.TrapV1
+		trapOID string
+		name    string
+		objects []string
+		missing []string
+		// translated counts the appended RFC 3584 varbinds; the community
+		// is only appended when known.
+		translated int
+	}{
+		{
+			trap: &mib_parser.TrapV1{
+				Enterprise:   []int{1, 3, 6, 1, 4, 1, 8072},
+				AgentAddress: net.ParseIP("192.0.2.1"),
+				GenericTrap:  mib_parser.GenericLinkDown,
+				Timestamp:    4200,
+				Community:    "public",
+				VarBinds:     []mib_parser.VarBind{{OID: ifIndex2, Value: mib_parser.NewValue(mib_parser.TypeInteger, 2)}},
+			},
+			trapOID:    "1.3.6.1.6.3.1.1.5.3",
+			name:       "linkDown",
+			objects:    []string{"ifIndex.2"},
+			missing:    []string{"ifAdminStatus", "ifOperStatus"},
+			translated: 3,
+		},
+		{
+			trap: &mib_parser.TrapV1{
+				Enterprise:   []int{1, 3, 6, 1, 4, 1, 99992},
+				AgentAddress: net.ParseIP("192.0.2.2"),
+				GenericTrap:  mib_parser.GenericEnterpriseSpecific,
+				SpecificTrap: 3,
+				Timestamp:    99,
+				VarBinds: []mib_parser.VarBind{{
+					OID:   []int{1, 3, 6, 1, 4, 1, 99992, 1, 0},
+					Value: mib_parser.NewValue(mib_parser.TypeInteger, 71),
+				}},
+			},
+			trapOID:    "1.3.6.1.4.1.99992.0.3",
+			name:       "acmeOverheat",
+			objects:    []string{"acmeTemp.0"},
+			translated: 2,
+		},
+	}
+	for _, tt := range tests {
+		if got := oidString(tt.trap.TrapOID()); got != tt.trapOID {
+			t.Errorf("TrapOID() = %s, want %s", got, tt.trapOID)
+		}
+		n, err := reg.DecodeTrapV1(tt.trap)
+		if err != nil {
+			t.Fatalf("DecodeTrapV1 failed: %v", err)
+		}
+		var objects []string
+		for _, vb := range n.Objects {
+			objects = append(objects, vb.Name.String())
+		}
+		if len(n.Translated) != tt.translated {
+			t.Errorf("%s: Translated = %v", tt.trapOID, n.Translated)
+		}
+		if n.Name.String() != tt.name || !reflect.DeepEqual(objects, tt.objects) || len(n.Unexpected) != 0 || !reflect.DeepEqual(n.Missing, tt.missing) {
+			t.Errorf("%s decoded as %s with objects %q, unexpected %v, missing %v", tt.trapOID, n.Name, objects, n.Unexpected, n.Missing)
+		}
+
+		back, err := mib_parser.TrapV2ToV1(mib_parser.TrapV1ToV2(tt.trap))
+		if err != nil {
+			t.Fatalf("TrapV2ToV1 failed: %v", err)
+		}
+		want := *tt.trap
+		want.AgentAddress = want.AgentAddress.To4()
+		if !reflect.DeepEqual(*back, want) {
+			t.Errorf("round trip of %s gave %+v, want %+v", tt.trapOID, *back, want)
+		}
+	}
+
+	v2 := []mib_parser.VarBind{
+		{OID: []int{1, 3, 6, 1, 2, 1, 1, 3, 0}, Value: mib_parser.NewValue(mib_parser.TypeTimeTicks, uint32(5))},
+		{OID: []int{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, Value: mib_parser.NewValue(mib_parser.TypeObjectIdentifier, []int{1, 3, 6, 1, 4, 1, 99992, 2, 7})},
+		{OID: []int{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 6, 2}, Value: mib_parser.NewValue(mib_parser.TypeCounter64, uint64(1)<<40)},
+		{OID: ifIndex2, Value: mib_parser.NewValue(mib_parser.TypeInteger, 2)},
+	}
+	v1, err := mib_parser.TrapV2ToV1(v2)
+	if err != nil {
+		t.Fatalf("TrapV2ToV1 failed: %v", err)
+	}
+	if oidString(v1.Enterprise) != "1.3.6.1.4.1.99992.2" || v1.GenericTrap != mib_parser.GenericEnterpriseSpecific ||
+		v1.SpecificTrap != 7 || v1.Timestamp != 5 || !v1.AgentAddress.Equal(net.IPv4zero) || len(v1.VarBinds) != 1 {
+		t.Errorf("SNMPv2 notification converted to %+v", v1)
+	}
+
+	v1, err = mib_parser.TrapV2ToV1(v2[:1:1])
+	if err == nil {
+		t.Errorf("converting a varbind list without snmpTrapOID.0 should fail, got %+v", v1)
+	}
+	v1, err = mib_parser.TrapV2ToV1([]mib_parser.VarBind{
+		{OID: []int{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, Value: mib_parser.NewValue(mib_parser.TypeObjectIdentifier, "1.3.6.1.6.3.1.1.5.1")},
+	})
+	if err != nil || v1.GenericTrap != mib_parser.GenericColdStart || oidString(v1.Enterprise) != "1.3.6.1.6.3.1.1.5" {
+		t.Errorf("coldStart converted to %+v, %v", v1, err)
+	}
+}
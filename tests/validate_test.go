@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+const validateTestMIB = `VALIDATE-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS MODULE-IDENTITY, OBJECT-TYPE, Unsigned32, enterprises FROM SNMPv2-SMI;
+
+validateTestMIB MODULE-IDENTITY
+    LAST-UPDATED "202601010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "noc"
+    DESCRIPTION  "Validation test."
+    ::= { enterprises 99994 }
+
+setFlags OBJECT-TYPE
+    SYNTAX      BITS { alarm(0), warning(1), maintenance(9) }
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Flags."
+    ::= { validateTestMIB 1 }
+
+setLimit OBJECT-TYPE
+    SYNTAX      Unsigned32 (1..100 | 200)
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "Limit."
+    ::= { validateTestMIB 2 }
+
+END
+`
+
+func TestValidateSet(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB([]byte(validateTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	if err := reg.Add(mod); err != nil {
+		t.Fatal(err)
+	}
+	reg.Resolve()
+
+	tests := []struct {
+		name  string
+		value mib_parser.Value
+		want  string // empty when the value is valid
+	}{
+		{"ifAdminStatus.1", mib_parser.NewValue(mib_parser.TypeInteger, 2), ""},
+		{"ifAdminStatus.1", mib_parser.NewValue(mib_parser.TypeInteger, 7), "value 7 not in enumeration {up(1), down(2), testing(3)} for ifAdminStatus"},
+		{"ifAdminStatus.1", mib_parser.NewValue(mib_parser.TypeOctetString, "up"), "ifAdminStatus expects INTEGER, got OCTET STRING"},
+		{"ifPromiscuousMode.1", mib_parser.NewValue(mib_parser.TypeInteger, 3), "value 3 not in enumeration {true(1), false(2)} for ifPromiscuousMode"},
+		{"ifDescr.1", mib_parser.NewValue(mib_parser.TypeOctetString, "eth0"), "ifDescr is not writable (MAX-ACCESS read-only)"},
+		{"ifAlias.1", mib_parser.NewValue(mib_parser.TypeOctetString, "uplink"), ""},
+		{"ifAlias.1", mib_parser.NewValue(mib_parser.TypeOctetString, strings.Repeat("x", 65)), "length 65 not in SIZE (0..64) for ifAlias"},
+		{"ifAlias.1", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0xff}), `value "\xff" does not match DISPLAY-HINT "255a" for ifAlias`},
+		{"snmpTargetAddrTimeout.'t1'", mib_parser.NewValue(mib_parser.TypeInteger, -1), "value -1 not in range (0..2147483647) for snmpTargetAddrTimeout"},
+		{"snmpTargetAddrTDomain.'t1'", mib_parser.NewValue(mib_parser.TypeObjectIdentifier, "1.3.6.1.6.1.1"), ""},
+		{"setFlags.0", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0xc0, 0x40}), ""},
+		{"setFlags.0", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0x20}), "bit 2 not in BITS {alarm(0), warning(1), maintenance(9)} for setFlags"},
+		{"setLimit.0", mib_parser.NewValue(mib_parser.TypeGauge32, uint32(200)), ""},
+		{"setLimit.0", mib_parser.NewValue(mib_parser.TypeGauge32, uint32(101)), "value 101 not in range (1..100 | 200) for setLimit"},
+		{"setLimit.0", mib_parser.NewValue(mib_parser.TypeInteger, 5), "setLimit expects Gauge32, got INTEGER"},
+		{"setLimit.0", mib_parser.NewValue(mib_parser.TypeGauge32, "five"), "value five is not a valid Gauge32 for setLimit"},
+	}
+	for _, tt := range tests {
+		oid, err := reg.ResolveName(tt.name)
+		if err != nil {
+			t.Errorf("ResolveName(%q) failed: %v", tt.name, err)
+			continue
+		}
+		err = reg.ValidateSetVarBind(oid, tt.value)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("ValidateSetVarBind(%s, %v) failed: %v", tt.name, tt.value.Data(), err)
+		case tt.want != "" && (err == nil || err.Error() != tt.want):
+			t.Errorf("ValidateSetVarBind(%s, %v) = %v, want %q", tt.name, tt.value.Data(), err, tt.want)
+		}
+	}
+
+	if err := reg.ValidateSetVarBind([]int{2, 999}, mib_parser.NewValue(mib_parser.TypeInteger, 1)); err == nil {
+		t.Errorf("validating an unknown OID should fail")
+	}
+
+	for name, status := range map[string]string{
+		"ifDescr.1":       "notWritable",
+		"ifAdminStatus.1": "wrongType",
+		"ifAlias.1":       "wrongLength",
+		"setLimit.0":      "wrongValue",
+	} {
+		oid, _ := reg.ResolveName(name)
+		v := mib_parser.NewValue(mib_parser.TypeOctetString, strings.Repeat("x", 65))
+		if status == "wrongValue" {
+			v = mib_parser.NewValue(mib_parser.TypeGauge32, uint32(0))
+		}
+		var se *mib_parser.SetError
+		if err := reg.ValidateSetVarBind(oid, v); !errors.As(err, &se) || se.Status != status {
+			t.Errorf("ValidateSetVarBind(%s) = %#v, want a SetError with status %s", name, err, status)
+		}
+	}
+}
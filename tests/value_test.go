@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/displayhint"
+)
+
+const valueTestMIB = `VALUE-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS MODULE-IDENTITY, OBJECT-TYPE, Integer32, enterprises FROM SNMPv2-SMI
+        TEXTUAL-CONVENTION, DateAndTime FROM SNMPv2-TC;
+
+valueTestMIB MODULE-IDENTITY
+    LAST-UPDATED "202601010000Z"
+    ORGANIZATION "ACME"
+    CONTACT-INFO "noc"
+    DESCRIPTION  "Value rendering test."
+    ::= { enterprises 99995 }
+
+Temperature ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "d-1"
+    STATUS       current
+    DESCRIPTION  "Tenths of a degree."
+    SYNTAX       Integer32
+
+valTemp OBJECT-TYPE
+    SYNTAX      Temperature
+    UNITS       "degrees Celsius"
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Temperature."
+    ::= { valueTestMIB 1 }
+
+valFlags OBJECT-TYPE
+    SYNTAX      BITS { alarm(0), warning(1), maintenance(9) }
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Flags."
+    ::= { valueTestMIB 2 }
+
+valChanged OBJECT-TYPE
+    SYNTAX      DateAndTime
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Last change."
+    ::= { valueTestMIB 3 }
+
+END
+`
+
+func TestRenderValue(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	mod, err := mib_parser.ParseMIB([]byte(valueTestMIB))
+	if err != nil {
+		t.Fatalf("Failed to parse test MIB: %v", err)
+	}
+	if err := reg.Add(mod); err != nil {
+		t.Fatal(err)
+	}
+	reg.Resolve()
+
+	tests := []struct {
+		name  string
+		value mib_parser.Value
+		want  string
+	}{
+		{"ifOperStatus.1", mib_parser.NewValue(mib_parser.TypeInteger, 2), "down(2)"},
+		{"ifOperStatus.1", mib_parser.NewValue(mib_parser.TypeInteger, int32(9)), "9"},
+		{"ifPhysAddress.1", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}), "00:1a:2b:3c:4d:5e"},
+		{"ifDescr.1", mib_parser.NewValue(mib_parser.TypeOctetString, "eth0"), "eth0"},
+		{"ifAlias.1", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0xff, 0x00}), "ff 00"},
+		{"sysUpTime.0", mib_parser.NewValue(mib_parser.TypeTimeTicks, uint32(26500123)), "3 days, 1:36:41.23"},
+		{"sysUpTime.0", mib_parser.NewValue(mib_parser.TypeTimeTicks, uint32(8640012)), "1 day, 0:00:00.12"},
+		{"sysObjectID.0", mib_parser.NewValue(mib_parser.TypeObjectIdentifier, []int{1, 3, 6, 1, 2, 1, 1, 3, 0}), "sysUpTime.0"},
+		{"ipReasmTimeout.0", mib_parser.NewValue(mib_parser.TypeInteger, 60), "60 seconds"},
+		{"ipAdEntAddr.10.0.0.1", mib_parser.NewValue(mib_parser.TypeIpAddress, net.ParseIP("10.0.0.1")), "10.0.0.1"},
+		{"ifHCInOctets.1", mib_parser.NewValue(mib_parser.TypeCounter64, uint64(18446744073709551615)), "18446744073709551615"},
+		{"ifInOctets.1", mib_parser.NewValue(mib_parser.TypeNoSuchInstance, nil), "noSuchInstance"},
+		{"valTemp.0", mib_parser.NewValue(mib_parser.TypeInteger, -53), "-5.3 degrees Celsius"},
+		{"valFlags.0", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0xc0, 0x60}), "{ alarm(0), warning(1), maintenance(9), 10 }"},
+		{"valFlags.0", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0}), "{ }"},
+		{"valChanged.0", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0x07, 0xea, 10, 18, 13, 30, 15, 0, '+', 2, 0}), "2026-10-18,13:30:15.0,+2:0"},
+		{"valChanged.0", mib_parser.NewValue(mib_parser.TypeOctetString, []byte{0x07, 0xea, 10, 18, 13, 30, 15, 0}), "2026-10-18,13:30:15.0"},
+		{".1.3.6.1.4.1.99994.1", mib_parser.NewValue(mib_parser.TypeGauge32, uint32(7)), "7"},
+	}
+	for _, tt := range tests {
+		oid, err := reg.ResolveName(tt.name)
+		if err != nil {
+			t.Errorf("ResolveName(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if got := reg.RenderVarBind(oid, tt.value); got != tt.want {
+			t.Errorf("RenderVarBind(%s, %v) = %q, want %q", tt.name, tt.value.Data(), got, tt.want)
+		}
+	}
+}
+
+func TestDisplayHint(t *testing.T) {
+	octets := []struct {
+		hint  string
+		value []byte
+		want  string
+	}{
+		{"255a", []byte("hello"), "hello"},
+		{"1d.1d.1d.1d", []byte{192, 168, 1, 1}, "192.168.1.1"},
+		{"2x:", []byte{0xfe, 0x80, 0, 1}, "fe80:0001"},
+		{"1o", []byte{8, 9}, "1011"},
+		{"*1d./1d", []byte{2, 10, 20, 7}, "10.20/7"},
+		{"1x:", nil, ""},
+	}
+	for _, tt := range octets {
+		got, err := displayhint.FormatOctets(tt.hint, tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("FormatOctets(%q, %v) = %q, %v, want %q", tt.hint, tt.value, got, err, tt.want)
+		}
+	}
+	integers := []struct {
+		hint  string
+		value int64
+		want  string
+	}{
+		{"d", 42, "42"},
+		{"d-2", 1234, "12.34"},
+		{"d-2", 5, "0.05"},
+		{"d-3", -1500, "-1.500"},
+		{"x", 255, "ff"},
+		{"b", 5, "101"},
+	}
+	for _, tt := range integers {
+		got, err := displayhint.FormatInteger(tt.hint, tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("FormatInteger(%q, %d) = %q, %v, want %q", tt.hint, tt.value, got, err, tt.want)
+		}
+	}
+	for _, hint := range []string{"", "x", "1q", "0a", "*a"} {
+		if _, err := displayhint.FormatOctets(hint, []byte("x")); err == nil {
+			t.Errorf("FormatOctets(%q) should fail", hint)
+		}
+	}
+	if _, err := displayhint.FormatInteger("d-x", 1); err == nil {
+		t.Errorf("FormatInteger(%q) should fail", "d-x")
+	}
+}
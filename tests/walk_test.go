@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+	"github.com/Olian04/go-mib-parser/walk"
+)
+
+const walkTestDump = `.1.3.6.1.2.1.1.1.0 = STRING: "Linux router 6.1.0
+x86_64"
+.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.8072.3.2.10
+.1.3.6.1.2.1.1.3.0 = Timeticks: (26980123) 3 days, 2:56:41.23
+.1.3.6.1.2.1.2.2.1.1.1 = INTEGER: 1
+.1.3.6.1.2.1.2.2.1.1.2 = INTEGER: 2
+.1.3.6.1.2.1.2.2.1.2.1 = STRING: "lo"
+.1.3.6.1.2.1.2.2.1.2.2 = STRING: "eth0"
+.1.3.6.1.2.1.2.2.1.6.1 = ""
+.1.3.6.1.2.1.2.2.1.6.2 = Hex-STRING: 00 1A 2B 3C 4D 5E
+.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up(1)
+.1.3.6.1.2.1.2.2.1.8.2 = INTEGER: 2
+.1.3.6.1.2.1.4.20.1.2.10.0.0.1 = INTEGER: 2
+.1.3.6.1.4.1.99991.1.0 = Counter32: 7
+`
+
+const walkTestSnmprec = `1.3.6.1.2.1.1.1.0|4|Linux router 6.1.0
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.8072.3.2.10
+1.3.6.1.2.1.1.3.0|67|26980123
+1.3.6.1.2.1.2.2.1.1.1|2|1
+1.3.6.1.2.1.2.2.1.1.2|2|2
+1.3.6.1.2.1.2.2.1.2.1|4|lo
+1.3.6.1.2.1.2.2.1.2.2|4|eth0
+1.3.6.1.2.1.2.2.1.6.1|4|
+1.3.6.1.2.1.2.2.1.6.2|4x|001a2b3c4d5e
+1.3.6.1.2.1.2.2.1.8.1|2|1
+1.3.6.1.2.1.2.2.1.8.2|2|2
+1.3.6.1.2.1.4.20.1.2.10.0.0.1|2|2
+1.3.6.1.4.1.99991.1.0|65|7
+`
+
+func TestInterpretWalk(t *testing.T) {
+	reg := mib_parser.NewRegistry()
+	if err := reg.LoadDir(filepath.Join("..", "mibs")); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+
+	for _, dump := range []struct {
+		format string
+		src    string
+		descr  string
+	}{
+		{"snmpwalk", walkTestDump, "Linux router 6.1.0\nx86_64"},
+		{"snmprec", walkTestSnmprec, "Linux router 6.1.0"},
+	} {
+		vbs, err := walk.Parse(strings.NewReader(dump.src))
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", dump.format, err)
+		}
+		if len(vbs) != 13 {
+			t.Fatalf("%s: parsed %d varbinds, want 13", dump.format, len(vbs))
+		}
+		rep := walk.Interpret(reg, vbs)
+
+		scalars := map[string]string{}
+		for _, e := range rep.Scalars {
+			scalars[e.Name] = e.Value
+		}
+		want := map[string]string{
+			"SNMPv2-MIB::sysDescr.0":    dump.descr,
+			"SNMPv2-MIB::sysObjectID.0": "enterprises.8072.3.2.10",
+			"SNMPv2-MIB::sysUpTime.0":   "3 days, 2:56:41.23",
+		}
+		if !reflect.DeepEqual(scalars, want) {
+			t.Errorf("%s: scalars = %q, want %q", dump.format, scalars, want)
+		}
+		if len(rep.Unknown) != 1 || rep.Unknown[0].OID != "1.3.6.1.4.1.99991.1.0" || rep.Unknown[0].Value != "7" {
+			t.Errorf("%s: unknown = %+v", dump.format, rep.Unknown)
+		}
+
+		ifTable, ok := rep.Table("ifTable")
+		if !ok {
+			t.Fatalf("%s: no ifTable in %+v", dump.format, rep.Tables)
+		}
+		if ifTable.Name != "IF-MIB::ifTable" || !reflect.DeepEqual(ifTable.Index, []string{"ifIndex"}) ||
+			!reflect.DeepEqual(ifTable.Columns, []string{"ifIndex", "ifDescr", "ifPhysAddress", "ifOperStatus"}) {
+			t.Errorf("%s: ifTable = %+v", dump.format, ifTable)
+		}
+		if len(ifTable.Rows) != 2 {
+			t.Fatalf("%s: ifTable has %d rows, want 2", dump.format, len(ifTable.Rows))
+		}
+		eth0 := ifTable.Rows[1]
+		if eth0.Instance != "2" || !reflect.DeepEqual(eth0.Index, []string{"2"}) ||
+			eth0.Values["ifDescr"] != "eth0" || eth0.Values["ifPhysAddress"] != "00:1a:2b:3c:4d:5e" ||
+			eth0.Values["ifOperStatus"] != "down(2)" || ifTable.Rows[0].Values["ifOperStatus"] != "up(1)" {
+			t.Errorf("%s: eth0 row = %+v", dump.format, eth0)
+		}
+
+		ipAddrTable, ok := rep.Table("IP-MIB::ipAddrTable")
+		if !ok || len(ipAddrTable.Rows) != 1 {
+			t.Fatalf("%s: ipAddrTable = %+v", dump.format, ipAddrTable)
+		}
+		if row := ipAddrTable.Rows[0]; row.Instance != "10.0.0.1" || !reflect.DeepEqual(row.Index, []string{"10.0.0.1"}) || row.Values["ipAdEntIfIndex"] != "2" {
+			t.Errorf("%s: ipAddrTable row = %+v", dump.format, row)
+		}
+
+		var buf bytes.Buffer
+		if err := ifTable.WriteCSV(&buf); err != nil {
+			t.Fatal(err)
+		}
+		wantCSV := "ifIndex,ifIndex,ifDescr,ifPhysAddress,ifOperStatus\n" +
+			"1,1,lo,,up(1)\n" +
+			"2,2,eth0,00:1a:2b:3c:4d:5e,down(2)\n"
+		if buf.String() != wantCSV {
+			t.Errorf("%s: ifTable CSV =\n%s\nwant\n%s", dump.format, buf.String(), wantCSV)
+		}
+		buf.Reset()
+		if err := rep.WriteCSV(&buf); err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil || len(records) != 14 || !reflect.DeepEqual(records[1], []string{"SNMPv2-MIB::sysDescr.0", "1.3.6.1.2.1.1.1.0", "OCTET STRING", dump.descr}) {
+			t.Errorf("%s: report CSV = %q, %v", dump.format, records, err)
+		}
+
+		buf.Reset()
+		if err := rep.WriteJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Tables []struct {
+				Name string
+				Rows []struct {
+					Values map[string]string
+				}
+			}
+		}
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("%s: WriteJSON produced invalid JSON: %v", dump.format, err)
+		}
+		if len(decoded.Tables) != 2 || decoded.Tables[0].Name != "IF-MIB::ifTable" || decoded.Tables[0].Rows[1].Values["ifDescr"] != "eth0" {
+			t.Errorf("%s: JSON = %s", dump.format, buf.String())
+		}
+	}
+
+	for _, bad := range []string{
+		"not a walk\n",
+		".1.3.6.1.2.1.1.3.0 = Timeticks: soon\n",
+		// An arc of the second entry does not fit in 32 bits.
+		".1.3.6.1.2.1.1.3.0 = Timeticks: (1) 0:00:00.01\n.1.3.6.1.4.1.99999999999.1 = INTEGER: 5\n",
+		"1.3.6.1.2.1.1.3.0|67:numeric|rate=1\n",
+		"1.3.6.1.2.1.1.3.0|99|1\n",
+	} {
+		if vbs, err := walk.Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", bad, vbs)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package mib_parser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// The SNMPv1 generic-trap values (RFC 1157 section 4.1.6).
+const (
+	GenericColdStart = iota
+	GenericWarmStart
+	GenericLinkDown
+	GenericLinkUp
+	GenericAuthenticationFailure
+	GenericEgpNeighborLoss
+	GenericEnterpriseSpecific
+)
+
+// The objects RFC 3584 uses to carry SNMPv1 trap fields in SNMPv2
+// notifications.
+var (
+	snmpTraps                  = OID{1, 3, 6, 1, 6, 3, 1, 1, 5}
+	snmpTrapEnterpriseInstance = OID{1, 3, 6, 1, 6, 3, 1, 1, 4, 3, 0}
+	snmpTrapAddressInstance    = OID{1, 3, 6, 1, 6, 3, 18, 1, 3, 0}
+	snmpTrapCommunityInstance  = OID{1, 3, 6, 1, 6, 3, 18, 1, 4, 0}
+)
+
+// TrapV1 is an SNMPv1 Trap-PDU.
+type TrapV1 struct {
+	Enterprise   OID
+	AgentAddress net.IP
+	GenericTrap  int
+	SpecificTrap int
+	// Timestamp is the agent's sysUpTime in hundredths of a second.
+	Timestamp uint32
+	// Community is the community string of the message that carried the
+	// trap; empty when unknown.
+	Community string
+	VarBinds  []VarBind
+}
+
+// TrapOID returns the snmpTrapOID.0 value equivalent to the trap (RFC 3584
+// section 3.1): snmpTraps.(generic+1) for the generic traps coldStart to
+// egpNeighborLoss, and enterprise.0.specific for enterpriseSpecific ones.
+// It is also the OID of the NOTIFICATION-TYPE, or TRAP-TYPE, that defines
+// the trap.
+func (t *TrapV1) TrapOID() OID {
+	if t.GenericTrap == GenericEnterpriseSpecific {
+		return t.Enterprise.Append(0, t.SpecificTrap)
+	}
+	return snmpTraps.Append(t.GenericTrap + 1)
+}
+
+// TrapV1ToV2 converts an SNMPv1 trap to the varbind list of the equivalent
+// SNMPv2-Trap (RFC 3584 section 3.1): sysUpTime.0 and snmpTrapOID.0,
+// followed by the trap's varbinds, then snmpTrapAddress.0,
+// snmpTrapCommunity.0 (when the community is known) and
+// snmpTrapEnterprise.0 unless the varbinds already carry them.
+func TrapV1ToV2(t *TrapV1) []VarBind {
+	vbs := []VarBind{
+		{OID: sysUpTimeInstance.Append(), Value: NewValue(TypeTimeTicks, t.Timestamp)},
+		{OID: snmpTrapOIDInstance.Append(), Value: NewValue(TypeObjectIdentifier, t.TrapOID())},
+	}
+	vbs = append(vbs, t.VarBinds...)
+	appendMissing := func(oid OID, v Value) {
+		for _, vb := range t.VarBinds {
+			if vb.OID.Equal(oid) {
+				return
+			}
+		}
+		vbs = append(vbs, VarBind{OID: oid.Append(), Value: v})
+	}
+	addr := t.AgentAddress.To4()
+	if addr == nil {
+		addr = net.IPv4zero.To4()
+	}
+	appendMissing(snmpTrapAddressInstance, NewValue(TypeIpAddress, []byte(addr)))
+	if t.Community != "" {
+		appendMissing(snmpTrapCommunityInstance, NewValue(TypeOctetString, []byte(t.Community)))
+	}
+	appendMissing(snmpTrapEnterpriseInstance, NewValue(TypeObjectIdentifier, t.Enterprise.Append()))
+	return vbs
+}
+
+// TrapV2ToV1 converts the varbind list of an SNMPv2-Trap or InformRequest
+// to an SNMPv1 trap (RFC 3584 section 3.2). The standard traps below
+// snmpTraps become generic traps with the enterprise taken from
+// snmpTrapEnterprise.0, or snmpTraps when absent. Other notifications
+// become enterpriseSpecific traps: the last sub-identifier of snmpTrapOID
+// is the specific-trap and the rest, less a trailing 0, the enterprise.
+// The agent address comes from snmpTrapAddress.0 (0.0.0.0 when absent).
+// Counter64 varbinds, which SNMPv1 cannot carry, are dropped, as are the
+// RFC 3584 varbinds consumed by the conversion.
+func TrapV2ToV1(vbs []VarBind) (*TrapV1, error) {
+	t := &TrapV1{AgentAddress: net.IPv4zero.To4()}
+	var trapOID, enterprise OID
+	for _, vb := range vbs {
+		switch {
+		case vb.OID.Equal(sysUpTimeInstance):
+			ticks, ok := valueUint(vb.Value.Data())
+			if !ok || ticks > 1<<32-1 {
+				return nil, fmt.Errorf("trap: sysUpTime.0 is %v, not a TimeTicks value", vb.Value.Data())
+			}
+			t.Timestamp = uint32(ticks)
+		case vb.OID.Equal(snmpTrapOIDInstance):
+			oid, ok := valueOID(vb.Value.Data())
+			if !ok || len(oid) < 2 {
+				return nil, fmt.Errorf("trap: snmpTrapOID.0 is %v, not an OBJECT IDENTIFIER", vb.Value.Data())
+			}
+			trapOID = oid
+		case vb.OID.Equal(snmpTrapEnterpriseInstance):
+			oid, ok := valueOID(vb.Value.Data())
+			if !ok {
+				return nil, fmt.Errorf("trap: snmpTrapEnterprise.0 is %v, not an OBJECT IDENTIFIER", vb.Value.Data())
+			}
+			enterprise = oid
+		case vb.OID.Equal(snmpTrapAddressInstance):
+			addr, ok := valueBytes(vb.Value.Data())
+			if !ok || len(addr) != 4 {
+				return nil, fmt.Errorf("trap: snmpTrapAddress.0 is %v, not an IpAddress", vb.Value.Data())
+			}
+			t.AgentAddress = net.IP(append([]byte(nil), addr...))
+		case vb.OID.Equal(snmpTrapCommunityInstance):
+			community, _ := valueBytes(vb.Value.Data())
+			t.Community = string(community)
+		case vb.Value.Type() == TypeCounter64:
+		default:
+			t.VarBinds = append(t.VarBinds, vb)
+		}
+	}
+	if trapOID == nil {
+		return nil, errors.New("trap: varbind list has no snmpTrapOID.0")
+	}
+	last := trapOID[len(trapOID)-1]
+	switch {
+	case len(trapOID) == len(snmpTraps)+1 && trapOID.HasPrefix(snmpTraps) &&
+		last >= 1 && last <= GenericEnterpriseSpecific:
+		t.GenericTrap = last - 1
+		t.Enterprise = enterprise
+		if t.Enterprise == nil {
+			t.Enterprise = snmpTraps.Append()
+		}
+	default:
+		t.GenericTrap = GenericEnterpriseSpecific
+		t.SpecificTrap = last
+		t.Enterprise = trapOID[:len(trapOID)-1]
+		if n := len(t.Enterprise); n > 1 && t.Enterprise[n-1] == 0 {
+			t.Enterprise = t.Enterprise[:n-1]
+		}
+		t.Enterprise = t.Enterprise.Append()
+	}
+	return t, nil
+}
+
+// DecodeTrapV1 decodes an SNMPv1 trap through the NOTIFICATION-TYPE or
+// TRAP-TYPE that defines it, as DecodeNotification does for SNMPv2 ones.
+func (r *Registry) DecodeTrapV1(t *TrapV1) (*Notification, error) {
+	return r.DecodeNotification(TrapV1ToV2(t))
+}
@@ -1,16 +1,37 @@
 package mib_parser
 
 import (
-	"fmt"
-	"strings"
+	"regexp"
+	"time"
 )
 
+// Object is any OID-bearing definition: a plain OBJECT IDENTIFIER, a
+// MODULE-IDENTITY, OBJECT-IDENTITY, OBJECT-TYPE, NOTIFICATION-TYPE or
+// TRAP-TYPE, an OBJECT-GROUP or NOTIFICATION-GROUP, a MODULE-COMPLIANCE or
+// an AGENT-CAPABILITIES statement.
 type Object interface {
 	// OIDString returns the dotted string representation of the object's OID
 	// (e.g., "1.3.6.1.2.1").
 	OIDString() string
-	// OIDSlice returns the numeric OID as a slice of integers.
-	OIDSlice() []int
+	// OIDSlice returns the numeric OID.
+	OIDSlice() OID
+	// ObjectName returns the definition's descriptor.
+	ObjectName() string
+	// ObjectModule returns the name of the defining module; empty for a
+	// definition that is not part of a parsed, built, loaded or registered
+	// module.
+	ObjectModule() string
+	// ObjectKind returns the macro the object is defined with.
+	ObjectKind() Kind
+	// ObjectStatus returns the normalised STATUS clause; StatusUnknown for
+	// definitions without one (OBJECT IDENTIFIER and MODULE-IDENTITY).
+	ObjectStatus() Status
+	// ObjectDescription returns the DESCRIPTION text; empty for a plain
+	// OBJECT IDENTIFIER.
+	ObjectDescription() string
+	// ObjectPos returns where the definition's name appears in the source;
+	// zero for definitions that were not parsed.
+	ObjectPos() Position
 }
 
 // Module represents a parsed SMIv2 MIB module.
@@ -19,6 +40,12 @@ type Object interface {
 type Module struct {
 	// Name is the ASN.1 module identifier (symbolic name) from the DEFINITIONS header.
 	Name string
+	// Imports lists the IMPORTS clause, one entry per "FROM <module>" group,
+	// in source order.
+	Imports []Import
+	// ObjectIdentifiers contains plain OBJECT IDENTIFIER value assignments
+	// (e.g., "interfaces OBJECT IDENTIFIER ::= { mib-2 2 }") keyed by name.
+	ObjectIdentifiers map[string]*ObjectIdentifier
 	// ObjectsByName contains all parsed OBJECT-TYPE definitions in the module,
 	// keyed by their symbolic name.
 	ObjectsByName map[string]*ObjectType
@@ -34,6 +61,29 @@ type Module struct {
 	// NotificationTypes contains parsed NOTIFICATION-TYPE definitions
 	// keyed by name.
 	NotificationTypes map[string]*NotificationType
+	// ObjectGroups contains OBJECT-GROUP definitions keyed by name.
+	ObjectGroups map[string]*ObjectGroup
+	// NotificationGroups contains NOTIFICATION-GROUP definitions keyed by name.
+	NotificationGroups map[string]*NotificationGroup
+	// ModuleCompliances contains MODULE-COMPLIANCE definitions keyed by name.
+	ModuleCompliances map[string]*ModuleCompliance
+	// AgentCapabilities contains AGENT-CAPABILITIES definitions keyed by name.
+	AgentCapabilities map[string]*AgentCapabilities
+	// Types lists, in source order, the type assignments other than textual
+	// conventions (e.g., "IfEntry ::= SEQUENCE { ... }" or SNMPv2-SMI's
+	// "Integer32 ::= INTEGER (...)"). Their source text is in DefinitionText.
+	Types []string
+	// Macros lists, in source order, the MACRO definitions (e.g., SNMPv2-SMI's
+	// OBJECT-TYPE). Their source text is in DefinitionText.
+	Macros []string
+	// DefinitionText holds the source text of the parsed entries of Types and
+	// Macros, keyed by name, for FormatMIB to write back unchanged.
+	DefinitionText map[string]string
+	// MacroReferences lists the identifiers used inside the MACRO bodies,
+	// once each, in source order. They include the macros' own notation
+	// keywords; imported types such as ObjectName appear here when only a
+	// MACRO uses them.
+	MacroReferences []string
 }
 
 // API helpers to explore and construct requests
@@ -47,12 +97,12 @@ func (m *Module) GetObjectByName(name string) (*ObjectType, bool) {
 
 // GetObjectByOID returns the OBJECT-TYPE whose fully resolved OID matches
 // the provided numeric OID slice exactly.
-func (m *Module) GetObjectByOID(oid []int) (*ObjectType, bool) {
+func (m *Module) GetObjectByOID(oid OID) (*ObjectType, bool) {
 	if m == nil || m.ObjectsByName == nil {
 		return nil, false
 	}
 	for _, obj := range m.ObjectsByName {
-		if oidsEqual(obj.OID, oid) {
+		if obj.OID.Equal(oid) {
 			return obj, true
 		}
 	}
@@ -66,23 +116,54 @@ func (m *Module) GetObjectByOIDString(oid string) (*ObjectType, bool) {
 		return nil, false
 	}
 	for _, obj := range m.ObjectsByName {
-		if oidToString(obj.OID) == oid {
+		if obj.OID.String() == oid {
 			return obj, true
 		}
 	}
 	return nil, false
 }
 
-func oidsEqual(a, b []int) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
-	}
-	return true
+// Import is one "<symbols> FROM <module>" group of a module's IMPORTS clause.
+type Import struct {
+	// Module is the name of the module the symbols are imported from.
+	Module string
+	// Symbols lists the imported descriptors, type names and macro names.
+	Symbols []string
+}
+
+// Position is a 1-based line and column in MIB source text.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// OIDAssignment is the OID value of a definition as written in its
+// "::= { parent n }" clause. It is kept alongside the resolved OID so the
+// definition can be re-emitted symbolically, even when the parent is imported
+// and the numeric OID could not be resolved.
+type OIDAssignment struct {
+	// Parent is the symbolic parent node; empty for fully numeric values such as { 0 0 }.
+	Parent string
+	// SubIDs are the sub-identifiers following Parent.
+	SubIDs []int
+}
+
+// ObjectIdentifier represents a plain OBJECT IDENTIFIER value assignment
+// (a named OID node without further metadata).
+// It implements the Object interface.
+type ObjectIdentifier struct {
+	// Name is the node's symbolic identifier.
+	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
+	// OID is the numeric OID for this node.
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
+
+	// module is the defining module's name, set by Module.bind.
+	module string
 }
 
 // ObjectType represents an SMIv2 OBJECT-TYPE definition with its resolved OID.
@@ -90,20 +171,38 @@ func oidsEqual(a, b []int) bool {
 type ObjectType struct {
 	// Name is the OBJECT-TYPE's symbolic identifier.
 	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
 	// OID is the fully resolved numeric OID for this object (e.g., 1.3.6.1.2.1.2.2.1.1).
-	OID []int
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
 	// Syntax is the declared SYNTAX for the object (e.g., INTEGER, Counter32, Gauge32, OCTET STRING).
 	// Any constraints (e.g., SIZE or ranges) are preserved in string form.
 	Syntax string
+	// Units is the UNITS text, when present.
+	Units string
 	// Access contains ACCESS or MAX-ACCESS from the definition (e.g., read-only, read-write).
 	Access string
 	// Status is the object's status (e.g., current, deprecated, obsolete).
 	Status string
 	// Description is the human-readable DESCRIPTION text.
 	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
 	// Index lists the index objects for tabular objects (INDEX clause).
-	// May include SMIv2 IMPLIED semantics; entries are symbolic names as written in the MIB.
+	// Entries are symbolic names as written in the MIB.
 	Index []string
+	// Implied reports whether the last Index entry carries the SMIv2 IMPLIED keyword.
+	Implied bool
+	// Augments is the base conceptual row named in an AUGMENTS clause, when present.
+	Augments string
+	// DefVal is the DEFVAL value without its enclosing braces, when present.
+	DefVal string
+
+	// module is the defining module's name, set by Module.bind.
+	module string
 }
 
 // ModuleIdentity represents the SMIv2 MODULE-IDENTITY statement.
@@ -112,8 +211,13 @@ type ObjectType struct {
 type ModuleIdentity struct {
 	// Name is the module identity's symbolic identifier.
 	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
 	// OID is the module identity's numeric OID.
-	OID []int
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
 	// LastUpdated is the LAST-UPDATED timestamp string (per RFC 2578 format).
 	LastUpdated string
 	// Organization is the ORGANIZATION text.
@@ -122,6 +226,36 @@ type ModuleIdentity struct {
 	ContactInfo string
 	// Description is the DESCRIPTION text summarizing the module.
 	Description string
+	// Revisions lists the REVISION clauses in source order (newest first by convention).
+	Revisions []Revision
+
+	// module is the defining module's name, set by Module.bind.
+	module string
+}
+
+// Revision is one REVISION clause of a MODULE-IDENTITY.
+type Revision struct {
+	// Date is the revision timestamp string (same format as LAST-UPDATED).
+	Date string
+	// Description is the DESCRIPTION text of the revision.
+	Description string
+}
+
+var dateRe = regexp.MustCompile(`^([0-9]{2}|[0-9]{4})[0-9]{8}Z$`)
+
+// ValidDate reports whether s is a valid LAST-UPDATED or REVISION date:
+// "YYYYMMDDHHMMZ", or "YYMMDDHHMMZ" for years in the 1900s (RFC 2578
+// section 2), naming a day and time that exist.
+func ValidDate(s string) bool {
+	if !dateRe.MatchString(s) {
+		return false
+	}
+	layout := "200601021504Z"
+	if len(s) == len("YYMMDDHHMMZ") {
+		layout = "0601021504Z"
+	}
+	_, err := time.Parse(layout, s)
+	return err == nil
 }
 
 // ObjectIdentity represents the SMIv2 OBJECT-IDENTITY statement (a named OID).
@@ -129,12 +263,22 @@ type ModuleIdentity struct {
 type ObjectIdentity struct {
 	// Name is the object's symbolic identifier.
 	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
 	// OID is the numeric OID for this identity node.
-	OID []int
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
 	// Status is the identity's status (e.g., current, deprecated, obsolete).
 	Status string
 	// Description is the human-readable DESCRIPTION text.
 	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
+
+	// module is the defining module's name, set by Module.bind.
+	module string
 }
 
 // TextualConvention represents the SMIv2 TEXTUAL-CONVENTION statement
@@ -142,75 +286,303 @@ type ObjectIdentity struct {
 type TextualConvention struct {
 	// Name is the convention's symbolic identifier.
 	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
 	// DisplayHint is the DISPLAY-HINT string, when present.
 	DisplayHint string
 	// Status is the convention's status (e.g., current, deprecated, obsolete).
 	Status string
 	// Description is the human-readable DESCRIPTION text.
 	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
 	// Syntax is the underlying base SYNTAX (e.g., OCTET STRING (SIZE(1..32))).
 	Syntax string
 }
 
 // NotificationType represents the SMIv2 NOTIFICATION-TYPE statement.
+// SMIv1 TRAP-TYPE definitions are represented as their RFC 3584
+// equivalent: the OID is { enterprise 0 trap-number } and VARIABLES
+// become Objects.
 // It implements the Object interface.
 type NotificationType struct {
 	// Name is the notification's symbolic identifier.
 	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
 	// OID is the notification's numeric OID.
-	OID []int
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
 	// Objects lists the object names included in the notification payload (OBJECTS clause).
 	Objects []string
 	// Status is the notification's status (e.g., current, deprecated, obsolete).
 	Status string
 	// Description is the human-readable DESCRIPTION text.
 	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
+	// TrapType reports that the notification was defined with SMIv1 TRAP-TYPE.
+	TrapType bool
+
+	// module is the defining module's name, set by Module.bind.
+	module string
+}
+
+// ObjectGroup represents the SMIv2 OBJECT-GROUP statement (RFC 2580).
+// It implements the Object interface.
+type ObjectGroup struct {
+	// Name is the group's symbolic identifier.
+	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
+	// OID is the group's numeric OID.
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
+	// Objects lists the member objects (OBJECTS clause).
+	Objects []string
+	// Status is the group's status (e.g., current, deprecated, obsolete).
+	Status string
+	// Description is the human-readable DESCRIPTION text.
+	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
+
+	// module is the defining module's name, set by Module.bind.
+	module string
+}
+
+// NotificationGroup represents the SMIv2 NOTIFICATION-GROUP statement (RFC 2580).
+// It implements the Object interface.
+type NotificationGroup struct {
+	// Name is the group's symbolic identifier.
+	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
+	// OID is the group's numeric OID.
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
+	// Notifications lists the member notifications (NOTIFICATIONS clause).
+	Notifications []string
+	// Status is the group's status (e.g., current, deprecated, obsolete).
+	Status string
+	// Description is the human-readable DESCRIPTION text.
+	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
+
+	// module is the defining module's name, set by Module.bind.
+	module string
+}
+
+// ModuleCompliance represents the SMIv2 MODULE-COMPLIANCE statement (RFC 2580).
+// It implements the Object interface.
+type ModuleCompliance struct {
+	// Name is the compliance statement's symbolic identifier.
+	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
+	// OID is the compliance statement's numeric OID.
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
+	// Status is the statement's status (e.g., current, deprecated, obsolete).
+	Status string
+	// Description is the human-readable DESCRIPTION text.
+	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
+	// Modules lists the MODULE clauses in source order.
+	Modules []ComplianceModule
+
+	// module is the defining module's name, set by Module.bind.
+	module string
+}
+
+// ComplianceModule is one MODULE clause of a MODULE-COMPLIANCE statement.
+type ComplianceModule struct {
+	// Module is the name of the module the requirements apply to; empty
+	// means the module containing the compliance statement.
+	Module string
+	// MandatoryGroups lists the MANDATORY-GROUPS clause.
+	MandatoryGroups []string
+	// Groups lists the conditionally required groups (GROUP clauses).
+	Groups []ComplianceGroup
+	// Objects lists the object refinements (OBJECT clauses).
+	Objects []ComplianceObject
+}
+
+// ComplianceGroup is a GROUP clause of a MODULE-COMPLIANCE statement.
+type ComplianceGroup struct {
+	// Name is the group's symbolic identifier.
+	Name string
+	// Description states when the group is required.
+	Description string
+}
+
+// ComplianceObject is an OBJECT refinement clause of a MODULE-COMPLIANCE statement.
+type ComplianceObject struct {
+	// Name is the refined object's symbolic identifier.
+	Name string
+	// Syntax is the refined SYNTAX, when present.
+	Syntax string
+	// WriteSyntax is the refined WRITE-SYNTAX, when present.
+	WriteSyntax string
+	// MinAccess is the MIN-ACCESS value, when present.
+	MinAccess string
+	// Description is the human-readable DESCRIPTION text.
+	Description string
+}
+
+// AgentCapabilities represents the SMIv2 AGENT-CAPABILITIES statement (RFC 2580).
+// It implements the Object interface.
+type AgentCapabilities struct {
+	// Name is the capabilities statement's symbolic identifier.
+	Name string
+	// Pos is where the definition's name appears in the source; zero for
+	// definitions that were not parsed.
+	Pos Position
+	// OID is the capabilities statement's numeric OID.
+	OID OID
+	// Assignment is the OID value as written in the MIB.
+	Assignment OIDAssignment
+	// ProductRelease is the PRODUCT-RELEASE text.
+	ProductRelease string
+	// Status is the statement's status (current or obsolete).
+	Status string
+	// Description is the human-readable DESCRIPTION text.
+	Description string
+	// Reference is the REFERENCE text, when present.
+	Reference string
+	// Supports lists the SUPPORTS clauses in source order.
+	Supports []CapabilitiesModule
+
+	// module is the defining module's name, set by Module.bind.
+	module string
+}
+
+// CapabilitiesModule is one SUPPORTS clause of an AGENT-CAPABILITIES statement.
+type CapabilitiesModule struct {
+	// Module is the name of the supported module.
+	Module string
+	// Includes lists the supported groups (INCLUDES clause).
+	Includes []string
+	// Variations lists the VARIATION clauses.
+	Variations []Variation
+}
+
+// Variation is a VARIATION clause of an AGENT-CAPABILITIES statement,
+// describing how an implementation differs from an object or notification.
+type Variation struct {
+	// Name is the object's or notification's symbolic identifier.
+	Name string
+	// Syntax is the implemented SYNTAX, when refined.
+	Syntax string
+	// WriteSyntax is the implemented WRITE-SYNTAX, when refined.
+	WriteSyntax string
+	// Access is the implemented ACCESS, when refined.
+	Access string
+	// CreationRequires lists the columns needed to create a row (CREATION-REQUIRES).
+	CreationRequires []string
+	// DefVal is the implemented DEFVAL value without its braces, when present.
+	DefVal string
+	// Description is the human-readable DESCRIPTION text.
+	Description string
+}
+
+// OIDSlice returns the numeric OID for the OBJECT IDENTIFIER node.
+func (o *ObjectIdentifier) OIDSlice() OID {
+	return o.OID
+}
+
+// OIDString returns the dotted string form of the OBJECT IDENTIFIER node's OID.
+func (o *ObjectIdentifier) OIDString() string {
+	return o.OID.String()
 }
 
 // OIDSlice returns the numeric OID for the OBJECT-TYPE.
-func (o *ObjectType) OIDSlice() []int {
+func (o *ObjectType) OIDSlice() OID {
 	return o.OID
 }
 
 // OIDString returns the dotted string form of the OBJECT-TYPE's OID.
 func (o *ObjectType) OIDString() string {
-	return oidToString(o.OID)
+	return o.OID.String()
 }
 
 // OIDSlice returns the numeric OID for the OBJECT-IDENTITY.
-func (o *ObjectIdentity) OIDSlice() []int {
+func (o *ObjectIdentity) OIDSlice() OID {
 	return o.OID
 }
 
 // OIDString returns the dotted string form of the OBJECT-IDENTITY's OID.
 func (o *ObjectIdentity) OIDString() string {
-	return oidToString(o.OID)
+	return o.OID.String()
 }
 
 // OIDSlice returns the numeric OID for the MODULE-IDENTITY.
-func (o *ModuleIdentity) OIDSlice() []int {
+func (o *ModuleIdentity) OIDSlice() OID {
 	return o.OID
 }
 
 // OIDString returns the dotted string form of the MODULE-IDENTITY's OID.
 func (o *ModuleIdentity) OIDString() string {
-	return oidToString(o.OID)
+	return o.OID.String()
 }
 
 // OIDSlice returns the numeric OID for the NOTIFICATION-TYPE.
-func (o *NotificationType) OIDSlice() []int {
+func (o *NotificationType) OIDSlice() OID {
 	return o.OID
 }
 
 // OIDString returns the dotted string form of the NOTIFICATION-TYPE's OID.
 func (o *NotificationType) OIDString() string {
-	return oidToString(o.OID)
+	return o.OID.String()
 }
 
-func oidToString(oid []int) string {
-	strs := []string{}
-	for _, n := range oid {
-		strs = append(strs, fmt.Sprintf("%d", n))
-	}
-	return strings.Join(strs, ".")
+// OIDSlice returns the numeric OID for the OBJECT-GROUP.
+func (o *ObjectGroup) OIDSlice() OID {
+	return o.OID
+}
+
+// OIDString returns the dotted string form of the OBJECT-GROUP's OID.
+func (o *ObjectGroup) OIDString() string {
+	return o.OID.String()
+}
+
+// OIDSlice returns the numeric OID for the NOTIFICATION-GROUP.
+func (o *NotificationGroup) OIDSlice() OID {
+	return o.OID
+}
+
+// OIDString returns the dotted string form of the NOTIFICATION-GROUP's OID.
+func (o *NotificationGroup) OIDString() string {
+	return o.OID.String()
+}
+
+// OIDSlice returns the numeric OID for the AGENT-CAPABILITIES.
+func (o *AgentCapabilities) OIDSlice() OID {
+	return o.OID
+}
+
+// OIDString returns the dotted string form of the AGENT-CAPABILITIES's OID.
+func (o *AgentCapabilities) OIDString() string {
+	return o.OID.String()
+}
+
+// OIDSlice returns the numeric OID for the MODULE-COMPLIANCE.
+func (o *ModuleCompliance) OIDSlice() OID {
+	return o.OID
+}
+
+// OIDString returns the dotted string form of the MODULE-COMPLIANCE's OID.
+func (o *ModuleCompliance) OIDString() string {
+	return o.OID.String()
 }
@@ -0,0 +1,175 @@
+package mib_parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Olian04/go-mib-parser/displayhint"
+)
+
+// wireTypes maps SMI base types to the type their values are sent as.
+var wireTypes = map[string]ValueType{
+	"INTEGER":           TypeInteger,
+	"Integer32":         TypeInteger,
+	"Unsigned32":        TypeGauge32,
+	"Gauge32":           TypeGauge32,
+	"Gauge":             TypeGauge32,
+	"Counter32":         TypeCounter32,
+	"Counter":           TypeCounter32,
+	"Counter64":         TypeCounter64,
+	"TimeTicks":         TypeTimeTicks,
+	"OCTET STRING":      TypeOctetString,
+	"BITS":              TypeOctetString,
+	"OBJECT IDENTIFIER": TypeObjectIdentifier,
+	"IpAddress":         TypeIpAddress,
+	"NetworkAddress":    TypeIpAddress,
+	"Opaque":            TypeOpaque,
+}
+
+// implicitRanges are the value ranges the SMI base types impose on their own.
+var implicitRanges = map[string][]Range{
+	"INTEGER":    {{Min: math.MinInt32, Max: math.MaxInt32}},
+	"Integer32":  {{Min: math.MinInt32, Max: math.MaxInt32}},
+	"Unsigned32": {{Min: 0, Max: math.MaxUint32}},
+	"Gauge32":    {{Min: 0, Max: math.MaxUint32}},
+	"Gauge":      {{Min: 0, Max: math.MaxUint32}},
+	"Counter32":  {{Min: 0, Max: math.MaxUint32}},
+	"Counter":    {{Min: 0, Max: math.MaxUint32}},
+	"TimeTicks":  {{Min: 0, Max: math.MaxUint32}},
+}
+
+// SetError is the error ValidateSet reports. Status names the RFC 3416
+// error-status an agent answers the SET with, e.g. "wrongType",
+// "wrongLength", "wrongValue", "notWritable" or "noCreation".
+type SetError struct {
+	Status string
+	msg    string
+}
+
+func (e *SetError) Error() string { return e.msg }
+
+func setError(status, format string, args ...any) error {
+	return &SetError{Status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+// ValidateSetVarBind finds the object that oid is an instance of and
+// validates v against it with ValidateSet.
+func (r *Registry) ValidateSetVarBind(oid OID, v Value) error {
+	sym, _, ok := r.SymbolAt(oid)
+	if !ok {
+		return setError("noCreation", "no object is known at %s", oid)
+	}
+	m, ok := r.modules[sym.Module]
+	if !ok || m.ObjectsByName[sym.Name] == nil {
+		return setError("noCreation", "%s is not an object", sym)
+	}
+	return r.ValidateSet(m, m.ObjectsByName[sym.Name], v)
+}
+
+// ValidateSet checks locally whether v may be written to object o, defined
+// in module m, before a SET is sent: the object must be read-write or
+// read-create, and v must have the object's base type and satisfy its
+// range, SIZE, enumeration and BITS constraints, including those inherited
+// from textual conventions. Failures are reported as a *SetError.
+func (r *Registry) ValidateSet(m *Module, o *ObjectType, v Value) error {
+	switch o.Access {
+	case "read-write", "read-create", "write-only":
+	default:
+		return setError("notWritable", "%s is not writable (MAX-ACCESS %s)", o.Name, o.Access)
+	}
+	return r.ValidateValue(m, o, v)
+}
+
+// ValidateValue checks whether v is a valid value of object o, defined in
+// module m, whatever the object's MAX-ACCESS: the checks of ValidateSet
+// without the one for write access.
+func (r *Registry) ValidateValue(m *Module, o *ObjectType, v Value) error {
+	syn := r.ResolveSyntax(m, o.Syntax)
+	want, known := syn.ValueType()
+	if known && v.Type() != want {
+		return setError("wrongType", "%s expects %s, got %s", o.Name, want, v.Type())
+	}
+	switch v.Type() {
+	case TypeInteger, TypeGauge32, TypeCounter32, TypeTimeTicks:
+		n, ok := valueInt(v.Data())
+		if !ok {
+			return setError("wrongType", "value %v is not a valid %s for %s", v.Data(), v.Type(), o.Name)
+		}
+		if len(syn.NamedNumbers) > 0 {
+			if _, ok := (Syntax{NamedNumbers: syn.NamedNumbers}).Enum(n); !ok {
+				return setError("wrongValue", "value %d not in enumeration %s for %s", n, formatNamedNumbers(syn.NamedNumbers), o.Name)
+			}
+			return nil
+		}
+		ranges := syn.Ranges
+		if ranges == nil {
+			ranges = implicitRanges[syn.Base]
+		}
+		if ranges == nil && v.Type() != TypeInteger {
+			ranges = implicitRanges[v.Type().String()]
+		}
+		if len(ranges) > 0 && !inRanges(ranges, n) {
+			return setError("wrongValue", "value %d not in range (%s) for %s", n, formatRanges(ranges), o.Name)
+		}
+	case TypeCounter64:
+		if _, ok := valueUint(v.Data()); !ok {
+			return setError("wrongType", "value %v is not a valid %s for %s", v.Data(), v.Type(), o.Name)
+		}
+	case TypeOctetString, TypeOpaque:
+		b, ok := valueBytes(v.Data())
+		if !ok {
+			return setError("wrongType", "value %v is not a valid %s for %s", v.Data(), v.Type(), o.Name)
+		}
+		if len(syn.Sizes) > 0 && !inRanges(syn.Sizes, int64(len(b))) {
+			return setError("wrongLength", "length %d not in SIZE (%s) for %s", len(b), formatRanges(syn.Sizes), o.Name)
+		}
+		if syn.Base == "BITS" {
+			for bit := 0; bit < 8*len(b); bit++ {
+				if b[bit/8]&(0x80>>(bit%8)) == 0 {
+					continue
+				}
+				if _, ok := (Syntax{NamedNumbers: syn.NamedNumbers}).Enum(int64(bit)); !ok {
+					return setError("wrongValue", "bit %d not in BITS %s for %s", bit, formatNamedNumbers(syn.NamedNumbers), o.Name)
+				}
+			}
+			return nil
+		}
+		if hint := syn.DisplayHint(); hint != "" {
+			if _, err := displayhint.FormatOctets(hint, b); err != nil {
+				return setError("wrongValue", "value %q does not match DISPLAY-HINT %q for %s", b, hint, o.Name)
+			}
+		}
+	case TypeIpAddress:
+		if b, ok := valueBytes(v.Data()); !ok || len(b) != 4 {
+			return setError("wrongType", "value %v is not a valid IpAddress for %s", v.Data(), o.Name)
+		}
+	case TypeObjectIdentifier:
+		oid, ok := valueOID(v.Data())
+		if !ok || len(oid) < 2 || len(oid) > 128 {
+			return setError("wrongType", "value %v is not a valid OBJECT IDENTIFIER for %s", v.Data(), o.Name)
+		}
+	default:
+		return setError("wrongType", "%s cannot be set to %s", o.Name, v.Type())
+	}
+	return nil
+}
+
+func inRanges(ranges []Range, n int64) bool {
+	for _, r := range ranges {
+		if n >= r.Min && n <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// formatNamedNumbers renders an enumeration as "{up(1), down(2)}".
+func formatNamedNumbers(names []NamedNumber) string {
+	items := make([]string, len(names))
+	for i, n := range names {
+		items[i] = n.Name + "(" + strconv.FormatInt(n.Value, 10) + ")"
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
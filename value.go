@@ -0,0 +1,286 @@
+package mib_parser
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Olian04/go-mib-parser/displayhint"
+)
+
+// ValueType is the SNMP wire type of a varbind value.
+type ValueType int
+
+const (
+	TypeNull ValueType = iota
+	TypeInteger
+	TypeOctetString
+	TypeObjectIdentifier
+	TypeIpAddress
+	TypeCounter32
+	TypeGauge32
+	TypeTimeTicks
+	TypeOpaque
+	TypeCounter64
+	TypeNoSuchObject
+	TypeNoSuchInstance
+	TypeEndOfMibView
+)
+
+var valueTypeNames = [...]string{
+	TypeNull:             "NULL",
+	TypeInteger:          "INTEGER",
+	TypeOctetString:      "OCTET STRING",
+	TypeObjectIdentifier: "OBJECT IDENTIFIER",
+	TypeIpAddress:        "IpAddress",
+	TypeCounter32:        "Counter32",
+	TypeGauge32:          "Gauge32",
+	TypeTimeTicks:        "TimeTicks",
+	TypeOpaque:           "Opaque",
+	TypeCounter64:        "Counter64",
+	TypeNoSuchObject:     "noSuchObject",
+	TypeNoSuchInstance:   "noSuchInstance",
+	TypeEndOfMibView:     "endOfMibView",
+}
+
+func (t ValueType) String() string {
+	if t >= 0 && int(t) < len(valueTypeNames) {
+		return valueTypeNames[t]
+	}
+	return "ValueType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Value is a raw varbind value as received from an agent. It is kept small
+// so that the values of any SNMP client library can be adapted to it;
+// NewValue builds one directly.
+//
+// Data holds an integer type (int64, uint32, ...) for INTEGER, Counter32,
+// Gauge32, TimeTicks and Counter64, a []byte or string for OCTET STRING
+// and Opaque, a []byte or net.IP for IpAddress, an OID, []int or dotted
+// string for OBJECT IDENTIFIER, and nil for NULL and the exceptions.
+type Value interface {
+	Type() ValueType
+	Data() any
+}
+
+// NewValue returns a Value of type t holding data.
+func NewValue(t ValueType, data any) Value {
+	return value{t, data}
+}
+
+type value struct {
+	typ  ValueType
+	data any
+}
+
+func (v value) Type() ValueType { return v.typ }
+func (v value) Data() any       { return v.data }
+
+// RenderVarBind renders the value of the instance oid through the object
+// it belongs to. Values of unknown objects are rendered by type alone.
+func (r *Registry) RenderVarBind(oid OID, v Value) string {
+	if sym, _, ok := r.SymbolAt(oid); ok {
+		if m, ok := r.modules[sym.Module]; ok {
+			if o, ok := m.ObjectsByName[sym.Name]; ok {
+				return r.RenderValue(m, o, v)
+			}
+		}
+	}
+	return r.renderValue(ResolvedSyntax{}, "", v)
+}
+
+// RenderValue renders v, a value of object o defined in module m, the way
+// an operator expects to read it: enumerations as "up(1)", BITS as
+// "{ linkUp(0), linkDown(2) }", octet strings and integers through the
+// DISPLAY-HINT of their textual convention, TimeTicks as a duration,
+// OBJECT IDENTIFIERs as qualified names, and numbers followed by the
+// object's UNITS.
+func (r *Registry) RenderValue(m *Module, o *ObjectType, v Value) string {
+	return r.renderValue(r.ResolveSyntax(m, o.Syntax), o.Units, v)
+}
+
+func (r *Registry) renderValue(syn ResolvedSyntax, units string, v Value) string {
+	withUnits := func(s string) string {
+		if units == "" {
+			return s
+		}
+		return s + " " + units
+	}
+	switch v.Type() {
+	case TypeInteger, TypeCounter32, TypeGauge32, TypeCounter64:
+		if n, ok := valueInt(v.Data()); ok {
+			if syn.Base != "BITS" && len(syn.NamedNumbers) > 0 {
+				return renderEnum(syn.NamedNumbers, n)
+			}
+			if hint := syn.DisplayHint(); hint != "" {
+				if s, err := displayhint.FormatInteger(hint, n); err == nil {
+					return withUnits(s)
+				}
+			}
+			return withUnits(strconv.FormatInt(n, 10))
+		}
+		if n, ok := valueUint(v.Data()); ok {
+			return withUnits(strconv.FormatUint(n, 10))
+		}
+	case TypeTimeTicks:
+		if n, ok := valueUint(v.Data()); ok {
+			return renderTimeTicks(n)
+		}
+	case TypeOctetString, TypeOpaque:
+		if b, ok := valueBytes(v.Data()); ok {
+			if syn.Base == "BITS" {
+				return renderBits(syn.NamedNumbers, b)
+			}
+			if hint := syn.DisplayHint(); hint != "" {
+				if s, err := displayhint.FormatOctets(hint, b); err == nil {
+					return s
+				}
+			}
+			return renderOctets(b)
+		}
+	case TypeIpAddress:
+		if b, ok := valueBytes(v.Data()); ok && len(b) == 4 {
+			return net.IP(b).String()
+		}
+	case TypeObjectIdentifier:
+		if oid, ok := valueOID(v.Data()); ok {
+			return r.FormatOID(oid)
+		}
+	case TypeNull, TypeNoSuchObject, TypeNoSuchInstance, TypeEndOfMibView:
+		return v.Type().String()
+	}
+	return fmt.Sprintf("%v", v.Data())
+}
+
+func renderEnum(names []NamedNumber, n int64) string {
+	for _, nn := range names {
+		if nn.Value == n {
+			return nn.Name + "(" + strconv.FormatInt(n, 10) + ")"
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// renderBits lists the set bits of a BITS value, bit 0 being the most
+// significant bit of the first octet.
+func renderBits(names []NamedNumber, b []byte) string {
+	var set []string
+	for bit := 0; bit < 8*len(b); bit++ {
+		if b[bit/8]&(0x80>>(bit%8)) == 0 {
+			continue
+		}
+		label := strconv.Itoa(bit)
+		for _, nn := range names {
+			if nn.Value == int64(bit) {
+				label = nn.Name + "(" + label + ")"
+				break
+			}
+		}
+		set = append(set, label)
+	}
+	if len(set) == 0 {
+		return "{ }"
+	}
+	return "{ " + strings.Join(set, ", ") + " }"
+}
+
+// renderOctets shows printable UTF-8 text as is and anything else as hex.
+func renderOctets(b []byte) string {
+	text := utf8.Valid(b)
+	for _, r := range string(b) {
+		text = text && (unicode.IsPrint(r) || r == '\t' || r == '\r' || r == '\n')
+	}
+	if text {
+		return string(b)
+	}
+	hex := make([]string, len(b))
+	for i, c := range b {
+		hex[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(hex, " ")
+}
+
+// renderTimeTicks formats hundredths of a second like net-snmp does, e.g.
+// "3 days, 4:05:06.07".
+func renderTimeTicks(ticks uint64) string {
+	days := ticks / 8640000
+	s := fmt.Sprintf("%d:%02d:%02d.%02d", ticks/360000%24, ticks/6000%60, ticks/100%60, ticks%100)
+	switch days {
+	case 0:
+		return s
+	case 1:
+		return "1 day, " + s
+	}
+	return strconv.FormatUint(days, 10) + " days, " + s
+}
+
+func valueInt(data any) (int64, bool) {
+	switch n := data.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	if u, ok := valueUint(data); ok && u <= math.MaxInt64 {
+		return int64(u), true
+	}
+	return 0, false
+}
+
+func valueUint(data any) (uint64, bool) {
+	switch n := data.(type) {
+	case uint:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case int, int8, int16, int32, int64:
+		if i, _ := valueInt(n); i >= 0 {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+func valueBytes(data any) ([]byte, bool) {
+	switch b := data.(type) {
+	case []byte:
+		return b, true
+	case net.IP:
+		if ip4 := b.To4(); ip4 != nil {
+			return ip4, true
+		}
+		return b, true
+	case string:
+		return []byte(b), true
+	}
+	return nil, false
+}
+
+func valueOID(data any) (OID, bool) {
+	switch oid := data.(type) {
+	case OID:
+		return oid, true
+	case []int:
+		return oid, true
+	case string:
+		arcs, err := ParseOID(oid)
+		return arcs, err == nil
+	}
+	return nil, false
+}
@@ -0,0 +1,316 @@
+package walk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// walkLine matches the start of an entry of `snmpwalk -On` output.
+var walkLine = regexp.MustCompile(`^\.?([0-9]+(?:\.[0-9]+)*) = (.*)$`)
+
+// Parse reads a dump in either format, telling them apart by the first
+// entry: snmpwalk lines contain " = ", snmprec lines are "oid|tag|value".
+func Parse(r io.Reader) ([]mib_parser.VarBind, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, " = ") && strings.Count(line, "|") >= 2 {
+			return ParseSnmprec(bytes.NewReader(src))
+		}
+		break
+	}
+	return ParseWalk(bytes.NewReader(src))
+}
+
+// ParseWalk reads the output of `snmpwalk -On`, where every entry starts
+// with a numeric OID, e.g. `.1.3.6.1.2.1.1.3.0 = Timeticks: (12345)
+// 0:02:03.45`. Strings and hex dumps may continue over several lines.
+func ParseWalk(r io.Reader) ([]mib_parser.VarBind, error) {
+	var vbs []mib_parser.VarBind
+	var oid mib_parser.OID
+	var text string
+	var start int
+	flush := func() error {
+		if oid == nil {
+			return nil
+		}
+		v, err := parseWalkValue(text)
+		if err != nil {
+			return fmt.Errorf("walk: line %d: %w", start, err)
+		}
+		vbs = append(vbs, mib_parser.VarBind{OID: oid, Value: v})
+		oid = nil
+		return nil
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if m := walkLine.FindStringSubmatch(line); m != nil {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			var err error
+			if oid, err = mib_parser.ParseOID(m[1]); err != nil {
+				return nil, fmt.Errorf("walk: line %d: %w", n, err)
+			}
+			text, start = m[2], n
+			continue
+		}
+		if oid != nil {
+			text += "\n" + line
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			return nil, fmt.Errorf("walk: line %d: expected a numeric OID, as printed by snmpwalk -On", n)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return vbs, nil
+}
+
+// parseWalkValue parses the text after " = ", e.g. `INTEGER: up(1)`.
+func parseWalkValue(text string) (mib_parser.Value, error) {
+	switch {
+	case text == `""`:
+		return mib_parser.NewValue(mib_parser.TypeOctetString, []byte{}), nil
+	case text == "NULL":
+		return mib_parser.NewValue(mib_parser.TypeNull, nil), nil
+	case strings.HasPrefix(text, "No Such Object"):
+		return mib_parser.NewValue(mib_parser.TypeNoSuchObject, nil), nil
+	case strings.HasPrefix(text, "No Such Instance"):
+		return mib_parser.NewValue(mib_parser.TypeNoSuchInstance, nil), nil
+	case strings.HasPrefix(text, "No more variables"):
+		return mib_parser.NewValue(mib_parser.TypeEndOfMibView, nil), nil
+	}
+	if strings.HasPrefix(text, "Wrong Type") {
+		if _, after, ok := strings.Cut(text, "): "); ok {
+			text = after
+		}
+	}
+	typ, rest, ok := strings.Cut(text, ": ")
+	if !ok {
+		typ, rest = strings.TrimSuffix(text, ":"), ""
+	}
+	switch typ {
+	case "STRING":
+		return mib_parser.NewValue(mib_parser.TypeOctetString, []byte(unquote(rest))), nil
+	case "Hex-STRING", "BITS", "Opaque":
+		b, err := parseHex(rest, typ == "BITS")
+		if err != nil {
+			return nil, err
+		}
+		if typ == "Opaque" {
+			return mib_parser.NewValue(mib_parser.TypeOpaque, b), nil
+		}
+		return mib_parser.NewValue(mib_parser.TypeOctetString, b), nil
+	case "INTEGER":
+		n, err := strconv.ParseInt(leadingNumber(rest), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INTEGER %q", rest)
+		}
+		return mib_parser.NewValue(mib_parser.TypeInteger, n), nil
+	case "Gauge32", "Unsigned32", "Counter32", "Counter64", "Timeticks":
+		num := leadingNumber(rest)
+		if typ == "Timeticks" {
+			num = strings.TrimPrefix(strings.SplitN(rest, ")", 2)[0], "(")
+		}
+		n, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", typ, rest)
+		}
+		return mib_parser.NewValue(walkTypes[typ], n), nil
+	case "OID":
+		oid, err := mib_parser.ParseOID(strings.TrimPrefix(rest, "."))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q; use snmpwalk -On", rest)
+		}
+		return mib_parser.NewValue(mib_parser.TypeObjectIdentifier, oid), nil
+	case "IpAddress":
+		ip := net.ParseIP(rest).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IpAddress %q", rest)
+		}
+		return mib_parser.NewValue(mib_parser.TypeIpAddress, []byte(ip)), nil
+	case "Network Address":
+		b, err := hex.DecodeString(strings.ReplaceAll(rest, ":", ""))
+		if err != nil || len(b) != 4 {
+			return nil, fmt.Errorf("invalid Network Address %q", rest)
+		}
+		return mib_parser.NewValue(mib_parser.TypeIpAddress, b), nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", text)
+}
+
+var walkTypes = map[string]mib_parser.ValueType{
+	"Gauge32":    mib_parser.TypeGauge32,
+	"Unsigned32": mib_parser.TypeGauge32,
+	"Counter32":  mib_parser.TypeCounter32,
+	"Counter64":  mib_parser.TypeCounter64,
+	"Timeticks":  mib_parser.TypeTimeTicks,
+}
+
+// unquote strips the quotes net-snmp puts around strings and undoes its
+// backslash escapes. Unquoted text is returned as is.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parseHex reads space-separated hex octets. For BITS, net-snmp follows
+// the octets with the names of the set bits, which are ignored.
+func parseHex(s string, bits bool) ([]byte, error) {
+	var out []byte
+	for _, field := range strings.Fields(s) {
+		b, err := hex.DecodeString(field)
+		if err != nil || len(b) != 1 {
+			if bits {
+				break
+			}
+			return nil, fmt.Errorf("invalid hex octet %q", field)
+		}
+		out = append(out, b[0])
+	}
+	return out, nil
+}
+
+// leadingNumber returns the number that starts s, dropping what follows
+// it such as the units or enumeration label net-snmp prints: "5 seconds"
+// and "up(1)" give "5" and "1".
+func leadingNumber(s string) string {
+	num, _, _ := strings.Cut(s, " ")
+	if open := strings.LastIndexByte(num, '('); open >= 0 && strings.HasSuffix(num, ")") {
+		return num[open+1 : len(num)-1]
+	}
+	return num
+}
+
+// snmprecTypes are the BER tags snmpsim's snmprec format uses.
+var snmprecTypes = map[string]mib_parser.ValueType{
+	"2":   mib_parser.TypeInteger,
+	"4":   mib_parser.TypeOctetString,
+	"5":   mib_parser.TypeNull,
+	"6":   mib_parser.TypeObjectIdentifier,
+	"64":  mib_parser.TypeIpAddress,
+	"65":  mib_parser.TypeCounter32,
+	"66":  mib_parser.TypeGauge32,
+	"67":  mib_parser.TypeTimeTicks,
+	"68":  mib_parser.TypeOpaque,
+	"70":  mib_parser.TypeCounter64,
+	"128": mib_parser.TypeNoSuchObject,
+	"129": mib_parser.TypeNoSuchInstance,
+	"130": mib_parser.TypeEndOfMibView,
+}
+
+// ParseSnmprec reads snmpsim's snmprec format: one "oid|tag|value" line
+// per varbind, where tag is the BER type and a trailing "x" on the tag
+// marks a hex-encoded value.
+func ParseSnmprec(r io.Reader) ([]mib_parser.VarBind, error) {
+	var vbs []mib_parser.VarBind
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, "|", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("snmprec: line %d: expected oid|tag|value", n)
+		}
+		oid, err := mib_parser.ParseOID(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("snmprec: line %d: invalid OID %q", n, fields[0])
+		}
+		v, err := parseSnmprecValue(fields[1], fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("snmprec: line %d: %w", n, err)
+		}
+		vbs = append(vbs, mib_parser.VarBind{OID: oid, Value: v})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return vbs, nil
+}
+
+func parseSnmprecValue(tag, text string) (mib_parser.Value, error) {
+	if strings.Contains(tag, ":") {
+		return nil, fmt.Errorf("variation module in tag %q is not supported", tag)
+	}
+	tag, isHex := strings.CutSuffix(tag, "x")
+	typ, ok := snmprecTypes[tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown tag %q", tag)
+	}
+	raw := []byte(text)
+	if isHex {
+		b, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value %q", text)
+		}
+		raw = b
+	}
+	switch typ {
+	case mib_parser.TypeOctetString, mib_parser.TypeOpaque:
+		return mib_parser.NewValue(typ, raw), nil
+	case mib_parser.TypeIpAddress:
+		ip := net.ParseIP(string(raw)).To4()
+		if isHex {
+			ip = raw
+		}
+		if len(ip) != 4 {
+			return nil, fmt.Errorf("invalid IpAddress %q", text)
+		}
+		return mib_parser.NewValue(typ, []byte(ip)), nil
+	case mib_parser.TypeObjectIdentifier:
+		oid, err := mib_parser.ParseOID(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", text)
+		}
+		return mib_parser.NewValue(typ, oid), nil
+	case mib_parser.TypeInteger:
+		n, err := strconv.ParseInt(string(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INTEGER %q", text)
+		}
+		return mib_parser.NewValue(typ, n), nil
+	case mib_parser.TypeCounter32, mib_parser.TypeGauge32, mib_parser.TypeTimeTicks, mib_parser.TypeCounter64:
+		n, err := strconv.ParseUint(string(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", typ, text)
+		}
+		return mib_parser.NewValue(typ, n), nil
+	}
+	return mib_parser.NewValue(typ, nil), nil
+}
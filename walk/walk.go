@@ -0,0 +1,173 @@
+// Package walk interprets snmpwalk and snmprec dumps through parsed MIB
+// modules: OIDs are translated to names, table instances are grouped into
+// rows with named columns and values are rendered from their SYNTAX, so a
+// device's state can be read without the device.
+package walk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	mib_parser "github.com/Olian04/go-mib-parser"
+)
+
+// Report is a dump interpreted through a registry.
+type Report struct {
+	// Scalars are the instances of objects outside tables.
+	Scalars []*Entry `json:"scalars"`
+	// Tables groups the instances of columnar objects by conceptual row.
+	Tables []*Table `json:"tables"`
+	// Unknown holds varbinds that are not instances of a loaded
+	// OBJECT-TYPE.
+	Unknown []*Entry `json:"unknown,omitempty"`
+	// entries lists every varbind in dump order.
+	entries []*Entry
+}
+
+// Entry is one varbind with its name and rendered value.
+type Entry struct {
+	// Name is the qualified instance name, e.g. "SNMPv2-MIB::sysUpTime.0".
+	Name string `json:"name"`
+	OID  string `json:"oid"`
+	// Type is the SNMP type of the value, e.g. "Counter32".
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Table is the content of one conceptual table.
+type Table struct {
+	// Name is the table's qualified name, e.g. "IF-MIB::ifTable".
+	Name string `json:"name"`
+	// Index names the INDEX objects, Columns the columns present in the
+	// dump in OID order.
+	Index   []string `json:"index"`
+	Columns []string `json:"columns"`
+	Rows    []*Row   `json:"rows"`
+
+	columns map[string]int
+	rows    map[string]*Row
+}
+
+// Row is one conceptual row.
+type Row struct {
+	// Instance is the numeric instance suffix, e.g. "2" or "1.4.10.0.0.1".
+	Instance string `json:"instance"`
+	// Index holds the rendered value of each INDEX object.
+	Index []string `json:"index"`
+	// Values maps column names to rendered values.
+	Values map[string]string `json:"values"`
+
+	suffix mib_parser.OID
+}
+
+// Interpret translates every varbind of a dump through reg.
+func Interpret(reg *mib_parser.Registry, vbs []mib_parser.VarBind) *Report {
+	rep := &Report{Scalars: []*Entry{}, Tables: []*Table{}}
+	tables := map[*mib_parser.ObjectType]*Table{}
+	for _, vb := range vbs {
+		e := &Entry{
+			Name:  reg.FormatOID(vb.OID),
+			OID:   vb.OID.String(),
+			Type:  vb.Value.Type().String(),
+			Value: reg.RenderVarBind(vb.OID, vb.Value),
+		}
+		rep.entries = append(rep.entries, e)
+		in, ok := reg.Instance(vb.OID)
+		if !ok || in.Object == nil {
+			rep.Unknown = append(rep.Unknown, e)
+			continue
+		}
+		q := reg.QualifiedNameOf(vb.OID)
+		q.Module = in.Symbol.Module
+		e.Name = q.String()
+		if in.Row == nil {
+			rep.Scalars = append(rep.Scalars, e)
+			continue
+		}
+		t, ok := tables[in.Row]
+		if !ok {
+			t = &Table{
+				Name:    in.Module.Name + "::" + in.Row.Assignment.Parent,
+				columns: map[string]int{},
+				rows:    map[string]*Row{},
+			}
+			for _, iv := range in.Index {
+				t.Index = append(t.Index, iv.Object.Name)
+			}
+			tables[in.Row] = t
+			rep.Tables = append(rep.Tables, t)
+		}
+		if _, ok := t.columns[in.Object.Name]; !ok {
+			t.columns[in.Object.Name] = in.Object.OID[len(in.Object.OID)-1]
+			t.Columns = append(t.Columns, in.Object.Name)
+		}
+		instance := in.Suffix.String()
+		row, ok := t.rows[instance]
+		if !ok {
+			row = &Row{Instance: instance, Values: map[string]string{}, suffix: in.Suffix}
+			for _, iv := range in.Index {
+				row.Index = append(row.Index, reg.RenderValue(iv.Module, iv.Object, iv.Value))
+			}
+			t.rows[instance] = row
+			t.Rows = append(t.Rows, row)
+		}
+		row.Values[in.Object.Name] = e.Value
+	}
+	for _, t := range rep.Tables {
+		sort.SliceStable(t.Columns, func(i, j int) bool {
+			return t.columns[t.Columns[i]] < t.columns[t.Columns[j]]
+		})
+		sort.SliceStable(t.Rows, func(i, j int) bool {
+			return t.Rows[i].suffix.Compare(t.Rows[j].suffix) < 0
+		})
+	}
+	return rep
+}
+
+// Table returns the table with the given descriptor or qualified name.
+func (rep *Report) Table(name string) (*Table, bool) {
+	for _, t := range rep.Tables {
+		if t.Name == name || strings.HasSuffix(t.Name, "::"+name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// WriteJSON writes the report as indented JSON.
+func (rep *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// WriteCSV writes every varbind in dump order as name, oid, type and value
+// columns.
+func (rep *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "oid", "type", "value"})
+	for _, e := range rep.entries {
+		cw.Write([]string{e.Name, e.OID, e.Type, e.Value})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes the table with one line per row: the index values
+// followed by the columns. Cells of columns a row lacks are left empty.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append(append([]string(nil), t.Index...), t.Columns...))
+	for _, row := range t.Rows {
+		record := append([]string(nil), row.Index...)
+		for _, col := range t.Columns {
+			record = append(record, row.Values[col])
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
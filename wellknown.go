@@ -0,0 +1,89 @@
+package mib_parser
+
+// wellKnownNodes are the OID nodes defined by SNMPv2-SMI (RFC 2578) that most
+// modules build on. They let OIDs resolve without loading SNMPv2-SMI itself.
+var wellKnownNodes = map[string]OID{
+	"iso":          {1},
+	"org":          {1, 3},
+	"dod":          {1, 3, 6},
+	"internet":     {1, 3, 6, 1},
+	"directory":    {1, 3, 6, 1, 1},
+	"mgmt":         {1, 3, 6, 1, 2},
+	"mib-2":        {1, 3, 6, 1, 2, 1},
+	"transmission": {1, 3, 6, 1, 2, 1, 10},
+	"experimental": {1, 3, 6, 1, 3},
+	"private":      {1, 3, 6, 1, 4},
+	"enterprises":  {1, 3, 6, 1, 4, 1},
+	"security":     {1, 3, 6, 1, 5},
+	"snmpV2":       {1, 3, 6, 1, 6},
+	"snmpDomains":  {1, 3, 6, 1, 6, 1},
+	"snmpProxys":   {1, 3, 6, 1, 6, 2},
+	"snmpModules":  {1, 3, 6, 1, 6, 3},
+	"zeroDotZero":  {0, 0},
+}
+
+// wellKnownSymbols maps the macros, base types, textual conventions and
+// nodes exported by the SMIv2 core modules to the module that defines them.
+var wellKnownSymbols = map[string]string{
+	// SNMPv2-SMI (RFC 2578)
+	"MODULE-IDENTITY":   "SNMPv2-SMI",
+	"OBJECT-IDENTITY":   "SNMPv2-SMI",
+	"OBJECT-TYPE":       "SNMPv2-SMI",
+	"NOTIFICATION-TYPE": "SNMPv2-SMI",
+	"Integer32":         "SNMPv2-SMI",
+	"Unsigned32":        "SNMPv2-SMI",
+	"Counter32":         "SNMPv2-SMI",
+	"Counter64":         "SNMPv2-SMI",
+	"Gauge32":           "SNMPv2-SMI",
+	"TimeTicks":         "SNMPv2-SMI",
+	"IpAddress":         "SNMPv2-SMI",
+	"Opaque":            "SNMPv2-SMI",
+	"iso":               "SNMPv2-SMI",
+	"org":               "SNMPv2-SMI",
+	"dod":               "SNMPv2-SMI",
+	"internet":          "SNMPv2-SMI",
+	"directory":         "SNMPv2-SMI",
+	"mgmt":              "SNMPv2-SMI",
+	"mib-2":             "SNMPv2-SMI",
+	"transmission":      "SNMPv2-SMI",
+	"experimental":      "SNMPv2-SMI",
+	"private":           "SNMPv2-SMI",
+	"enterprises":       "SNMPv2-SMI",
+	"security":          "SNMPv2-SMI",
+	"snmpV2":            "SNMPv2-SMI",
+	"snmpDomains":       "SNMPv2-SMI",
+	"snmpProxys":        "SNMPv2-SMI",
+	"snmpModules":       "SNMPv2-SMI",
+	"zeroDotZero":       "SNMPv2-SMI",
+	// SNMPv2-TC (RFC 2579)
+	"TEXTUAL-CONVENTION": "SNMPv2-TC",
+	"DisplayString":      "SNMPv2-TC",
+	"PhysAddress":        "SNMPv2-TC",
+	"MacAddress":         "SNMPv2-TC",
+	"TruthValue":         "SNMPv2-TC",
+	"TestAndIncr":        "SNMPv2-TC",
+	"AutonomousType":     "SNMPv2-TC",
+	"InstancePointer":    "SNMPv2-TC",
+	"VariablePointer":    "SNMPv2-TC",
+	"RowPointer":         "SNMPv2-TC",
+	"RowStatus":          "SNMPv2-TC",
+	"TimeStamp":          "SNMPv2-TC",
+	"TimeInterval":       "SNMPv2-TC",
+	"DateAndTime":        "SNMPv2-TC",
+	"StorageType":        "SNMPv2-TC",
+	"TDomain":            "SNMPv2-TC",
+	"TAddress":           "SNMPv2-TC",
+	// SNMPv2-CONF (RFC 2580)
+	"MODULE-COMPLIANCE":  "SNMPv2-CONF",
+	"OBJECT-GROUP":       "SNMPv2-CONF",
+	"NOTIFICATION-GROUP": "SNMPv2-CONF",
+	"AGENT-CAPABILITIES": "SNMPv2-CONF",
+}
+
+// builtinTypes are the ASN.1 types usable in SYNTAX clauses without an import.
+var builtinTypes = map[string]bool{
+	"INTEGER":           true,
+	"OCTET STRING":      true,
+	"OBJECT IDENTIFIER": true,
+	"BITS":              true,
+}